@@ -8,6 +8,7 @@ import (
 	stdContext "context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -23,6 +24,7 @@ import (
 	tsuruEnvs "github.com/tsuru/tsuru/envs"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/recording"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/log"
 	tsuruNet "github.com/tsuru/tsuru/net"
@@ -349,6 +351,7 @@ type inputApp struct {
 	Tags         []string
 	PlanOverride appTypes.PlanOverride
 	Metadata     appTypes.Metadata
+	TTL          string
 
 	Processes []appTypes.Process
 }
@@ -407,6 +410,10 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	}
 	tags, _ := InputValues(r, "tag")
 	a.Tags = append(a.Tags, tags...) // for compatibility
+	a.ExpiresAt, err = expirationFromTTL(ia.TTL, time.Now())
+	if err != nil {
+		return err
+	}
 	if a.TeamOwner == "" {
 		a.TeamOwner, err = autoTeamOwner(ctx, t, permission.PermAppCreate)
 		if err != nil {
@@ -465,28 +472,7 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	defer func() { evt.Done(ctx, err) }()
 	err = app.CreateApp(ctx, a, u)
 	if err != nil {
-		log.Errorf("Got error while creating app: %s", err)
-		if _, ok := err.(appTypes.NoTeamsError); ok {
-			return &errors.HTTP{
-				Code:    http.StatusBadRequest,
-				Message: "In order to create an app, you should be member of at least one team",
-			}
-		}
-		if e, ok := err.(*appTypes.AppCreationError); ok {
-			if e.Err == app.ErrAppAlreadyExists {
-				return &errors.HTTP{Code: http.StatusConflict, Message: e.Error()}
-			}
-			if _, ok := pkgErrors.Cause(e.Err).(*quota.QuotaExceededError); ok {
-				return &errors.HTTP{
-					Code:    http.StatusForbidden,
-					Message: "Quota exceeded",
-				}
-			}
-		}
-		if err == appTypes.ErrInvalidPlatform {
-			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
-		}
-		return err
+		return createAppError(err)
 	}
 	msg := map[string]interface{}{
 		"status": "success",
@@ -508,6 +494,31 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	return nil
 }
 
+func createAppError(err error) error {
+	log.Errorf("Got error while creating app: %s", err)
+	if _, ok := err.(appTypes.NoTeamsError); ok {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "In order to create an app, you should be member of at least one team",
+		}
+	}
+	if e, ok := err.(*appTypes.AppCreationError); ok {
+		if e.Err == app.ErrAppAlreadyExists {
+			return &errors.HTTP{Code: http.StatusConflict, Message: e.Error()}
+		}
+		if _, ok := pkgErrors.Cause(e.Err).(*quota.QuotaExceededError); ok {
+			return &errors.HTTP{
+				Code:    http.StatusForbidden,
+				Message: "Quota exceeded",
+			}
+		}
+	}
+	if err == appTypes.ErrInvalidPlatform {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
 // title: app update
 // path: /apps/{name}
 // method: PUT
@@ -999,7 +1010,9 @@ func runCommand(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	isolatedBool, _ := strconv.ParseBool(isolated)
 	debugBool, _ := strconv.ParseBool(debug)
 	args := provision.RunArgs{Once: onceBool, Isolated: isolatedBool, Debug: debugBool}
-	return app.Run(ctx, a, command, evt, args)
+	rec := recording.NewRecorder(recording.Header{Title: command})
+	defer saveRecording(ctx, evt, rec)
+	return app.Run(ctx, a, command, io.MultiWriter(evt, rec.Writer(recording.Output)), args)
 }
 
 // title: get envs
@@ -1125,12 +1138,16 @@ func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		if e.Private {
 			private = true
 		}
+		if v.SecretRef != nil {
+			private = true
+		}
 		variables = append(variables, bindTypes.EnvVar{
 			Name:      v.Name,
 			Value:     v.Value,
 			Public:    !private,
 			Alias:     v.Alias,
 			ManagedBy: e.ManagedBy,
+			SecretRef: v.SecretRef,
 		})
 	}
 	w.Header().Set("Content-Type", "application/x-json-stream")
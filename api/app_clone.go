@@ -0,0 +1,185 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: app clone
+// path: /apps/{app}/clone
+// method: POST
+// consume: application/json
+// produce: application/x-json-stream
+// responses:
+//
+//	200: App cloned
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Quota exceeded
+//	404: App not found
+//	409: App already exists
+func appClone(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	src, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canClone := permission.Check(ctx, t, permission.PermAppReadClone, contextsForApp(src)...)
+	if !canClone {
+		return permission.ErrUnauthorized
+	}
+	// the clone gets the values of the private env vars of the source app,
+	// possibly under another team owner
+	canSetEnv := permission.Check(ctx, t, permission.PermAppUpdateEnvSet, contextsForApp(src)...)
+	if !canSetEnv {
+		return permission.ErrUnauthorized
+	}
+	var opts appTypes.CloneOptions
+	err = ParseJSON(r, &opts)
+	if err != nil {
+		return err
+	}
+	exp, changes, err := app.CloneApp(ctx, src, opts)
+	if err != nil {
+		return err
+	}
+	a, err := app.AppFromExport(exp)
+	if err != nil {
+		return err
+	}
+	a.ExpiresAt, err = expirationFromTTL(opts.TTL, time.Now())
+	if err != nil {
+		return err
+	}
+	canCreate := permission.Check(ctx, t, permission.PermAppCreate,
+		permission.Context(permTypes.CtxTeam, a.TeamOwner),
+	)
+	if !canCreate {
+		return permission.ErrUnauthorized
+	}
+	var instances []*service.ServiceInstance
+	if opts.Services == appTypes.CloneServicesNew {
+		instances, err = instancesToClone(ctx, t, a, exp.Manifest.Services)
+		if err != nil {
+			return err
+		}
+	}
+	deployOpts := app.DeployOptions{
+		Image:   exp.Image,
+		Origin:  "image",
+		User:    t.GetUserName(),
+		Message: fmt.Sprintf("cloned from app %q", src.Name),
+	}
+	plan, err := prepareAppImport(ctx, t, a, exp.Teams, changes, deployOpts)
+	if err != nil {
+		return err
+	}
+	var beforeDeploy func(io.Writer) error
+	if len(instances) > 0 {
+		beforeDeploy = func(w io.Writer) error {
+			for _, si := range instances {
+				if err := cloneServiceInstance(ctx, r, t, a, si, w); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return plan.run(w, r, t, beforeDeploy)
+}
+
+// instancesToClone returns the service instances bound to the source app,
+// checking whether the user is able to create new instances of them for the
+// team owner of the clone.
+func instancesToClone(ctx stdContext.Context, t auth.Token, a *appTypes.App, services []appTypes.ManifestService) ([]*service.ServiceInstance, error) {
+	canCreate := permission.Check(ctx, t, permission.PermServiceInstanceCreate,
+		permission.Context(permTypes.CtxTeam, a.TeamOwner),
+	)
+	if len(services) > 0 && !canCreate {
+		return nil, permission.ErrUnauthorized
+	}
+	var instances []*service.ServiceInstance
+	for _, s := range services {
+		srv, err := getService(ctx, s.Service)
+		if err != nil {
+			return nil, err
+		}
+		if srv.IsRestricted && !permission.Check(ctx, t, permission.PermServiceRead, contextsForService(&srv)...) {
+			return nil, permission.ErrUnauthorized
+		}
+		si, err := getServiceInstanceOrError(ctx, s.Service, s.Instance)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, si)
+	}
+	return instances, nil
+}
+
+// cloneServiceInstance creates a new instance with the plan and parameters
+// of src, named after the clone, and binds it to the clone.
+func cloneServiceInstance(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App, src *service.ServiceInstance, w io.Writer) error {
+	srv, err := getService(ctx, src.ServiceName)
+	if err != nil {
+		return err
+	}
+	instance := service.ServiceInstance{
+		Name:        a.Name + "-" + src.Name,
+		ServiceName: src.ServiceName,
+		PlanName:    src.PlanName,
+		TeamOwner:   a.TeamOwner,
+		Description: src.Description,
+		Tags:        src.Tags,
+		Parameters:  src.Parameters,
+	}
+	if srv.IsMultiCluster {
+		instance.Pool = a.Pool
+	}
+	fmt.Fprintf(w, "---- Creating service instance %q of service %q ----\n", instance.Name, instance.ServiceName)
+	err = createClonedServiceInstance(ctx, r, t, instance, &srv)
+	if err != nil {
+		return err
+	}
+	step, err := prepareManifestStep(ctx, t, a, appTypes.ManifestChange{
+		Field:   appTypes.ManifestFieldService,
+		Action:  appTypes.ManifestActionAdd,
+		Name:    instance.ServiceName + "/" + instance.Name,
+		Desired: appTypes.ManifestService{Service: instance.ServiceName, Instance: instance.Name},
+	})
+	if err != nil {
+		return err
+	}
+	return importManifestStep(ctx, r, t, a, step, w)
+}
+
+func createClonedServiceInstance(ctx stdContext.Context, r *http.Request, t auth.Token, instance service.ServiceInstance, srv *service.Service) (err error) {
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     serviceInstanceTarget(srv.Name, instance.Name),
+		Kind:       permission.PermServiceInstanceCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]interface{}{"plan": instance.PlanName, "owner": instance.TeamOwner, "pool": instance.Pool},
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents,
+			contextsForServiceInstance(&instance, srv.Name)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return service.CreateServiceInstance(ctx, instance, srv, evt, requestIDHeader(r))
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppClone(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Description: "my app"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &myapp, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "FOO", Value: "bar"}, {Name: "BAZ", Value: "qux", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"name": "myapp-pr-1", "env": [{"name": "BAZ", "value": "preview"}]}`)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/myapp/clone", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*App \\"myapp-pr-1\\" ready.*`)
+	clone, err := app.GetByName(context.TODO(), "myapp-pr-1")
+	c.Assert(err, check.IsNil)
+	c.Assert(clone.Description, check.Equals, "my app")
+	c.Assert(clone.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(clone.Env["FOO"].Value, check.Equals, "bar")
+	c.Assert(clone.Env["FOO"].Public, check.Equals, false)
+	c.Assert(clone.Env["BAZ"].Value, check.Equals, "preview")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp-pr-1"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.create",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppCloneUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/myapp/clone", strings.NewReader(`{"name": "myapp-pr-1"}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	_, err = app.GetByName(context.TODO(), "myapp-pr-1")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
+
+func (s *S) TestAppCloneRequiresEnvSetOnSource(c *check.C) {
+	perms := []permTypes.Permission{
+		{Scheme: permission.PermAppReadClone, Context: permission.Context(permTypes.CtxTeam, s.team.Name)},
+		{Scheme: permission.PermAppCreate, Context: permission.Context(permTypes.CtxTeam, s.team.Name)},
+	}
+	_, reader := permissiontest.CustomUserWithPermission(c, nativeScheme, "reader", perms...)
+	_, setter := permissiontest.CustomUserWithPermission(c, nativeScheme, "setter", append(perms, permTypes.Permission{
+		Scheme: permission.PermAppUpdateEnvSet, Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})...)
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	for _, tt := range []struct {
+		token auth.Token
+		code  int
+	}{
+		{token: reader, code: http.StatusForbidden},
+		// the env set check passes and the invalid body is rejected
+		{token: setter, code: http.StatusBadRequest},
+	} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/1.33/apps/myapp/clone", strings.NewReader(`{"name": `))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+tt.token.GetValue())
+		request.Header.Set("Content-Type", "application/json")
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, tt.code, check.Commentf("body: %q", recorder.Body.String()))
+	}
+}
+
+func (s *S) TestAppCloneAppNotFound(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/unknown/clone", strings.NewReader(`{"name": "myapp-pr-1"}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
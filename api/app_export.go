@@ -0,0 +1,283 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+// passphraseHeader holds the passphrase used to encrypt the private env vars
+// of an export and to decrypt them on import.
+const passphraseHeader = "X-Tsuru-Passphrase"
+
+// title: app export
+// path: /apps/{app}/export
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func appExport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canExport := permission.Check(ctx, t, permission.PermAppReadExport, contextsForApp(a)...)
+	if !canExport {
+		return permission.ErrUnauthorized
+	}
+	passphrase := r.Header.Get(passphraseHeader)
+	if !permission.Check(ctx, t, permission.PermAppAdminExport, contextsForApp(a)...) {
+		// private values are only exported by name
+		passphrase = ""
+	}
+	exp, err := app.ExportApp(ctx, a, passphrase)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(exp)
+}
+
+// title: app import
+// path: /apps/import
+// method: POST
+// consume: application/json
+// produce: application/x-json-stream
+// responses:
+//
+//	200: App imported
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Quota exceeded
+//	404: Service instance or volume not found
+//	409: App already exists
+func appImport(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var exp appTypes.AppExport
+	err = ParseJSON(r, &exp)
+	if err != nil {
+		return err
+	}
+	a, err := app.AppFromExport(&exp)
+	if err != nil {
+		return err
+	}
+	canCreate := permission.Check(ctx, t, permission.PermAppCreate,
+		permission.Context(permTypes.CtxTeam, a.TeamOwner),
+	)
+	if !canCreate {
+		return permission.ErrUnauthorized
+	}
+	changes, err := app.ImportChanges(&exp, r.Header.Get(passphraseHeader))
+	if err != nil {
+		return err
+	}
+	deployOpts := app.DeployOptions{Image: exp.Image, Origin: "image", User: t.GetUserName(), Message: "imported app"}
+	plan, err := prepareAppImport(ctx, t, a, exp.Teams, changes, deployOpts)
+	if err != nil {
+		return err
+	}
+	return plan.run(w, r, t, nil)
+}
+
+// appImportPlan holds the checked steps to create an app and set up its
+// configuration, shared by app import and app clone.
+type appImportPlan struct {
+	app            *appTypes.App
+	teams          []string
+	steps          []manifestStep
+	autoscaleSteps []manifestStep
+	deployOpts     app.DeployOptions
+}
+
+// prepareAppImport checks the permissions required by every step of the
+// import before anything is created, the app is only created by run.
+func prepareAppImport(ctx stdContext.Context, t auth.Token, a *appTypes.App, teams []string, changes []appTypes.ManifestChange, deployOpts app.DeployOptions) (*appImportPlan, error) {
+	plan := &appImportPlan{app: a, deployOpts: deployOpts}
+	for _, change := range changes {
+		step, err := prepareManifestStep(ctx, t, a, change)
+		if err != nil {
+			return nil, err
+		}
+		if change.Field == appTypes.ManifestFieldAutoscale {
+			// autoscales are only set once the image is deployed
+			plan.autoscaleSteps = append(plan.autoscaleSteps, step)
+			continue
+		}
+		plan.steps = append(plan.steps, step)
+	}
+	for _, team := range teams {
+		if team != a.TeamOwner {
+			plan.teams = append(plan.teams, team)
+		}
+	}
+	if len(plan.teams) > 0 && !permission.Check(ctx, t, permission.PermAppUpdateGrant, contextsForApp(a)...) {
+		return nil, permission.ErrUnauthorized
+	}
+	if deployOpts.Image != "" && !permission.Check(ctx, t, permSchemeForDeploy(deployOpts), contextsForApp(a)...) {
+		return nil, permission.ErrUnauthorized
+	}
+	return plan, nil
+}
+
+// run creates the app and streams the progress of the remaining steps,
+// calling beforeDeploy, when set, right before deploying the image.
+func (p *appImportPlan) run(w http.ResponseWriter, r *http.Request, t auth.Token, beforeDeploy func(io.Writer) error) error {
+	ctx := r.Context()
+	u, err := auth.ConvertNewUser(t.User(ctx))
+	if err != nil {
+		return err
+	}
+	err = importCreateApp(ctx, r, t, p.app, u)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	fmt.Fprintf(writer, "---- App %q created ----\n", p.app.Name)
+	for _, team := range p.teams {
+		err = importGrantTeam(ctx, r, t, p.app, team, writer)
+		if err != nil {
+			return err
+		}
+	}
+	for _, step := range p.steps {
+		err = importManifestStep(ctx, r, t, p.app, step, writer)
+		if err != nil {
+			return err
+		}
+	}
+	if beforeDeploy != nil {
+		err = beforeDeploy(writer)
+		if err != nil {
+			return err
+		}
+	}
+	if p.deployOpts.Image != "" {
+		fmt.Fprintf(writer, "---- Deploying image %q ----\n", p.deployOpts.Image)
+		err = importDeploy(ctx, r, p.app, p.deployOpts, writer)
+		if err != nil {
+			return err
+		}
+	}
+	for _, step := range p.autoscaleSteps {
+		err = importManifestStep(ctx, r, t, p.app, step, writer)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(writer, "---- App %q ready ----\n", p.app.Name)
+	return nil
+}
+
+func importCreateApp(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App, u *auth.User) (err error) {
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]interface{}{"import": true, "pool": a.Pool, "plan": a.Plan.Name},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.CreateApp(ctx, a, u)
+	if err != nil {
+		return createAppError(err)
+	}
+	return nil
+}
+
+func importGrantTeam(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App, teamName string, w io.Writer) (err error) {
+	team, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		fmt.Fprintf(w, "---- Skipping access to team %q, team not found ----\n", teamName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "---- Granting access to team %q ----\n", teamName)
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateGrant,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]interface{}{"team": teamName},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	current, err := app.GetByName(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	return app.Grant(ctx, current, team)
+}
+
+func importManifestStep(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App, step manifestStep, w io.Writer) error {
+	fmt.Fprintf(w, "---- Adding %s %s ----\n", step.change.Field, step.change.Name)
+	err := applyManifestStep(ctx, r, t, a, step)
+	if err != nil {
+		return manifestError(err)
+	}
+	return nil
+}
+
+func importDeploy(ctx stdContext.Context, r *http.Request, a *appTypes.App, opts app.DeployOptions, w io.Writer) (err error) {
+	current, err := app.GetByName(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	opts.App = current
+	opts.Kind = provisionTypes.DeployImage
+	var imageID string
+	evt, err := event.New(ctx, &event.Opts{
+		Target:        appTarget(a.Name),
+		Kind:          permission.PermAppDeploy,
+		RawOwner:      eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: opts.User},
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    opts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(current)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(current)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.DoneCustomData(ctx, err, map[string]string{"image": imageID}) }()
+	opts.Event = evt
+	opts.OutputStream = w
+	imageID, err = app.Deploy(ctx, opts)
+	return err
+}
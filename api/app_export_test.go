@@ -0,0 +1,190 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppExport(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Description: "my app"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/apps/myapp/export", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var exp appTypes.AppExport
+	err = json.Unmarshal(recorder.Body.Bytes(), &exp)
+	c.Assert(err, check.IsNil)
+	c.Assert(exp.FormatVersion, check.Equals, appTypes.AppExportFormatVersion)
+	c.Assert(exp.Name, check.Equals, "myapp")
+	c.Assert(exp.Platform, check.Equals, "go")
+	c.Assert(exp.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(exp.Pool, check.Equals, s.Pool)
+	c.Assert(exp.Manifest.Description, check.Equals, "my app")
+}
+
+func (s *S) TestAppExportUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/apps/myapp/export", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppExportPrivateEnvsRequireAdminExport(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &myapp, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "SECRET", Value: "secret value"}},
+	})
+	c.Assert(err, check.IsNil)
+	_, reader := permissiontest.CustomUserWithPermission(c, nativeScheme, "reader", permTypes.Permission{
+		Scheme:  permission.PermAppReadExport,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	_, admin := permissiontest.CustomUserWithPermission(c, nativeScheme, "exporter", permTypes.Permission{
+		Scheme:  permission.PermAppReadExport,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppAdminExport,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	for _, tt := range []struct {
+		token     auth.Token
+		encrypted bool
+	}{
+		{token: reader},
+		{token: admin, encrypted: true},
+	} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/1.33/apps/myapp/export", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+tt.token.GetValue())
+		request.Header.Set(passphraseHeader, "my passphrase")
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+		var exp appTypes.AppExport
+		err = json.Unmarshal(recorder.Body.Bytes(), &exp)
+		c.Assert(err, check.IsNil)
+		if tt.encrypted {
+			c.Assert(exp.EnvEncryption, check.NotNil)
+			c.Assert(exp.OmittedEnvs, check.IsNil)
+			c.Assert(exp.Manifest.Env, check.HasLen, 1)
+			c.Assert(exp.Manifest.Env[0].Private, check.Equals, true)
+			continue
+		}
+		c.Assert(exp.EnvEncryption, check.IsNil)
+		c.Assert(exp.OmittedEnvs, check.DeepEquals, []string{"SECRET"})
+		c.Assert(exp.Manifest.Env, check.HasLen, 0)
+	}
+}
+
+func (s *S) TestAppImport(c *check.C) {
+	exp := appTypes.AppExport{
+		FormatVersion: appTypes.AppExportFormatVersion,
+		Name:          "myapp",
+		Platform:      "go",
+		TeamOwner:     s.team.Name,
+		Pool:          s.Pool,
+		Manifest: appTypes.Manifest{
+			Description: "imported",
+			CNames:      []string{"myapp.io"},
+			Env:         []appTypes.ManifestEnv{{Name: "FOO", Value: "bar"}},
+		},
+	}
+	data, err := json.Marshal(exp)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/import", strings.NewReader(string(data)))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*App \\"myapp\\" ready.*`)
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "imported")
+	c.Assert(dbApp.CName, check.DeepEquals, []string{"myapp.io"})
+	c.Assert(dbApp.Env["FOO"].Value, check.Equals, "bar")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.create",
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.cname.add",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppImportInvalidFormatVersion(c *check.C) {
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"formatVersion": 99, "name": "myapp", "teamOwner": "` + s.team.Name + `"}`)
+	request, err := http.NewRequest("POST", "/1.33/apps/import", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "unsupported export format version 99\n")
+	_, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
+
+func (s *S) TestAppImportMissingPassphrase(c *check.C) {
+	exp := appTypes.AppExport{
+		FormatVersion: appTypes.AppExportFormatVersion,
+		Name:          "myapp",
+		TeamOwner:     s.team.Name,
+		Pool:          s.Pool,
+		EnvEncryption: &appTypes.EnvEncryption{Algorithm: "scrypt-aes-256-gcm", Salt: []byte("salt")},
+		Manifest: appTypes.Manifest{
+			Env: []appTypes.ManifestEnv{{Name: "SECRET", Value: "encrypted", Private: true}},
+		},
+	}
+	data, err := json.Marshal(exp)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/import", strings.NewReader(string(data)))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "a passphrase is required to decrypt the private env vars\n")
+	_, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
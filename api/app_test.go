@@ -28,6 +28,7 @@ import (
 	tsuruEnvs "github.com/tsuru/tsuru/envs"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/event/recording"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
 	"github.com/tsuru/tsuru/provision"
@@ -35,6 +36,7 @@ import (
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/routertest"
+	_ "github.com/tsuru/tsuru/secret/secrettest"
 	"github.com/tsuru/tsuru/service"
 	"github.com/tsuru/tsuru/servicemanager"
 	apiTypes "github.com/tsuru/tsuru/types/api"
@@ -49,6 +51,7 @@ import (
 	"github.com/tsuru/tsuru/types/quota"
 	tagTypes "github.com/tsuru/tsuru/types/tag"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	check "gopkg.in/check.v1"
 )
 
@@ -3221,6 +3224,36 @@ func (s *S) TestRunEventIsCancelable(c *check.C) {
 	c.Assert(evt.Cancelable, check.Equals, true)
 }
 
+func (s *S) TestRunRecordsSession(c *check.C) {
+	s.provisioner.PrepareOutput([]byte("lots of\nfiles"))
+	a := appTypes.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	url := fmt.Sprintf("/apps/%s/run", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("command=ls"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	collection, err := storagev2.EventsCollection()
+	c.Assert(err, check.IsNil)
+	var evt struct {
+		UniqueID primitive.ObjectID `bson:"uniqueid"`
+	}
+	err = collection.FindOne(context.TODO(), mongoBSON.M{"kind.name": "app.run"}).Decode(&evt)
+	c.Assert(err, check.IsNil)
+	rec, err := recording.Get(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	lines := strings.Split(strings.TrimSpace(string(rec.Data)), "\n")
+	c.Assert(lines, check.HasLen, 2)
+	c.Assert(lines[0], check.Matches, `\{"version":2,"width":80,"height":24,"timestamp":\d+,"title":"ls"\}`)
+	c.Assert(lines[1], check.Matches, `\[[\d.]+,"o","lots of\\nfiles"\]`)
+	c.Assert(rec.Truncated, check.Equals, false)
+}
+
 func (s *S) TestRunWithMaxDuration(c *check.C) {
 	oldMaxDuration := appRunMaxDuration
 	appRunMaxDuration = 100 * time.Millisecond
@@ -3529,6 +3562,88 @@ func (s *S) TestSetEnvPublicAndPrivate(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestSetEnvWithSecretRef(c *check.C) {
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddSecret("db-credentials", s.team.Name)
+	d := apiTypes.Envs{
+		Envs: []apiTypes.Env{
+			{Name: "DATABASE_PASSWORD", SecretRef: &bindTypes.SecretRef{Provider: "kubernetes", Path: "db-credentials", Key: "password"}},
+		},
+	}
+	v, err := json.Marshal(d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/env", a.Name), bytes.NewReader(v))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DATABASE_PASSWORD"], check.DeepEquals, bindTypes.EnvVar{
+		Name:      "DATABASE_PASSWORD",
+		SecretRef: &bindTypes.SecretRef{Provider: "kubernetes", Path: "db-credentials", Key: "password"},
+	})
+	request, err = http.NewRequest("GET", fmt.Sprintf("/apps/%s/env?env=DATABASE_PASSWORD", a.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var envs []bindTypes.EnvVar
+	err = json.Unmarshal(recorder.Body.Bytes(), &envs)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.HasLen, 1)
+	c.Assert(envs[0].Value, check.Equals, "")
+	c.Assert(envs[0].SecretRef, check.DeepEquals, &bindTypes.SecretRef{Provider: "kubernetes", Path: "db-credentials", Key: "password"})
+}
+
+func (s *S) TestSetEnvWithInvalidSecretRef(c *check.C) {
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		env     apiTypes.Env
+		message string
+	}{
+		{
+			env:     apiTypes.Env{Name: "DATABASE_PASSWORD", Value: "leaked", SecretRef: &bindTypes.SecretRef{Provider: "kubernetes", Path: "db-credentials", Key: "password"}},
+			message: `Environment variable "DATABASE_PASSWORD" referencing a secret must be private and have no value`,
+		},
+		{
+			env:     apiTypes.Env{Name: "DATABASE_PASSWORD", SecretRef: &bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "password"}},
+			message: `Invalid secret reference for environment variable "DATABASE_PASSWORD": secret provider "vault" not found`,
+		},
+		{
+			env:     apiTypes.Env{Name: "DATABASE_PASSWORD", SecretRef: &bindTypes.SecretRef{Provider: "kubernetes", Path: "other-credentials", Key: "password"}},
+			message: `Invalid secret reference for environment variable "DATABASE_PASSWORD": secret "other-credentials" is not owned by team "` + s.team.Name + `"`,
+		},
+		{
+			env:     apiTypes.Env{Name: "DATABASE_PASSWORD", SecretRef: &bindTypes.SecretRef{Provider: "local", Path: "otherteam/db", Key: "password"}},
+			message: `Invalid secret reference for environment variable "DATABASE_PASSWORD": secret path "otherteam/db" is not allowed, paths of app "black-dog" in provider "local" must be under "` + s.team.Name + `/"`,
+		},
+	}
+	s.provisioner.AddSecret("other-credentials", "otherteam")
+	config.Set("secret-providers:local:type", "file")
+	config.Set("secret-providers:local:path", "/dev/null")
+	defer config.Unset("secret-providers")
+	for _, tt := range tests {
+		v, err := json.Marshal(apiTypes.Envs{Envs: []apiTypes.Env{tt.env}})
+		c.Assert(err, check.IsNil)
+		request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/env", a.Name), bytes.NewReader(v))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(recorder.Body.String(), check.Equals, tt.message+"\n")
+	}
+}
+
 func (s *S) TestSetEnvCanPruneOldVariables(c *check.C) {
 	a := appTypes.App{
 		Name:      "black-dog",
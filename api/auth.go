@@ -8,13 +8,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
 	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/ratelimit"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
@@ -31,10 +36,15 @@ import (
 const (
 	nonManagedSchemeMsg = "Authentication scheme does not allow this operation."
 	createDisabledMsg   = "User registration is disabled for non-admin users."
+	mfaRequiredHeader   = "X-Tsuru-Mfa-Required"
 )
 
 var createDisabledErr = &errors.HTTP{Code: http.StatusUnauthorized, Message: createDisabledMsg}
 
+func init() {
+	native.OnUserLock = userLockEvent
+}
+
 func handleAuthError(err error) error {
 	if err == authTypes.ErrUserNotFound {
 		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
@@ -129,6 +139,7 @@ func createUser(w http.ResponseWriter, r *http.Request) (err error) {
 //	401: Unauthorized
 //	403: Forbidden
 //	404: Not found
+//	429: Too many login attempts
 func login(w http.ResponseWriter, r *http.Request) (err error) {
 	ctx := r.Context()
 	params := map[string]string{
@@ -140,7 +151,14 @@ func login(w http.ResponseWriter, r *http.Request) (err error) {
 	}
 
 	if userScheme, ok := app.AuthScheme.(auth.UserScheme); ok {
+		if err = checkLoginThrottle(w, r); err != nil {
+			return err
+		}
 		token, err := userScheme.Login(ctx, params)
+		if err == auth.ErrMFARequired {
+			w.Header().Set(mfaRequiredHeader, "true")
+			return &errors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+		}
 		if err != nil {
 			return handleAuthError(err)
 		}
@@ -259,6 +277,210 @@ func resetPassword(w http.ResponseWriter, r *http.Request) (err error) {
 	return managed.ResetPassword(ctx, u, token)
 }
 
+// title: enroll mfa
+// path: /users/{email}/mfa
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	201: Enrollment started
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Not found
+//	409: MFA already enabled
+func enrollMFA(w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := r.Context()
+	scheme, ok := app.AuthScheme.(auth.MFAScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	if err = checkLoginThrottle(w, r); err != nil {
+		return err
+	}
+	email := r.URL.Query().Get(":email")
+	evt, err := mfaEvent(r, email)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	u, err := auth.GetUserByEmail(ctx, email)
+	if err != nil {
+		return handleAuthError(err)
+	}
+	enrollment, err := scheme.EnrollMFA(ctx, u, InputValue(r, "password"))
+	if err != nil {
+		return handleAuthError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(enrollment)
+}
+
+// title: confirm mfa
+// path: /users/{email}/mfa/confirm
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: MFA enabled
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Not found
+//	409: MFA already enabled
+func confirmMFA(w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := r.Context()
+	scheme, ok := app.AuthScheme.(auth.MFAScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	if err = checkLoginThrottle(w, r); err != nil {
+		return err
+	}
+	email := r.URL.Query().Get(":email")
+	evt, err := mfaEvent(r, email)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	u, err := auth.GetUserByEmail(ctx, email)
+	if err != nil {
+		return handleAuthError(err)
+	}
+	return handleAuthError(scheme.ConfirmMFA(ctx, u, InputValue(r, "password"), InputValue(r, "otp")))
+}
+
+// title: disable mfa
+// path: /users/{email}/mfa
+// method: DELETE
+// responses:
+//
+//	200: MFA disabled
+//	400: Invalid data
+//	401: Unauthorized
+//	403: MFA required for the user
+//	404: Not found
+func disableMFA(w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := r.Context()
+	scheme, ok := app.AuthScheme.(auth.MFAScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	if err = checkLoginThrottle(w, r); err != nil {
+		return err
+	}
+	email := r.URL.Query().Get(":email")
+	evt, err := mfaEvent(r, email)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	u, err := auth.GetUserByEmail(ctx, email)
+	if err != nil {
+		return handleAuthError(err)
+	}
+	return handleAuthError(scheme.DisableMFA(ctx, u, InputValue(r, "password"), InputValue(r, "otp")))
+}
+
+func mfaEvent(r *http.Request, email string) (*event.Event, error) {
+	return event.New(r.Context(), &event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserUpdateMfa,
+		RawOwner:   eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: email},
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+}
+
+// title: unlock user
+// path: /users/{email}/unlock
+// method: POST
+// responses:
+//
+//	200: User unlocked
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Forbidden
+//	404: Not found
+func unlockUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	scheme, ok := app.AuthScheme.(auth.LockableScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	email := r.URL.Query().Get(":email")
+	allowed := permission.Check(ctx, t, permission.PermUserUpdateUnlock, permission.Context(permTypes.CtxUser, email))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	u, err := auth.GetUserByEmail(ctx, email)
+	if err != nil {
+		return handleAuthError(err)
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserUpdateUnlock,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return scheme.Unlock(ctx, u)
+}
+
+// checkLoginThrottle limits the requests verifying passwords coming from the
+// same client IP address, see ratelimit.ClientIP.
+func checkLoginThrottle(w http.ResponseWriter, r *http.Request) error {
+	limit, ok := ratelimit.LoginLimit()
+	if !ok {
+		return nil
+	}
+	ip := ratelimit.ClientIP(r)
+	result, err := ratelimit.Hit(r.Context(), "login:"+ip, limit, time.Now())
+	if err != nil {
+		log.Errorf("unable to check login throttle for %q: %v", ip, err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return &errors.HTTP{
+		Code:    http.StatusTooManyRequests,
+		Message: fmt.Sprintf("too many login attempts, retry in %d seconds", retryAfter),
+	}
+}
+
+func lockedUsers(ctx context.Context) (map[string]time.Time, error) {
+	scheme, ok := app.AuthScheme.(auth.LockableScheme)
+	if !ok {
+		return nil, nil
+	}
+	return scheme.LockedUsers(ctx)
+}
+
+func userLockEvent(ctx context.Context, email string, failures, lockouts int, lockedUntil time.Time) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       userTarget(email),
+		InternalKind: "user.lock",
+		CustomData: map[string]interface{}{
+			"failures":    failures,
+			"lockouts":    lockouts,
+			"lockedUntil": lockedUntil,
+		},
+		Allowed: event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+	if err != nil {
+		log.Errorf("unable to create lock event for user %q: %v", email, err)
+		return
+	}
+	evt.Done(ctx, nil)
+}
+
 var teamRenameFns = []func(ctx context.Context, oldName, newName string) error{
 	app.RenameTeam,
 	service.RenameServiceTeam,
@@ -758,6 +980,7 @@ type apiUser struct {
 	Roles       []rolePermissionData
 	Permissions []rolePermissionData
 	Groups      []string
+	LockedUntil *time.Time `json:",omitempty"`
 }
 
 func createAPIUser(ctx context.Context, perms []permTypes.Permission, user *auth.User, roleMap map[string]*permission.Role, includeAll bool) (*apiUser, error) {
@@ -869,6 +1092,10 @@ func listUsers(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
+	locked, err := lockedUsers(ctx)
+	if err != nil {
+		return err
+	}
 	for _, user := range users {
 		usrData, err := createAPIUser(ctx, perms, &user, roleMap, includeAll)
 		if err != nil {
@@ -877,6 +1104,9 @@ func listUsers(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		if usrData == nil {
 			continue
 		}
+		if lockedUntil, ok := locked[user.Email]; ok {
+			usrData.LockedUntil = &lockedUntil
+		}
 		if userEmail == "" && roleName == "" {
 			apiUsers = append(apiUsers, *usrData)
 		}
@@ -7,6 +7,10 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -336,6 +340,219 @@ func (s *AuthSuite) TestLoginPasswordIsInvalid(c *check.C) {
 	}
 }
 
+func totpCode(c *check.C, secret string) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	c.Assert(err, check.IsNil)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func (s *AuthSuite) enrollMFA(c *check.C, email string) authTypes.MFAEnrollment {
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/"+email+"/mfa", strings.NewReader("password=123456"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var enrollment authTypes.MFAEnrollment
+	err = json.Unmarshal(recorder.Body.Bytes(), &enrollment)
+	c.Assert(err, check.IsNil)
+	return enrollment
+}
+
+func (s *AuthSuite) TestEnrollMFA(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	enrollment := s.enrollMFA(c, u.Email)
+	c.Assert(enrollment.Secret, check.Not(check.Equals), "")
+	c.Assert(enrollment.URL, check.Matches, "otpauth://totp/.*")
+	c.Assert(enrollment.RecoveryCodes, check.HasLen, 10)
+	b := strings.NewReader("password=123456&otp=" + totpCode(c, enrollment.Secret))
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/nobody@globo.com/mfa/confirm", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  u.Email,
+		Kind:   "user.update.mfa",
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestEnrollMFAWrongPassword(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/nobody@globo.com/mfa", strings.NewReader("password=654321"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
+
+func (s *AuthSuite) TestLoginMFARequired(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	enrollment := s.enrollMFA(c, u.Email)
+	err = nativeScheme.ConfirmMFA(context.TODO(), &u, "123456", totpCode(c, enrollment.Secret))
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", strings.NewReader("password=123456"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+	c.Assert(recorder.Header().Get("X-Tsuru-Mfa-Required"), check.Equals, "true")
+	c.Assert(recorder.Body.String(), check.Equals, auth.ErrMFARequired.Error()+"\n")
+	b := strings.NewReader("password=123456&otp=" + enrollment.RecoveryCodes[0])
+	request, err = http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("X-Tsuru-Mfa-Required"), check.Equals, "")
+}
+
+func (s *AuthSuite) TestDisableMFA(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	enrollment := s.enrollMFA(c, u.Email)
+	err = nativeScheme.ConfirmMFA(context.TODO(), &u, "123456", totpCode(c, enrollment.Secret))
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/1.33/users/nobody@globo.com/mfa?password=123456&otp="+enrollment.RecoveryCodes[1], nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *AuthSuite) TestDisableMFANotEnabled(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/1.33/users/nobody@globo.com/mfa?password=123456&otp=123456", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, native.ErrMFANotEnabled.Error()+"\n")
+}
+
+func (s *AuthSuite) TestLoginThrottle(c *check.C) {
+	config.Set("auth:login-throttle:requests", 2)
+	config.Set("auth:login-throttle:window", "1h")
+	defer config.Unset("auth:login-throttle")
+	for i := 0; i < 3; i++ {
+		request, err := http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", strings.NewReader("password=123456"))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.RemoteAddr = "10.0.0.1:4321"
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		if i < 2 {
+			c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+			continue
+		}
+		c.Assert(recorder.Code, check.Equals, http.StatusTooManyRequests)
+		c.Assert(recorder.Header().Get("Retry-After"), check.Not(check.Equals), "")
+		c.Assert(recorder.Body.String(), check.Matches, "too many login attempts, retry in [0-9]+ seconds\n")
+	}
+	request, err := http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", strings.NewReader("password=123456"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.RemoteAddr = "10.0.0.2:4321"
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *AuthSuite) TestLoginThrottleBehindProxy(c *check.C) {
+	config.Set("auth:login-throttle:requests", 1)
+	config.Set("auth:login-throttle:window", "1h")
+	config.Set("api:client-ip:header", "X-Forwarded-For")
+	defer config.Unset("auth:login-throttle")
+	defer config.Unset("api:client-ip")
+	login := func(forwardedFor string) int {
+		request, err := http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", strings.NewReader("password=123456"))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		request.RemoteAddr = "10.0.0.1:4321"
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+	c.Assert(login("192.168.0.1"), check.Equals, http.StatusNotFound)
+	c.Assert(login("192.168.0.2"), check.Equals, http.StatusNotFound)
+	c.Assert(login("1.2.3.4, 192.168.0.1"), check.Equals, http.StatusTooManyRequests)
+}
+
+func (s *AuthSuite) TestUnlockUser(c *check.C) {
+	config.Set("auth:lockout:max-attempts", 1)
+	defer config.Unset("auth:lockout")
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "wrong-password"})
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Kind:   "user.lock",
+	}, eventtest.HasEvent)
+	request, err := http.NewRequest(http.MethodGet, "/users?userEmail=nobody@globo.com", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var users []apiUser
+	err = json.NewDecoder(recorder.Body).Decode(&users)
+	c.Assert(err, check.IsNil)
+	c.Assert(users, check.HasLen, 1)
+	c.Assert(users[0].LockedUntil, check.NotNil)
+	request, err = http.NewRequest(http.MethodPost, "/1.33/users/nobody@globo.com/unlock", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.update.unlock",
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestUnlockUserWithoutPermission(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c)
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/nobody@globo.com/unlock", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *AuthSuite) TestLogout(c *check.C) {
 	token, err := nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
 	c.Assert(err, check.IsNil)
@@ -9,12 +9,14 @@ import (
 	"fmt"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/builder"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
@@ -55,6 +57,7 @@ func init() {
 //	400: Invalid data
 //	403: Forbidden
 //	404: Not found
+//	413: Archive too large
 func build(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	startingBuildTime := time.Now()
 	ctx := r.Context()
@@ -88,6 +91,10 @@ func build(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if !canBuild {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "User does not have permission to do this action in this app"}
 	}
+	err = checkSkipScan(ctx, t, opts)
+	if err != nil {
+		return err
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppBuild,
@@ -132,11 +139,16 @@ func prepareToBuild(r *http.Request) (opts app.DeployOptions, err error) {
 		}
 
 		opts.FileSize = fh.Size
+		if err = checkArchiveSize(opts.FileSize); err != nil {
+			return opts, err
+		}
 	}
 
 	opts.ArchiveURL = InputValue(r, "archive-url")
 	opts.Image = InputValue(r, "image")
 	opts.Dockerfile = InputValue(r, "dockerfile")
+	opts.NoCache, _ = strconv.ParseBool(InputValue(r, "no-cache"))
+	opts.SkipScan, _ = strconv.ParseBool(InputValue(r, "skip-scan"))
 
 	if opts.ArchiveURL != "" && (opts.FileSize > 0 || opts.Image != "" || opts.Dockerfile != "") {
 		return opts, &tsuruErrors.HTTP{
@@ -172,6 +184,9 @@ func prepareToBuildJob(r *http.Request) (opts jobTypes.DeployOptions, err error)
 		}
 
 		opts.FileSize = fh.Size
+		if err = checkArchiveSize(opts.FileSize); err != nil {
+			return opts, err
+		}
 	}
 
 	opts.Image = InputValue(r, "image")
@@ -193,3 +208,13 @@ func prepareToBuildJob(r *http.Request) (opts jobTypes.DeployOptions, err error)
 
 	return
 }
+
+func checkArchiveSize(size int64) error {
+	if max := builder.MaxArchiveSize(); size > max {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("archive has %d bytes, the maximum size is %d bytes", size, max),
+		}
+	}
+	return nil
+}
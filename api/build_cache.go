@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+type buildCacheResponse struct {
+	Enabled   bool       `json:"enabled"`
+	Shared    bool       `json:"shared"`
+	MaxAge    string     `json:"maxAge,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+func newBuildCacheResponse(settings appTypes.BuildCache) buildCacheResponse {
+	resp := buildCacheResponse{
+		Enabled:   !settings.Disabled,
+		Shared:    !settings.Isolated,
+		CreatedAt: settings.CreatedAt,
+	}
+	if settings.MaxAge > 0 {
+		resp.MaxAge = settings.MaxAge.String()
+	}
+	return resp
+}
+
+// buildCacheFromInput applies the fields set in the request to the current
+// settings of the build cache.
+func buildCacheFromInput(r *http.Request, settings appTypes.BuildCache) (appTypes.BuildCache, error) {
+	var changed bool
+	if raw := InputValue(r, "enabled"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return settings, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid enabled value %q", raw)}
+		}
+		settings.Disabled = !enabled
+		changed = true
+	}
+	if raw := InputValue(r, "shared"); raw != "" {
+		shared, err := strconv.ParseBool(raw)
+		if err != nil {
+			return settings, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid shared value %q", raw)}
+		}
+		settings.Isolated = !shared
+		changed = true
+	}
+	if raw := InputValue(r, "max-age"); raw != "" {
+		maxAge, err := time.ParseDuration(raw)
+		if err != nil || maxAge < 0 {
+			return settings, &errors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid max-age %q, it must be a duration, like 168h, or 0 to disable it", raw),
+			}
+		}
+		settings.MaxAge = maxAge
+		changed = true
+	}
+	if !changed {
+		return settings, &errors.HTTP{Code: http.StatusBadRequest, Message: "at least one of enabled, shared or max-age must be set"}
+	}
+	return settings, nil
+}
+
+// title: app build cache info
+// path: /apps/{app}/build/cache
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func appBuildCacheInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(ctx, t, permission.PermAppRead, contextsForApp(a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(newBuildCacheResponse(a.BuildCache))
+}
+
+// title: app build cache update
+// path: /apps/{app}/build/cache
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Build cache updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func appBuildCacheUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canUpdate := permission.Check(ctx, t, permission.PermAppUpdateBuildCache, contextsForApp(a)...)
+	if !canUpdate {
+		return permission.ErrUnauthorized
+	}
+	settings, err := buildCacheFromInput(r, a.BuildCache)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateBuildCache,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.SetBuildCache(ctx, a, settings)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(newBuildCacheResponse(a.BuildCache))
+}
+
+// title: app build cache purge
+// path: /apps/{app}/build/cache/purge
+// method: POST
+// responses:
+//
+//	200: Build cache purged
+//	401: Unauthorized
+//	404: App not found
+func appBuildCachePurge(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canPurge := permission.Check(ctx, t, permission.PermAppUpdateBuildCachePurge, contextsForApp(a)...)
+	if !canPurge {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateBuildCachePurge,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.PurgeBuildCache(ctx, a)
+}
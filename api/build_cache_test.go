@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppBuildCacheInfo(c *check.C) {
+	myapp := appTypes.App{
+		Name:       "myapp",
+		Platform:   "go",
+		TeamOwner:  s.team.Name,
+		BuildCache: appTypes.BuildCache{Isolated: true, MaxAge: 72 * time.Hour},
+	}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/apps/myapp/build/cache", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var result buildCacheResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, buildCacheResponse{Enabled: true, MaxAge: "72h0m0s"})
+}
+
+func (s *S) TestAppBuildCacheUpdate(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/myapp/build/cache", strings.NewReader("shared=false&max-age=168h"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.BuildCache, check.DeepEquals, appTypes.BuildCache{Isolated: true, MaxAge: 168 * time.Hour})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.build-cache",
+		StartCustomData: []map[string]interface{}{
+			{"name": "shared", "value": "false"},
+			{"name": "max-age", "value": "168h"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppBuildCacheUpdateInvalid(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	for _, body := range []string{"", "enabled=maybe", "max-age=a-week", "max-age=-1h"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/1.33/apps/myapp/build/cache", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %q", body))
+	}
+}
+
+func (s *S) TestAppBuildCacheUpdateUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateBuildCachePurge,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/myapp/build/cache", strings.NewReader("enabled=false"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppBuildCachePurge(c *check.C) {
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	myapp := appTypes.App{
+		Name:       "myapp",
+		Platform:   "go",
+		TeamOwner:  s.team.Name,
+		BuildCache: appTypes.BuildCache{CreatedAt: &createdAt},
+	}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/myapp/build/cache/purge", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.UpdatePlatform, check.Equals, true)
+	c.Assert(dbApp.BuildCache.CreatedAt, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.build-cache.purge",
+	}, eventtest.HasEvent)
+}
@@ -5,6 +5,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -73,6 +74,7 @@ func init() {
 //	400: Invalid data
 //	403: Forbidden
 //	404: Not found
+//	413: Archive too large
 func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	startingDeployTime := time.Now()
 	ctx := r.Context()
@@ -116,6 +118,10 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if !canDeploy {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "User does not have permission to do this action in this app"}
 	}
+	err = checkSkipScan(ctx, t, opts)
+	if err != nil {
+		return err
+	}
 
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
@@ -160,6 +166,7 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 //	400: Invalid data
 //	403: Forbidden
 //	404: Not found
+//	413: Archive too large
 func jobDeploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	startingDeployTime := time.Now()
 	ctx := r.Context()
@@ -249,6 +256,18 @@ func deployStatus(evt *event.Event) string {
 	return "success"
 }
 
+// checkSkipScan ensures the user is allowed to skip the image scan when it
+// was requested for the deploy.
+func checkSkipScan(ctx context.Context, t auth.Token, opts app.DeployOptions) error {
+	if !opts.SkipScan {
+		return nil
+	}
+	if !permission.Check(ctx, t, permission.PermAppAdminSkipScan, contextsForApp(opts.App)...) {
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "User does not have permission to skip the image scan in this app"}
+	}
+	return nil
+}
+
 func permSchemeForDeploy(opts app.DeployOptions) *permTypes.PermissionScheme {
 	switch opts.GetKind() {
 	case provisionTypes.DeployGit:
@@ -9,12 +9,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/recording"
 	"github.com/tsuru/tsuru/permission"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -126,6 +128,54 @@ func eventInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(eventInfo)
 }
 
+// title: event recording
+// path: /events/{uuid}/recording
+// method: GET
+// produce: application/x-asciicast
+// responses:
+//
+//	200: OK
+//	400: Invalid uuid
+//	401: Unauthorized
+//	404: Not found
+func eventRecording(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	uuid := r.URL.Query().Get(":uuid")
+	if _, err := primitive.ObjectIDFromHex(uuid); err != nil {
+		msg := fmt.Sprintf("uuid parameter is not ObjectId: %s", uuid)
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	e, err := event.GetByHexID(ctx, uuid)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	scheme, err := permission.SafeGet(e.Allowed.Scheme)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, scheme, e.Allowed.Contexts...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	// recordings hold the whole input and output of the session, so they're
+	// only available to those allowed to run the recorded command.
+	kindScheme, err := permission.SafeGet(e.Kind.Name)
+	if err != nil || !permission.Check(ctx, t, kindScheme, e.Allowed.Contexts...) {
+		return permission.ErrUnauthorized
+	}
+	rec, err := recording.Get(ctx, e.UniqueID)
+	if err == recording.ErrRecordingNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", recording.ContentType)
+	w.Header().Set("X-Tsuru-Recording-Truncated", strconv.FormatBool(rec.Truncated))
+	_, err = w.Write(rec.Data)
+	return err
+}
+
 // title: event cancel
 // path: /events/{uuid}/cancel
 // method: POST
@@ -184,12 +234,18 @@ func eventBlockList(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	if !permission.Check(ctx, t, permission.PermEventBlockRead) {
 		return permission.ErrUnauthorized
 	}
-	var active *bool
-	if activeStr := InputValue(r, "active"); activeStr != "" {
-		b, _ := strconv.ParseBool(activeStr)
-		active = &b
+	var blocks []event.Block
+	var err error
+	if upcoming, _ := strconv.ParseBool(InputValue(r, "upcoming")); upcoming {
+		blocks, err = event.ListUpcomingBlocks(ctx)
+	} else {
+		var active *bool
+		if activeStr := InputValue(r, "active"); activeStr != "" {
+			b, _ := strconv.ParseBool(activeStr)
+			active = &b
+		}
+		blocks, err = event.ListBlocks(ctx, active)
 	}
-	blocks, err := event.ListBlocks(ctx, active)
 	if err != nil {
 		return err
 	}
@@ -223,6 +279,12 @@ func eventBlockAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	if block.Reason == "" {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: "reason is required"}
 	}
+	if durationStr := InputValue(r, "duration"); durationStr != "" {
+		block.Duration, err = time.ParseDuration(durationStr)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid duration %q: %v", durationStr, err)}
+		}
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     eventTypes.Target{Type: eventTypes.TargetTypeEventBlock},
 		Kind:       permission.PermEventBlockAdd,
@@ -249,7 +311,7 @@ func eventBlockAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 //	200: OK
 //	400: Invalid uuid
 //	401: Unauthorized
-//	404: Active block with provided uuid not found
+//	404: Active or scheduled block with provided uuid not found
 func eventBlockRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	if !permission.Check(ctx, t, permission.PermEventBlockRemove) {
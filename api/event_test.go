@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/cezarsa/form"
 	"github.com/tsuru/config"
@@ -19,6 +20,7 @@ import (
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/event/recording"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
 	"github.com/tsuru/tsuru/router/routertest"
@@ -528,6 +530,108 @@ func (s *EventSuite) TestEventInfoWithoutPermission(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
 }
 
+func (s *EventSuite) TestEventRecording(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppRunShell,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:      eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha"},
+		Owner:       s.token,
+		Kind:        permission.PermAppRunShell,
+		Allowed:     event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	rec := recording.NewRecorder(recording.Header{})
+	rec.Record(recording.Output, []byte("hello"))
+	err = rec.Save(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("/1.33/events/%s/recording", evt.UniqueID.Hex())
+	request, err := http.NewRequest("GET", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-asciicast")
+	c.Assert(recorder.Header().Get("X-Tsuru-Recording-Truncated"), check.Equals, "false")
+	c.Assert(recorder.Body.Bytes(), check.DeepEquals, rec.Bytes())
+}
+
+func (s *EventSuite) TestEventRecordingNotFound(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha"},
+		Owner:   s.token,
+		Kind:    permission.PermAppDeploy,
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+	})
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("/1.33/events/%s/recording", evt.UniqueID.Hex())
+	request, err := http.NewRequest("GET", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "recording not found\n")
+}
+
+func (s *EventSuite) TestEventRecordingWithoutPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, "some-other-team"),
+	})
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:      eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha"},
+		Owner:       s.token,
+		Kind:        permission.PermAppRunShell,
+		Allowed:     event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	err = recording.NewRecorder(recording.Header{}).Save(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("/1.33/events/%s/recording", evt.UniqueID.Hex())
+	request, err := http.NewRequest("GET", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *EventSuite) TestEventRecordingRequiresKindPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:      eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "aha"},
+		Owner:       s.token,
+		Kind:        permission.PermAppRunShell,
+		Allowed:     event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxTeam, s.team.Name)),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	err = recording.NewRecorder(recording.Header{}).Save(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("/1.33/events/%s/recording", evt.UniqueID.Hex())
+	request, err := http.NewRequest("GET", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *EventSuite) TestEventCancelPermission(c *check.C) {
 	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
 		Scheme:  permission.PermAppUpdate,
@@ -623,6 +727,30 @@ func (s *EventSuite) TestEventBlockListFiltered(c *check.C) {
 	c.Assert(blocks[1].Active, check.Equals, true)
 }
 
+func (s *EventSuite) TestEventBlockListUpcoming(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermEventBlockRead,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	addBlocks(c)
+	scheduled := &event.Block{KindName: "app.deploy", Reason: "maintenance", StartTime: time.Now().Add(time.Hour)}
+	err := event.AddBlock(context.TODO(), scheduled)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/events/blocks?upcoming=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var blocks []event.Block
+	err = json.NewDecoder(recorder.Body).Decode(&blocks)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].ID.Hex(), check.Equals, scheduled.ID.Hex())
+	c.Assert(blocks[0].Active, check.Equals, false)
+}
+
 func (s *EventSuite) TestEventBlockListWithoutPermission(c *check.C) {
 	request, err := http.NewRequest("GET", "/events/blocks", nil)
 	c.Assert(err, check.IsNil)
@@ -673,6 +801,48 @@ func (s *EventSuite) TestEventBlockAdd(c *check.C) {
 	c.Assert(blocks[0].Reason, check.Equals, "block reason")
 }
 
+func (s *EventSuite) TestEventBlockAddRecurring(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermEventBlockAdd,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	body := strings.NewReader("KindName=app.deploy&Reason=weekly+maintenance&Schedule=0+3+*+*+6&duration=2h")
+	request, err := http.NewRequest("POST", "/events/blocks", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	blocks, err := event.ListBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].Schedule, check.Equals, "0 3 * * 6")
+	c.Assert(blocks[0].Duration, check.Equals, 2*time.Hour)
+	c.Assert(blocks[0].EndTime.Sub(blocks[0].StartTime), check.Equals, 2*time.Hour)
+}
+
+func (s *EventSuite) TestEventBlockAddInvalidDuration(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermEventBlockAdd,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	body := strings.NewReader("KindName=app.deploy&Reason=maintenance&Schedule=0+3+*+*+6&duration=forever")
+	request, err := http.NewRequest("POST", "/events/blocks", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `invalid duration "forever": .*\n`)
+	blocks, err := event.ListBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 0)
+}
+
 func (s *EventSuite) TestEventBlockAddWithoutPermission(c *check.C) {
 	block := &event.Block{KindName: "app.deploy", Reason: "block reason"}
 	values, err := form.EncodeToValues(block)
@@ -0,0 +1,208 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/job"
+	"github.com/tsuru/tsuru/permission"
+)
+
+type expirationResponse struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// expirationFromTTL returns from plus the ttl, or nil when the ttl is empty.
+func expirationFromTTL(ttl string, from time.Time) (*time.Time, error) {
+	if ttl == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return nil, &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid ttl %q, it must be a positive duration, like 72h", ttl),
+		}
+	}
+	expiresAt := from.Add(d).UTC()
+	return &expiresAt, nil
+}
+
+// extendedExpiration adds the ttl to the current expiration, or to the
+// current time when there's no expiration or it's already past.
+func extendedExpiration(ttl string, current *time.Time) (*time.Time, error) {
+	if ttl == "" {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: "ttl is required"}
+	}
+	from := time.Now()
+	if current != nil && current.After(from) {
+		from = *current
+	}
+	return expirationFromTTL(ttl, from)
+}
+
+// title: app extend ttl
+// path: /apps/{app}/ttl
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Expiration extended
+//	400: Invalid ttl
+//	401: Unauthorized
+//	404: App not found
+func appExtendTTL(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canUpdate := permission.Check(ctx, t, permission.PermAppUpdateExpiration, contextsForApp(a)...)
+	if !canUpdate {
+		return permission.ErrUnauthorized
+	}
+	expiresAt, err := extendedExpiration(InputValue(r, "ttl"), a.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateExpiration,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = app.SetExpiration(ctx, a, expiresAt)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(expirationResponse{ExpiresAt: expiresAt})
+}
+
+// title: app pin
+// path: /apps/{app}/pin
+// method: POST
+// responses:
+//
+//	200: App pinned
+//	401: Unauthorized
+//	404: App not found
+func appPin(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canUpdate := permission.Check(ctx, t, permission.PermAppUpdateExpiration, contextsForApp(a)...)
+	if !canUpdate {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateExpiration,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]interface{}{"pin": true},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.SetExpiration(ctx, a, nil)
+}
+
+// title: job extend ttl
+// path: /jobs/{name}/ttl
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Expiration extended
+//	400: Invalid ttl
+//	401: Unauthorized
+//	404: Job not found
+func jobExtendTTL(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	canUpdate := permission.Check(ctx, t, permission.PermJobUpdateExpiration, contextsForJob(j)...)
+	if !canUpdate {
+		return permission.ErrUnauthorized
+	}
+	expiresAt, err := extendedExpiration(InputValue(r, "ttl"), j.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     jobTarget(j.Name),
+		Kind:       permission.PermJobUpdateExpiration,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermJobReadEvents, contextsForJob(j)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = job.SetExpiration(ctx, j, expiresAt)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(expirationResponse{ExpiresAt: expiresAt})
+}
+
+// title: job pin
+// path: /jobs/{name}/pin
+// method: POST
+// responses:
+//
+//	200: Job pinned
+//	401: Unauthorized
+//	404: Job not found
+func jobPin(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	canUpdate := permission.Check(ctx, t, permission.PermJobUpdateExpiration, contextsForJob(j)...)
+	if !canUpdate {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     jobTarget(j.Name),
+		Kind:       permission.PermJobUpdateExpiration,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]interface{}{"pin": true},
+		Allowed:    event.Allowed(permission.PermJobReadEvents, contextsForJob(j)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return job.SetExpiration(ctx, j, nil)
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppExtendTTL(c *check.C) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, ExpiresAt: &expiresAt}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/myapp/ttl", strings.NewReader("ttl=48h"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var result expirationResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ExpiresAt.Equal(expiresAt.Add(48*time.Hour)), check.Equals, true)
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ExpiresAt.Equal(expiresAt.Add(48*time.Hour)), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.expiration",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppExtendTTLInvalidTTL(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	for _, body := range []string{"", "ttl=tomorrow", "ttl=-1h"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/1.33/apps/myapp/ttl", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %q", body))
+	}
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ExpiresAt, check.IsNil)
+}
+
+func (s *S) TestAppExtendTTLUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/myapp/ttl", strings.NewReader("ttl=1h"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppPin(c *check.C) {
+	expiresAt := time.Now().Add(time.Hour)
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, ExpiresAt: &expiresAt}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.33/apps/myapp/pin", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ExpiresAt, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.expiration",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestCreateAppWithTTL(c *check.C) {
+	s.setupMockForCreateApp(c, "zend")
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader("name=myapp&platform=zend&teamOwner=" + s.team.Name + "&ttl=24h")
+	request, err := http.NewRequest("POST", "/apps", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %q", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ExpiresAt, check.NotNil)
+	c.Assert(dbApp.ExpiresAt.After(time.Now().Add(23*time.Hour)), check.Equals, true)
+}
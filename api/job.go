@@ -9,6 +9,7 @@ import (
 	stdContext "context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -55,6 +56,9 @@ type inputJob struct {
 	Trigger               bool                   `json:"trigger"` // Trigger means the client wants to forcefully run a job
 	ActiveDeadlineSeconds *int64                 `json:"activeDeadlineSeconds,omitempty"`
 	ConcurrencyPolicy     *string                `json:"concurrencyPolicy,omitempty"`
+	RunsHistoryLimit      *int                   `json:"runsHistoryLimit,omitempty"`
+	Triggers              *jobTypes.JobTriggers  `json:"triggers,omitempty"`
+	TTL                   string                 `json:"ttl,omitempty"`
 }
 
 func getJob(ctx stdContext.Context, name string) (*jobTypes.Job, error) {
@@ -368,6 +372,10 @@ func updateJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !canUpdate {
 		return permission.ErrUnauthorized
 	}
+	err = checkJobTriggers(ctx, t, ij.Triggers)
+	if err != nil {
+		return err
+	}
 	user, err := t.User(ctx)
 	if err != nil {
 		return err
@@ -386,6 +394,8 @@ func updateJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			Container:             ij.Container,
 			Manual:                ij.Manual,
 			ActiveDeadlineSeconds: ij.ActiveDeadlineSeconds,
+			RunsHistoryLimit:      ij.RunsHistoryLimit,
+			Triggers:              ij.Triggers,
 		},
 	}
 
@@ -479,11 +489,17 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			Manual:            ij.Manual,
 			Schedule:          ij.Schedule,
 			Container:         ij.Container,
+			RunsHistoryLimit:  ij.RunsHistoryLimit,
+			Triggers:          ij.Triggers,
 		},
 	}
 	if ij.ActiveDeadlineSeconds != nil && *ij.ActiveDeadlineSeconds >= 0 {
 		j.Spec.ActiveDeadlineSeconds = ij.ActiveDeadlineSeconds
 	}
+	j.ExpiresAt, err = expirationFromTTL(ij.TTL, time.Now())
+	if err != nil {
+		return err
+	}
 
 	tagResponse, err := servicemanager.Tag.Validate(ctx, &tagTypes.TagValidationRequest{
 		Operation: tagTypes.OperationKind_OPERATION_KIND_CREATE,
@@ -508,6 +524,10 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !canCreate {
 		return permission.ErrUnauthorized
 	}
+	err = checkJobTriggers(ctx, t, j.Spec.Triggers)
+	if err != nil {
+		return err
+	}
 	u, err := t.User(ctx)
 	if err != nil {
 		return err
@@ -1034,10 +1054,90 @@ func jobLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return followLogs(tsuruNet.CancelableParentContext(r.Context()), j.Name, watcher, encoder)
 }
 
+// title: job runs
+// path: /jobs/{name}/runs
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+//	404: Job not found
+func jobRuns(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	runs, err := servicemanager.Job.ListRuns(ctx, j)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(runs)
+}
+
+// title: job run log
+// path: /jobs/{name}/runs/{id}/log
+// method: GET
+// produce: text/plain
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Job or run not found
+func jobRunLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobReadLogs, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	run, err := servicemanager.Job.GetRun(ctx, j, r.URL.Query().Get(":id"))
+	if err != nil {
+		if err == jobTypes.ErrJobRunNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, err = io.WriteString(w, run.Log)
+	return err
+}
+
 func jobTarget(jobName string) eventTypes.Target {
 	return eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: jobName}
 }
 
+// checkJobTriggers ensures the token is allowed to trigger every job in the
+// triggers, as triggered jobs run without the token of the user. Jobs that
+// don't exist are reported when the triggers are validated.
+func checkJobTriggers(ctx stdContext.Context, t auth.Token, triggers *jobTypes.JobTriggers) error {
+	for _, name := range triggers.Jobs() {
+		triggered, err := servicemanager.Job.GetByName(ctx, name)
+		if err == jobTypes.ErrJobNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !permission.Check(ctx, t, permission.PermJobTrigger, contextsForJob(triggered)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	return nil
+}
+
 func contextsForJob(job *jobTypes.Job) []permTypes.PermissionContext {
 	return append([]permTypes.PermissionContext{},
 		permission.Context(permTypes.CtxTeam, job.TeamOwner),
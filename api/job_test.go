@@ -267,6 +267,68 @@ func (s *S) TestCreateFullyFeaturedCronjob(c *check.C) {
 	c.Assert(gotJob, check.DeepEquals, expectedJob)
 }
 
+func (s *S) TestCreateJobTriggersRequireTriggerPermission(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		return &provisiontest.JobProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	defer provision.Unregister("jobProv")
+	downstream := jobTypes.Job{
+		TeamOwner: s.team.Name,
+		Pool:      "test1",
+		Name:      "downstream",
+		Spec:      jobTypes.JobSpec{Manual: true},
+		DeployOptions: &jobTypes.DeployOptions{
+			Kind:  provTypes.DeployImage,
+			Image: "busybox:1.28",
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &downstream, user)
+	c.Assert(err, check.IsNil)
+	s.mockService.UserQuota.OnInc = func(item quota.QuotaItem, q int) error {
+		return nil
+	}
+	createJob := func(name string, token auth.Token) *httptest.ResponseRecorder {
+		j := inputJob{
+			Name:      name,
+			TeamOwner: s.team.Name,
+			Pool:      "test1",
+			Plan:      "default-plan",
+			Container: jobTypes.ContainerInfo{OriginalImageSrc: "busybox:1.28"},
+			Schedule:  "* * * * *",
+			Triggers:  &jobTypes.JobTriggers{OnSuccess: []string{"downstream"}},
+		}
+		var buffer bytes.Buffer
+		encodeErr := json.NewEncoder(&buffer).Encode(j)
+		c.Assert(encodeErr, check.IsNil)
+		request, reqErr := http.NewRequest("POST", "/jobs", &buffer)
+		c.Assert(reqErr, check.IsNil)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "b "+token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		return recorder
+	}
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermJobCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	recorder := createJob("upstream", token)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	token = userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermJobCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermJobTrigger,
+		Context: permission.Context(permTypes.CtxJob, "downstream"),
+	})
+	recorder = createJob("upstream", token)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+}
+
 func (s *S) TestCreateManualJob(c *check.C) {
 	oldProvisioner := provision.DefaultProvisioner
 	defer func() { provision.DefaultProvisioner = oldProvisioner }()
@@ -3724,3 +3786,116 @@ func (s *S) TestJobListFilteringByTags(c *check.C) {
 	c.Assert(jobs[0].Tags, check.DeepEquals, []string{"tag1", "tag2"})
 	c.Assert(jobs[1].Tags, check.DeepEquals, []string{"tag2", "tag3"})
 }
+
+func (s *S) createJobWithRuns(c *check.C) *jobTypes.Job {
+	j := jobTypes.Job{
+		Name:      "job-with-runs",
+		Pool:      s.Pool,
+		TeamOwner: s.team.Name,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+		},
+		DeployOptions: &jobTypes.DeployOptions{
+			Kind:  provTypes.DeployImage,
+			Image: "busybox:1.18",
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, user)
+	c.Assert(err, check.IsNil)
+	start := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	exitCode := int32(0)
+	runs := []jobTypes.JobRun{
+		{ID: "run-0", Job: j.Name, Unit: "job-with-runs-0", Trigger: jobTypes.RunTriggerCron, Status: jobTypes.RunStatusSucceeded, StartTime: start, EndTime: &end, ExitCode: &exitCode, Log: "hello\nworld\n"},
+		{ID: "run-1", Job: j.Name, Unit: "job-with-runs-1", Trigger: jobTypes.RunTriggerManual, Status: jobTypes.RunStatusRunning, StartTime: end},
+	}
+	for i := range runs {
+		err = servicemanager.Job.SaveRun(context.TODO(), &runs[i])
+		c.Assert(err, check.IsNil)
+	}
+	return &j
+}
+
+func (s *S) TestJobRuns(c *check.C) {
+	j := s.createJobWithRuns(c)
+	request, err := http.NewRequest("GET", fmt.Sprintf("/1.33/jobs/%s/runs", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var runs []jobTypes.JobRun
+	err = json.Unmarshal(recorder.Body.Bytes(), &runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 2)
+	c.Assert(runs[0].ID, check.Equals, "run-1")
+	c.Assert(runs[0].Trigger, check.Equals, jobTypes.RunTriggerManual)
+	c.Assert(runs[0].EndTime, check.IsNil)
+	c.Assert(runs[1].ID, check.Equals, "run-0")
+	c.Assert(runs[1].Status, check.Equals, jobTypes.RunStatusSucceeded)
+	c.Assert(*runs[1].ExitCode, check.Equals, int32(0))
+	c.Assert(runs[1].Log, check.Equals, "")
+}
+
+func (s *S) TestJobRunsNoContent(c *check.C) {
+	j := jobTypes.Job{
+		Name:      "job-without-runs",
+		Pool:      s.Pool,
+		TeamOwner: s.team.Name,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+		},
+		DeployOptions: &jobTypes.DeployOptions{
+			Kind:  provTypes.DeployImage,
+			Image: "busybox:1.18",
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", fmt.Sprintf("/1.33/jobs/%s/runs", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestJobRunsForbidden(c *check.C) {
+	j := s.createJobWithRuns(c)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermJobRead,
+		Context: permission.Context(permTypes.CtxTeam, "no-access"),
+	})
+	request, err := http.NewRequest("GET", fmt.Sprintf("/1.33/jobs/%s/runs", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestJobRunLog(c *check.C) {
+	j := s.createJobWithRuns(c)
+	request, err := http.NewRequest("GET", fmt.Sprintf("/1.33/jobs/%s/runs/run-0/log", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/plain")
+	c.Assert(recorder.Body.String(), check.Equals, "hello\nworld\n")
+}
+
+func (s *S) TestJobRunLogNotFound(c *check.C) {
+	j := s.createJobWithRuns(c)
+	request, err := http.NewRequest("GET", fmt.Sprintf("/1.33/jobs/%s/runs/unknown/log", j.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, jobTypes.ErrJobRunNotFound.Error()+"\n")
+}
@@ -0,0 +1,256 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	"sigs.k8s.io/yaml"
+)
+
+type manifestStep struct {
+	change       appTypes.ManifestChange
+	kind         *permTypes.PermissionScheme
+	extraTargets []eventTypes.ExtraTarget
+}
+
+var manifestPermissions = map[string]map[string]*permTypes.PermissionScheme{
+	appTypes.ManifestFieldDescription: {appTypes.ManifestActionUpdate: permission.PermAppUpdateDescription},
+	appTypes.ManifestFieldPlan:        {appTypes.ManifestActionUpdate: permission.PermAppUpdatePlan},
+	appTypes.ManifestFieldTags:        {appTypes.ManifestActionUpdate: permission.PermAppUpdateTags},
+	appTypes.ManifestFieldMetadata:    {appTypes.ManifestActionUpdate: permission.PermAppUpdateMetadata},
+	appTypes.ManifestFieldProcess: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateProcesses,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateProcesses,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateProcesses,
+	},
+	appTypes.ManifestFieldEnv: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateEnvSet,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateEnvSet,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateEnvUnset,
+	},
+	appTypes.ManifestFieldCName: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateCnameAdd,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateCnameRemove,
+	},
+	appTypes.ManifestFieldRouter: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateRouterAdd,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateRouterUpdate,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateRouterRemove,
+	},
+	appTypes.ManifestFieldAutoscale: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateUnitAutoscaleAdd,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateUnitAutoscaleAdd,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateUnitAutoscaleRemove,
+	},
+	appTypes.ManifestFieldService: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateBind,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateUnbind,
+	},
+	appTypes.ManifestFieldVolume: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateBindVolume,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateBindVolume,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateUnbindVolume,
+	},
+}
+
+// title: app manifest apply
+// path: /apps/{app}/manifest
+// method: PUT
+// consume: application/x-yaml
+// produce: application/json
+// responses:
+//
+//	200: Changes applied
+//	400: Invalid manifest
+//	401: Unauthorized
+//	404: App not found
+func appManifestApply(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	data, err := context.GetBody(r)
+	if err != nil {
+		return err
+	}
+	var manifest appTypes.Manifest
+	err = yaml.UnmarshalStrict(data, &manifest)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("unable to parse manifest: %v", err)}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(ctx, t, permission.PermAppRead, contextsForApp(a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	changes, err := app.DiffManifest(ctx, a, &manifest)
+	if err != nil {
+		return err
+	}
+	steps := make([]manifestStep, 0, len(changes))
+	for _, change := range changes {
+		step, stepErr := prepareManifestStep(ctx, t, a, change)
+		if stepErr != nil {
+			return stepErr
+		}
+		steps = append(steps, step)
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run"))
+	if !dryRun {
+		noRestart, _ := strconv.ParseBool(r.URL.Query().Get("noRestart"))
+		err = applyManifestSteps(ctx, r, t, a, steps, !noRestart)
+		if err != nil {
+			return manifestError(err)
+		}
+	}
+	if changes == nil {
+		changes = []appTypes.ManifestChange{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(changes)
+}
+
+func prepareManifestStep(ctx stdContext.Context, t auth.Token, a *appTypes.App, change appTypes.ManifestChange) (manifestStep, error) {
+	step := manifestStep{change: change, kind: manifestPermissions[change.Field][change.Action]}
+	if step.kind == nil {
+		return step, pkgErrors.Errorf("no permission for manifest change %s %s", change.Action, change.Field)
+	}
+	if !permission.Check(ctx, t, step.kind, contextsForApp(a)...) {
+		return step, permission.ErrUnauthorized
+	}
+	switch change.Field {
+	case appTypes.ManifestFieldService:
+		s := change.Desired.(appTypes.ManifestService)
+		instance, err := getServiceInstanceOrError(ctx, s.Service, s.Instance)
+		if err != nil {
+			return step, err
+		}
+		instancePerm := permission.PermServiceInstanceUpdateBind
+		if change.Action == appTypes.ManifestActionRemove {
+			instancePerm = permission.PermServiceInstanceUpdateUnbind
+		}
+		allowed := permission.Check(ctx, t, instancePerm,
+			append(permission.Contexts(permTypes.CtxTeam, instance.Teams),
+				permission.Context(permTypes.CtxTeam, instance.TeamOwner),
+				permission.Context(permTypes.CtxServiceInstance, instance.Name),
+			)...,
+		)
+		if !allowed {
+			return step, permission.ErrUnauthorized
+		}
+		step.extraTargets = []eventTypes.ExtraTarget{
+			{Target: serviceInstanceTarget(s.Service, s.Instance), Lock: true},
+		}
+	case appTypes.ManifestFieldVolume:
+		v := change.Desired.(appTypes.ManifestVolume)
+		dbVolume, err := servicemanager.Volume.Get(ctx, v.Name)
+		if err != nil {
+			if err == volumeTypes.ErrVolumeNotFound {
+				return step, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+			}
+			return step, err
+		}
+		volumePerm := permission.PermVolumeUpdateBind
+		if change.Action == appTypes.ManifestActionRemove {
+			volumePerm = permission.PermVolumeUpdateUnbind
+		}
+		if !permission.Check(ctx, t, volumePerm, contextsForVolume(dbVolume)...) {
+			return step, permission.ErrUnauthorized
+		}
+		step.extraTargets = []eventTypes.ExtraTarget{
+			{Target: eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: dbVolume.Name}},
+		}
+	}
+	return step, nil
+}
+
+func applyManifestSteps(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App, steps []manifestStep, shouldRestart bool) error {
+	var needsRestart bool
+	for _, step := range steps {
+		err := applyManifestStep(ctx, r, t, a, step)
+		if err != nil {
+			return err
+		}
+		needsRestart = needsRestart || app.ManifestChangeRequiresRestart(step.change)
+	}
+	if !needsRestart || !shouldRestart {
+		return nil
+	}
+	return restartAfterManifest(ctx, r, t, a)
+}
+
+func applyManifestStep(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App, step manifestStep) (err error) {
+	evt, err := event.New(ctx, &event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: step.extraTargets,
+		Kind:         step.kind,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   step.change,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	// the app is fetched again as it may have been changed while waiting for
+	// the event lock.
+	current, err := app.GetByName(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	return app.ApplyManifestChange(ctx, current, step.change, evt)
+}
+
+func restartAfterManifest(ctx stdContext.Context, r *http.Request, t auth.Token, a *appTypes.App) (err error) {
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateRestart,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	current, err := app.GetByName(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	return app.RestartForManifest(ctx, current, evt)
+}
+
+func manifestError(err error) error {
+	cause := pkgErrors.Cause(err)
+	switch cause {
+	case appTypes.ErrPlanNotFound, pool.ErrPoolHasNoRouter, pool.ErrPoolHasNoService, app.ErrRouterAlreadyLinked:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if _, ok := cause.(*router.ErrRouterNotFound); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
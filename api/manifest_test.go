@@ -0,0 +1,139 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppManifestApply(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Description: "old"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("description: new description\ncnames:\n- myapp.io\n")
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/myapp/manifest", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-yaml")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var changes []appTypes.ManifestChange
+	err = json.Unmarshal(recorder.Body.Bytes(), &changes)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []appTypes.ManifestChange{
+		{Field: appTypes.ManifestFieldDescription, Action: appTypes.ManifestActionUpdate, Before: "old", After: "new description"},
+		{Field: appTypes.ManifestFieldCName, Action: appTypes.ManifestActionAdd, Name: "myapp.io"},
+	})
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "new description")
+	c.Assert(dbApp.CName, check.DeepEquals, []string{"myapp.io"})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.description",
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.cname.add",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppManifestApplyDryRun(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Description: "old"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"description": "new description"}`)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/myapp/manifest?dry-run=true", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var changes []appTypes.ManifestChange
+	err = json.Unmarshal(recorder.Body.Bytes(), &changes)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 1)
+	c.Assert(changes[0].Field, check.Equals, appTypes.ManifestFieldDescription)
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "old")
+}
+
+func (s *S) TestAppManifestApplyNoChanges(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Description: "desc"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("description: desc\n")
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/myapp/manifest", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "[]\n")
+}
+
+func (s *S) TestAppManifestApplyInvalidManifest(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	for _, manifest := range []string{"description: [", "unknown: field\n"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/1.33/apps/myapp/manifest", strings.NewReader(manifest))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(recorder.Body.String(), check.Matches, "unable to parse manifest: .*\n")
+	}
+}
+
+func (s *S) TestAppManifestApplyUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateDescription,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("description: new\ncnames:\n- myapp.io\n")
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/myapp/manifest", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	dbApp, err := app.GetByName(context.TODO(), myapp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "")
+}
+
+func (s *S) TestAppManifestApplyAppNotFound(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/1.33/apps/unknown/manifest", strings.NewReader("description: x\n"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
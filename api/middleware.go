@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	stdIO "io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -24,6 +25,7 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/api/observability"
+	"github.com/tsuru/tsuru/api/ratelimit"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/auth/peer"
@@ -64,6 +66,20 @@ var (
 		Name:      "token_invalid_total",
 		Help:      "The number of unsuccessful validation of tokens",
 	})
+
+	rateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: promSubsystem,
+		Name:      "rate_limited_total",
+		Help:      "The number of requests rejected by the rate limiter",
+	}, []string{"route"})
+
+	rateLimitErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: promSubsystem,
+		Name:      "rate_limit_errors_total",
+		Help:      "The number of requests allowed due to failures checking the rate limits",
+	})
 )
 
 func validate(token string, r *http.Request) (auth.Token, error) {
@@ -101,6 +117,11 @@ func tokenByAllAuthEngines(ctx stdContext.Context, token string) (auth.Token, er
 		return t, nil
 	}
 
+	t, err = auth.PersonalTokenAuth(ctx, token)
+	if err == nil {
+		return t, nil
+	}
+
 	t, err = servicemanager.TeamToken.Authenticate(ctx, token)
 	if err == nil {
 		return t, nil
@@ -229,6 +250,50 @@ func authTokenMiddleware(w http.ResponseWriter, r *http.Request, next http.Handl
 	next(w, r)
 }
 
+// rateLimitMiddleware limits the number of requests of each token, counting
+// them in the global limit and, when configured, in the limit of the route.
+// Requests without a token and requests from other tsuru API instances are not
+// limited.
+func rateLimitMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	t := context.GetAuthToken(r)
+	if t == nil || t.Engine() == "peer" || !ratelimit.Enabled() {
+		next(w, r)
+		return
+	}
+	route := r.URL.Query().Get(":mux-route-name")
+	if route == "" {
+		route = r.URL.Query().Get(":mux-path-template")
+	}
+	key := t.Engine() + ":" + t.GetUserName()
+	buckets := map[string]ratelimit.Limit{}
+	if limit, ok := ratelimit.DefaultLimit(); ok {
+		buckets[key] = limit
+	}
+	if limit, ok := ratelimit.RouteLimit(route); ok {
+		buckets[key+":"+route] = limit
+	}
+	now := time.Now()
+	for bucket, limit := range buckets {
+		result, err := ratelimit.Hit(r.Context(), bucket, limit, now)
+		if err != nil {
+			rateLimitErrorsTotal.Inc()
+			log.Errorf("unable to check rate limit for %q: %v", bucket, err)
+			continue
+		}
+		if !result.Allowed {
+			rateLimitedTotal.WithLabelValues(route).Inc()
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			context.AddRequestError(r, &tsuruErrors.HTTP{
+				Code:    http.StatusTooManyRequests,
+				Message: fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter),
+			})
+			return
+		}
+	}
+	next(w, r)
+}
+
 func runDelayedHandler(w http.ResponseWriter, r *http.Request) {
 	h := context.GetDelayedHandler(r)
 	if h != nil {
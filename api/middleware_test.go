@@ -596,3 +596,82 @@ func (s *S) TestInputFields(c *check.C) {
 		c.Check(values, check.DeepEquals, tt.expected)
 	}
 }
+
+func (s *S) TestRateLimitMiddleware(c *check.C) {
+	config.Set("api:rate-limit:enabled", true)
+	config.Set("api:rate-limit:requests", 2)
+	config.Set("api:rate-limit:window", "1h")
+	defer config.Unset("api:rate-limit")
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/apps/myapp", nil)
+		c.Assert(err, check.IsNil)
+		context.SetAuthToken(request, s.token)
+		h, log := doHandler()
+		rateLimitMiddleware(recorder, request, h)
+		if i < 2 {
+			c.Assert(log.called, check.Equals, true)
+			c.Assert(context.GetRequestError(request), check.IsNil)
+			continue
+		}
+		c.Assert(log.called, check.Equals, false)
+		c.Assert(recorder.Header().Get("Retry-After"), check.Matches, `\d+`)
+		e, ok := context.GetRequestError(request).(*tsuruErrors.HTTP)
+		c.Assert(ok, check.Equals, true)
+		c.Assert(e.Code, check.Equals, http.StatusTooManyRequests)
+		c.Assert(e.Message, check.Matches, `rate limit exceeded, retry in \d+ seconds`)
+	}
+}
+
+func (s *S) TestRateLimitMiddlewareByRoute(c *check.C) {
+	config.Set("api:rate-limit:enabled", true)
+	config.Set("api:rate-limit:routes:log-get:requests", 1)
+	config.Set("api:rate-limit:routes:log-get:window", "1h")
+	defer config.Unset("api:rate-limit")
+	tests := []struct {
+		url    string
+		called bool
+	}{
+		{url: "/apps/myapp/log?:mux-route-name=log-get", called: true},
+		{url: "/apps/myapp?:mux-path-template=/apps/{app}", called: true},
+		{url: "/apps/myapp/log?:mux-route-name=log-get", called: false},
+		{url: "/apps/myapp?:mux-path-template=/apps/{app}", called: true},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", tt.url, nil)
+		c.Assert(err, check.IsNil)
+		context.SetAuthToken(request, s.token)
+		h, log := doHandler()
+		rateLimitMiddleware(recorder, request, h)
+		c.Assert(log.called, check.Equals, tt.called, check.Commentf("url: %s", tt.url))
+	}
+}
+
+func (s *S) TestRateLimitMiddlewareDisabled(c *check.C) {
+	config.Set("api:rate-limit:requests", 1)
+	defer config.Unset("api:rate-limit")
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/apps/myapp", nil)
+		c.Assert(err, check.IsNil)
+		context.SetAuthToken(request, s.token)
+		h, log := doHandler()
+		rateLimitMiddleware(recorder, request, h)
+		c.Assert(log.called, check.Equals, true)
+	}
+}
+
+func (s *S) TestRateLimitMiddlewareWithoutToken(c *check.C) {
+	config.Set("api:rate-limit:enabled", true)
+	config.Set("api:rate-limit:requests", 1)
+	defer config.Unset("api:rate-limit")
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/healthcheck", nil)
+		c.Assert(err, check.IsNil)
+		h, log := doHandler()
+		rateLimitMiddleware(recorder, request, h)
+		c.Assert(log.called, check.Equals, true)
+	}
+}
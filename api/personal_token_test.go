@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPersonalTokenCreate(c *check.C) {
+	body := strings.NewReader("token_id=ci&description=deploys&scopes.0=app.read&scopes.1=app.deploy:app:myapp")
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/"+s.user.Email+"/personal-tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var token authTypes.PersonalToken
+	err = json.Unmarshal(recorder.Body.Bytes(), &token)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Not(check.Equals), "")
+	c.Assert(token.TokenID, check.Equals, "ci")
+	c.Assert(token.Scopes, check.DeepEquals, []authTypes.PersonalTokenScope{
+		{Permission: "app.read"},
+		{Permission: "app.deploy", ContextType: "app", ContextValue: "myapp"},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(s.user.Email),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.token.create",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPersonalTokenCreateInvalidScope(c *check.C) {
+	body := strings.NewReader("scopes.0=app.fly")
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/"+s.user.Email+"/personal-tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid scope \"app.fly\": unregistered permission\n")
+}
+
+func (s *S) TestPersonalTokenCreateFromTeamToken(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "token-creator", "global", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "user.token.create")
+	c.Assert(err, check.IsNil)
+	teamToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team: s.team.Name,
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.TeamToken.AddRole(context.TODO(), teamToken.TokenID, "token-creator", "")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/1.33/users/"+s.user.Email+"/personal-tokens", strings.NewReader("token_id=wide"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+teamToken.Token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Equals, "token doesn't have all the permissions of the user, only scoped tokens can be created\n")
+	tokens, err := auth.ListPersonalTokens(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+}
+
+func (s *S) TestPersonalTokenListAndRevoke(c *check.C) {
+	_, err := auth.CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{TokenID: "ci"}, s.token)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/1.33/users/"+s.user.Email+"/personal-tokens", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var tokens []authTypes.PersonalToken
+	err = json.Unmarshal(recorder.Body.Bytes(), &tokens)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].TokenID, check.Equals, "ci")
+	c.Assert(tokens[0].Token, check.Equals, "")
+	request, err = http.NewRequest(http.MethodDelete, "/1.33/users/"+s.user.Email+"/personal-tokens/ci", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	request, err = http.NewRequest(http.MethodDelete, "/1.33/users/"+s.user.Email+"/personal-tokens/ci", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPersonalTokenScopeRestrictsPermissions(c *check.C) {
+	created, err := auth.CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app.read"}}, s.token)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/1.33/users/"+s.user.Email+"/personal-tokens", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+created.Token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	request, err = http.NewRequest(http.MethodGet, "/apps", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+created.Token)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestPersonalTokenCreateFromScopedToken(c *check.C) {
+	created, err := auth.CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"user.token.create"}}, s.token)
+	c.Assert(err, check.IsNil)
+	for _, form := range []string{"token_id=wide", "token_id=wide&scopes.0=app.deploy"} {
+		request, err := http.NewRequest(http.MethodPost, "/1.33/users/"+s.user.Email+"/personal-tokens", strings.NewReader(form))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+created.Token)
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("form: %q, body: %q", form, recorder.Body.String()))
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
@@ -271,3 +272,185 @@ func changeTeamQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	}
 	return err
 }
+
+// title: pool resource quota
+// path: /pools/{name}/resource-quota
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Pool not found
+func getPoolResourceQuota(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolReadQuota, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(ctx, poolName)
+	if err == pool.ErrPoolNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	q, err := app.GetResourceQuota(ctx, app.ResourceQuotaPool, poolName)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(q)
+}
+
+// title: update pool resource quota
+// path: /pools/{name}/resource-quota
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Quota updated
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Limit lower than allocated value
+//	404: Pool not found
+func changePoolResourceQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolUpdateQuota, permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = pool.GetPoolByName(ctx, poolName)
+	if err == pool.ErrPoolNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateQuota,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return setResourceQuotaLimit(r, app.ResourceQuotaPool, poolName)
+}
+
+// title: team resource quota
+// path: /teams/{name}/resource-quota
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Team not found
+func getTeamResourceQuota(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamReadQuota, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	q, err := app.GetResourceQuota(ctx, app.ResourceQuotaTeam, teamName)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(q)
+}
+
+// title: update team resource quota
+// path: /teams/{name}/resource-quota
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Quota updated
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Limit lower than allocated value
+//	404: Team not found
+func changeTeamResourceQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateQuota, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:       permission.PermTeamUpdateQuota,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return setResourceQuotaLimit(r, app.ResourceQuotaTeam, teamName)
+}
+
+// setResourceQuotaLimit reads the cpumilli and memory limits from the
+// request, limits not sent are kept.
+func setResourceQuotaLimit(r *http.Request, scope, name string) error {
+	var limits [2]*int
+	for i, field := range []string{"cpumilli", "memory"} {
+		value := InputValue(r, field)
+		if value == "" {
+			continue
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return &errors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid " + field + " limit",
+			}
+		}
+		limits[i] = &limit
+	}
+	err := app.SetResourceQuotaLimit(r.Context(), scope, name, limits[0], limits[1])
+	if err == quota.ErrLimitLowerThanAllocated {
+		return &errors.HTTP{
+			Code:    http.StatusForbidden,
+			Message: err.Error(),
+		}
+	}
+	return err
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision/pool"
 	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -68,6 +69,12 @@ func (s *QuotaSuite) SetUpTest(c *check.C) {
 	}, permTypes.Permission{
 		Scheme:  permission.PermTeamUpdateQuota,
 		Context: permission.Context(permTypes.CtxGlobal, ""),
+	}, permTypes.Permission{
+		Scheme:  permission.PermPoolReadQuota,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	}, permTypes.Permission{
+		Scheme:  permission.PermPoolUpdateQuota,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
 	})
 	var err error
 	s.user, err = auth.ConvertNewUser(s.token.User(context.TODO()))
@@ -673,3 +680,145 @@ func (s *QuotaSuite) TestChangeAppQuotaLimitLowerThanAllocated(c *check.C) {
 		ErrorMatches: `New limit is less than the current allocated value`,
 	}, eventtest.HasEvent)
 }
+
+func (s *QuotaSuite) TestGetPoolResourceQuota(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	memory := 2048
+	err = app.SetResourceQuotaLimit(context.TODO(), app.ResourceQuotaPool, "pool1", nil, &memory)
+	c.Assert(err, check.IsNil)
+	request, _ := http.NewRequest("GET", "/pools/pool1/resource-quota", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result quota.ResourceQuota
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, quota.ResourceQuota{
+		CPUMilli: quota.Quota{Limit: -1, InUse: 0},
+		Memory:   quota.Quota{Limit: 2048, InUse: 0},
+	})
+}
+
+func (s *QuotaSuite) TestGetPoolResourceQuotaRequiresPermission(c *check.C) {
+	token := userWithPermission(c)
+	request, _ := http.NewRequest("GET", "/pools/pool1/resource-quota", nil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *QuotaSuite) TestGetPoolResourceQuotaPoolNotFound(c *check.C) {
+	request, _ := http.NewRequest("GET", "/pools/unknown/resource-quota", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *QuotaSuite) TestChangePoolResourceQuota(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := bytes.NewBufferString("cpumilli=4000&memory=8192")
+	request, _ := http.NewRequest("PUT", "/pools/pool1/resource-quota", body)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	q, err := app.GetResourceQuota(context.TODO(), app.ResourceQuotaPool, "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(q.CPUMilli.Limit, check.Equals, 4000)
+	c.Assert(q.Memory.Limit, check.Equals, 8192)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.quota",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "pool1"},
+			{"name": "cpumilli", "value": "4000"},
+			{"name": "memory", "value": "8192"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *QuotaSuite) TestChangePoolResourceQuotaInvalidLimitValue(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := bytes.NewBufferString("memory=a")
+	request, _ := http.NewRequest("PUT", "/pools/pool1/resource-quota", body)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "Invalid memory limit\n")
+}
+
+func (s *QuotaSuite) TestGetTeamResourceQuota(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	request, _ := http.NewRequest("GET", "/teams/avengers/resource-quota", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result quota.ResourceQuota
+	err := json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, quota.ResourceQuota{
+		CPUMilli: quota.Quota{Limit: -1, InUse: 0},
+		Memory:   quota.Quota{Limit: -1, InUse: 0},
+	})
+}
+
+func (s *QuotaSuite) TestChangeTeamResourceQuota(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	body := bytes.NewBufferString("cpumilli=2000")
+	request, _ := http.NewRequest("PUT", "/teams/avengers/resource-quota", body)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	q, err := app.GetResourceQuota(context.TODO(), app.ResourceQuotaTeam, "avengers")
+	c.Assert(err, check.IsNil)
+	c.Assert(q.CPUMilli.Limit, check.Equals, 2000)
+	c.Assert(q.Memory.IsUnlimited(), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: "avengers"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.quota",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "avengers"},
+			{"name": "cpumilli", "value": "2000"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *QuotaSuite) TestChangeTeamResourceQuotaTeamNotFound(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return nil, authTypes.ErrTeamNotFound
+	}
+	body := bytes.NewBufferString("cpumilli=2000")
+	request, _ := http.NewRequest("PUT", "/teams/avengers/resource-quota", body)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	handler := RunServer(true)
+	handler.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
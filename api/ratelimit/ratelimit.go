@@ -0,0 +1,157 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ratelimit implements fixed window request counters shared by every
+// API instance through the storage.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const defaultWindow = time.Minute
+
+// Limit is the maximum number of requests accepted during a window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result is the outcome of counting a request against a limit.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+type counter struct {
+	ID       string `bson:"_id"`
+	Count    int
+	ExpireAt time.Time
+}
+
+// Enabled returns whether API rate limiting is enabled, configured in
+// api:rate-limit:enabled.
+func Enabled() bool {
+	enabled, _ := config.GetBool("api:rate-limit:enabled")
+	return enabled
+}
+
+// DefaultLimit returns the limit applied to every request of a token,
+// configured in api:rate-limit:requests and api:rate-limit:window.
+func DefaultLimit() (Limit, bool) {
+	return limitFromConfig("api:rate-limit")
+}
+
+// RouteLimit returns the limit configured for the route in
+// api:rate-limit:routes:<route>, where route is either the route name or its
+// path template, e.g. /apps/{app}/log.
+func RouteLimit(route string) (Limit, bool) {
+	if route == "" {
+		return Limit{}, false
+	}
+	return limitFromConfig("api:rate-limit:routes:" + route)
+}
+
+// LoginLimit returns the limit of login attempts from a single IP address,
+// configured in auth:login-throttle:requests and auth:login-throttle:window.
+// It's applied regardless of api:rate-limit:enabled.
+func LoginLimit() (Limit, bool) {
+	return limitFromConfig("auth:login-throttle")
+}
+
+// ClientIP returns the address of the client sending the request. Behind
+// load balancers, api:client-ip:header names the header where they record
+// the client address, e.g. X-Forwarded-For, and
+// api:client-ip:trusted-proxies is the number of proxies appending to it,
+// which defaults to 1. Only the entry appended by the farthest trusted proxy
+// is used, as the entries before it are set by the client.
+func ClientIP(r *http.Request) string {
+	ip := hostOnly(r.RemoteAddr)
+	header, _ := config.GetString("api:client-ip:header")
+	if header == "" {
+		return ip
+	}
+	trusted, err := config.GetInt("api:client-ip:trusted-proxies")
+	if err != nil || trusted <= 0 {
+		trusted = 1
+	}
+	var entries []string
+	for _, value := range r.Header.Values(header) {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if len(entries) < trusted {
+		return ip
+	}
+	return hostOnly(entries[len(entries)-trusted])
+}
+
+func hostOnly(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func limitFromConfig(prefix string) (Limit, bool) {
+	requests, err := config.GetInt(prefix + ":requests")
+	if err != nil || requests <= 0 {
+		return Limit{}, false
+	}
+	window, err := config.GetDuration(prefix + ":window")
+	if err != nil || window <= 0 {
+		window = defaultWindow
+	}
+	return Limit{Requests: requests, Window: window}, true
+}
+
+// Hit counts one request for key in the window containing now and reports
+// whether the limit was exceeded.
+func Hit(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	collection, err := storagev2.RateLimitsCollection()
+	if err != nil {
+		return Result{}, err
+	}
+	start := now.Truncate(limit.Window)
+	end := start.Add(limit.Window)
+	query := mongoBSON.M{"_id": fmt.Sprintf("%s/%d", key, start.Unix())}
+	update := mongoBSON.M{
+		"$inc":         mongoBSON.M{"count": 1},
+		"$setOnInsert": mongoBSON.M{"expireat": end},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var c counter
+	err = collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&c)
+	if mongo.IsDuplicateKeyError(err) {
+		// concurrent upserts of a new window, the counter exists now.
+		err = collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&c)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Allowed: c.Count <= limit.Requests}
+	if result.Allowed {
+		result.Remaining = limit.Requests - c.Count
+	} else {
+		result.RetryAfter = end.Sub(now)
+	}
+	return result, nil
+}
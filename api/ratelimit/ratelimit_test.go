@@ -0,0 +1,99 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestHit(c *check.C) {
+	limit := Limit{Requests: 2, Window: time.Minute}
+	now := time.Date(2026, 5, 10, 12, 0, 15, 0, time.UTC)
+	result, err := Hit(context.TODO(), "native:user@tsuru.io", limit, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, Result{Allowed: true, Remaining: 1})
+	result, err = Hit(context.TODO(), "native:user@tsuru.io", limit, now.Add(time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, Result{Allowed: true, Remaining: 0})
+	result, err = Hit(context.TODO(), "native:user@tsuru.io", limit, now.Add(5*time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, Result{Allowed: false, RetryAfter: 40 * time.Second})
+	result, err = Hit(context.TODO(), "native:other@tsuru.io", limit, now.Add(5*time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Allowed, check.Equals, true)
+	result, err = Hit(context.TODO(), "native:user@tsuru.io", limit, now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, Result{Allowed: true, Remaining: 1})
+}
+
+func (s *S) TestDefaultLimit(c *check.C) {
+	_, ok := DefaultLimit()
+	c.Assert(ok, check.Equals, false)
+	config.Set("api:rate-limit:requests", 100)
+	limit, ok := DefaultLimit()
+	c.Assert(ok, check.Equals, true)
+	c.Assert(limit, check.DeepEquals, Limit{Requests: 100, Window: time.Minute})
+	config.Set("api:rate-limit:window", "10s")
+	limit, ok = DefaultLimit()
+	c.Assert(ok, check.Equals, true)
+	c.Assert(limit, check.DeepEquals, Limit{Requests: 100, Window: 10 * time.Second})
+}
+
+func (s *S) TestRouteLimit(c *check.C) {
+	config.Set("api:rate-limit:routes", map[interface{}]interface{}{
+		"/apps/{app}/log": map[interface{}]interface{}{"requests": 10, "window": "30s"},
+		"log-get":         map[interface{}]interface{}{"requests": 5},
+	})
+	limit, ok := RouteLimit("/apps/{app}/log")
+	c.Assert(ok, check.Equals, true)
+	c.Assert(limit, check.DeepEquals, Limit{Requests: 10, Window: 30 * time.Second})
+	limit, ok = RouteLimit("log-get")
+	c.Assert(ok, check.Equals, true)
+	c.Assert(limit, check.DeepEquals, Limit{Requests: 5, Window: time.Minute})
+	_, ok = RouteLimit("/apps/{app}")
+	c.Assert(ok, check.Equals, false)
+	_, ok = RouteLimit("")
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestLoginLimit(c *check.C) {
+	_, ok := LoginLimit()
+	c.Assert(ok, check.Equals, false)
+	config.Set("auth:login-throttle:requests", 20)
+	defer config.Unset("auth:login-throttle")
+	limit, ok := LoginLimit()
+	c.Assert(ok, check.Equals, true)
+	c.Assert(limit, check.DeepEquals, Limit{Requests: 20, Window: time.Minute})
+}
+
+func (s *S) TestClientIP(c *check.C) {
+	defer config.Unset("api:client-ip")
+	r, err := http.NewRequest(http.MethodPost, "/users/me/tokens", nil)
+	c.Assert(err, check.IsNil)
+	r.RemoteAddr = "10.0.0.1:4321"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2, 3.3.3.3")
+	c.Assert(ClientIP(r), check.Equals, "10.0.0.1")
+	config.Set("api:client-ip:header", "X-Forwarded-For")
+	c.Assert(ClientIP(r), check.Equals, "3.3.3.3")
+	config.Set("api:client-ip:trusted-proxies", 2)
+	c.Assert(ClientIP(r), check.Equals, "2.2.2.2")
+	config.Set("api:client-ip:trusted-proxies", 4)
+	c.Assert(ClientIP(r), check.Equals, "10.0.0.1")
+	config.Set("api:client-ip:header", "X-Real-Ip")
+	config.Unset("api:client-ip:trusted-proxies")
+	r.Header.Set("X-Real-Ip", "[::1]:80")
+	c.Assert(ClientIP(r), check.Equals, "::1")
+}
+
+func (s *S) TestEnabled(c *check.C) {
+	c.Assert(Enabled(), check.Equals, false)
+	config.Set("api:rate-limit:enabled", true)
+	c.Assert(Enabled(), check.Equals, true)
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "api_ratelimit_tests")
+	storagev2.Reset()
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	err := storagev2.ClearAllCollections(nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("api:rate-limit")
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	storagev2.ClearAllCollections(nil)
+}
@@ -8,11 +8,13 @@ import (
 	"encoding/json"
 	stderrors "errors"
 	"net/http"
+	"time"
 
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/router"
@@ -474,3 +476,81 @@ func appSetRoutable(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 	}
 	return app.SetRoutable(ctx, a, version, args.IsRoutable)
 }
+
+type canaryRequest struct {
+	Version      string `json:"version"`
+	Steps        []int  `json:"steps"`
+	StepInterval string `json:"stepInterval"`
+}
+
+// title: shift traffic progressively to an app version
+// path: /apps/{app}/canary
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: OK
+//	400: Bad request
+//	401: Not authorized
+//	404: App not found
+func appCanary(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var args canaryRequest
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	var stepInterval time.Duration
+	if args.StepInterval != "" {
+		stepInterval, err = time.ParseDuration(args.StepInterval)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppUpdateCanary,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, a, args.Version)
+	if err != nil {
+		if appTypes.IsInvalidVersionError(err) {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:        appTarget(appName),
+		Kind:          permission.PermAppUpdateCanary,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    event.FormToCustomData(InputFields(r)),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(a)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	ctx, cancel := evt.CancelableContext(ctx)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return app.Canary(ctx, a, app.CanaryArgs{
+		Version:      version,
+		Steps:        args.Steps,
+		StepInterval: stepInterval,
+		Event:        evt,
+		Output:       evt,
+	})
+}
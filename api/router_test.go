@@ -8,12 +8,14 @@ import (
 	"context"
 	"encoding/json"
 	stderrors "errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/router"
@@ -477,6 +479,62 @@ func (s *S) TestUpdateAppRouterBlockedByConstraint(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %q", recorder.Body.String()))
 }
 
+func (s *S) TestAppCanaryInvalidStepInterval(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`version=1&stepInterval=abc`)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.32/apps/myapp/canary", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*invalid duration.*`)
+}
+
+func (s *S) TestAppCanaryUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateRoutable,
+		Context: permission.Context(permTypes.CtxTeam, "tsuruteam"),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`version=1`)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.32/apps/myapp/canary", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppCanaryRouterWithoutWeights(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	body := strings.NewReader(fmt.Sprintf(`version=%d&steps.0=10&steps.1=50`, version.Version()))
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.32/apps/myapp/canary", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*no router with weighted backends support.*`)
+	c.Assert(eventtest.EventDesc{
+		Target:       appTarget("myapp"),
+		Owner:        s.token.GetUserName(),
+		Kind:         "app.update.canary",
+		ErrorMatches: `.*no router with weighted backends support`,
+	}, eventtest.HasEvent)
+}
+
 func (s *S) TestRemoveAppRouter(c *check.C) {
 	ctx := context.Background()
 	token := userWithPermission(c, permTypes.Permission{
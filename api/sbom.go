@@ -0,0 +1,191 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/sbom"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// getAppVersionFromRequest returns the version of the app in the request
+// path, which must not be removed.
+func getAppVersionFromRequest(ctx context.Context, a *appTypes.App, r *http.Request) (appTypes.AppVersion, error) {
+	versionStr := r.URL.Query().Get(":version")
+	number, err := strconv.Atoi(versionStr)
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid version %q", versionStr)}
+	}
+	versions, err := servicemanager.AppVersion.AppVersions(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	vi, ok := versions.Versions[number]
+	if !ok || vi.MarkedToRemoval {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: appTypes.ErrInvalidVersion{Version: versionStr}.Error()}
+	}
+	return servicemanager.AppVersion.AppVersionFromInfo(ctx, a, vi)
+}
+
+// title: app version sbom
+// path: /apps/{app}/versions/{version}/sbom
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	400: Invalid version
+//	401: Unauthorized
+//	404: Not found
+func appVersionSBOM(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(ctx, t, permission.PermAppReadSbom, contextsForApp(a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	version, err := getAppVersionFromRequest(ctx, a, r)
+	if err != nil {
+		return err
+	}
+	s, err := sbom.Get(ctx, a.Name, version.Version())
+	if err == sbom.ErrSBOMNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Tsuru-SBOM-Format", s.Format)
+	_, err = w.Write([]byte(s.Document))
+	return err
+}
+
+// title: app version sbom upload
+// path: /apps/{app}/versions/{version}/sbom
+// method: PUT
+// consume: application/json
+// produce: application/json
+// responses:
+//
+//	200: SBOM stored
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Not found
+func appVersionSBOMUpload(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canUpdate := permission.Check(ctx, t, permission.PermAppUpdateSbom, contextsForApp(a)...)
+	if !canUpdate {
+		return permission.ErrUnauthorized
+	}
+	version, err := getAppVersionFromRequest(ctx, a, r)
+	if err != nil {
+		return err
+	}
+	document, err := sbom.ReadDocument(r.Body)
+	if err == nil {
+		_, _, err = sbom.Parse(document)
+	}
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	imageName, err := version.BaseImageName()
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateSbom,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(r.URL.Query()),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	s, err := sbom.Save(ctx, a.Name, version.Version(), imageName, document)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"format":   s.Format,
+		"packages": len(s.Packages),
+	})
+}
+
+// title: sbom search
+// path: /sbom/search
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+func sbomSearch(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	filter := sbom.Filter{
+		Name:    r.URL.Query().Get("name"),
+		Version: r.URL.Query().Get("version"),
+	}
+	if filter.Name == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "package name is required"}
+	}
+	contexts := permission.ContextsForPermission(ctx, t, permission.PermAppReadSbom)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	isGlobal := false
+	for _, c := range contexts {
+		if c.CtxType == permTypes.CtxGlobal {
+			isGlobal = true
+			break
+		}
+	}
+	if !isGlobal {
+		apps, err := app.List(ctx, appFilterByContext(contexts, nil))
+		if err != nil {
+			return err
+		}
+		filter.Apps = make([]string, len(apps))
+		for i, a := range apps {
+			filter.Apps[i] = a.Name
+		}
+	}
+	results, err := sbom.Search(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(results)
+}
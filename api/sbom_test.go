@@ -0,0 +1,153 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/sbom"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+const testSBOM = `{"bomFormat": "CycloneDX", "components": [{"name": "express", "version": "4.18.2", "purl": "pkg:npm/express@4.18.2"}]}`
+
+func (s *S) TestAppVersionSBOM(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	_, err = sbom.Save(context.TODO(), myapp.Name, version.Version(), "myapp:v1", []byte(testSBOM))
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/1.33/apps/myapp/versions/%d/sbom", version.Version())
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(recorder.Header().Get("X-Tsuru-SBOM-Format"), check.Equals, "cyclonedx")
+	c.Assert(recorder.Body.String(), check.Equals, testSBOM)
+}
+
+func (s *S) TestAppVersionSBOMNotFound(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	for _, v := range []string{fmt.Sprint(version.Version()), "99"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/1.33/apps/myapp/versions/"+v+"/sbom", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusNotFound, check.Commentf("version: %s", v))
+	}
+}
+
+func (s *S) TestAppVersionSBOMUpload(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/1.33/apps/myapp/versions/%d/sbom", version.Version())
+	request, err := http.NewRequest("PUT", url, strings.NewReader(testSBOM))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var result map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string]interface{}{"format": "cyclonedx", "packages": float64(1)})
+	stored, err := sbom.Get(context.TODO(), myapp.Name, version.Version())
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Document, check.Equals, testSBOM)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.sbom",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppVersionSBOMUploadInvalid(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/1.33/apps/myapp/versions/%d/sbom", version.Version())
+	request, err := http.NewRequest("PUT", url, strings.NewReader(`{"not": "a sbom"}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, sbom.ErrInvalidFormat.Error()+"\n")
+}
+
+func (s *S) TestSBOMSearch(c *check.C) {
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	_, err = sbom.Save(context.TODO(), myapp.Name, version.Version(), "myapp:v1", []byte(testSBOM))
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/sbom/search?name=express&version=4.18.2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var results []sbom.SearchResult
+	err = json.Unmarshal(recorder.Body.Bytes(), &results)
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.DeepEquals, []sbom.SearchResult{{
+		App:     "myapp",
+		Version: version.Version(),
+		Image:   "myapp:v1",
+		Package: sbom.Package{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+	}})
+}
+
+func (s *S) TestSBOMSearchFiltersAppsByPermission(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadSbom,
+		Context: permission.Context(permTypes.CtxApp, "otherapp"),
+	})
+	myapp := appTypes.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &myapp)
+	_, err = sbom.Save(context.TODO(), myapp.Name, version.Version(), "myapp:v1", []byte(testSBOM))
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/sbom/search?name=express", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestSBOMSearchWithoutName(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/sbom/search", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
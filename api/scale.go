@@ -17,6 +17,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
 )
 
 // title: units autoscale info
@@ -61,6 +62,7 @@ func autoScaleUnitsInfo(w http.ResponseWriter, r *http.Request, t auth.Token) (e
 //	200: Ok
 //	400: Invalid data
 //	401: Unauthorized
+//	403: Quota exceeded
 //	404: App not found
 func addAutoScaleUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
@@ -106,7 +108,14 @@ func addAutoScaleUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
-	return app.AutoScale(ctx, a, spec)
+	err = app.AutoScale(ctx, a, spec)
+	if _, ok := err.(*quotaTypes.QuotaExceededError); ok {
+		return &errors.HTTP{
+			Code:    http.StatusForbidden,
+			Message: err.Error(),
+		}
+	}
+	return err
 }
 
 // title: swap unit auto scale
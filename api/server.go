@@ -31,8 +31,10 @@ import (
 	"github.com/tsuru/tsuru/api/shutdown"
 	"github.com/tsuru/tsuru/api/tracker"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/expiration"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/app/image/gc"
+	"github.com/tsuru/tsuru/app/usage"
 	"github.com/tsuru/tsuru/app/version"
 	"github.com/tsuru/tsuru/applog"
 	"github.com/tsuru/tsuru/auth"
@@ -40,6 +42,7 @@ import (
 	_ "github.com/tsuru/tsuru/auth/native"
 	_ "github.com/tsuru/tsuru/auth/oauth"
 	_ "github.com/tsuru/tsuru/auth/oidc"
+	"github.com/tsuru/tsuru/auth/rotation"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/webhook"
 	"github.com/tsuru/tsuru/hc"
@@ -48,8 +51,10 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/cluster"
 	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/quota/reconcile"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/rebuild"
+	_ "github.com/tsuru/tsuru/secret/vault"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/storage"
 	"github.com/tsuru/tsuru/tag"
@@ -307,6 +312,19 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.5", http.MethodDelete, "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(removeAppRouter))
 	m.Add("1.5", http.MethodGet, "/apps/{app}/routers", AuthorizationRequiredHandler(listAppRouters))
 	m.Add("1.8", http.MethodPost, "/apps/{app}/routable", AuthorizationRequiredHandler(appSetRoutable))
+	m.Add("1.33", http.MethodPost, "/apps/{app}/canary", AuthorizationRequiredHandler(appCanary))
+	m.Add("1.33", http.MethodPut, "/apps/{app}/manifest", AuthorizationRequiredHandler(appManifestApply))
+	m.Add("1.33", http.MethodGet, "/apps/{app}/export", AuthorizationRequiredHandler(appExport))
+	m.Add("1.33", http.MethodPost, "/apps/import", AuthorizationRequiredHandler(appImport))
+	m.Add("1.33", http.MethodPost, "/apps/{app}/clone", AuthorizationRequiredHandler(appClone))
+	m.Add("1.33", http.MethodPost, "/apps/{app}/ttl", AuthorizationRequiredHandler(appExtendTTL))
+	m.Add("1.33", http.MethodPost, "/apps/{app}/pin", AuthorizationRequiredHandler(appPin))
+	m.Add("1.33", http.MethodGet, "/apps/{app}/build/cache", AuthorizationRequiredHandler(appBuildCacheInfo))
+	m.Add("1.33", http.MethodPut, "/apps/{app}/build/cache", AuthorizationRequiredHandler(appBuildCacheUpdate))
+	m.Add("1.33", http.MethodPost, "/apps/{app}/build/cache/purge", AuthorizationRequiredHandler(appBuildCachePurge))
+	m.Add("1.33", http.MethodGet, "/apps/{app}/versions/{version}/sbom", AuthorizationRequiredHandler(appVersionSBOM))
+	m.Add("1.33", http.MethodPut, "/apps/{app}/versions/{version}/sbom", AuthorizationRequiredHandler(appVersionSBOMUpload))
+	m.Add("1.33", http.MethodGet, "/sbom/search", AuthorizationRequiredHandler(sbomSearch))
 	m.Add("1.0", http.MethodGet, "/deploys", AuthorizationRequiredHandler(deploysList))
 	m.Add("1.0", http.MethodGet, "/deploys/{deploy}", AuthorizationRequiredHandler(deployInfo))
 
@@ -317,12 +335,15 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.1", http.MethodGet, "/events/kinds", AuthorizationRequiredHandler(kindList))
 	m.Add("1.1", http.MethodGet, "/events/{uuid}", AuthorizationRequiredHandler(eventInfo))
 	m.Add("1.1", http.MethodPost, "/events/{uuid}/cancel", AuthorizationRequiredHandler(eventCancel))
+	m.Add("1.33", http.MethodGet, "/events/{uuid}/recording", AuthorizationRequiredHandler(eventRecording))
 
 	m.Add("1.6", http.MethodGet, "/events/webhooks", AuthorizationRequiredHandler(webhookList))
 	m.Add("1.6", http.MethodPost, "/events/webhooks", AuthorizationRequiredHandler(webhookCreate))
 	m.Add("1.6", http.MethodGet, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookInfo))
 	m.Add("1.6", http.MethodPut, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookUpdate))
 	m.Add("1.6", http.MethodDelete, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookDelete))
+	m.Add("1.33", http.MethodGet, "/events/webhooks/{name}/deliveries", AuthorizationRequiredHandler(webhookDeliveries))
+	m.Add("1.33", http.MethodPost, "/events/webhooks/{name}/deliveries/{id}/redeliver", AuthorizationRequiredHandler(webhookRedeliver))
 
 	m.Add("1.0", http.MethodGet, "/platforms", AuthorizationRequiredHandler(platformList))
 	m.Add("1.0", http.MethodPost, "/platforms", AuthorizationRequiredHandler(platformAdd))
@@ -352,6 +373,13 @@ func RunServer(dry bool) http.Handler {
 
 	m.Add("1.0", http.MethodPost, "/users/{email}/password", Handler(resetPassword))
 	m.Add("1.0", http.MethodPost, "/users/{email}/tokens", Handler(login))
+	m.Add("1.33", http.MethodPost, "/users/{email}/mfa", Handler(enrollMFA))
+	m.Add("1.33", http.MethodPost, "/users/{email}/mfa/confirm", Handler(confirmMFA))
+	m.Add("1.33", http.MethodDelete, "/users/{email}/mfa", Handler(disableMFA))
+	m.Add("1.33", http.MethodPost, "/users/{email}/unlock", AuthorizationRequiredHandler(unlockUser))
+	m.Add("1.33", http.MethodGet, "/users/{email}/personal-tokens", AuthorizationRequiredHandler(personalTokenList))
+	m.Add("1.33", http.MethodPost, "/users/{email}/personal-tokens", AuthorizationRequiredHandler(personalTokenCreate))
+	m.Add("1.33", http.MethodDelete, "/users/{email}/personal-tokens/{token_id}", AuthorizationRequiredHandler(personalTokenRevoke))
 	m.Add("1.0", http.MethodGet, "/users/{email}/quota", AuthorizationRequiredHandler(getUserQuota))
 	m.Add("1.0", http.MethodPut, "/users/{email}/quota", AuthorizationRequiredHandler(changeUserQuota))
 	m.Add("1.0", http.MethodDelete, "/users/tokens", AuthorizationRequiredHandler(logout))
@@ -369,6 +397,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.4", http.MethodGet, "/teams/{name}", AuthorizationRequiredHandler(teamInfo))
 	m.Add("1.12", http.MethodGet, "/teams/{name}/quota", AuthorizationRequiredHandler(getTeamQuota))
 	m.Add("1.12", http.MethodPut, "/teams/{name}/quota", AuthorizationRequiredHandler(changeTeamQuota))
+	m.Add("1.33", http.MethodGet, "/teams/{name}/resource-quota", AuthorizationRequiredHandler(getTeamResourceQuota))
+	m.Add("1.33", http.MethodPut, "/teams/{name}/resource-quota", AuthorizationRequiredHandler(changeTeamResourceQuota))
 	m.Add("1.17", http.MethodGet, "/teams/{name}/users", AuthorizationRequiredHandler(teamUserList))
 	m.Add("1.17", http.MethodGet, "/teams/{name}/groups", AuthorizationRequiredHandler(teamGroupList))
 
@@ -377,6 +407,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/healthcheck/", http.HandlerFunc(healthcheck))
 	m.Add("1.0", http.MethodGet, "/healthcheck", http.HandlerFunc(healthcheck))
 
+	m.Add("1.33", http.MethodGet, "/reports/usage", AuthorizationRequiredHandler(usageReport))
+
 	m.Add("1.0", http.MethodGet, "/plans", AuthorizationRequiredHandler(listPlans))
 	m.Add("1.0", http.MethodPost, "/plans", AuthorizationRequiredHandler(addPlan))
 	m.Add("1.0", http.MethodDelete, "/plans/{planname}", AuthorizationRequiredHandler(removePlan))
@@ -388,6 +420,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/pools/{name}/team", AuthorizationRequiredHandler(addTeamToPoolHandler))
 	m.Add("1.0", http.MethodDelete, "/pools/{name}/team", AuthorizationRequiredHandler(removeTeamToPoolHandler))
 	m.Add("1.8", http.MethodGet, "/pools/{name}", AuthorizationRequiredHandler(getPoolHandler))
+	m.Add("1.33", http.MethodGet, "/pools/{name}/resource-quota", AuthorizationRequiredHandler(getPoolResourceQuota))
+	m.Add("1.33", http.MethodPut, "/pools/{name}/resource-quota", AuthorizationRequiredHandler(changePoolResourceQuota))
 
 	m.Add("1.3", http.MethodGet, "/constraints", AuthorizationRequiredHandler(poolConstraintList))
 	m.Add("1.3", http.MethodPut, "/constraints", AuthorizationRequiredHandler(poolConstraintSet))
@@ -466,8 +500,12 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.13", http.MethodPost, "/jobs/{name}/env", AuthorizationRequiredHandler(setJobEnv))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/env", AuthorizationRequiredHandler(unsetJobEnv))
 	m.Add("1.13", http.MethodGet, "/jobs/{name}/log", AuthorizationRequiredHandler(jobLog))
+	m.Add("1.33", http.MethodGet, "/jobs/{name}/runs", AuthorizationRequiredHandler(jobRuns))
+	m.Add("1.33", http.MethodGet, "/jobs/{name}/runs/{id}/log", AuthorizationRequiredHandler(jobRunLog))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/units/{unit}", AuthorizationRequiredHandler(killJob))
 	m.Add("1.23", http.MethodPost, "/jobs/{name}/deploy", AuthorizationRequiredHandler(jobDeploy))
+	m.Add("1.33", http.MethodPost, "/jobs/{name}/ttl", AuthorizationRequiredHandler(jobExtendTTL))
+	m.Add("1.33", http.MethodPost, "/jobs/{name}/pin", AuthorizationRequiredHandler(jobPin))
 
 	n := negroni.New()
 	n.Use(negroni.NewRecovery())
@@ -491,6 +529,7 @@ func RunServer(dry bool) http.Handler {
 	n.Use(negroni.HandlerFunc(errorHandlingMiddleware))
 	n.Use(negroni.HandlerFunc(setVersionHeadersMiddleware))
 	n.Use(negroni.HandlerFunc(authTokenMiddleware))
+	n.Use(negroni.HandlerFunc(rateLimitMiddleware))
 
 	n.UseHandler(http.HandlerFunc(runDelayedHandler))
 
@@ -584,6 +623,22 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize old image gc")
 	}
+	err = usage.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize usage snapshots")
+	}
+	err = rotation.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize team token rotation")
+	}
+	err = reconcile.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize quota reconciliation")
+	}
+	err = expiration.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize expiration reaper")
+	}
 	log.Debugf("Checking components status:")
 	results := hc.Check(ctx, "all")
 	for _, result := range results {
@@ -20,6 +20,8 @@ import (
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/recording"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -186,11 +188,17 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	rec := recording.NewRecorder(recording.Header{
+		Width:  width,
+		Height: height,
+		Term:   clientTerm,
+	})
 	defer func() {
 		var finalErr error
 		if httpErr != nil {
 			finalErr = httpErr
 		}
+		saveRecording(ctx, evt, rec)
 		for term != nil {
 			buf.disableWrite = true
 			var line string
@@ -220,7 +228,7 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 			ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(2*time.Second))
 		}
 	}()
-	conn := &cmdLogger{base: &wsReadWriteCloser{ws}, term: term}
+	conn := &cmdLogger{base: rec.Wrap(&wsReadWriteCloser{ws}), term: term}
 	opts := provision.ExecOptions{
 		Stdout: conn,
 		Stderr: conn,
@@ -240,6 +248,17 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func saveRecording(ctx stdContext.Context, evt *event.Event, rec *recording.Recorder) {
+	err := rec.Save(stdContext.WithoutCancel(ctx), evt.UniqueID)
+	if err != nil {
+		log.Errorf("unable to save recording for event %s: %v", evt.UniqueID.Hex(), err)
+		return
+	}
+	if rec.Truncated() {
+		fmt.Fprintf(evt, "session recording truncated at %d bytes\n", recording.MaxSize())
+	}
+}
+
 func unitsForShell(ctx stdContext.Context, a *appTypes.App, unitID string, isolated bool) []string {
 	if isolated {
 		return nil
@@ -84,6 +84,7 @@ func tokenInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 // responses:
 //
 //	201: Token created
+//	400: Invalid data
 //	401: Unauthorized
 //	409: Token already exists
 func tokenCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
@@ -118,6 +119,12 @@ func tokenCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	}
 	defer func() { evt.Done(ctx, err) }()
 	token, err := servicemanager.TeamToken.Create(ctx, args, t)
+	if err == authTypes.ErrInvalidTeamTokenRotation {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -141,6 +148,7 @@ func tokenCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 // responses:
 //
 //	200: Token updated
+//	400: Invalid data
 //	401: Unauthorized
 //	404: Token not found
 func tokenUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
@@ -186,6 +194,12 @@ func tokenUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 			Message: err.Error(),
 		}
 	}
+	if err == authTypes.ErrInvalidTeamTokenRotation {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -240,6 +240,48 @@ func (s *S) TestTeamTokenUpdate(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestTeamTokenUpdateRotate(c *check.C) {
+	originalToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "id1",
+	}, s.token)
+	c.Assert(err, check.IsNil)
+
+	body := strings.NewReader(`rotate=true&grace_period=3600`)
+	request, err := http.NewRequest("PUT", "/1.6/tokens/id1", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+
+	newToken, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "id1")
+	c.Assert(err, check.IsNil)
+	c.Assert(originalToken.Token, check.Not(check.Equals), newToken.Token)
+	c.Assert(newToken.PreviousToken, check.Equals, originalToken.Token)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+originalToken.Token)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestTeamTokenUpdateInvalidGracePeriod(c *check.C) {
+	_, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "id1",
+	}, s.token)
+	c.Assert(err, check.IsNil)
+
+	body := strings.NewReader(`rotate=true&grace_period=-1`)
+	request, err := http.NewRequest("PUT", "/1.6/tokens/id1", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, authTypes.ErrInvalidTeamTokenRotation.Error()+"\n")
+}
+
 func (s *S) TestTeamTokenInfo(c *check.C) {
 	newToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
 		Team:        s.team.Name,
@@ -0,0 +1,125 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app/usage"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultUsageReportPeriod = 30 * 24 * time.Hour
+
+// title: usage report
+// path: /reports/usage
+// method: GET
+// produce: application/json, text/csv
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+func usageReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	filter := usage.Filter{
+		Team:    r.URL.Query().Get("team"),
+		Pool:    r.URL.Query().Get("pool"),
+		GroupBy: r.URL.Query().Get("groupBy"),
+	}
+	var err error
+	filter.To, err = parseUsageTime(r, "to", time.Now())
+	if err != nil {
+		return err
+	}
+	filter.From, err = parseUsageTime(r, "from", filter.To.Add(-defaultUsageReportPeriod))
+	if err != nil {
+		return err
+	}
+	if !filter.From.Before(filter.To) {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "from must be before to"}
+	}
+	contexts := permission.ContextsForPermission(ctx, t, permission.PermTeamReadUsage)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	filter.Teams = []string{}
+	for _, c := range contexts {
+		if c.CtxType == permTypes.CtxGlobal {
+			filter.Teams = nil
+			break
+		}
+		if c.CtxType == permTypes.CtxTeam {
+			filter.Teams = append(filter.Teams, c.Value)
+		}
+	}
+	items, err := usage.Report(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	if r.URL.Query().Get("format") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		w.Header().Set("Content-Type", "text/csv")
+		return writeUsageCSV(w, items)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(items)
+}
+
+func parseUsageTime(r *http.Request, param string, defaultValue time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid %s, must be a RFC3339 date: %v", param, err),
+		}
+	}
+	return parsed, nil
+}
+
+func writeUsageCSV(w http.ResponseWriter, items []usage.ReportItem) error {
+	formatFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"app", "team", "pool", "cluster", "unit_hours", "cpu_hours", "memory_gib_hours"})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		err = writer.Write([]string{
+			item.App,
+			item.Team,
+			item.Pool,
+			item.Cluster,
+			formatFloat(item.UnitHours),
+			formatFloat(item.CPUHours),
+			formatFloat(item.MemoryGiBHours),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app/usage"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) insertUsageSnapshots(c *check.C, now time.Time) {
+	collection, err := storagev2.UsageSnapshotsCollection()
+	c.Assert(err, check.IsNil)
+	snapshots := []usage.Snapshot{
+		{ID: "app1/1", Time: now.Add(-2 * time.Hour), Interval: time.Hour, App: "app1", Team: "team1", Pool: "pool1", Cluster: "c1", Units: 2, CPUMilli: 1000, Memory: 2 << 30},
+		{ID: "app1/2", Time: now.Add(-time.Hour), Interval: time.Hour, App: "app1", Team: "team1", Pool: "pool1", Cluster: "c1", Units: 1, CPUMilli: 500, Memory: 1 << 30},
+		{ID: "app2/1", Time: now.Add(-time.Hour), Interval: time.Hour, App: "app2", Team: "team2", Pool: "pool2", Cluster: "c2", Units: 1, CPUMilli: 250, Memory: 1 << 29},
+	}
+	for _, snapshot := range snapshots {
+		_, err = collection.InsertOne(context.TODO(), snapshot)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *S) TestUsageReport(c *check.C) {
+	s.insertUsageSnapshots(c, time.Now())
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/reports/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var items []usage.ReportItem
+	err = json.Unmarshal(recorder.Body.Bytes(), &items)
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.DeepEquals, []usage.ReportItem{
+		{App: "app1", Team: "team1", Pool: "pool1", Cluster: "c1", UnitHours: 3, CPUHours: 1.5, MemoryGiBHours: 3},
+		{App: "app2", Team: "team2", Pool: "pool2", Cluster: "c2", UnitHours: 1, CPUHours: 0.25, MemoryGiBHours: 0.5},
+	})
+}
+
+func (s *S) TestUsageReportFilters(c *check.C) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	s.insertUsageSnapshots(c, now)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/reports/usage?groupBy=pool&pool=pool1&from=2026-05-10T11:00:00Z&to=2026-05-10T12:00:00Z", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %q", recorder.Body.String()))
+	var items []usage.ReportItem
+	err = json.Unmarshal(recorder.Body.Bytes(), &items)
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.DeepEquals, []usage.ReportItem{
+		{Pool: "pool1", UnitHours: 1, CPUHours: 0.5, MemoryGiBHours: 1},
+	})
+}
+
+func (s *S) TestUsageReportCSV(c *check.C) {
+	s.insertUsageSnapshots(c, time.Now())
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/reports/usage?groupBy=team", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Accept", "text/csv")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/csv")
+	c.Assert(recorder.Body.String(), check.Equals, `app,team,pool,cluster,unit_hours,cpu_hours,memory_gib_hours
+,team1,,,3.000,1.500,3.000
+,team2,,,1.000,0.250,0.500
+`)
+}
+
+func (s *S) TestUsageReportOnlyAllowedTeams(c *check.C) {
+	s.insertUsageSnapshots(c, time.Now())
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermTeamReadUsage,
+		Context: permission.Context(permTypes.CtxTeam, "team2"),
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/reports/usage?format=csv", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, `app,team,pool,cluster,unit_hours,cpu_hours,memory_gib_hours
+app2,team2,pool2,c2,1.000,0.250,0.500
+`)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", "/1.33/reports/usage?team=team1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestUsageReportWithoutPermission(c *check.C) {
+	s.insertUsageSnapshots(c, time.Now())
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermTeamRead,
+		Context: permission.Context(permTypes.CtxTeam, "team1"),
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.33/reports/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestUsageReportInvalidParams(c *check.C) {
+	tests := []struct {
+		query   string
+		message string
+	}{
+		{query: "from=yesterday", message: "invalid from, must be a RFC3339 date: .*"},
+		{query: "from=2026-05-10T12:00:00Z&to=2026-05-10T11:00:00Z", message: "from must be before to"},
+		{query: "groupBy=planet", message: `invalid group "planet", must be one of: app, team, pool, cluster`},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/1.33/reports/usage?"+tt.query, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("query: %s", tt.query))
+		c.Assert(recorder.Body.String(), check.Matches, tt.message+"\n")
+	}
+}
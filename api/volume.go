@@ -281,6 +281,7 @@ func volumeDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 // responses:
 //
 //	200: Volume binded
+//	400: Invalid data
 //	401: Unauthorized
 //	404: Volume not found
 //	409: Volume bind already exists
@@ -288,6 +289,7 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	ctx := r.Context()
 	var bindInfo struct {
 		App        string
+		Job        string
 		MountPoint string
 		ReadOnly   bool
 		NoRestart  bool
@@ -296,6 +298,9 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if err != nil {
 		return err
 	}
+	if bindInfo.App != "" && bindInfo.Job != "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "volume must be bound to either an app or a job"}
+	}
 	dbVolume, err := servicemanager.Volume.Get(ctx, r.URL.Query().Get(":name"))
 	if err != nil {
 		if err == volumeTypes.ErrVolumeNotFound {
@@ -307,6 +312,14 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if !canBindVolume {
 		return permission.ErrUnauthorized
 	}
+	if bindInfo.Job != "" {
+		return volumeBindJob(r, t, &volumeTypes.BindOpts{
+			Volume:     dbVolume,
+			JobName:    bindInfo.Job,
+			MountPoint: bindInfo.MountPoint,
+			ReadOnly:   bindInfo.ReadOnly,
+		}, bindInfo.NoRestart)
+	}
 	a, err := getAppFromContext(bindInfo.App, r)
 	if err != nil {
 		return err
@@ -347,6 +360,47 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	return app.Restart(ctx, a, "", "", evt)
 }
 
+func volumeBindJob(r *http.Request, t auth.Token, opts *volumeTypes.BindOpts, noRestart bool) (err error) {
+	ctx := r.Context()
+	j, err := getJob(ctx, opts.JobName)
+	if err != nil {
+		return err
+	}
+	canBindJob := permission.Check(ctx, t, permission.PermJobUpdate, contextsForJob(j)...)
+	if !canBindJob {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: opts.Volume.Name},
+		Kind:       permission.PermVolumeUpdateBind,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermVolumeReadEvents, contextsForVolume(opts.Volume)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Volume.BindJob(ctx, opts)
+	if err != nil {
+		switch err {
+		case volumeTypes.ErrVolumeAlreadyBound:
+			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		case volumeTypes.ErrVolumePlanNotFound, pool.ErrPoolHasNoVolumePlan:
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		if v, ok := err.(*errors.ValidationError); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+		}
+		return err
+	}
+	if noRestart {
+		return nil
+	}
+	return servicemanager.Job.UpdateJobProv(ctx, j)
+}
+
 // title: volume unbind
 // path: /volumes/{name}/bind
 // method: DELETE
@@ -354,12 +408,14 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 // responses:
 //
 //	200: Volume unbinded
+//	400: Invalid data
 //	401: Unauthorized
 //	404: Volume not found
 func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	var bindInfo struct {
 		App        string
+		Job        string
 		MountPoint string
 		NoRestart  bool
 	}
@@ -367,6 +423,9 @@ func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 	if err != nil {
 		return err
 	}
+	if bindInfo.App != "" && bindInfo.Job != "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "volume must be unbound from either an app or a job"}
+	}
 	dbVolume, err := servicemanager.Volume.Get(ctx, r.URL.Query().Get(":name"))
 	if err != nil {
 		if err == volumeTypes.ErrVolumeNotFound {
@@ -378,6 +437,13 @@ func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 	if !canUnbind {
 		return permission.ErrUnauthorized
 	}
+	if bindInfo.Job != "" {
+		return volumeUnbindJob(r, t, &volumeTypes.BindOpts{
+			Volume:     dbVolume,
+			JobName:    bindInfo.Job,
+			MountPoint: bindInfo.MountPoint,
+		}, bindInfo.NoRestart)
+	}
 	a, err := getAppFromContext(bindInfo.App, r)
 	if err != nil {
 		return err
@@ -416,3 +482,38 @@ func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) (err err
 	evt.SetLogWriter(writer)
 	return app.Restart(ctx, a, "", "", evt)
 }
+
+func volumeUnbindJob(r *http.Request, t auth.Token, opts *volumeTypes.BindOpts, noRestart bool) (err error) {
+	ctx := r.Context()
+	j, err := getJob(ctx, opts.JobName)
+	if err != nil {
+		return err
+	}
+	canUnbindJob := permission.Check(ctx, t, permission.PermJobUpdate, contextsForJob(j)...)
+	if !canUnbindJob {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: opts.Volume.Name},
+		Kind:       permission.PermVolumeUpdateUnbind,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermVolumeReadEvents, contextsForVolume(opts.Volume)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Volume.UnbindJob(ctx, opts)
+	if err != nil {
+		if err == volumeTypes.ErrVolumeBindNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	if noRestart {
+		return nil
+	}
+	return servicemanager.Job.UpdateJobProv(ctx, j)
+}
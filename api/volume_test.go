@@ -15,12 +15,15 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	check "gopkg.in/check.v1"
@@ -733,6 +736,109 @@ func (s *S) TestVolumeBindNoRestart(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, "")
 }
 
+func (s *S) TestVolumeBindJob(c *check.C) {
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	s.mockService.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		c.Assert(name, check.Equals, "myjob")
+		return &jobTypes.Job{Name: "myjob", TeamOwner: s.team.Name, Pool: s.Pool}, nil
+	}
+	var bindOpts *volumeTypes.BindOpts
+	s.mockService.VolumeService.OnBindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		bindOpts = opts
+		return nil
+	}
+	var updated bool
+	s.mockService.JobService.OnUpdateJobProv = func(job *jobTypes.Job) error {
+		c.Assert(job.Name, check.Equals, "myjob")
+		updated = true
+		return nil
+	}
+	body := strings.NewReader(`job=myjob&mountpoint=/data&readonly=true`)
+	request, err := http.NewRequest("POST", "/1.4/volumes/v1/bind", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(bindOpts, check.DeepEquals, &volumeTypes.BindOpts{
+		Volume:     &v1,
+		JobName:    "myjob",
+		MountPoint: "/data",
+		ReadOnly:   true,
+	})
+	c.Assert(updated, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: "v1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "volume.update.bind",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestVolumeBindJobNoRestart(c *check.C) {
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	s.mockService.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		return &jobTypes.Job{Name: "myjob", TeamOwner: s.team.Name, Pool: s.Pool}, nil
+	}
+	s.mockService.JobService.OnUpdateJobProv = func(job *jobTypes.Job) error {
+		c.Fatal("job should not be updated")
+		return nil
+	}
+	body := strings.NewReader(`job=myjob&mountpoint=/data&norestart=true`)
+	request, err := http.NewRequest("POST", "/1.4/volumes/v1/bind", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestVolumeBindJobErrors(c *check.C) {
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	s.mockService.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		if name != "myjob" {
+			return nil, jobTypes.ErrJobNotFound
+		}
+		return &jobTypes.Job{Name: "myjob", TeamOwner: s.team.Name, Pool: s.Pool}, nil
+	}
+	var bindErr error
+	s.mockService.VolumeService.OnBindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		return bindErr
+	}
+	tests := []struct {
+		body     string
+		bindErr  error
+		expected int
+	}{
+		{body: `job=myjob&app=myapp&mountpoint=/data`, expected: http.StatusBadRequest},
+		{body: `job=otherjob&mountpoint=/data`, expected: http.StatusNotFound},
+		{body: `job=myjob&mountpoint=/data`, bindErr: volumeTypes.ErrVolumePlanNotFound, expected: http.StatusBadRequest},
+		{body: `job=myjob&mountpoint=/data`, bindErr: pool.ErrPoolHasNoVolumePlan, expected: http.StatusBadRequest},
+		{body: `job=myjob&mountpoint=/data`, bindErr: volumeTypes.ErrVolumeAlreadyBound, expected: http.StatusConflict},
+		{body: `job=myjob&mountpoint=/data`, bindErr: &errors.ValidationError{Message: "volume and job must be in the same pool"}, expected: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		bindErr = tt.bindErr
+		request, err := http.NewRequest("POST", "/1.4/volumes/v1/bind", strings.NewReader(tt.body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, tt.expected, check.Commentf("body: %s", tt.body))
+	}
+}
+
 func (s *S) TestVolumeUnbind(c *check.C) {
 	s.mockService.Team.OnList = func() ([]authTypes.Team, error) {
 		return []authTypes.Team{{Name: s.team.Name}}, nil
@@ -878,3 +984,54 @@ func (s *S) TestVolumeUnbindNoRestart(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 	c.Assert(recorder.Body.String(), check.Equals, "")
 }
+
+func (s *S) TestVolumeUnbindJob(c *check.C) {
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	s.mockService.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		return &jobTypes.Job{Name: "myjob", TeamOwner: s.team.Name, Pool: s.Pool}, nil
+	}
+	var unbindOpts *volumeTypes.BindOpts
+	s.mockService.VolumeService.OnUnbindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		unbindOpts = opts
+		return nil
+	}
+	var updated bool
+	s.mockService.JobService.OnUpdateJobProv = func(job *jobTypes.Job) error {
+		updated = true
+		return nil
+	}
+	request, err := http.NewRequest("DELETE", "/1.4/volumes/v1/bind?job=myjob&mountpoint=/data", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(unbindOpts, check.DeepEquals, &volumeTypes.BindOpts{
+		Volume:     &v1,
+		JobName:    "myjob",
+		MountPoint: "/data",
+	})
+	c.Assert(updated, check.Equals, true)
+}
+
+func (s *S) TestVolumeUnbindJobNotFound(c *check.C) {
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	s.mockService.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		return &jobTypes.Job{Name: "myjob", TeamOwner: s.team.Name, Pool: s.Pool}, nil
+	}
+	s.mockService.VolumeService.OnUnbindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		return volumeTypes.ErrVolumeBindNotFound
+	}
+	request, err := http.NewRequest("DELETE", "/1.4/volumes/v1/bind?job=myjob&mountpoint=/data", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
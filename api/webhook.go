@@ -201,3 +201,90 @@ func webhookDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	}()
 	return servicemanager.Webhook.Delete(ctx, webhookName)
 }
+
+// title: webhook deliveries
+// path: /events/webhooks/{name}/deliveries
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List webhook deliveries
+//	204: No content
+//	401: Unauthorized
+//	404: Webhook not found
+func webhookDeliveries(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookRead, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	deliveries, err := servicemanager.Webhook.Deliveries(ctx, webhookName)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deliveries)
+}
+
+// title: webhook redeliver
+// path: /events/webhooks/{name}/deliveries/{id}/redeliver
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Event redelivered
+//	401: Unauthorized
+//	404: Webhook or delivery not found
+func webhookRedeliver(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	deliveryID := r.URL.Query().Get(":id")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookUpdate, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: webhook.Name},
+		Kind:       permission.PermWebhookUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: []map[string]interface{}{
+			{"name": "delivery", "value": deliveryID},
+		},
+		Allowed: event.Allowed(permission.PermWebhookReadEvents, permissionCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		evt.Done(ctx, err)
+	}()
+	delivery, err := servicemanager.Webhook.Redeliver(ctx, webhookName, deliveryID)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound || err == eventTypes.ErrWebhookDeliveryNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(delivery)
+}
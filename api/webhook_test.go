@@ -10,8 +10,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/cezarsa/form"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	eventTypes "github.com/tsuru/tsuru/types/event"
@@ -395,3 +399,120 @@ func (s *S) TestWebhookInfoNotFound(c *check.C) {
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 }
+
+func (s *S) TestWebhookDeliveries(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.InsertOne(context.TODO(), eventTypes.WebhookDelivery{
+		ID:         "d1",
+		Webhook:    "wh1",
+		EventID:    "e1",
+		Timestamp:  time.Now(),
+		Attempts:   3,
+		StatusCode: http.StatusBadGateway,
+		Error:      "invalid status code calling hook: 502",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.33/events/webhooks/wh1/deliveries", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var deliveries []eventTypes.WebhookDelivery
+	err = json.Unmarshal(recorder.Body.Bytes(), &deliveries)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].ID, check.Equals, "d1")
+	c.Assert(deliveries[0].Attempts, check.Equals, 3)
+	c.Assert(deliveries[0].Success, check.Equals, false)
+}
+
+func (s *S) TestWebhookDeliveriesNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.33/events/webhooks/wh1/deliveries", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestWebhookRedeliver(c *check.C) {
+	called := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       srv.URL,
+		EventFilter: eventTypes.WebhookEventFilter{
+			TargetTypes: []string{"none"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.token.GetUserName()},
+		Allowed:  event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.InsertOne(context.TODO(), eventTypes.WebhookDelivery{
+		ID:         "d1",
+		Webhook:    "wh1",
+		EventID:    evt.UniqueID.Hex(),
+		Timestamp:  time.Now(),
+		Attempts:   1,
+		StatusCode: http.StatusBadRequest,
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.33/events/webhooks/wh1/deliveries/d1/redeliver", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	<-called
+	var delivery eventTypes.WebhookDelivery
+	err = json.Unmarshal(recorder.Body.Bytes(), &delivery)
+	c.Assert(err, check.IsNil)
+	c.Assert(delivery.EventID, check.Equals, evt.UniqueID.Hex())
+	c.Assert(delivery.Success, check.Equals, true)
+	c.Assert(delivery.Redelivery, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: "wh1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "webhook.update",
+		StartCustomData: []map[string]interface{}{
+			{"name": "delivery", "value": "d1"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestWebhookRedeliverDeliveryNotFound(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.33/events/webhooks/wh1/deliveries/d1/redeliver", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
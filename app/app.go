@@ -20,12 +20,14 @@ import (
 	"strings"
 	"sync"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/action"
 	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/app/sbom"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db/storagev2"
@@ -38,6 +40,7 @@ import (
 	"github.com/tsuru/tsuru/registry"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/secret"
 	"github.com/tsuru/tsuru/service"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
@@ -163,6 +166,7 @@ func AppInfo(ctx context.Context, app *appTypes.App) (*appTypes.AppInfo, error)
 		TeamOwner:   app.TeamOwner,
 		Tags:        app.Tags,
 		Metadata:    app.Metadata,
+		ExpiresAt:   app.ExpiresAt,
 	}
 
 	if version := image.GetPlatformVersion(app); version != "latest" {
@@ -440,6 +444,9 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 	platform := args.UpdateData.Platform
 	tags := processTags(args.UpdateData.Tags)
 	oldApp := *app
+	// updateProcesses changes the processes in place
+	previous := oldApp
+	previous.Processes = append([]appTypes.Process(nil), app.Processes...)
 
 	oldPlan, err := json.Marshal(oldApp.Plan)
 	if err != nil {
@@ -538,6 +545,10 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 	if err != nil {
 		return err
 	}
+	err = ensureUpdateResourceQuota(ctx, &previous, app)
+	if err != nil {
+		return err
+	}
 	actions := []*action.Action{
 		&saveApp,
 	}
@@ -785,6 +796,10 @@ func Delete(ctx context.Context, app *appTypes.App, evt *event.Event, requestID
 	if err != nil {
 		log.Errorf("failed to remove image names from storage for app %s: %s", appName, err)
 	}
+	err = sbom.RemoveApp(ctx, appName)
+	if err != nil {
+		log.Errorf("failed to remove sboms from storage for app %s: %s", appName, err)
+	}
 	routers := GetRouters(app)
 	for _, appRouter := range routers {
 		var r router.Router
@@ -891,6 +906,12 @@ func AddUnits(ctx context.Context, app *appTypes.App, n uint, process, versionSt
 			return errors.New("Cannot add units to an app that has stopped units")
 		}
 	}
+	err = ensureResourceQuota(ctx, app, func(units map[string]int) {
+		units[process] += int(n)
+	})
+	if err != nil {
+		return err
+	}
 	version, err := getVersion(ctx, app, versionStr)
 	if err != nil {
 		return err
@@ -1498,6 +1519,10 @@ func SetEnvs(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArg
 		if err != nil {
 			return err
 		}
+		err = validateEnvSecretRef(ctx, app, env)
+		if err != nil {
+			return err
+		}
 		envNames = append(envNames, env.Name)
 	}
 
@@ -1741,6 +1766,8 @@ type Filter struct {
 	Statuses    []string
 	Tags        []string
 	Extra       map[string][]string
+
+	ExpiresBefore *time.Time
 }
 
 func (f *Filter) IsEmpty() bool {
@@ -1810,6 +1837,9 @@ func (f *Filter) Query() mongoBSON.M {
 	if len(tags) > 0 {
 		query["tags"] = mongoBSON.M{"$all": tags}
 	}
+	if f.ExpiresBefore != nil {
+		query["expiresat"] = mongoBSON.M{"$lte": *f.ExpiresBefore}
+	}
 	return query
 }
 
@@ -2465,6 +2495,38 @@ func validateEnv(envName string) error {
 	return nil
 }
 
+// validateEnvSecretRef checks that the env references a secret the app is
+// allowed to use, either in an external provider, under the path prefix of the
+// app, or in the provisioner, which checks who owns the secret.
+func validateEnvSecretRef(ctx context.Context, app *appTypes.App, env bindTypes.EnvVar) error {
+	if env.SecretRef == nil {
+		return nil
+	}
+	if env.Value != "" || env.Alias != "" || env.Public {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Environment variable %q referencing a secret must be private and have no value", env.Name)}
+	}
+	err := secret.Validate(app, *env.SecretRef)
+	if err == nil && env.SecretRef.Provider == secret.KubernetesProvider {
+		err = validateProvisionerSecretRef(ctx, app, *env.SecretRef)
+	}
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid secret reference for environment variable %q: %s", env.Name, err)}
+	}
+	return nil
+}
+
+func validateProvisionerSecretRef(ctx context.Context, app *appTypes.App, ref bindTypes.SecretRef) error {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return err
+	}
+	sprov, ok := prov.(provision.SecretRefProvisioner)
+	if !ok {
+		return errors.Errorf("provisioner %v does not support %s secret references", prov.GetName(), ref.Provider)
+	}
+	return sprov.ValidateSecretRef(ctx, app, ref)
+}
+
 func SetRoutable(ctx context.Context, app *appTypes.App, version appTypes.AppVersion, isRoutable bool) error {
 	prov, err := getProvisioner(ctx, app)
 	if err != nil {
@@ -2596,6 +2658,12 @@ func AutoScale(ctx context.Context, app *appTypes.App, spec provTypes.AutoScaleS
 	if !ok {
 		return errors.Errorf("provisioner %q does not support native autoscaling", prov.GetName())
 	}
+	err = ensureResourceQuota(ctx, app, func(units map[string]int) {
+		units[spec.Process] = int(spec.MaxUnits)
+	})
+	if err != nil {
+		return err
+	}
 	return autoscaleProv.SetAutoScale(ctx, app, spec)
 }
 
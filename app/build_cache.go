@@ -0,0 +1,101 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provisionTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// SetBuildCache updates the build cache settings of the app, keeping the
+// time when the current cache was created.
+func SetBuildCache(ctx context.Context, app *appTypes.App, settings appTypes.BuildCache) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{
+			"buildcache.disabled": settings.Disabled,
+			"buildcache.isolated": settings.Isolated,
+			"buildcache.maxage":   settings.MaxAge,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return appTypes.ErrAppNotFound
+	}
+	settings.CreatedAt = app.BuildCache.CreatedAt
+	app.BuildCache = settings
+	return nil
+}
+
+// PurgeBuildCache makes the next build of the app start over from the
+// platform image.
+func PurgeBuildCache(ctx context.Context, app *appTypes.App) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set":   mongoBSON.M{"updateplatform": true},
+		"$unset": mongoBSON.M{"buildcache.createdat": ""},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return appTypes.ErrAppNotFound
+	}
+	app.UpdatePlatform = true
+	app.BuildCache.CreatedAt = nil
+	return nil
+}
+
+func setBuildCacheCreatedAt(ctx context.Context, app *appTypes.App, createdAt time.Time) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"buildcache.createdat": createdAt},
+	})
+	if err != nil {
+		return err
+	}
+	app.BuildCache.CreatedAt = &createdAt
+	return nil
+}
+
+// usesBuildCache tells whether builds of the kind start from the image of
+// a previous version.
+func usesBuildCache(kind provisionTypes.DeployKind) bool {
+	switch kind {
+	case provisionTypes.DeployArchiveURL, provisionTypes.DeployGit, provisionTypes.DeployUpload, provisionTypes.DeployUploadBuild:
+		return true
+	}
+	return false
+}
+
+// checkBuildCache records in the deploy event whether the build reuses the
+// cache of a previous version.
+func checkBuildCache(ctx context.Context, opts *DeployOptions, evt *event.Event) appTypes.BuildCacheStatus {
+	status := image.GetBuildCacheStatus(ctx, opts.App, opts.NoCache, opts.NewVersion)
+	err := evt.SetOtherCustomData(ctx, map[string]interface{}{"buildCache": status})
+	if err != nil {
+		log.Errorf("unable to record build cache status of app %q: %v", opts.App.Name, err)
+	}
+	return status
+}
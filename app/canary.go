@@ -0,0 +1,318 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/streamfmt"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultCanaryStepInterval = time.Minute
+
+var (
+	ErrNoRouterWithWeights  = errors.New("no router with weighted backends support")
+	ErrCanaryNoBaseVersion  = errors.New("no routable version to shift traffic from")
+	ErrCanarySameVersion    = errors.New("canary version is already the only routable version")
+	ErrCanaryMultipleRouted = errors.New("canary requires a single routable version, found multiple")
+
+	defaultCanarySteps = []int{5, 25, 100}
+
+	canaryWait = func(ctx context.Context, d time.Duration) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+			return nil
+		}
+	}
+)
+
+// ErrCanaryRolledBack is returned when traffic was moved back to the base
+// version because the app health degraded during a canary step.
+type ErrCanaryRolledBack struct {
+	Weight int
+	Reason string
+}
+
+func (e *ErrCanaryRolledBack) Error() string {
+	return fmt.Sprintf("canary rolled back at %d%% of traffic: %s", e.Weight, e.Reason)
+}
+
+type weightedRouter interface {
+	router.Router
+	router.WeightedRouter
+}
+
+type CanaryArgs struct {
+	Version      appTypes.AppVersion
+	Steps        []int
+	StepInterval time.Duration
+	Event        *event.Event
+	Output       io.Writer
+}
+
+type canaryStepData struct {
+	BaseVersion   int
+	CanaryVersion int
+	Weight        int
+}
+
+type canaryHealth struct {
+	notReady []string
+	restarts int32
+}
+
+func (h canaryHealth) degradedFrom(baseline canaryHealth) string {
+	if len(h.notReady) > len(baseline.notReady) {
+		return fmt.Sprintf("router backend not ready: %v", h.notReady)
+	}
+	if h.restarts > baseline.restarts {
+		return fmt.Sprintf("units restarted %d times", h.restarts-baseline.restarts)
+	}
+	return ""
+}
+
+// Canary shifts the traffic of an app from its current routable version to
+// args.Version, following the weights in args.Steps and waiting
+// args.StepInterval between them. Each step is recorded as an event and the
+// traffic is moved back to the base version if the router backend status or
+// the unit restarts get worse during a step.
+func Canary(ctx context.Context, app *appTypes.App, args CanaryArgs) error {
+	steps, err := canarySteps(args.Steps)
+	if err != nil {
+		return err
+	}
+	interval := args.StepInterval
+	if interval <= 0 {
+		interval, err = config.GetDuration("apps:canary:step-interval")
+		if err != nil || interval <= 0 {
+			interval = defaultCanaryStepInterval
+		}
+	}
+	w := args.Output
+	if w == nil {
+		w = io.Discard
+	}
+	routers, err := weightedRoutersForApp(ctx, app)
+	if err != nil {
+		return err
+	}
+	baseVersion, err := canaryBaseVersion(ctx, app, args.Version.Version())
+	if err != nil {
+		return err
+	}
+	canaryVersion := args.Version.Version()
+	baseline, err := canaryCheckHealth(ctx, app, routers)
+	if err != nil {
+		return err
+	}
+	err = setBackendWeights(ctx, app, routers, baseVersion, canaryVersion, 0)
+	if err != nil {
+		return err
+	}
+	err = SetRoutable(ctx, app, args.Version, true)
+	if err != nil {
+		return rollbackCanary(ctx, app, routers, args.Version, baseVersion, err)
+	}
+	for _, weight := range steps {
+		streamfmt.FprintlnSectionf(w, "Shifting %d%% of traffic to version %d", weight, canaryVersion)
+		err = runCanaryStep(ctx, app, routers, args, baseVersion, weight, interval, baseline)
+		if err != nil {
+			streamfmt.FprintlnErrorf(w, "Rolling back traffic to version %d: %v", baseVersion, err)
+			return rollbackCanary(ctx, app, routers, args.Version, baseVersion, err)
+		}
+	}
+	streamfmt.FprintlnSectionf(w, "Removing version %d from routers", baseVersion)
+	baseVersionObj, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(baseVersion))
+	if err != nil {
+		return err
+	}
+	err = SetRoutable(ctx, app, baseVersionObj, false)
+	if err != nil {
+		return err
+	}
+	for _, r := range routers {
+		err = r.SetBackendWeights(ctx, app, []router.BackendWeight{{Version: canaryVersion, Weight: 100}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCanaryStep(ctx context.Context, app *appTypes.App, routers []weightedRouter, args CanaryArgs, baseVersion, weight int, interval time.Duration, baseline canaryHealth) (err error) {
+	canaryVersion := args.Version.Version()
+	opts := &event.Opts{
+		Target:      eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: app.Name},
+		Kind:        permission.PermAppUpdateCanary,
+		RawOwner:    eventTypes.Owner{Type: eventTypes.OwnerTypeInternal},
+		DisableLock: true,
+		CustomData: canaryStepData{
+			BaseVersion:   baseVersion,
+			CanaryVersion: canaryVersion,
+			Weight:        weight,
+		},
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, app.Name)),
+	}
+	if args.Event != nil {
+		opts.RawOwner = args.Event.Owner
+		opts.Allowed = args.Event.Allowed
+	}
+	evt, err := event.New(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = setBackendWeights(ctx, app, routers, baseVersion, canaryVersion, weight)
+	if err != nil {
+		return err
+	}
+	if weight == 100 {
+		return nil
+	}
+	err = canaryWait(ctx, interval)
+	if err != nil {
+		return err
+	}
+	current, err := canaryCheckHealth(ctx, app, routers)
+	if err != nil {
+		return err
+	}
+	if reason := current.degradedFrom(baseline); reason != "" {
+		return &ErrCanaryRolledBack{Weight: weight, Reason: reason}
+	}
+	return nil
+}
+
+// rollbackCanary must run to completion even when the canary was canceled,
+// otherwise the routers would be left with partial weights.
+func rollbackCanary(ctx context.Context, app *appTypes.App, routers []weightedRouter, version appTypes.AppVersion, baseVersion int, cause error) error {
+	ctx = tsuruNet.WithoutCancel(ctx)
+	multi := tsuruErrors.NewMultiError(cause)
+	err := setBackendWeights(ctx, app, routers, baseVersion, version.Version(), 0)
+	if err != nil {
+		multi.Add(errors.Wrap(err, "unable to restore router weights"))
+	}
+	err = SetRoutable(ctx, app, version, false)
+	if err != nil {
+		log.Errorf("unable to set version %d of app %q as not routable: %v", version.Version(), app.Name, err)
+		multi.Add(err)
+	}
+	return multi.ToError()
+}
+
+func setBackendWeights(ctx context.Context, app *appTypes.App, routers []weightedRouter, baseVersion, canaryVersion, weight int) error {
+	weights := []router.BackendWeight{
+		{Version: baseVersion, Weight: 100 - weight},
+		{Version: canaryVersion, Weight: weight},
+	}
+	for _, r := range routers {
+		err := r.SetBackendWeights(ctx, app, weights)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func canarySteps(steps []int) ([]int, error) {
+	if len(steps) == 0 {
+		return defaultCanarySteps, nil
+	}
+	last := 0
+	for _, s := range steps {
+		if s <= last || s > 100 {
+			return nil, &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("invalid canary steps %v: weights must be increasing values between 1 and 100", steps),
+			}
+		}
+		last = s
+	}
+	if last != 100 {
+		steps = append(steps, 100)
+	}
+	return steps, nil
+}
+
+func weightedRoutersForApp(ctx context.Context, app *appTypes.App) ([]weightedRouter, error) {
+	var routers []weightedRouter
+	for _, appRouter := range GetRouters(app) {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return nil, err
+		}
+		wr, ok := r.(weightedRouter)
+		if !ok {
+			return nil, errors.Wrapf(ErrNoRouterWithWeights, "router %q", appRouter.Name)
+		}
+		routers = append(routers, wr)
+	}
+	if len(routers) == 0 {
+		return nil, ErrNoRouterWithWeights
+	}
+	return routers, nil
+}
+
+func canaryBaseVersion(ctx context.Context, app *appTypes.App, canaryVersion int) (int, error) {
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return 0, err
+	}
+	routable := map[int]struct{}{}
+	for _, u := range units {
+		if u.Routable {
+			routable[u.Version] = struct{}{}
+		}
+	}
+	_, canaryRoutable := routable[canaryVersion]
+	delete(routable, canaryVersion)
+	if len(routable) > 1 {
+		return 0, ErrCanaryMultipleRouted
+	}
+	for v := range routable {
+		return v, nil
+	}
+	if canaryRoutable {
+		return 0, ErrCanarySameVersion
+	}
+	return 0, ErrCanaryNoBaseVersion
+}
+
+func canaryCheckHealth(ctx context.Context, app *appTypes.App, routers []weightedRouter) (canaryHealth, error) {
+	var health canaryHealth
+	for _, r := range routers {
+		status, err := r.GetBackendStatus(ctx, app)
+		if err != nil || status.Status != router.BackendStatusReady {
+			health.notReady = append(health.notReady, r.GetName())
+		}
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return health, err
+	}
+	for _, u := range units {
+		if u.Restarts != nil {
+			health.restarts += *u.Restarts
+		}
+	}
+	return health, nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+type routableCall struct {
+	version    int
+	isRoutable bool
+}
+
+type canaryProvisioner struct {
+	*provisiontest.FakeProvisioner
+	calls []routableCall
+}
+
+func (p *canaryProvisioner) ToggleRoutable(ctx context.Context, a *appTypes.App, version appTypes.AppVersion, isRoutable bool) error {
+	p.calls = append(p.calls, routableCall{version: version.Version(), isRoutable: isRoutable})
+	return nil
+}
+
+func (p *canaryProvisioner) DeployedVersions(ctx context.Context, a *appTypes.App) ([]int, error) {
+	return nil, nil
+}
+
+func (s *S) setupCanaryApp(c *check.C) (*appTypes.App, *canaryProvisioner, appTypes.AppVersion, appTypes.AppVersion) {
+	prov := &canaryProvisioner{FakeProvisioner: provisiontest.NewFakeProvisioner()}
+	provision.Register("fake-canary", func() (provision.Provisioner, error) {
+		return prov, nil
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "canary-pool", Provisioner: "fake-canary", Public: true})
+	c.Assert(err, check.IsNil)
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Pool: "canary-pool", Routers: []appTypes.AppRouter{{Name: "fake-weighted"}}}
+	err = CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	err = routertest.WeightedRouter.EnsureBackend(context.TODO(), a, router.EnsureBackendOpts{})
+	c.Assert(err, check.IsNil)
+	base := newSuccessfulAppVersion(c, a)
+	canary := newSuccessfulAppVersion(c, a)
+	prov.AddUnit(a, provTypes.Unit{ID: "u1", AppName: a.Name, Version: base.Version(), Routable: true, Status: provTypes.UnitStatusStarted})
+	prov.AddUnit(a, provTypes.Unit{ID: "u2", AppName: a.Name, Version: canary.Version(), Status: provTypes.UnitStatusStarted})
+	return a, prov, base, canary
+}
+
+func (s *S) TestCanary(c *check.C) {
+	defer provision.Unregister("fake-canary")
+	a, prov, base, canary := s.setupCanaryApp(c)
+	var seenWeights [][]router.BackendWeight
+	oldWait := canaryWait
+	defer func() { canaryWait = oldWait }()
+	canaryWait = func(ctx context.Context, d time.Duration) error {
+		c.Assert(d, check.Equals, time.Second)
+		seenWeights = append(seenWeights, routertest.WeightedRouter.Weights[a.Name])
+		return nil
+	}
+	err := Canary(context.TODO(), a, CanaryArgs{
+		Version:      canary,
+		Steps:        []int{10, 50},
+		StepInterval: time.Second,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(seenWeights, check.DeepEquals, [][]router.BackendWeight{
+		{{Version: base.Version(), Weight: 90}, {Version: canary.Version(), Weight: 10}},
+		{{Version: base.Version(), Weight: 50}, {Version: canary.Version(), Weight: 50}},
+	})
+	c.Assert(routertest.WeightedRouter.Weights[a.Name], check.DeepEquals, []router.BackendWeight{
+		{Version: canary.Version(), Weight: 100},
+	})
+	c.Assert(prov.calls, check.DeepEquals, []routableCall{
+		{version: canary.Version(), isRoutable: true},
+		{version: base.Version(), isRoutable: false},
+	})
+	evts, err := event.All(context.TODO())
+	c.Assert(err, check.IsNil)
+	var steps int
+	for _, evt := range evts {
+		if evt.Kind.Name == permission.PermAppUpdateCanary.FullName() {
+			steps++
+			c.Assert(evt.Error, check.Equals, "")
+		}
+	}
+	c.Assert(steps, check.Equals, 3)
+}
+
+func (s *S) TestCanaryRollbackOnRestarts(c *check.C) {
+	defer provision.Unregister("fake-canary")
+	a, prov, base, canary := s.setupCanaryApp(c)
+	oldWait := canaryWait
+	defer func() { canaryWait = oldWait }()
+	canaryWait = func(ctx context.Context, d time.Duration) error {
+		restarts := int32(2)
+		prov.AddUnit(a, provTypes.Unit{ID: "u3", AppName: a.Name, Version: canary.Version(), Restarts: &restarts})
+		return nil
+	}
+	parent, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:     permission.PermAppUpdateCanary,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Canary(context.TODO(), a, CanaryArgs{
+		Version: canary,
+		Event:   parent,
+	})
+	c.Assert(err, check.FitsTypeOf, &ErrCanaryRolledBack{})
+	c.Assert(err.(*ErrCanaryRolledBack).Weight, check.Equals, 5)
+	c.Assert(routertest.WeightedRouter.Weights[a.Name], check.DeepEquals, []router.BackendWeight{
+		{Version: base.Version(), Weight: 100},
+		{Version: canary.Version(), Weight: 0},
+	})
+	c.Assert(prov.calls, check.DeepEquals, []routableCall{
+		{version: canary.Version(), isRoutable: true},
+		{version: canary.Version(), isRoutable: false},
+	})
+	evts, err := event.List(context.TODO(), &event.Filter{KindNames: []string{permission.PermAppUpdateCanary.FullName()}, ErrorOnly: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Owner.Name, check.Equals, s.user.Email)
+	c.Assert(evts[0].Error, check.Matches, "canary rolled back at 5% of traffic: units restarted 2 times")
+}
+
+func (s *S) TestCanaryRollbackWhenCanceled(c *check.C) {
+	defer provision.Unregister("fake-canary")
+	a, prov, base, canary := s.setupCanaryApp(c)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	oldWait := canaryWait
+	defer func() { canaryWait = oldWait }()
+	canaryWait = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+	err := Canary(ctx, a, CanaryArgs{Version: canary})
+	c.Assert(err, check.ErrorMatches, "context canceled")
+	c.Assert(routertest.WeightedRouter.Weights[a.Name], check.DeepEquals, []router.BackendWeight{
+		{Version: base.Version(), Weight: 100},
+		{Version: canary.Version(), Weight: 0},
+	})
+	c.Assert(prov.calls, check.DeepEquals, []routableCall{
+		{version: canary.Version(), isRoutable: true},
+		{version: canary.Version(), isRoutable: false},
+	})
+}
+
+func (s *S) TestCanaryRollbackOnRouterStatus(c *check.C) {
+	defer provision.Unregister("fake-canary")
+	a, _, _, canary := s.setupCanaryApp(c)
+	oldWait := canaryWait
+	defer func() { canaryWait = oldWait }()
+	canaryWait = func(ctx context.Context, d time.Duration) error {
+		routertest.WeightedRouter.FailuresByHost[a.Name] = true
+		return nil
+	}
+	err := Canary(context.TODO(), a, CanaryArgs{Version: canary, Steps: []int{20}})
+	c.Assert(err, check.ErrorMatches, `canary rolled back at 20% of traffic: router backend not ready: \[fake-weighted\]`)
+}
+
+func (s *S) TestCanaryRouterWithoutWeights(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, a)
+	err = Canary(context.TODO(), a, CanaryArgs{Version: version})
+	c.Assert(err, check.ErrorMatches, `router "fake": no router with weighted backends support`)
+}
+
+func (s *S) TestCanaryNoBaseVersion(c *check.C) {
+	defer provision.Unregister("fake-canary")
+	a, prov, _, canary := s.setupCanaryApp(c)
+	prov.Reset()
+	err := Canary(context.TODO(), a, CanaryArgs{Version: canary})
+	c.Assert(err, check.Equals, ErrCanaryNoBaseVersion)
+}
+
+func (s *S) TestCanarySteps(c *check.C) {
+	steps, err := canarySteps(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(steps, check.DeepEquals, []int{5, 25, 100})
+	steps, err = canarySteps([]int{1, 50})
+	c.Assert(err, check.IsNil)
+	c.Assert(steps, check.DeepEquals, []int{1, 50, 100})
+	_, err = canarySteps([]int{50, 10})
+	c.Assert(err, check.ErrorMatches, `invalid canary steps \[50 10\].*`)
+	_, err = canarySteps([]int{0, 100})
+	c.Assert(err, check.ErrorMatches, `invalid canary steps \[0 100\].*`)
+	_, err = canarySteps([]int{150})
+	c.Assert(err, check.ErrorMatches, `invalid canary steps \[150\].*`)
+}
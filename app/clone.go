@@ -0,0 +1,75 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+// CloneApp returns the definition of a copy of the app, to be created with
+// AppFromExport, along with the manifest changes copying its configuration.
+// The copy keeps the image, plan, processes, routers, env vars, along with
+// their secret references and aliases, volumes and autoscales of the source
+// app, but not its cnames, which can't be shared. Access is only granted to
+// the teams of the source app when the team owner is kept. Service binds are
+// only part of the changes with CloneServicesSame, the services bound to the
+// source app are listed in the manifest of the returned definition either
+// way.
+func CloneApp(ctx context.Context, src *appTypes.App, opts appTypes.CloneOptions) (*appTypes.AppExport, []appTypes.ManifestChange, error) {
+	switch opts.Services {
+	case "", appTypes.CloneServicesSame, appTypes.CloneServicesNew:
+	default:
+		return nil, nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid services option %q, must be either %q or %q", opts.Services, appTypes.CloneServicesSame, appTypes.CloneServicesNew)}
+	}
+	exp, err := ExportApp(ctx, src, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	exp.Name = opts.Name
+	exp.OmittedEnvs = nil
+	exp.Manifest.Env = nil
+	exp.Manifest.CNames = nil
+	if opts.Pool != "" {
+		exp.Pool = opts.Pool
+	}
+	if opts.TeamOwner != "" && opts.TeamOwner != src.TeamOwner {
+		exp.TeamOwner = opts.TeamOwner
+		exp.Teams = nil
+	}
+	envs := map[string]bindTypes.EnvVar{}
+	for name, env := range src.Env {
+		if env.ManagedBy == "" {
+			env.Name = name
+			if env.SecretRef != nil {
+				ref := *env.SecretRef
+				env.SecretRef = &ref
+			}
+			envs[name] = env
+		}
+	}
+	for _, e := range opts.Env {
+		envs[e.Name] = bindTypes.EnvVar{Name: e.Name, Value: e.Value, Alias: e.Alias, Public: !e.Private, SecretRef: e.SecretRef}
+	}
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	envList := make([]bindTypes.EnvVar, len(names))
+	for i, name := range names {
+		envList[i] = envs[name]
+	}
+	m := exp.Manifest
+	if opts.Services != appTypes.CloneServicesSame {
+		m.Services = nil
+	}
+	return exp, manifestAddChanges(m, envList), nil
+}
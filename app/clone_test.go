@@ -0,0 +1,79 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCloneApp(c *check.C) {
+	a := s.createExportedApp(c)
+	exp, changes, err := CloneApp(context.TODO(), a, appTypes.CloneOptions{
+		Name: "myapp-pr-1",
+		Env:  []appTypes.ManifestEnv{{Name: "PUBLIC", Value: "overridden"}, {Name: "NEW", Value: "new value", Private: true}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(exp.Name, check.Equals, "myapp-pr-1")
+	c.Assert(exp.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(exp.Pool, check.Equals, s.Pool)
+	c.Assert(exp.Image, check.Not(check.Equals), "")
+	c.Assert(exp.Manifest.CNames, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []appTypes.ManifestChange{
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "NEW",
+			After:   maskedManifestValue,
+			Desired: bindTypes.EnvVar{Name: "NEW", Value: "new value"},
+		},
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "PUBLIC",
+			After:   "overridden",
+			Desired: bindTypes.EnvVar{Name: "PUBLIC", Value: "overridden", Public: true},
+		},
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "SECRET",
+			After:   maskedManifestValue,
+			Desired: bindTypes.EnvVar{Name: "SECRET", Value: "secret value"},
+		},
+	})
+}
+
+func (s *S) TestCloneAppSecretRefsAndAliases(c *check.C) {
+	a := s.createExportedApp(c)
+	ref := bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "password"}
+	a.Env["DB_PASSWORD"] = bindTypes.EnvVar{Name: "DB_PASSWORD", SecretRef: &ref}
+	a.Env["PUBLIC_ALIAS"] = bindTypes.EnvVar{Name: "PUBLIC_ALIAS", Alias: "PUBLIC", Public: true}
+	_, changes, err := CloneApp(context.TODO(), a, appTypes.CloneOptions{Name: "myapp-pr-1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 4)
+	c.Assert(changes[0].Desired, check.DeepEquals, bindTypes.EnvVar{Name: "DB_PASSWORD", SecretRef: &ref})
+	c.Assert(changes[0].Desired.(bindTypes.EnvVar).SecretRef == a.Env["DB_PASSWORD"].SecretRef, check.Equals, false)
+	c.Assert(changes[2].Desired, check.DeepEquals, bindTypes.EnvVar{Name: "PUBLIC_ALIAS", Alias: "PUBLIC", Public: true})
+}
+
+func (s *S) TestCloneAppOverrideTeamOwner(c *check.C) {
+	a := s.createExportedApp(c)
+	exp, _, err := CloneApp(context.TODO(), a, appTypes.CloneOptions{Name: "myapp-pr-1", TeamOwner: "other-team", Pool: "other-pool"})
+	c.Assert(err, check.IsNil)
+	c.Assert(exp.TeamOwner, check.Equals, "other-team")
+	c.Assert(exp.Teams, check.IsNil)
+	c.Assert(exp.Pool, check.Equals, "other-pool")
+}
+
+func (s *S) TestCloneAppInvalidServicesOption(c *check.C) {
+	a := s.createExportedApp(c)
+	_, _, err := CloneApp(context.TODO(), a, appTypes.CloneOptions{Name: "myapp-pr-1", Services: "all"})
+	c.Assert(err, check.DeepEquals, &errors.ValidationError{Message: `invalid services option "all", must be either "same" or "new"`})
+}
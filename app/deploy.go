@@ -186,6 +186,8 @@ type DeployOptions struct {
 	Build            bool
 	NewVersion       bool
 	OverrideVersions bool
+	NoCache          bool
+	SkipScan         bool
 }
 
 func (o *DeployOptions) GetOrigin() string {
@@ -404,6 +406,11 @@ func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Ev
 		}
 	}
 
+	err = ensureDeployResourceQuota(ctx, opts.App, version)
+	if err != nil {
+		return "", err
+	}
+
 	err = evt.SetCancelable(ctx, false)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to set event as non-cancelable")
@@ -436,12 +443,40 @@ func builderDeploy(ctx context.Context, opts *DeployOptions, evt *event.Event) (
 		return nil, err
 	}
 
+	if opts.GetKind() == provisionTypes.DeployImage {
+		buildOpts.ImageID, err = checkImageSignature(ctx, opts.App, opts.Image, evt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cacheStatus *appTypes.BuildCacheStatus
+	if usesBuildCache(opts.GetKind()) {
+		status := checkBuildCache(ctx, opts, evt)
+		buildOpts.NoCache = !status.Hit
+		cacheStatus = &status
+	}
+
 	var version appTypes.AppVersion
 	version, err = b.Build(ctx, opts.App, evt, buildOpts)
 	if err != nil {
 		return nil, err
 	}
 
+	if cacheStatus != nil && !cacheStatus.Hit {
+		err = setBuildCacheCreatedAt(ctx, opts.App, time.Now().UTC())
+		if err != nil {
+			log.Errorf("unable to record build cache creation of app %q: %v", opts.App.Name, err)
+		}
+	}
+
+	err = scanImage(ctx, opts, version, evt)
+	if err != nil {
+		return nil, err
+	}
+
+	generateSBOM(ctx, opts.App, version, evt)
+
 	return version, nil
 }
 
@@ -7,18 +7,31 @@ package app
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
+	registrytest "github.com/tsuru/tsuru/registry/testing"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -913,6 +926,38 @@ func (s *S) TestDeployToProvisionerArchive(c *check.C) {
 	c.Assert(evt.Log(), check.Matches, ".*Builder deploy called")
 }
 
+func (s *S) TestDeployToProvisionerArchiveBuildCache(c *check.C) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	opts := DeployOptions{App: &a, ArchiveURL: "https://s3.amazonaws.com/smt/archive.tar.gz", NoCache: true}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = deployToProvisioner(context.TODO(), &opts, evt)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	dbEvt, err := event.GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	var otherData map[string]appTypes.BuildCacheStatus
+	err = dbEvt.OtherData(&otherData)
+	c.Assert(err, check.IsNil)
+	c.Assert(otherData["buildCache"], check.DeepEquals, appTypes.BuildCacheStatus{Reason: "no-cache requested"})
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.BuildCache.CreatedAt, check.NotNil)
+}
+
 func (s *S) TestDeployToProvisionerUpload(c *check.C) {
 	a := appTypes.App{
 		Name:      "some-app",
@@ -962,6 +1007,147 @@ func (s *S) TestDeployToProvisionerImage(c *check.C) {
 	c.Assert(evt.Log(), check.Matches, ".*Builder deploy called")
 }
 
+func (s *S) TestDeployToProvisionerImageSignatureEnforced(c *check.C) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: a.Pool, Field: pool.ConstraintTypeImageSignature, Values: []string{"team-key"}})
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: a.Pool, Field: pool.ConstraintTypeImageSignatureMode, Values: []string{"enforce"}})
+	c.Assert(err, check.IsNil)
+	opts := DeployOptions{App: &a, Image: "my-image-x"}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = deployToProvisioner(context.TODO(), &opts, evt)
+	c.Assert(err, check.ErrorMatches, `image "my-image-x" failed the signature verification required by pool "`+a.Pool+`": image signature key "team-key" is not configured`)
+	err = evt.Done(context.TODO(), err)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Log(), check.Not(check.Matches), "(?s).*Builder deploy called.*")
+	dbEvt, err := event.GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	var otherData map[string]imageSignatureStatus
+	err = dbEvt.OtherData(&otherData)
+	c.Assert(err, check.IsNil)
+	c.Assert(otherData["imageSignature"], check.DeepEquals, imageSignatureStatus{
+		Mode:  "enforce",
+		Error: `image signature key "team-key" is not configured`,
+	})
+}
+
+// writeImageSignatureKeys configures an ecdsa key pair to sign images and
+// to verify their signatures with the given key name.
+func writeImageSignatureKeys(c *check.C, name string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	privData, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, check.IsNil)
+	pubData, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	c.Assert(err, check.IsNil)
+	dir := c.MkDir()
+	privPath := filepath.Join(dir, name+".key")
+	pubPath := filepath.Join(dir, name+".pub")
+	err = os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privData}), 0600)
+	c.Assert(err, check.IsNil)
+	err = os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubData}), 0600)
+	c.Assert(err, check.IsNil)
+	config.Set("image-signature:signing-key", privPath)
+	config.Set("image-signature:keys:"+name, pubPath)
+}
+
+func (s *S) TestDeployToProvisionerImageSignatureVerified(c *check.C) {
+	server, err := registrytest.NewServer("127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer server.Stop()
+	writeImageSignatureKeys(c, "team-key")
+	defer config.Unset("image-signature")
+	manifest := []byte(`{"layers": []}`)
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	server.SetManifest("tsuru/my-image", digest, manifest)
+	server.AddRepo(registrytest.Repository{Name: "tsuru/my-image", Tags: map[string]string{"v1": digest}})
+	imageName := server.Addr() + "/tsuru/my-image:v1"
+	err = registry.SignImage(context.TODO(), imageName)
+	c.Assert(err, check.IsNil)
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+	}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: a.Pool, Field: pool.ConstraintTypeImageSignature, Values: []string{"team-key"}})
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: a.Pool, Field: pool.ConstraintTypeImageSignatureMode, Values: []string{"enforce"}})
+	c.Assert(err, check.IsNil)
+	var builtImage string
+	build := s.builder.OnBuild
+	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		builtImage = opts.ImageID
+		return build(app, evt, opts)
+	}
+	opts := DeployOptions{App: &a, Image: imageName}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = deployToProvisioner(context.TODO(), &opts, evt)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	pinned := server.Addr() + "/tsuru/my-image@" + digest
+	c.Assert(builtImage, check.Equals, pinned)
+	dbEvt, err := event.GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	var otherData map[string]imageSignatureStatus
+	err = dbEvt.OtherData(&otherData)
+	c.Assert(err, check.IsNil)
+	c.Assert(otherData["imageSignature"], check.DeepEquals, imageSignatureStatus{
+		Verified: true,
+		Key:      "team-key",
+		Image:    pinned,
+		Mode:     "enforce",
+	})
+}
+
+func (s *S) TestDeployToProvisionerImageSignatureAudit(c *check.C) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: a.Pool, Field: pool.ConstraintTypeImageSignature, Values: []string{"team-key"}})
+	c.Assert(err, check.IsNil)
+	opts := DeployOptions{App: &a, Image: "my-image-x"}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = deployToProvisioner(context.TODO(), &opts, evt)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Log(), check.Matches, `(?s).*WARNING: image "my-image-x" failed the signature verification.*Builder deploy called`)
+}
+
 func (s *S) TestRollbackWithNameImage(c *check.C) {
 	appsCollection, err := storagev2.AppsCollection()
 	c.Assert(err, check.IsNil)
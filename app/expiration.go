@@ -0,0 +1,52 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// SetExpiration sets the time when the app expires, resetting the expiration
+// warning. A nil expiresAt pins the app, which then never expires.
+func SetExpiration(ctx context.Context, app *appTypes.App, expiresAt *time.Time) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"expiresat": expiresAt, "expirationwarned": false},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return appTypes.ErrAppNotFound
+	}
+	app.ExpiresAt = expiresAt
+	app.ExpirationWarned = false
+	return nil
+}
+
+// MarkExpirationWarned records that the owners of the app were warned about
+// its expiration.
+func MarkExpirationWarned(ctx context.Context, app *appTypes.App) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"expirationwarned": true},
+	})
+	if err != nil {
+		return err
+	}
+	app.ExpirationWarned = true
+	return nil
+}
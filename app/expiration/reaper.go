@@ -0,0 +1,214 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package expiration deletes apps and jobs once their expiration time is
+// reached, warning their owners through events beforehand.
+package expiration
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/job"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	WarningEventKind = "expiration.warning"
+	DeleteEventKind  = "expiration.delete"
+
+	defaultInterval      = 5 * time.Minute
+	defaultWarningBefore = 24 * time.Hour
+)
+
+// Initialize starts the reaper, running every expiration:interval. Owners
+// are warned expiration:warning-before the expiration time.
+func Initialize() error {
+	interval, _ := config.GetDuration("expiration:interval")
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	r := &reaper{once: &sync.Once{}, interval: interval}
+	r.start()
+	shutdown.Register(r)
+	return nil
+}
+
+type reaper struct {
+	once     *sync.Once
+	stopCh   chan struct{}
+	interval time.Duration
+}
+
+func (r *reaper) start() {
+	r.once.Do(func() {
+		r.stopCh = make(chan struct{})
+		go r.spin()
+	})
+}
+
+func (r *reaper) Shutdown(ctx context.Context) error {
+	if r.stopCh == nil {
+		return nil
+	}
+	r.stopCh <- struct{}{}
+	r.stopCh = nil
+	r.once = &sync.Once{}
+	return nil
+}
+
+func (r *reaper) spin() {
+	for {
+		err := Run(context.Background())
+		if err != nil {
+			log.Errorf("[expiration reaper] %v", err)
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+func warningBefore() time.Duration {
+	d, _ := config.GetDuration("expiration:warning-before")
+	if d <= 0 {
+		return defaultWarningBefore
+	}
+	return d
+}
+
+// Run deletes the expired apps and jobs and warns the owners of the ones
+// expiring within the warning period. Apps and jobs locked by other
+// operations are left to the next run.
+func Run(ctx context.Context) error {
+	now := time.Now()
+	limit := now.Add(warningBefore())
+	multi := tsuruErrors.NewMultiError()
+	apps, err := app.List(ctx, &app.Filter{ExpiresBefore: &limit})
+	if err != nil {
+		return err
+	}
+	for _, a := range apps {
+		if a.ExpiresAt.After(now) {
+			if !a.ExpirationWarned {
+				err = warnApp(ctx, a)
+			}
+		} else {
+			err = deleteApp(ctx, a)
+		}
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to handle expiration of app %q", a.Name))
+		}
+	}
+	jobs, err := servicemanager.Job.List(ctx, &jobTypes.Filter{ExpiresBefore: &limit})
+	if err != nil {
+		multi.Add(err)
+		return multi.ToError()
+	}
+	for i := range jobs {
+		j := &jobs[i]
+		if j.ExpiresAt.After(now) {
+			if !j.ExpirationWarned {
+				err = warnJob(ctx, j)
+			}
+		} else {
+			err = deleteJob(ctx, j)
+		}
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to handle expiration of job %q", j.Name))
+		}
+	}
+	return multi.ToError()
+}
+
+func appEventOpts(a *appTypes.App, kind string) *event.Opts {
+	return &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: kind,
+		CustomData:   map[string]interface{}{"expiresAt": a.ExpiresAt},
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permTypes.CtxTeam, a.Teams),
+			permission.Context(permTypes.CtxApp, a.Name),
+			permission.Context(permTypes.CtxPool, a.Pool),
+		)...),
+	}
+}
+
+func jobEventOpts(j *jobTypes.Job, kind string) *event.Opts {
+	return &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: j.Name},
+		InternalKind: kind,
+		CustomData:   map[string]interface{}{"expiresAt": j.ExpiresAt},
+		Allowed: event.Allowed(permission.PermJobReadEvents,
+			permission.Context(permTypes.CtxTeam, j.TeamOwner),
+			permission.Context(permTypes.CtxJob, j.Name),
+			permission.Context(permTypes.CtxPool, j.Pool),
+		),
+	}
+}
+
+// newEvent returns a nil event, without error, when the target is locked.
+func newEvent(ctx context.Context, opts *event.Opts) (*event.Event, error) {
+	evt, err := event.NewInternal(ctx, opts)
+	if _, ok := err.(event.ErrEventLocked); ok {
+		return nil, nil
+	}
+	return evt, err
+}
+
+func warnApp(ctx context.Context, a *appTypes.App) (err error) {
+	evt, err := newEvent(ctx, appEventOpts(a, WarningEventKind))
+	if evt == nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	evt.Logf("app %q expires at %s and will be deleted", a.Name, a.ExpiresAt.Format(time.RFC3339))
+	return app.MarkExpirationWarned(ctx, a)
+}
+
+func deleteApp(ctx context.Context, a *appTypes.App) (err error) {
+	evt, err := newEvent(ctx, appEventOpts(a, DeleteEventKind))
+	if evt == nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.Delete(ctx, a, evt, "")
+}
+
+func warnJob(ctx context.Context, j *jobTypes.Job) (err error) {
+	evt, err := newEvent(ctx, jobEventOpts(j, WarningEventKind))
+	if evt == nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	evt.Logf("job %q expires at %s and will be deleted", j.Name, j.ExpiresAt.Format(time.RFC3339))
+	return job.MarkExpirationWarned(ctx, j)
+}
+
+func deleteJob(ctx context.Context, j *jobTypes.Job) (err error) {
+	evt, err := newEvent(ctx, jobEventOpts(j, DeleteEventKind))
+	if evt == nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Job.RemoveJobProv(ctx, j)
+	if err != nil {
+		return err
+	}
+	return servicemanager.Job.RemoveJob(ctx, j)
+}
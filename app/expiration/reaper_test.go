@@ -0,0 +1,129 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package expiration
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createApp(c *check.C, name string, expiresAt *time.Time) *appTypes.App {
+	a := &appTypes.App{Name: name, TeamOwner: "team1", Pool: "pool1", Quota: quota.UnlimitedQuota, ExpiresAt: expiresAt}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	return a
+}
+
+func timeFromNow(d time.Duration) *time.Time {
+	t := time.Now().Add(d)
+	return &t
+}
+
+func (s *S) TestRunDeletesExpiredApps(c *check.C) {
+	s.createApp(c, "expired", timeFromNow(-time.Minute))
+	s.createApp(c, "pinned", nil)
+	s.createApp(c, "later", timeFromNow(72*time.Hour))
+	err := Run(context.TODO())
+	c.Assert(err, check.IsNil)
+	_, err = app.GetByName(context.TODO(), "expired")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+	_, err = app.GetByName(context.TODO(), "pinned")
+	c.Assert(err, check.IsNil)
+	later, err := app.GetByName(context.TODO(), "later")
+	c.Assert(err, check.IsNil)
+	c.Assert(later.ExpirationWarned, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "expired"},
+		Kind:   DeleteEventKind,
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestRunWarnsBeforeExpiration(c *check.C) {
+	config.Set("expiration:warning-before", "2h")
+	defer config.Unset("expiration:warning-before")
+	s.createApp(c, "expiring", timeFromNow(time.Hour))
+	err := Run(context.TODO())
+	c.Assert(err, check.IsNil)
+	a, err := app.GetByName(context.TODO(), "expiring")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.ExpirationWarned, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "expiring"},
+		Kind:   WarningEventKind,
+	}, eventtest.HasEvent)
+	err = Run(context.TODO())
+	c.Assert(err, check.IsNil)
+	evts, err := event.All(context.TODO())
+	c.Assert(err, check.IsNil)
+	var warnings int
+	for _, evt := range evts {
+		if evt.Kind.Name == WarningEventKind {
+			warnings++
+		}
+	}
+	c.Assert(warnings, check.Equals, 1)
+}
+
+func (s *S) TestRunSkipsLockedApps(c *check.C) {
+	a := s.createApp(c, "expired", timeFromNow(-time.Minute))
+	evt, err := event.NewInternal(context.TODO(), &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: "deploy",
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(context.TODO(), nil)
+	err = Run(context.TODO())
+	c.Assert(err, check.IsNil)
+	_, err = app.GetByName(context.TODO(), "expired")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestRunDeletesExpiredJobs(c *check.C) {
+	var filter *jobTypes.Filter
+	s.mockService.JobService.OnList = func(f *jobTypes.Filter) ([]jobTypes.Job, error) {
+		filter = f
+		return []jobTypes.Job{{Name: "job1", TeamOwner: "team1", Pool: "pool1", ExpiresAt: timeFromNow(-time.Minute)}}, nil
+	}
+	var removedProv, removed []string
+	s.mockService.JobService.OnRemoveJobProv = func(j *jobTypes.Job) error {
+		removedProv = append(removedProv, j.Name)
+		return nil
+	}
+	s.mockService.JobService.OnRemoveJob = func(j *jobTypes.Job) error {
+		removed = append(removed, j.Name)
+		return nil
+	}
+	err := Run(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(filter.ExpiresBefore, check.NotNil)
+	c.Assert(removedProv, check.DeepEquals, []string{"job1"})
+	c.Assert(removed, check.DeepEquals, []string{"job1"})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "job1"},
+		Kind:   DeleteEventKind,
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestReaperStartShutdown(c *check.C) {
+	r := &reaper{once: &sync.Once{}, interval: time.Hour}
+	r.start()
+	c.Assert(r.stopCh, check.NotNil)
+	err := r.Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(r.stopCh, check.IsNil)
+}
@@ -0,0 +1,288 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/quota"
+	"golang.org/x/crypto/scrypt"
+)
+
+const envEncryptionAlgorithm = "scrypt-aes-256-gcm"
+
+// ExportApp returns a portable definition of the app, including the image of
+// its last successful version. The values of private env vars are encrypted
+// with the passphrase, or omitted when the passphrase is empty. Env vars
+// referencing secrets are exported with the reference only. Env vars set by
+// service bindings or managed by other tools are not exported, services are
+// exported by name to be bound again on import.
+func ExportApp(ctx context.Context, app *appTypes.App, passphrase string) (*appTypes.AppExport, error) {
+	exp := &appTypes.AppExport{
+		FormatVersion:   appTypes.AppExportFormatVersion,
+		ExportedAt:      time.Now().UTC(),
+		Name:            app.Name,
+		Platform:        app.Platform,
+		PlatformVersion: app.PlatformVersion,
+		TeamOwner:       app.TeamOwner,
+		Teams:           app.Teams,
+		Pool:            app.Pool,
+		Manifest: appTypes.Manifest{
+			Description: app.Description,
+			Plan:        app.Plan.Name,
+			Tags:        app.Tags,
+			Metadata:    &app.Metadata,
+			Processes:   app.Processes,
+			CNames:      app.CName,
+		},
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
+	if err != nil && err != appTypes.ErrNoVersionsAvailable {
+		return nil, err
+	}
+	if err == nil {
+		exp.Image = version.VersionInfo().DeployImage
+	}
+	var key []byte
+	if passphrase != "" {
+		exp.EnvEncryption = &appTypes.EnvEncryption{Algorithm: envEncryptionAlgorithm, Salt: make([]byte, 16)}
+		if _, err = rand.Read(exp.EnvEncryption.Salt); err != nil {
+			return nil, err
+		}
+		key, err = envEncryptionKey(passphrase, exp.EnvEncryption.Salt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var names []string
+	for name, env := range app.Env {
+		if env.ManagedBy == "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env := app.Env[name]
+		if env.Public {
+			exp.Manifest.Env = append(exp.Manifest.Env, appTypes.ManifestEnv{Name: name, Value: env.Value, Alias: env.Alias})
+			continue
+		}
+		if env.SecretRef != nil {
+			ref := *env.SecretRef
+			exp.Manifest.Env = append(exp.Manifest.Env, appTypes.ManifestEnv{Name: name, Private: true, SecretRef: &ref})
+			continue
+		}
+		if key == nil {
+			exp.OmittedEnvs = append(exp.OmittedEnvs, name)
+			continue
+		}
+		value, err := encryptEnvValue(key, env.Value)
+		if err != nil {
+			return nil, err
+		}
+		exp.Manifest.Env = append(exp.Manifest.Env, appTypes.ManifestEnv{Name: name, Value: value, Alias: env.Alias, Private: true})
+	}
+	for _, r := range GetRouters(app) {
+		exp.Manifest.Routers = append(exp.Manifest.Routers, appTypes.AppRouter{Name: r.Name, Opts: r.Opts})
+	}
+	autoscales, err := AutoScaleInfo(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, spec := range autoscales {
+		// versions are specific to the source installation
+		spec.Version = 0
+		exp.Manifest.Autoscale = append(exp.Manifest.Autoscale, spec)
+	}
+	instances, err := service.GetServiceInstancesBoundToApp(ctx, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, si := range instances {
+		exp.Manifest.Services = append(exp.Manifest.Services, appTypes.ManifestService{Service: si.ServiceName, Instance: si.Name})
+	}
+	binds, err := servicemanager.Volume.BindsForApp(ctx, nil, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range binds {
+		exp.Manifest.Volumes = append(exp.Manifest.Volumes, appTypes.ManifestVolume{Name: b.ID.Volume, MountPoint: b.ID.MountPoint, ReadOnly: b.ReadOnly})
+	}
+	return exp, nil
+}
+
+// AppFromExport returns the app to be created with CreateApp from an export,
+// along with the fields applied on creation: plan, description, tags,
+// metadata, processes and routers.
+func AppFromExport(exp *appTypes.AppExport) (*appTypes.App, error) {
+	if exp.FormatVersion != appTypes.AppExportFormatVersion {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("unsupported export format version %d", exp.FormatVersion)}
+	}
+	app := &appTypes.App{
+		Name:            exp.Name,
+		Platform:        exp.Platform,
+		PlatformVersion: exp.PlatformVersion,
+		TeamOwner:       exp.TeamOwner,
+		Teams:           []string{exp.TeamOwner},
+		Pool:            exp.Pool,
+		Plan:            appTypes.Plan{Name: exp.Manifest.Plan},
+		Description:     exp.Manifest.Description,
+		Tags:            exp.Manifest.Tags,
+		Processes:       exp.Manifest.Processes,
+		Routers:         exp.Manifest.Routers,
+		Quota:           quota.UnlimitedQuota,
+	}
+	if exp.Manifest.Metadata != nil {
+		app.Metadata = *exp.Manifest.Metadata
+	}
+	return app, nil
+}
+
+// ImportChanges returns the manifest changes that configure an app created
+// from an export with AppFromExport: env vars, cnames, service and volume
+// binds and autoscales. The values of private env vars are decrypted with
+// the passphrase, env vars referencing secrets keep their references.
+func ImportChanges(exp *appTypes.AppExport, passphrase string) ([]appTypes.ManifestChange, error) {
+	var key []byte
+	if exp.EnvEncryption != nil {
+		if exp.EnvEncryption.Algorithm != envEncryptionAlgorithm {
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("unsupported env encryption algorithm %q", exp.EnvEncryption.Algorithm)}
+		}
+		if passphrase == "" {
+			return nil, &tsuruErrors.ValidationError{Message: "a passphrase is required to decrypt the private env vars"}
+		}
+		var err error
+		key, err = envEncryptionKey(passphrase, exp.EnvEncryption.Salt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var envs []bindTypes.EnvVar
+	for _, e := range exp.Manifest.Env {
+		if e.SecretRef != nil {
+			ref := *e.SecretRef
+			envs = append(envs, bindTypes.EnvVar{Name: e.Name, SecretRef: &ref})
+			continue
+		}
+		value := e.Value
+		if e.Private {
+			if key == nil {
+				return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("private env var %q is not encrypted", e.Name)}
+			}
+			var err error
+			value, err = decryptEnvValue(key, e.Value)
+			if err != nil {
+				return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("unable to decrypt env var %q, check the passphrase", e.Name)}
+			}
+		}
+		envs = append(envs, bindTypes.EnvVar{Name: e.Name, Value: value, Alias: e.Alias, Public: !e.Private})
+	}
+	return manifestAddChanges(exp.Manifest, envs), nil
+}
+
+// manifestAddChanges returns the changes adding the env vars, cnames, volume
+// and service binds and autoscales of the manifest to a new app.
+func manifestAddChanges(m appTypes.Manifest, envs []bindTypes.EnvVar) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	for _, e := range envs {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    e.Name,
+			After:   maskedEnvValue(e.Value, e.Public),
+			Desired: e,
+		})
+	}
+	for _, cname := range m.CNames {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:  appTypes.ManifestFieldCName,
+			Action: appTypes.ManifestActionAdd,
+			Name:   cname,
+		})
+	}
+	for _, v := range m.Volumes {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldVolume,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    v.Name + ":" + v.MountPoint,
+			After:   v,
+			Desired: v,
+		})
+	}
+	for _, s := range m.Services {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldService,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    s.Service + "/" + s.Instance,
+			Desired: s,
+		})
+	}
+	for _, spec := range m.Autoscale {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldAutoscale,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    spec.Process,
+			After:   spec,
+			Desired: spec,
+		})
+	}
+	return changes
+}
+
+func envEncryptionKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+func encryptEnvValue(key []byte, value string) (string, error) {
+	gcm, err := envCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+func decryptEnvValue(key []byte, value string) (string, error) {
+	gcm, err := envCipher(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("encrypted value too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func envCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
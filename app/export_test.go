@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createExportedApp(c *check.C) *appTypes.App {
+	a := &appTypes.App{
+		Name:        "myapp",
+		TeamOwner:   s.team.Name,
+		Description: "my app",
+		Tags:        []string{"tag1"},
+		Quota:       quota.UnlimitedQuota,
+	}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	err = SetEnvs(context.TODO(), a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "PUBLIC", Value: "public value", Public: true},
+			{Name: "SECRET", Value: "secret value"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = AddCName(context.TODO(), a, "myapp.io")
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, a)
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	return a
+}
+
+func (s *S) TestExportApp(c *check.C) {
+	a := s.createExportedApp(c)
+	exp, err := ExportApp(context.TODO(), a, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(exp.FormatVersion, check.Equals, appTypes.AppExportFormatVersion)
+	c.Assert(exp.Name, check.Equals, "myapp")
+	c.Assert(exp.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(exp.Pool, check.Equals, s.Pool)
+	c.Assert(exp.Image, check.Not(check.Equals), "")
+	c.Assert(exp.EnvEncryption, check.IsNil)
+	c.Assert(exp.OmittedEnvs, check.DeepEquals, []string{"SECRET"})
+	c.Assert(exp.Manifest.Description, check.Equals, "my app")
+	c.Assert(exp.Manifest.Plan, check.Equals, s.defaultPlan.Name)
+	c.Assert(exp.Manifest.Tags, check.DeepEquals, []string{"tag1"})
+	c.Assert(exp.Manifest.CNames, check.DeepEquals, []string{"myapp.io"})
+	c.Assert(exp.Manifest.Env, check.DeepEquals, []appTypes.ManifestEnv{
+		{Name: "PUBLIC", Value: "public value"},
+	})
+	c.Assert(exp.Manifest.Routers, check.HasLen, 1)
+	c.Assert(exp.Manifest.Routers[0].Name, check.Equals, "fake")
+}
+
+func (s *S) TestExportAppEncryptedEnvs(c *check.C) {
+	a := s.createExportedApp(c)
+	exp, err := ExportApp(context.TODO(), a, "my passphrase")
+	c.Assert(err, check.IsNil)
+	c.Assert(exp.OmittedEnvs, check.IsNil)
+	c.Assert(exp.EnvEncryption, check.NotNil)
+	c.Assert(exp.Manifest.Env, check.HasLen, 2)
+	c.Assert(exp.Manifest.Env[1].Name, check.Equals, "SECRET")
+	c.Assert(exp.Manifest.Env[1].Private, check.Equals, true)
+	c.Assert(exp.Manifest.Env[1].Value, check.Not(check.Equals), "secret value")
+	changes, err := ImportChanges(exp, "my passphrase")
+	c.Assert(err, check.IsNil)
+	c.Assert(changes[:3], check.DeepEquals, []appTypes.ManifestChange{
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "PUBLIC",
+			After:   "public value",
+			Desired: bindTypes.EnvVar{Name: "PUBLIC", Value: "public value", Public: true},
+		},
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "SECRET",
+			After:   maskedManifestValue,
+			Desired: bindTypes.EnvVar{Name: "SECRET", Value: "secret value"},
+		},
+		{Field: appTypes.ManifestFieldCName, Action: appTypes.ManifestActionAdd, Name: "myapp.io"},
+	})
+	_, err = ImportChanges(exp, "wrong passphrase")
+	c.Assert(err, check.DeepEquals, &errors.ValidationError{Message: `unable to decrypt env var "SECRET", check the passphrase`})
+	_, err = ImportChanges(exp, "")
+	c.Assert(err, check.DeepEquals, &errors.ValidationError{Message: "a passphrase is required to decrypt the private env vars"})
+}
+
+func (s *S) TestExportAppSecretRefsAndAliases(c *check.C) {
+	a := s.createExportedApp(c)
+	ref := bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "password"}
+	a.Env["DB_PASSWORD"] = bindTypes.EnvVar{Name: "DB_PASSWORD", SecretRef: &ref}
+	a.Env["PUBLIC_ALIAS"] = bindTypes.EnvVar{Name: "PUBLIC_ALIAS", Alias: "PUBLIC", Public: true}
+	exp, err := ExportApp(context.TODO(), a, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(exp.OmittedEnvs, check.DeepEquals, []string{"SECRET"})
+	c.Assert(exp.Manifest.Env, check.DeepEquals, []appTypes.ManifestEnv{
+		{Name: "DB_PASSWORD", Private: true, SecretRef: &ref},
+		{Name: "PUBLIC", Value: "public value"},
+		{Name: "PUBLIC_ALIAS", Alias: "PUBLIC"},
+	})
+	changes, err := ImportChanges(exp, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(changes[:3], check.DeepEquals, []appTypes.ManifestChange{
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "DB_PASSWORD",
+			After:   maskedManifestValue,
+			Desired: bindTypes.EnvVar{Name: "DB_PASSWORD", SecretRef: &ref},
+		},
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "PUBLIC",
+			After:   "public value",
+			Desired: bindTypes.EnvVar{Name: "PUBLIC", Value: "public value", Public: true},
+		},
+		{
+			Field:   appTypes.ManifestFieldEnv,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    "PUBLIC_ALIAS",
+			Desired: bindTypes.EnvVar{Name: "PUBLIC_ALIAS", Alias: "PUBLIC", Public: true},
+		},
+	})
+}
+
+func (s *S) TestAppFromExport(c *check.C) {
+	exp := &appTypes.AppExport{
+		FormatVersion: appTypes.AppExportFormatVersion,
+		Name:          "myapp",
+		TeamOwner:     s.team.Name,
+		Pool:          s.Pool,
+		Manifest: appTypes.Manifest{
+			Description: "my app",
+			Plan:        "large",
+			Metadata:    &appTypes.Metadata{Labels: []appTypes.MetadataItem{{Name: "a", Value: "b"}}},
+			Routers:     []appTypes.AppRouter{{Name: "fake"}},
+		},
+	}
+	a, err := AppFromExport(exp)
+	c.Assert(err, check.IsNil)
+	c.Assert(a, check.DeepEquals, &appTypes.App{
+		Name:        "myapp",
+		TeamOwner:   s.team.Name,
+		Teams:       []string{s.team.Name},
+		Pool:        s.Pool,
+		Plan:        appTypes.Plan{Name: "large"},
+		Description: "my app",
+		Metadata:    appTypes.Metadata{Labels: []appTypes.MetadataItem{{Name: "a", Value: "b"}}},
+		Routers:     []appTypes.AppRouter{{Name: "fake"}},
+		Quota:       quota.UnlimitedQuota,
+	})
+	exp.FormatVersion = 2
+	_, err = AppFromExport(exp)
+	c.Assert(err, check.DeepEquals, &errors.ValidationError{Message: "unsupported export format version 2"})
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// GetBuildCacheStatus returns whether the next platform build of app reuses
+// the image of its latest successful version, following the same rules as
+// GetBuildImage plus the build cache settings of the app. noCache forces a
+// cache miss and newVersion tells whether the build is deployed alongside
+// the current versions.
+func GetBuildCacheStatus(ctx context.Context, app *appTypes.App, noCache, newVersion bool) appTypes.BuildCacheStatus {
+	settings := app.BuildCache
+	switch {
+	case noCache:
+		return appTypes.BuildCacheStatus{Reason: "no-cache requested"}
+	case settings.Disabled:
+		return appTypes.BuildCacheStatus{Reason: "build cache disabled"}
+	case newVersion && settings.Isolated:
+		return appTypes.BuildCacheStatus{Reason: "build cache isolated from new versions"}
+	case settings.MaxAge > 0 && (settings.CreatedAt == nil || time.Since(*settings.CreatedAt) > settings.MaxAge):
+		return appTypes.BuildCacheStatus{Reason: "build cache older than " + settings.MaxAge.String()}
+	case app.UpdatePlatform:
+		return appTypes.BuildCacheStatus{Reason: "build cache purged or platform updated"}
+	case app.Deploys > 0 && usePlatformImage(app):
+		return appTypes.BuildCacheStatus{Reason: "maximum number of image layers reached"}
+	case app.Deploys == 0:
+		return appTypes.BuildCacheStatus{Reason: "no previous version"}
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
+	if err != nil {
+		return appTypes.BuildCacheStatus{Reason: "no previous version"}
+	}
+	return appTypes.BuildCacheStatus{Hit: true, Version: version.Version()}
+}
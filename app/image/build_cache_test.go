@@ -0,0 +1,98 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestGetBuildCacheStatus(c *check.C) {
+	version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+		App: &appTypes.App{Name: "myapp"},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.CommitBaseImage()
+	c.Assert(err, check.IsNil)
+	err = version.CommitSuccessful()
+	c.Assert(err, check.IsNil)
+	recent := time.Now().Add(-time.Hour)
+	old := time.Now().Add(-48 * time.Hour)
+	tests := []struct {
+		name       string
+		app        appTypes.App
+		noCache    bool
+		newVersion bool
+		expected   appTypes.BuildCacheStatus
+	}{
+		{
+			name:     "hit",
+			app:      appTypes.App{Name: "myapp", Deploys: 1},
+			expected: appTypes.BuildCacheStatus{Hit: true, Version: version.Version()},
+		},
+		{
+			name:     "no previous deploy",
+			app:      appTypes.App{Name: "myapp"},
+			expected: appTypes.BuildCacheStatus{Reason: "no previous version"},
+		},
+		{
+			name:     "no previous version",
+			app:      appTypes.App{Name: "otherapp", Deploys: 1},
+			expected: appTypes.BuildCacheStatus{Reason: "no previous version"},
+		},
+		{
+			name:     "no-cache",
+			app:      appTypes.App{Name: "myapp", Deploys: 1},
+			noCache:  true,
+			expected: appTypes.BuildCacheStatus{Reason: "no-cache requested"},
+		},
+		{
+			name:     "disabled",
+			app:      appTypes.App{Name: "myapp", Deploys: 1, BuildCache: appTypes.BuildCache{Disabled: true}},
+			expected: appTypes.BuildCacheStatus{Reason: "build cache disabled"},
+		},
+		{
+			name:       "isolated new version",
+			app:        appTypes.App{Name: "myapp", Deploys: 1, BuildCache: appTypes.BuildCache{Isolated: true}},
+			newVersion: true,
+			expected:   appTypes.BuildCacheStatus{Reason: "build cache isolated from new versions"},
+		},
+		{
+			name:     "isolated same version",
+			app:      appTypes.App{Name: "myapp", Deploys: 1, BuildCache: appTypes.BuildCache{Isolated: true}},
+			expected: appTypes.BuildCacheStatus{Hit: true, Version: version.Version()},
+		},
+		{
+			name:     "within max age",
+			app:      appTypes.App{Name: "myapp", Deploys: 1, BuildCache: appTypes.BuildCache{MaxAge: 24 * time.Hour, CreatedAt: &recent}},
+			expected: appTypes.BuildCacheStatus{Hit: true, Version: version.Version()},
+		},
+		{
+			name:     "older than max age",
+			app:      appTypes.App{Name: "myapp", Deploys: 1, BuildCache: appTypes.BuildCache{MaxAge: 24 * time.Hour, CreatedAt: &old}},
+			expected: appTypes.BuildCacheStatus{Reason: "build cache older than 24h0m0s"},
+		},
+		{
+			name:     "purged",
+			app:      appTypes.App{Name: "myapp", Deploys: 1, UpdatePlatform: true},
+			expected: appTypes.BuildCacheStatus{Reason: "build cache purged or platform updated"},
+		},
+		{
+			name:     "max layers",
+			app:      appTypes.App{Name: "myapp", Deploys: 10},
+			expected: appTypes.BuildCacheStatus{Reason: "maximum number of image layers reached"},
+		},
+	}
+	for _, tt := range tests {
+		c.Logf("test %v", tt.name)
+		status := image.GetBuildCacheStatus(context.TODO(), &tt.app, tt.noCache, tt.newVersion)
+		c.Check(status, check.DeepEquals, tt.expected)
+	}
+}
@@ -148,11 +148,11 @@ func basicImageName(reg imgTypes.ImageRegistry, repoName string) (string, error)
 // in all other cases the app image name will be returned.
 func GetBuildImage(ctx context.Context, app *appTypes.App) (string, error) {
 	if usePlatformImage(app) {
-		return getPlatformImage(ctx, app)
+		return GetPlatformImage(ctx, app)
 	}
 	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
 	if err != nil {
-		return getPlatformImage(ctx, app)
+		return GetPlatformImage(ctx, app)
 	}
 	return version.VersionInfo().DeployImage, nil
 }
@@ -166,7 +166,8 @@ func usePlatformImage(app *appTypes.App) bool {
 	return deploys%maxLayers == 0 || app.UpdatePlatform
 }
 
-func getPlatformImage(ctx context.Context, app *appTypes.App) (string, error) {
+// GetPlatformImage returns the image of the platform version used by app.
+func GetPlatformImage(ctx context.Context, app *appTypes.App) (string, error) {
 	reg, err := servicemanager.App.GetRegistry(ctx, app)
 	if err != nil {
 		return "", err
@@ -0,0 +1,120 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
+)
+
+const httpScannerName = "http"
+
+var (
+	scannersMu sync.RWMutex
+	scanners   = map[string]imgTypes.ImageScanner{
+		httpScannerName: &httpScanner{},
+	}
+)
+
+// RegisterScanner makes an image scanner available to be set in
+// image-scan:scanner.
+func RegisterScanner(name string, scanner imgTypes.ImageScanner) {
+	scannersMu.Lock()
+	defer scannersMu.Unlock()
+	scanners[name] = scanner
+}
+
+// GetScanner returns the scanner set in image-scan:scanner, which defaults to
+// the http scanner when image-scan:http:url is set. It returns nil when image
+// scanning is not configured.
+func GetScanner() (imgTypes.ImageScanner, error) {
+	name, _ := config.GetString("image-scan:scanner")
+	if name == "" {
+		if url, _ := config.GetString("image-scan:http:url"); url == "" {
+			return nil, nil
+		}
+		name = httpScannerName
+	}
+	scannersMu.RLock()
+	defer scannersMu.RUnlock()
+	scanner, ok := scanners[name]
+	if !ok {
+		return nil, errors.Errorf("unknown image scanner %q", name)
+	}
+	return scanner, nil
+}
+
+// httpScanner sends the image name to the endpoint in image-scan:http:url,
+// which must reply with the vulnerabilities found in the image, as in:
+//
+//	{"vulnerabilities": {"critical": 1, "high": 3}}
+type httpScanner struct{}
+
+func (s *httpScanner) Scan(ctx context.Context, imageName string) (*imgTypes.ScanResult, error) {
+	url, err := config.GetString("image-scan:http:url")
+	if err != nil {
+		return nil, err
+	}
+	timeout, _ := config.GetDuration("image-scan:http:timeout")
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]string{"image": imageName})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token, _ := config.GetString("image-scan:http:token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rsp, err := tsuruNet.Dial15FullUnlimitedClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to reach the image scanner")
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return nil, errors.Errorf("invalid status code from the image scanner %d: %s", rsp.StatusCode, string(data))
+	}
+	var result imgTypes.ScanResult
+	err = json.NewDecoder(rsp.Body).Decode(&result)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid response from the image scanner")
+	}
+	if result.Scanner == "" {
+		result.Scanner = httpScannerName
+	}
+	return &result, nil
+}
+
+// FormatScanResult lists the vulnerabilities found by severity, as in
+// "critical=1 high=3 medium=0 low=0 unknown=0".
+func FormatScanResult(result *imgTypes.ScanResult) string {
+	var buf bytes.Buffer
+	for i, severity := range imgTypes.Severities {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(&buf, "%s=%d", severity, result.Vulnerabilities[severity])
+	}
+	return buf.String()
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/image"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
+	check "gopkg.in/check.v1"
+)
+
+type fakeScanner struct{}
+
+func (fakeScanner) Scan(ctx context.Context, imageName string) (*imgTypes.ScanResult, error) {
+	return &imgTypes.ScanResult{Scanner: "fake"}, nil
+}
+
+func (s *S) TestGetScannerNotConfigured(c *check.C) {
+	scanner, err := image.GetScanner()
+	c.Assert(err, check.IsNil)
+	c.Assert(scanner, check.IsNil)
+}
+
+func (s *S) TestGetScannerRegistered(c *check.C) {
+	image.RegisterScanner("fake", fakeScanner{})
+	config.Set("image-scan:scanner", "fake")
+	defer config.Unset("image-scan")
+	scanner, err := image.GetScanner()
+	c.Assert(err, check.IsNil)
+	c.Assert(scanner, check.Equals, fakeScanner{})
+	config.Set("image-scan:scanner", "unknown")
+	_, err = image.GetScanner()
+	c.Assert(err, check.ErrorMatches, `unknown image scanner "unknown"`)
+}
+
+func (s *S) TestHTTPScanner(c *check.C) {
+	var received map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, http.MethodPost)
+		c.Check(r.Header.Get("Authorization"), check.Equals, "Bearer mytoken")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"scanner": "trivy", "vulnerabilities": {"critical": 1, "high": 3}}`))
+	}))
+	defer srv.Close()
+	config.Set("image-scan:http:url", srv.URL)
+	config.Set("image-scan:http:token", "mytoken")
+	defer config.Unset("image-scan")
+	scanner, err := image.GetScanner()
+	c.Assert(err, check.IsNil)
+	result, err := scanner.Scan(context.TODO(), "tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(received, check.DeepEquals, map[string]string{"image": "tsuru/app-myapp:v1"})
+	c.Assert(result, check.DeepEquals, &imgTypes.ScanResult{
+		Scanner:         "trivy",
+		Vulnerabilities: map[string]int{"critical": 1, "high": 3},
+	})
+	c.Assert(image.FormatScanResult(result), check.Equals, "critical=1 high=3 medium=0 low=0 unknown=0")
+}
+
+func (s *S) TestHTTPScannerError(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "scanner unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	config.Set("image-scan:http:url", srv.URL)
+	defer config.Unset("image-scan")
+	scanner, err := image.GetScanner()
+	c.Assert(err, check.IsNil)
+	_, err = scanner.Scan(context.TODO(), "tsuru/app-myapp:v1")
+	c.Assert(err, check.ErrorMatches, "invalid status code from the image scanner 503: scanner unavailable\n")
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/streamfmt"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
+)
+
+// imageScanCustomDataKey is the key of the scan summary in the custom data of
+// the app version.
+const imageScanCustomDataKey = "imageScan"
+
+// scanImage scans the image built for version with the configured scanner,
+// recording the summary in the version. The deploy fails when the
+// vulnerabilities found exceed the threshold of the pool of the app.
+func scanImage(ctx context.Context, opts *DeployOptions, version appTypes.AppVersion, evt *event.Event) error {
+	scanner, err := image.GetScanner()
+	if err != nil || scanner == nil {
+		return err
+	}
+	p, err := pool.GetPoolByName(ctx, opts.App.Pool)
+	if err != nil {
+		return err
+	}
+	threshold, err := p.GetImageScanThreshold()
+	if err != nil {
+		return err
+	}
+	if opts.SkipScan {
+		streamfmt.FprintlnActionf(evt, "Skipping image scan as requested by %s", opts.User)
+		return version.SetCustomData(imageScanCustomDataKey, map[string]interface{}{
+			"skipped":   true,
+			"scannedAt": time.Now().UTC(),
+		})
+	}
+	imageName, err := version.BaseImageName()
+	if err != nil {
+		return err
+	}
+	streamfmt.FprintlnActionf(evt, "Scanning image %q for vulnerabilities", imageName)
+	result, err := scanner.Scan(ctx, imageName)
+	if err != nil {
+		if len(threshold) > 0 {
+			return errors.Wrapf(err, "unable to scan image %q, required by pool %q", imageName, p.Name)
+		}
+		streamfmt.FprintlnActionf(evt, "WARNING: unable to scan image %q: %v", imageName, err)
+		return nil
+	}
+	streamfmt.FprintlnActionf(evt, "Vulnerabilities found: %s", image.FormatScanResult(result))
+	exceeded := exceededSeverities(result, threshold)
+	vulnerabilities := map[string]interface{}{}
+	for severity, count := range result.Vulnerabilities {
+		vulnerabilities[severity] = count
+	}
+	err = version.SetCustomData(imageScanCustomDataKey, map[string]interface{}{
+		"scanner":         result.Scanner,
+		"vulnerabilities": vulnerabilities,
+		"blocked":         len(exceeded) > 0,
+		"scannedAt":       time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	reason := fmt.Sprintf("image exceeds the vulnerability threshold of pool %q: %s", p.Name, strings.Join(exceeded, ", "))
+	// blocked versions must not be deployed through a rollback either
+	err = version.ToggleEnabled(false, reason)
+	if err != nil {
+		return err
+	}
+	return errors.Errorf("image %q not deployed, %s", imageName, reason)
+}
+
+// exceededSeverities describes the severities with more vulnerabilities than
+// allowed by threshold.
+func exceededSeverities(result *imgTypes.ScanResult, threshold map[string]int) []string {
+	var exceeded []string
+	for _, severity := range imgTypes.Severities {
+		limit, ok := threshold[severity]
+		if !ok {
+			continue
+		}
+		if count := result.Vulnerabilities[severity]; count > limit {
+			exceeded = append(exceeded, fmt.Sprintf("%d %s (max %d)", count, severity, limit))
+		}
+	}
+	return exceeded
+}
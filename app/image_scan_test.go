@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+type fakeScanner struct {
+	scanned []string
+	result  imgTypes.ScanResult
+}
+
+func (f *fakeScanner) Scan(ctx context.Context, imageName string) (*imgTypes.ScanResult, error) {
+	f.scanned = append(f.scanned, imageName)
+	result := f.result
+	return &result, nil
+}
+
+func (s *S) setupImageScan(c *check.C, threshold string) *fakeScanner {
+	scanner := &fakeScanner{result: imgTypes.ScanResult{
+		Scanner:         "fake",
+		Vulnerabilities: map[string]int{"critical": 1, "high": 2},
+	}}
+	image.RegisterScanner("fake", scanner)
+	config.Set("image-scan:scanner", "fake")
+	err := pool.PoolUpdate(context.TODO(), s.Pool, pool.UpdatePoolOptions{
+		Labels: map[string]string{"image-scan-threshold": threshold},
+	})
+	c.Assert(err, check.IsNil)
+	return scanner
+}
+
+func (s *S) newScanDeploy(c *check.C) (*DeployOptions, *event.Event) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	return &DeployOptions{App: &a, Image: "my-image-x", User: s.user.Email}, evt
+}
+
+func (s *S) TestDeployToProvisionerImageScan(c *check.C) {
+	defer config.Unset("image-scan")
+	scanner := s.setupImageScan(c, "critical=1")
+	opts, evt := s.newScanDeploy(c)
+	_, err := deployToProvisioner(context.TODO(), opts, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(scanner.scanned, check.HasLen, 1)
+	c.Assert(evt.Log(), check.Matches, `(?s).*Vulnerabilities found: critical=1 high=2 medium=0 low=0 unknown=0.*Builder deploy called`)
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), opts.App)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions.Versions, check.HasLen, 1)
+	for _, vi := range versions.Versions {
+		c.Assert(vi.Disabled, check.Equals, false)
+		c.Assert(vi.CustomData["imageScan"], check.NotNil)
+	}
+}
+
+func (s *S) TestDeployToProvisionerImageScanBlocked(c *check.C) {
+	defer config.Unset("image-scan")
+	s.setupImageScan(c, "critical=0,high=5")
+	opts, evt := s.newScanDeploy(c)
+	_, err := deployToProvisioner(context.TODO(), opts, evt)
+	c.Assert(err, check.ErrorMatches, `image ".*" not deployed, image exceeds the vulnerability threshold of pool "pool1": 1 critical \(max 0\)`)
+	c.Assert(evt.Log(), check.Not(check.Matches), "(?s).*Builder deploy called.*")
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), opts.App)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions.Versions, check.HasLen, 1)
+	for _, vi := range versions.Versions {
+		c.Assert(vi.Disabled, check.Equals, true)
+		c.Assert(vi.CustomData["imageScan"], check.NotNil)
+	}
+}
+
+func (s *S) TestDeployToProvisionerImageScanSkipped(c *check.C) {
+	defer config.Unset("image-scan")
+	scanner := s.setupImageScan(c, "critical=0")
+	opts, evt := s.newScanDeploy(c)
+	opts.SkipScan = true
+	_, err := deployToProvisioner(context.TODO(), opts, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(scanner.scanned, check.HasLen, 0)
+	c.Assert(evt.Log(), check.Matches, `(?s).*Skipping image scan as requested by .*Builder deploy called`)
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
+	"github.com/tsuru/tsuru/streamfmt"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// imageSignatureStatus is recorded in the deploy event of images verified
+// against the keys trusted by the pool of the app.
+type imageSignatureStatus struct {
+	Verified bool   `json:"verified"`
+	Key      string `json:"key,omitempty"`
+	Image    string `json:"image,omitempty"`
+	Mode     string `json:"mode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// checkImageSignature verifies the signature of the image deployed to app
+// when its pool trusts image signature keys, returning the image to deploy,
+// which is pinned to the verified digest. In the audit mode images failing
+// the verification are still deployed.
+func checkImageSignature(ctx context.Context, app *appTypes.App, imageName string, evt *event.Event) (string, error) {
+	p, err := pool.GetPoolByName(ctx, app.Pool)
+	if err != nil {
+		return "", err
+	}
+	keyNames, err := p.GetImageSignatureKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(keyNames) == 0 {
+		return imageName, nil
+	}
+	mode, err := p.GetImageSignatureMode(ctx)
+	if err != nil {
+		return "", err
+	}
+	status := imageSignatureStatus{Mode: mode}
+	var verified *registry.VerifiedImage
+	keys, err := registry.LoadVerificationKeys(keyNames)
+	if err == nil {
+		verified, err = registry.VerifyImageSignature(ctx, imageName, keys)
+	}
+	if err == nil {
+		status.Verified = true
+		status.Key = verified.Key
+		status.Image = verified.Name
+		streamfmt.FprintlnActionf(evt, "Image %q signed by trusted key %q, deploying %q", imageName, verified.Key, verified.Name)
+	} else {
+		status.Error = err.Error()
+	}
+	if dataErr := evt.SetOtherCustomData(ctx, map[string]interface{}{"imageSignature": status}); dataErr != nil {
+		log.Errorf("unable to record image signature status of app %q: %v", app.Name, dataErr)
+	}
+	if err == nil {
+		return verified.Name, nil
+	}
+	if status.Mode == pool.ImageSignatureModeEnforce {
+		return "", errors.Wrapf(err, "image %q failed the signature verification required by pool %q", imageName, app.Pool)
+	}
+	streamfmt.FprintlnActionf(evt, "WARNING: image %q failed the signature verification required by pool %q, deploying anyway in audit mode: %v", imageName, app.Pool, err)
+	return imageName, nil
+}
@@ -0,0 +1,615 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+const maskedManifestValue = "*****"
+
+// DiffManifest compares the desired state described by m with the current
+// state of the app and returns the changes required to converge them, in the
+// order they should be applied.
+func DiffManifest(ctx context.Context, app *appTypes.App, m *appTypes.Manifest) ([]appTypes.ManifestChange, error) {
+	var changes []appTypes.ManifestChange
+	if m.Description != "" && m.Description != app.Description {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldDescription,
+			Action:  appTypes.ManifestActionUpdate,
+			Before:  app.Description,
+			After:   m.Description,
+			Desired: m.Description,
+		})
+	}
+	if m.Plan != "" && m.Plan != app.Plan.Name {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldPlan,
+			Action:  appTypes.ManifestActionUpdate,
+			Before:  app.Plan.Name,
+			After:   m.Plan,
+			Desired: m.Plan,
+		})
+	}
+	if m.Tags != nil {
+		tags := processTags(m.Tags)
+		if !sameStringSet(app.Tags, tags) {
+			changes = append(changes, appTypes.ManifestChange{
+				Field:   appTypes.ManifestFieldTags,
+				Action:  appTypes.ManifestActionUpdate,
+				Before:  app.Tags,
+				After:   tags,
+				Desired: tags,
+			})
+		}
+	}
+	if m.Metadata != nil {
+		if update := metadataChanges(app.Metadata, *m.Metadata); !update.Empty() {
+			changes = append(changes, appTypes.ManifestChange{
+				Field:   appTypes.ManifestFieldMetadata,
+				Action:  appTypes.ManifestActionUpdate,
+				Before:  app.Metadata,
+				After:   *m.Metadata,
+				Desired: update,
+			})
+		}
+	}
+	if m.Processes != nil {
+		changes = append(changes, diffManifestProcesses(app.Processes, m.Processes)...)
+	}
+	if m.Env != nil {
+		changes = append(changes, diffManifestEnvs(app.Env, m.Env)...)
+	}
+	if m.CNames != nil {
+		changes = append(changes, diffManifestCNames(app.CName, m.CNames)...)
+	}
+	if m.Routers != nil {
+		changes = append(changes, diffManifestRouters(GetRouters(app), m.Routers)...)
+	}
+	if m.Autoscale != nil {
+		current, err := AutoScaleInfo(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffManifestAutoscale(current, m.Autoscale)...)
+	}
+	if m.Services != nil {
+		instances, err := service.GetServiceInstancesBoundToApp(ctx, app.Name)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffManifestServices(instances, m.Services)...)
+	}
+	if m.Volumes != nil {
+		binds, err := servicemanager.Volume.BindsForApp(ctx, nil, app.Name)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffManifestVolumes(binds, m.Volumes)...)
+	}
+	return changes, nil
+}
+
+// ManifestChangeRequiresRestart reports whether the app must be restarted for
+// the change to reach its units.
+func ManifestChangeRequiresRestart(change appTypes.ManifestChange) bool {
+	switch change.Field {
+	case appTypes.ManifestFieldPlan,
+		appTypes.ManifestFieldMetadata,
+		appTypes.ManifestFieldProcess,
+		appTypes.ManifestFieldEnv,
+		appTypes.ManifestFieldService,
+		appTypes.ManifestFieldVolume:
+		return true
+	}
+	return false
+}
+
+// RestartForManifest restarts the units of the app, if there are any, once
+// the changes that require a restart were applied.
+func RestartForManifest(ctx context.Context, app *appTypes.App, w io.Writer) error {
+	return restartIfUnits(ctx, app, w)
+}
+
+// ApplyManifestChange applies a single change returned by DiffManifest to the
+// app. The app is never restarted here, callers are expected to restart it
+// once after all changes are applied.
+func ApplyManifestChange(ctx context.Context, app *appTypes.App, change appTypes.ManifestChange, evt *event.Event) error {
+	switch change.Field {
+	case appTypes.ManifestFieldDescription:
+		return Update(ctx, app, UpdateAppArgs{UpdateData: &appTypes.App{Description: change.Desired.(string)}, Writer: evt})
+	case appTypes.ManifestFieldPlan:
+		return Update(ctx, app, UpdateAppArgs{UpdateData: &appTypes.App{Plan: appTypes.Plan{Name: change.Desired.(string)}}, Writer: evt})
+	case appTypes.ManifestFieldTags:
+		return Update(ctx, app, UpdateAppArgs{UpdateData: &appTypes.App{Tags: change.Desired.([]string)}, Writer: evt})
+	case appTypes.ManifestFieldMetadata:
+		return Update(ctx, app, UpdateAppArgs{UpdateData: &appTypes.App{Metadata: change.Desired.(appTypes.Metadata)}, Writer: evt})
+	case appTypes.ManifestFieldProcess:
+		process := change.Desired.(appTypes.Process)
+		return Update(ctx, app, UpdateAppArgs{UpdateData: &appTypes.App{Processes: []appTypes.Process{process}}, Writer: evt})
+	case appTypes.ManifestFieldEnv:
+		if change.Action == appTypes.ManifestActionRemove {
+			return UnsetEnvs(ctx, app, bindTypes.UnsetEnvArgs{VariableNames: []string{change.Name}, Writer: evt})
+		}
+		return SetEnvs(ctx, app, bindTypes.SetEnvArgs{Envs: []bindTypes.EnvVar{change.Desired.(bindTypes.EnvVar)}, Writer: evt})
+	case appTypes.ManifestFieldCName:
+		if change.Action == appTypes.ManifestActionRemove {
+			return RemoveCName(ctx, app, change.Name)
+		}
+		return AddCName(ctx, app, change.Name)
+	case appTypes.ManifestFieldRouter:
+		return applyManifestRouter(ctx, app, change)
+	case appTypes.ManifestFieldAutoscale:
+		return applyManifestAutoscale(ctx, app, change)
+	case appTypes.ManifestFieldService:
+		return applyManifestService(ctx, app, change, evt)
+	case appTypes.ManifestFieldVolume:
+		return applyManifestVolume(ctx, app, change)
+	}
+	return errors.Errorf("unknown manifest field %q", change.Field)
+}
+
+func applyManifestRouter(ctx context.Context, app *appTypes.App, change appTypes.ManifestChange) error {
+	if change.Action == appTypes.ManifestActionRemove {
+		return RemoveRouter(ctx, app, change.Name)
+	}
+	appRouter := change.Desired.(appTypes.AppRouter)
+	p, err := pool.GetPoolByName(ctx, app.Pool)
+	if err != nil {
+		return err
+	}
+	err = p.ValidateRouters(ctx, []appTypes.AppRouter{appRouter})
+	if err != nil {
+		return err
+	}
+	if change.Action == appTypes.ManifestActionAdd {
+		return AddRouter(ctx, app, appRouter)
+	}
+	return UpdateRouter(ctx, app, appRouter)
+}
+
+func applyManifestAutoscale(ctx context.Context, app *appTypes.App, change appTypes.ManifestChange) error {
+	if change.Action == appTypes.ManifestActionRemove {
+		return RemoveAutoScale(ctx, app, change.Desired.(provTypes.AutoScaleSpec).Process)
+	}
+	spec := change.Desired.(provTypes.AutoScaleSpec)
+	q, err := GetQuota(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = provision.ValidateAutoScaleSpec(&spec, q.Limit, app)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid autoscale for process %q: %v", spec.Process, err)}
+	}
+	return AutoScale(ctx, app, spec)
+}
+
+func applyManifestService(ctx context.Context, app *appTypes.App, change appTypes.ManifestChange, evt *event.Event) error {
+	s := change.Desired.(appTypes.ManifestService)
+	si, err := service.GetServiceInstance(ctx, s.Service, s.Instance)
+	if err != nil {
+		return err
+	}
+	if change.Action == appTypes.ManifestActionRemove {
+		return si.UnbindApp(ctx, service.UnbindAppArgs{App: app, Event: evt})
+	}
+	err = ValidateService(ctx, app, s.Service)
+	if err != nil {
+		return err
+	}
+	return si.BindApp(ctx, app, nil, false, evt, evt, "")
+}
+
+func applyManifestVolume(ctx context.Context, app *appTypes.App, change appTypes.ManifestChange) error {
+	v := change.Desired.(appTypes.ManifestVolume)
+	vol, err := servicemanager.Volume.Get(ctx, v.Name)
+	if err != nil {
+		return err
+	}
+	opts := &volumeTypes.BindOpts{
+		Volume:     vol,
+		AppName:    app.Name,
+		MountPoint: v.MountPoint,
+		ReadOnly:   v.ReadOnly,
+	}
+	if change.Action == appTypes.ManifestActionAdd {
+		return servicemanager.Volume.BindApp(ctx, opts)
+	}
+	err = servicemanager.Volume.UnbindApp(ctx, opts)
+	if err != nil || change.Action == appTypes.ManifestActionRemove {
+		return err
+	}
+	// binds are identified by their mount point, so changing the read only
+	// flag requires unbinding first, the previous bind is restored when the
+	// new one fails to keep the volume mounted.
+	err = servicemanager.Volume.BindApp(ctx, opts)
+	if err == nil {
+		return nil
+	}
+	if old, ok := change.Before.(appTypes.ManifestVolume); ok {
+		previous := *opts
+		previous.ReadOnly = old.ReadOnly
+		if rollbackErr := servicemanager.Volume.BindApp(ctx, &previous); rollbackErr != nil {
+			log.Errorf("unable to restore the bind of volume %q to app %q: %v", v.Name, app.Name, rollbackErr)
+		}
+	}
+	return err
+}
+
+func diffManifestProcesses(current, desired []appTypes.Process) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	currentByName := map[string]appTypes.Process{}
+	for _, p := range current {
+		currentByName[p.Name] = p
+	}
+	desiredNames := map[string]struct{}{}
+	for _, p := range desired {
+		desiredNames[p.Name] = struct{}{}
+		old, exists := currentByName[p.Name]
+		update := appTypes.Process{Name: p.Name, Plan: p.Plan}
+		if p.Plan == old.Plan {
+			update.Plan = ""
+		} else if p.Plan == "" {
+			update.Plan = "$default"
+		}
+		update.Metadata = metadataChanges(old.Metadata, p.Metadata)
+		if update.Plan == "" && update.Metadata.Empty() {
+			continue
+		}
+		change := appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldProcess,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    p.Name,
+			After:   p,
+			Desired: update,
+		}
+		if exists {
+			change.Action = appTypes.ManifestActionUpdate
+			change.Before = old
+		}
+		changes = append(changes, change)
+	}
+	for _, p := range current {
+		if _, ok := desiredNames[p.Name]; ok {
+			continue
+		}
+		changes = append(changes, appTypes.ManifestChange{
+			Field:  appTypes.ManifestFieldProcess,
+			Action: appTypes.ManifestActionRemove,
+			Name:   p.Name,
+			Before: p,
+			Desired: appTypes.Process{
+				Name:     p.Name,
+				Plan:     "$default",
+				Metadata: metadataChanges(p.Metadata, appTypes.Metadata{}),
+			},
+		})
+	}
+	return changes
+}
+
+func diffManifestEnvs(current map[string]bindTypes.EnvVar, desired []appTypes.ManifestEnv) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	desiredNames := map[string]struct{}{}
+	for _, e := range desired {
+		desiredNames[e.Name] = struct{}{}
+		old, exists := current[e.Name]
+		if exists && old.Value == e.Value && old.Alias == e.Alias && old.Public == !e.Private && sameSecretRef(old.SecretRef, e.SecretRef) {
+			continue
+		}
+		change := appTypes.ManifestChange{
+			Field:  appTypes.ManifestFieldEnv,
+			Action: appTypes.ManifestActionAdd,
+			Name:   e.Name,
+			After:  maskedEnvValue(e.Value, !e.Private),
+			Desired: bindTypes.EnvVar{
+				Name:      e.Name,
+				Value:     e.Value,
+				Alias:     e.Alias,
+				Public:    !e.Private,
+				SecretRef: e.SecretRef,
+			},
+		}
+		if exists {
+			change.Action = appTypes.ManifestActionUpdate
+			change.Before = maskedEnvValue(old.Value, old.Public)
+		}
+		changes = append(changes, change)
+	}
+	var removed []string
+	for name, e := range current {
+		if _, ok := desiredNames[name]; ok || e.ManagedBy != "" {
+			continue
+		}
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		changes = append(changes, appTypes.ManifestChange{
+			Field:  appTypes.ManifestFieldEnv,
+			Action: appTypes.ManifestActionRemove,
+			Name:   name,
+			Before: maskedEnvValue(current[name].Value, current[name].Public),
+		})
+	}
+	return changes
+}
+
+func sameSecretRef(a, b *bindTypes.SecretRef) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func maskedEnvValue(value string, public bool) string {
+	if public {
+		return value
+	}
+	return maskedManifestValue
+}
+
+func diffManifestCNames(current, desired []string) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	for _, cname := range desired {
+		if !cnameInSet(cname, current) {
+			changes = append(changes, appTypes.ManifestChange{
+				Field:  appTypes.ManifestFieldCName,
+				Action: appTypes.ManifestActionAdd,
+				Name:   cname,
+			})
+		}
+	}
+	for _, cname := range current {
+		if !cnameInSet(cname, desired) {
+			changes = append(changes, appTypes.ManifestChange{
+				Field:  appTypes.ManifestFieldCName,
+				Action: appTypes.ManifestActionRemove,
+				Name:   cname,
+			})
+		}
+	}
+	return changes
+}
+
+func diffManifestRouters(current, desired []appTypes.AppRouter) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	currentByName := map[string]appTypes.AppRouter{}
+	for _, r := range current {
+		currentByName[r.Name] = r
+	}
+	desiredNames := map[string]struct{}{}
+	for _, r := range desired {
+		desiredNames[r.Name] = struct{}{}
+		wanted := appTypes.AppRouter{Name: r.Name, Opts: r.Opts}
+		old, exists := currentByName[r.Name]
+		if exists && sameStringMap(old.Opts, r.Opts) {
+			continue
+		}
+		change := appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldRouter,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    r.Name,
+			After:   wanted,
+			Desired: wanted,
+		}
+		if exists {
+			change.Action = appTypes.ManifestActionUpdate
+			change.Before = appTypes.AppRouter{Name: old.Name, Opts: old.Opts}
+		}
+		changes = append(changes, change)
+	}
+	for _, r := range current {
+		if _, ok := desiredNames[r.Name]; ok {
+			continue
+		}
+		changes = append(changes, appTypes.ManifestChange{
+			Field:  appTypes.ManifestFieldRouter,
+			Action: appTypes.ManifestActionRemove,
+			Name:   r.Name,
+			Before: appTypes.AppRouter{Name: r.Name, Opts: r.Opts},
+		})
+	}
+	return changes
+}
+
+func diffManifestAutoscale(current, desired []provTypes.AutoScaleSpec) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	currentByProcess := map[string]provTypes.AutoScaleSpec{}
+	for _, spec := range current {
+		currentByProcess[spec.Process] = spec
+	}
+	desiredProcesses := map[string]struct{}{}
+	for _, spec := range desired {
+		desiredProcesses[spec.Process] = struct{}{}
+		old, exists := currentByProcess[spec.Process]
+		if exists {
+			compared := old
+			if spec.Version == 0 {
+				compared.Version = 0
+			}
+			if reflect.DeepEqual(compared, spec) {
+				continue
+			}
+		}
+		change := appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldAutoscale,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    spec.Process,
+			After:   spec,
+			Desired: spec,
+		}
+		if exists {
+			change.Action = appTypes.ManifestActionUpdate
+			change.Before = old
+		}
+		changes = append(changes, change)
+	}
+	for _, spec := range current {
+		if _, ok := desiredProcesses[spec.Process]; ok {
+			continue
+		}
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldAutoscale,
+			Action:  appTypes.ManifestActionRemove,
+			Name:    spec.Process,
+			Before:  spec,
+			Desired: spec,
+		})
+	}
+	return changes
+}
+
+func diffManifestServices(current []service.ServiceInstance, desired []appTypes.ManifestService) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	key := func(s appTypes.ManifestService) string {
+		return s.Service + "/" + s.Instance
+	}
+	currentKeys := map[string]struct{}{}
+	for _, si := range current {
+		currentKeys[key(appTypes.ManifestService{Service: si.ServiceName, Instance: si.Name})] = struct{}{}
+	}
+	desiredKeys := map[string]struct{}{}
+	for _, s := range desired {
+		desiredKeys[key(s)] = struct{}{}
+		if _, ok := currentKeys[key(s)]; ok {
+			continue
+		}
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldService,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    key(s),
+			Desired: s,
+		})
+	}
+	for _, si := range current {
+		s := appTypes.ManifestService{Service: si.ServiceName, Instance: si.Name}
+		if _, ok := desiredKeys[key(s)]; ok {
+			continue
+		}
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldService,
+			Action:  appTypes.ManifestActionRemove,
+			Name:    key(s),
+			Desired: s,
+		})
+	}
+	return changes
+}
+
+func diffManifestVolumes(current []volumeTypes.VolumeBind, desired []appTypes.ManifestVolume) []appTypes.ManifestChange {
+	var changes []appTypes.ManifestChange
+	key := func(v appTypes.ManifestVolume) string {
+		return v.Name + ":" + v.MountPoint
+	}
+	currentByKey := map[string]appTypes.ManifestVolume{}
+	for _, b := range current {
+		v := appTypes.ManifestVolume{Name: b.ID.Volume, MountPoint: b.ID.MountPoint, ReadOnly: b.ReadOnly}
+		currentByKey[key(v)] = v
+	}
+	desiredKeys := map[string]struct{}{}
+	for _, v := range desired {
+		desiredKeys[key(v)] = struct{}{}
+		old, exists := currentByKey[key(v)]
+		if exists && old.ReadOnly == v.ReadOnly {
+			continue
+		}
+		change := appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldVolume,
+			Action:  appTypes.ManifestActionAdd,
+			Name:    key(v),
+			After:   v,
+			Desired: v,
+		}
+		if exists {
+			change.Action = appTypes.ManifestActionUpdate
+			change.Before = old
+		}
+		changes = append(changes, change)
+	}
+	for _, b := range current {
+		v := appTypes.ManifestVolume{Name: b.ID.Volume, MountPoint: b.ID.MountPoint, ReadOnly: b.ReadOnly}
+		if _, ok := desiredKeys[key(v)]; ok {
+			continue
+		}
+		changes = append(changes, appTypes.ManifestChange{
+			Field:   appTypes.ManifestFieldVolume,
+			Action:  appTypes.ManifestActionRemove,
+			Name:    key(v),
+			Before:  v,
+			Desired: v,
+		})
+	}
+	return changes
+}
+
+// metadataChanges returns the metadata update that turns current into
+// desired, items missing from desired are marked for deletion.
+func metadataChanges(current, desired appTypes.Metadata) appTypes.Metadata {
+	return appTypes.Metadata{
+		Labels:      metadataItemChanges(current.Labels, desired.Labels),
+		Annotations: metadataItemChanges(current.Annotations, desired.Annotations),
+	}
+}
+
+func metadataItemChanges(current, desired []appTypes.MetadataItem) []appTypes.MetadataItem {
+	var items []appTypes.MetadataItem
+	for _, item := range desired {
+		value, ok := getItemValue(current, item.Name)
+		if !ok || value != item.Value {
+			items = append(items, appTypes.MetadataItem{Name: item.Name, Value: item.Value})
+		}
+	}
+	for _, item := range current {
+		if _, ok := getItemValue(desired, item.Name); !ok {
+			items = append(items, appTypes.MetadataItem{Name: item.Name, Delete: true})
+		}
+	}
+	return items
+}
+
+func getItemValue(items []appTypes.MetadataItem, name string) (string, bool) {
+	for _, item := range items {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+func sameStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return reflect.DeepEqual(sortedA, sortedB)
+}
+
+func sameStringMap(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDiffManifestUnmanagedFields(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Description: "my app", Tags: []string{"a"}, CName: []string{"myapp.io"}}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	changes, err := DiffManifest(context.TODO(), a, &appTypes.Manifest{})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+}
+
+func (s *S) TestDiffManifest(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Description: "my app", Tags: []string{"a", "b"}}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	err = AddCName(context.TODO(), a, "old.myapp.io")
+	c.Assert(err, check.IsNil)
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	changes, err := DiffManifest(context.TODO(), a, &appTypes.Manifest{
+		Description: "new description",
+		Tags:        []string{"b", "a"},
+		CNames:      []string{"new.myapp.io"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []appTypes.ManifestChange{
+		{Field: appTypes.ManifestFieldDescription, Action: appTypes.ManifestActionUpdate, Before: "my app", After: "new description", Desired: "new description"},
+		{Field: appTypes.ManifestFieldCName, Action: appTypes.ManifestActionAdd, Name: "new.myapp.io"},
+		{Field: appTypes.ManifestFieldCName, Action: appTypes.ManifestActionRemove, Name: "old.myapp.io"},
+	})
+}
+
+func (s *S) TestApplyManifestChange(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Description: "my app"}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:     permission.PermAppUpdate,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(context.TODO(), nil)
+	changes, err := DiffManifest(context.TODO(), a, &appTypes.Manifest{
+		Description: "new description",
+		Tags:        []string{"tag1"},
+		CNames:      []string{"myapp.io"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 3)
+	for _, change := range changes {
+		a, err = GetByName(context.TODO(), a.Name)
+		c.Assert(err, check.IsNil)
+		err = ApplyManifestChange(context.TODO(), a, change, evt)
+		c.Assert(err, check.IsNil)
+		c.Assert(ManifestChangeRequiresRestart(change), check.Equals, false)
+	}
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Description, check.Equals, "new description")
+	c.Assert(a.Tags, check.DeepEquals, []string{"tag1"})
+	c.Assert(a.CName, check.DeepEquals, []string{"myapp.io"})
+	changes, err = DiffManifest(context.TODO(), a, &appTypes.Manifest{
+		Description: "new description",
+		Tags:        []string{"tag1"},
+		CNames:      []string{"myapp.io"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+}
+
+func (s *S) TestApplyManifestVolumeUpdateRestoresBindOnFailure(c *check.C) {
+	var binds []volumeTypes.BindOpts
+	volumeService := servicemanager.Volume
+	defer func() { servicemanager.Volume = volumeService }()
+	servicemanager.Volume = &volumeTypes.MockVolumeService{
+		OnGet: func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+			return &volumeTypes.Volume{Name: name}, nil
+		},
+		OnUnbindApp: func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+			return nil
+		},
+		OnBindApp: func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+			binds = append(binds, *opts)
+			if opts.ReadOnly {
+				return errors.New("bind failed")
+			}
+			return nil
+		},
+	}
+	a := &appTypes.App{Name: "myapp"}
+	err := applyManifestVolume(context.TODO(), a, appTypes.ManifestChange{
+		Field:   appTypes.ManifestFieldVolume,
+		Action:  appTypes.ManifestActionUpdate,
+		Before:  appTypes.ManifestVolume{Name: "v1", MountPoint: "/data"},
+		Desired: appTypes.ManifestVolume{Name: "v1", MountPoint: "/data", ReadOnly: true},
+	})
+	c.Assert(err, check.ErrorMatches, "bind failed")
+	c.Assert(binds, check.HasLen, 2)
+	c.Assert(binds[0].ReadOnly, check.Equals, true)
+	c.Assert(binds[1].ReadOnly, check.Equals, false)
+	c.Assert(binds[1].MountPoint, check.Equals, "/data")
+}
+
+func (s *S) TestDiffManifestEnvs(c *check.C) {
+	current := map[string]bindTypes.EnvVar{
+		"KEEP":     {Name: "KEEP", Value: "1", Public: true},
+		"CHANGED":  {Name: "CHANGED", Value: "old", Public: true},
+		"SECRET":   {Name: "SECRET", Value: "s3cr3t"},
+		"REMOVED":  {Name: "REMOVED", Value: "x", Public: true},
+		"INTERNAL": {Name: "INTERNAL", Value: "y", ManagedBy: "tsuru"},
+	}
+	changes := diffManifestEnvs(current, []appTypes.ManifestEnv{
+		{Name: "KEEP", Value: "1"},
+		{Name: "CHANGED", Value: "new"},
+		{Name: "SECRET", Value: "other", Private: true},
+		{Name: "NEW", Value: "hidden", Private: true},
+	})
+	c.Assert(changes, check.DeepEquals, []appTypes.ManifestChange{
+		{
+			Field: appTypes.ManifestFieldEnv, Action: appTypes.ManifestActionUpdate, Name: "CHANGED",
+			Before: "old", After: "new",
+			Desired: bindTypes.EnvVar{Name: "CHANGED", Value: "new", Public: true},
+		},
+		{
+			Field: appTypes.ManifestFieldEnv, Action: appTypes.ManifestActionUpdate, Name: "SECRET",
+			Before: "*****", After: "*****",
+			Desired: bindTypes.EnvVar{Name: "SECRET", Value: "other"},
+		},
+		{
+			Field: appTypes.ManifestFieldEnv, Action: appTypes.ManifestActionAdd, Name: "NEW",
+			After:   "*****",
+			Desired: bindTypes.EnvVar{Name: "NEW", Value: "hidden"},
+		},
+		{Field: appTypes.ManifestFieldEnv, Action: appTypes.ManifestActionRemove, Name: "REMOVED", Before: "x"},
+	})
+}
+
+func (s *S) TestDiffManifestProcesses(c *check.C) {
+	current := []appTypes.Process{
+		{Name: "web", Plan: "small", Metadata: appTypes.Metadata{Labels: []appTypes.MetadataItem{{Name: "a", Value: "1"}}}},
+		{Name: "worker", Plan: "large"},
+	}
+	changes := diffManifestProcesses(current, []appTypes.Process{
+		{Name: "web", Plan: "small", Metadata: appTypes.Metadata{Labels: []appTypes.MetadataItem{{Name: "b", Value: "2"}}}},
+		{Name: "cron", Plan: "small"},
+	})
+	c.Assert(changes, check.HasLen, 3)
+	c.Assert(changes[0].Action, check.Equals, appTypes.ManifestActionUpdate)
+	c.Assert(changes[0].Desired, check.DeepEquals, appTypes.Process{
+		Name: "web",
+		Metadata: appTypes.Metadata{Labels: []appTypes.MetadataItem{
+			{Name: "b", Value: "2"},
+			{Name: "a", Delete: true},
+		}},
+	})
+	c.Assert(changes[1].Action, check.Equals, appTypes.ManifestActionAdd)
+	c.Assert(changes[1].Desired, check.DeepEquals, appTypes.Process{Name: "cron", Plan: "small"})
+	c.Assert(changes[2].Action, check.Equals, appTypes.ManifestActionRemove)
+	c.Assert(changes[2].Desired, check.DeepEquals, appTypes.Process{Name: "worker", Plan: "$default"})
+}
+
+func (s *S) TestDiffManifestRouters(c *check.C) {
+	current := []appTypes.AppRouter{
+		{Name: "r1", Address: "r1.addr"},
+		{Name: "r2", Opts: map[string]string{"a": "1"}},
+		{Name: "r3"},
+	}
+	changes := diffManifestRouters(current, []appTypes.AppRouter{
+		{Name: "r1", Opts: map[string]string{}},
+		{Name: "r2", Opts: map[string]string{"a": "2"}},
+		{Name: "r4"},
+	})
+	c.Assert(changes, check.DeepEquals, []appTypes.ManifestChange{
+		{
+			Field: appTypes.ManifestFieldRouter, Action: appTypes.ManifestActionUpdate, Name: "r2",
+			Before:  appTypes.AppRouter{Name: "r2", Opts: map[string]string{"a": "1"}},
+			After:   appTypes.AppRouter{Name: "r2", Opts: map[string]string{"a": "2"}},
+			Desired: appTypes.AppRouter{Name: "r2", Opts: map[string]string{"a": "2"}},
+		},
+		{
+			Field: appTypes.ManifestFieldRouter, Action: appTypes.ManifestActionAdd, Name: "r4",
+			After:   appTypes.AppRouter{Name: "r4"},
+			Desired: appTypes.AppRouter{Name: "r4"},
+		},
+		{
+			Field: appTypes.ManifestFieldRouter, Action: appTypes.ManifestActionRemove, Name: "r3",
+			Before: appTypes.AppRouter{Name: "r3"},
+		},
+	})
+}
+
+func (s *S) TestDiffManifestVolumes(c *check.C) {
+	current := []volumeTypes.VolumeBind{
+		{ID: volumeTypes.VolumeBindID{App: "myapp", Volume: "v1", MountPoint: "/data"}},
+		{ID: volumeTypes.VolumeBindID{App: "myapp", Volume: "v2", MountPoint: "/cache"}},
+	}
+	changes := diffManifestVolumes(current, []appTypes.ManifestVolume{
+		{Name: "v1", MountPoint: "/data", ReadOnly: true},
+		{Name: "v2", MountPoint: "/other"},
+	})
+	c.Assert(changes, check.HasLen, 3)
+	c.Assert(changes[0].Action, check.Equals, appTypes.ManifestActionUpdate)
+	c.Assert(changes[0].Name, check.Equals, "v1:/data")
+	c.Assert(changes[1].Action, check.Equals, appTypes.ManifestActionAdd)
+	c.Assert(changes[1].Name, check.Equals, "v2:/other")
+	c.Assert(changes[2].Action, check.Equals, appTypes.ManifestActionRemove)
+	c.Assert(changes[2].Name, check.Equals, "v2:/cache")
+}
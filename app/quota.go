@@ -77,6 +77,24 @@ func (s *appQuotaService) SetLimit(ctx context.Context, app *appTypes.App, limit
 // smaller than or equal to the current limit. It also must be a non negative
 // number.
 func (s *appQuotaService) Set(ctx context.Context, app *appTypes.App, inUse int) error {
+	err := s.checkInUse(ctx, app, inUse)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Set(ctx, app.Name, inUse)
+}
+
+// CompareAndSet redefines the inuse value for the named resource, like Set,
+// only if the recorded inuse value is still old.
+func (s *appQuotaService) CompareAndSet(ctx context.Context, app *appTypes.App, old, inUse int) error {
+	err := s.checkInUse(ctx, app, inUse)
+	if err != nil {
+		return err
+	}
+	return s.Storage.CompareAndSet(ctx, app.Name, old, inUse)
+}
+
+func (s *appQuotaService) checkInUse(ctx context.Context, app *appTypes.App, inUse int) error {
 	q, err := s.Storage.Get(ctx, app.Name)
 	if err != nil {
 		return err
@@ -90,7 +108,7 @@ func (s *appQuotaService) Set(ctx context.Context, app *appTypes.App, inUse int)
 			Available: uint(q.Limit),
 		}
 	}
-	return s.Storage.Set(ctx, app.Name, inUse)
+	return nil
 }
 
 func (s *appQuotaService) Get(ctx context.Context, app *appTypes.App) (*quotaTypes.Quota, error) {
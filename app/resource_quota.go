@@ -0,0 +1,352 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ResourceQuotaPool = "pool"
+	ResourceQuotaTeam = "team"
+)
+
+// resourceLimits holds the limits of the resource quota of a pool or team,
+// identified by <scope>/<name>. Negative limits are unlimited.
+type resourceLimits struct {
+	ID       string `bson:"_id"`
+	CPUMilli int
+	Memory   int
+}
+
+func (l resourceLimits) unlimited() bool {
+	return l.CPUMilli < 0 && l.Memory < 0
+}
+
+type resources struct {
+	cpuMilli int
+	memory   int
+}
+
+// GetResourceQuota returns the resource quota of a pool or team, along with
+// the resources currently reserved by its apps.
+func GetResourceQuota(ctx context.Context, scope, name string) (*quotaTypes.ResourceQuota, error) {
+	limits, err := getResourceLimits(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	inUse, err := resourcesInUse(ctx, scope, name, unitsCache{})
+	if err != nil {
+		return nil, err
+	}
+	return &quotaTypes.ResourceQuota{
+		CPUMilli: quotaTypes.Quota{Limit: limits.CPUMilli, InUse: inUse.cpuMilli},
+		Memory:   quotaTypes.Quota{Limit: limits.Memory, InUse: inUse.memory},
+	}, nil
+}
+
+// SetResourceQuotaLimit changes the limits of the resource quota of a pool
+// or team. Nil limits are kept and negative limits remove the limit.
+func SetResourceQuotaLimit(ctx context.Context, scope, name string, cpuMilli, memory *int) error {
+	q, err := GetResourceQuota(ctx, scope, name)
+	if err != nil {
+		return err
+	}
+	limits := resourceLimits{
+		ID:       resourceQuotaID(scope, name),
+		CPUMilli: q.CPUMilli.Limit,
+		Memory:   q.Memory.Limit,
+	}
+	for _, l := range []struct {
+		value *int
+		limit *int
+		inUse int
+	}{
+		{cpuMilli, &limits.CPUMilli, q.CPUMilli.InUse},
+		{memory, &limits.Memory, q.Memory.InUse},
+	} {
+		if l.value == nil {
+			continue
+		}
+		if *l.value < 0 {
+			*l.limit = -1
+			continue
+		}
+		if *l.value < l.inUse {
+			return quotaTypes.ErrLimitLowerThanAllocated
+		}
+		*l.limit = *l.value
+	}
+	collection, err := storagev2.ResourceQuotasCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": limits.ID}, limits, options.Replace().SetUpsert(true))
+	return err
+}
+
+func resourceQuotaID(scope, name string) string {
+	return scope + "/" + name
+}
+
+func getResourceLimits(ctx context.Context, scope, name string) (resourceLimits, error) {
+	limits := resourceLimits{ID: resourceQuotaID(scope, name), CPUMilli: -1, Memory: -1}
+	if scope != ResourceQuotaPool && scope != ResourceQuotaTeam {
+		return limits, fmt.Errorf("invalid resource quota scope %q", scope)
+	}
+	collection, err := storagev2.ResourceQuotasCollection()
+	if err != nil {
+		return limits, err
+	}
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": limits.ID}).Decode(&limits)
+	if err == mongo.ErrNoDocuments {
+		return limits, nil
+	}
+	return limits, err
+}
+
+func resourcesInUse(ctx context.Context, scope, name string, cache unitsCache) (resources, error) {
+	filter := &Filter{Pool: name}
+	if scope == ResourceQuotaTeam {
+		filter = &Filter{TeamOwner: name}
+	}
+	apps, err := List(ctx, filter)
+	if err != nil {
+		return resources{}, err
+	}
+	err = cache.load(ctx, apps)
+	if err != nil {
+		return resources{}, err
+	}
+	var total resources
+	for _, a := range apps {
+		r, err := reservedResources(ctx, a, cache[a.Name])
+		if err != nil {
+			return resources{}, err
+		}
+		total.cpuMilli += r.cpuMilli
+		total.memory += r.memory
+	}
+	return total, nil
+}
+
+// unitsCache holds the units reserved by each process of the apps seen in a
+// single quota check, so apps in both the pool and the team being checked
+// are only looked up once.
+type unitsCache map[string]map[string]int
+
+// reservedUnits returns the number of units each process of the app reserves
+// resources for, the max units of its autoscale or its current units.
+func (c unitsCache) reservedUnits(ctx context.Context, a *appTypes.App) (map[string]int, error) {
+	err := c.load(ctx, []*appTypes.App{a})
+	if err != nil {
+		return nil, err
+	}
+	return c[a.Name], nil
+}
+
+// load fills the cache with the units reserved by the apps that aren't
+// cached yet, listing their units in a single call to each provisioner.
+func (c unitsCache) load(ctx context.Context, apps []*appTypes.App) error {
+	var missing []*appTypes.App
+	for _, a := range apps {
+		if _, ok := c[a.Name]; !ok {
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	appUnits, err := Units(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for _, a := range missing {
+		rsp := appUnits[a.Name]
+		if rsp.Err != nil {
+			return rsp.Err
+		}
+		reserved := map[string]int{}
+		for _, u := range rsp.Units {
+			reserved[u.ProcessName]++
+		}
+		autoscales, err := AutoScaleInfo(ctx, a)
+		if err != nil {
+			return err
+		}
+		autoscaled := map[string]int{}
+		for _, as := range autoscales {
+			autoscaled[as.Process] += int(as.MaxUnits)
+		}
+		for process, maxUnits := range autoscaled {
+			reserved[process] = maxUnits
+		}
+		c[a.Name] = reserved
+	}
+	return nil
+}
+
+func reservedResources(ctx context.Context, a *appTypes.App, units map[string]int) (resources, error) {
+	var r resources
+	for process, n := range units {
+		plan, err := processPlan(ctx, a, process)
+		if err != nil {
+			return r, err
+		}
+		r.cpuMilli += n * plan.GetMilliCPU()
+		r.memory += n * int(plan.GetMemory())
+	}
+	return r, nil
+}
+
+func processPlan(ctx context.Context, a *appTypes.App, process string) (appTypes.Plan, error) {
+	for _, p := range a.Processes {
+		if p.Name == process && p.Plan != "" {
+			plan, err := servicemanager.Plan.FindByName(ctx, p.Plan)
+			if err != nil {
+				return appTypes.Plan{}, err
+			}
+			return *plan, nil
+		}
+	}
+	return a.Plan, nil
+}
+
+// ensureResourceQuota checks the resource quotas of the pool and team of the
+// app for a change in the units reserved by its processes.
+func ensureResourceQuota(ctx context.Context, a *appTypes.App, change func(units map[string]int)) error {
+	limited, err := hasResourceLimits(ctx, a)
+	if err != nil || !limited {
+		return err
+	}
+	cache := unitsCache{}
+	units, err := cache.reservedUnits(ctx, a)
+	if err != nil {
+		return err
+	}
+	newUnits := make(map[string]int, len(units))
+	for process, n := range units {
+		newUnits[process] = n
+	}
+	change(newUnits)
+	return checkResourceQuota(ctx, cache, a, a, units, newUnits)
+}
+
+// ensureDeployResourceQuota checks the resource quotas for a deploy of the
+// version, which starts one unit for each process without units.
+func ensureDeployResourceQuota(ctx context.Context, a *appTypes.App, version appTypes.AppVersion) error {
+	processes, err := version.Processes()
+	if err != nil {
+		return err
+	}
+	return ensureResourceQuota(ctx, a, func(units map[string]int) {
+		for process := range processes {
+			if units[process] == 0 {
+				units[process] = 1
+			}
+		}
+	})
+}
+
+// ensureUpdateResourceQuota checks the resource quotas for changes in the
+// plan, process plans, pool or team owner of the app.
+func ensureUpdateResourceQuota(ctx context.Context, oldApp, newApp *appTypes.App) error {
+	limited, err := hasResourceLimits(ctx, newApp)
+	if err != nil || !limited {
+		return err
+	}
+	cache := unitsCache{}
+	units, err := cache.reservedUnits(ctx, oldApp)
+	if err != nil {
+		return err
+	}
+	return checkResourceQuota(ctx, cache, oldApp, newApp, units, units)
+}
+
+func hasResourceLimits(ctx context.Context, a *appTypes.App) (bool, error) {
+	for _, scope := range []struct{ scope, name string }{
+		{ResourceQuotaPool, a.Pool},
+		{ResourceQuotaTeam, a.TeamOwner},
+	} {
+		limits, err := getResourceLimits(ctx, scope.scope, scope.name)
+		if err != nil {
+			return false, err
+		}
+		if !limits.unlimited() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkResourceQuota checks that the resources reserved by newApp with
+// newUnits fit in the quotas of its pool and team owner, discounting the
+// resources already reserved by oldApp with oldUnits in the same pool or team.
+func checkResourceQuota(ctx context.Context, cache unitsCache, oldApp, newApp *appTypes.App, oldUnits, newUnits map[string]int) error {
+	before, err := reservedResources(ctx, oldApp, oldUnits)
+	if err != nil {
+		return err
+	}
+	after, err := reservedResources(ctx, newApp, newUnits)
+	if err != nil {
+		return err
+	}
+	for _, scope := range []struct{ scope, name, oldName string }{
+		{ResourceQuotaPool, newApp.Pool, oldApp.Pool},
+		{ResourceQuotaTeam, newApp.TeamOwner, oldApp.TeamOwner},
+	} {
+		requested := after
+		if scope.name == scope.oldName {
+			requested = resources{
+				cpuMilli: after.cpuMilli - before.cpuMilli,
+				memory:   after.memory - before.memory,
+			}
+		}
+		if requested.cpuMilli <= 0 && requested.memory <= 0 {
+			continue
+		}
+		limits, err := getResourceLimits(ctx, scope.scope, scope.name)
+		if err != nil {
+			return err
+		}
+		if limits.unlimited() {
+			continue
+		}
+		inUse, err := resourcesInUse(ctx, scope.scope, scope.name, cache)
+		if err != nil {
+			return err
+		}
+		for _, dimension := range []struct {
+			name                    string
+			limit, inUse, requested int
+		}{
+			{"cpumilli", limits.CPUMilli, inUse.cpuMilli, requested.cpuMilli},
+			{"memory", limits.Memory, inUse.memory, requested.memory},
+		} {
+			if dimension.limit < 0 || dimension.requested <= 0 || dimension.inUse+dimension.requested <= dimension.limit {
+				continue
+			}
+			available := dimension.limit - dimension.inUse
+			if available < 0 {
+				available = 0
+			}
+			return &quotaTypes.QuotaExceededError{
+				Resource:  fmt.Sprintf("%s of %s %q", dimension.name, scope.scope, scope.name),
+				Available: uint(available),
+				Requested: uint(dimension.requested),
+			}
+		}
+	}
+	return nil
+}
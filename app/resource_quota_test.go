@@ -0,0 +1,171 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setResourceLimits(c *check.C, scope, name string, cpuMilli, memory int) {
+	err := SetResourceQuotaLimit(context.TODO(), scope, name, &cpuMilli, &memory)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestGetResourceQuota(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 3, "web", newSuccessfulAppVersion(c, &a), nil)
+	q, err := GetResourceQuota(context.TODO(), ResourceQuotaPool, s.Pool)
+	c.Assert(err, check.IsNil)
+	c.Assert(q, check.DeepEquals, &quota.ResourceQuota{
+		CPUMilli: quota.Quota{Limit: -1, InUse: 0},
+		Memory:   quota.Quota{Limit: -1, InUse: 3 * 1024},
+	})
+	s.setResourceLimits(c, ResourceQuotaTeam, s.team.Name, 1000, 4096)
+	q, err = GetResourceQuota(context.TODO(), ResourceQuotaTeam, s.team.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(q, check.DeepEquals, &quota.ResourceQuota{
+		CPUMilli: quota.Quota{Limit: 1000, InUse: 0},
+		Memory:   quota.Quota{Limit: 4096, InUse: 3 * 1024},
+	})
+}
+
+func (s *S) TestSetResourceQuotaLimit(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 2, "web", newSuccessfulAppVersion(c, &a), nil)
+	memory := 1024
+	err = SetResourceQuotaLimit(context.TODO(), ResourceQuotaPool, s.Pool, nil, &memory)
+	c.Assert(err, check.Equals, quota.ErrLimitLowerThanAllocated)
+	memory = 4096
+	err = SetResourceQuotaLimit(context.TODO(), ResourceQuotaPool, s.Pool, nil, &memory)
+	c.Assert(err, check.IsNil)
+	cpuMilli := 500
+	err = SetResourceQuotaLimit(context.TODO(), ResourceQuotaPool, s.Pool, &cpuMilli, nil)
+	c.Assert(err, check.IsNil)
+	q, err := GetResourceQuota(context.TODO(), ResourceQuotaPool, s.Pool)
+	c.Assert(err, check.IsNil)
+	c.Assert(q.CPUMilli.Limit, check.Equals, 500)
+	c.Assert(q.Memory.Limit, check.Equals, 4096)
+	memory = -10
+	err = SetResourceQuotaLimit(context.TODO(), ResourceQuotaPool, s.Pool, nil, &memory)
+	c.Assert(err, check.IsNil)
+	q, err = GetResourceQuota(context.TODO(), ResourceQuotaPool, s.Pool)
+	c.Assert(err, check.IsNil)
+	c.Assert(q.Memory.IsUnlimited(), check.Equals, true)
+}
+
+func (s *S) TestAddUnitsResourceQuotaExceeded(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.setResourceLimits(c, ResourceQuotaPool, s.Pool, -1, 3*1024)
+	err = AddUnits(context.TODO(), &a, 2, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = AddUnits(context.TODO(), &a, 2, "web", "", nil)
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Resource:  `memory of pool "pool1"`,
+		Available: 1024,
+		Requested: 2 * 1024,
+	})
+	c.Assert(err, check.ErrorMatches, `Quota exceeded for memory of pool "pool1". Available: 1024, Requested: 2048.`)
+	units, err := AppUnits(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+}
+
+func (s *S) TestAddUnitsTeamResourceQuotaExceeded(c *check.C) {
+	s.defaultPlan.CPUMilli = 500
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.setResourceLimits(c, ResourceQuotaTeam, s.team.Name, 1000, -1)
+	err = AddUnits(context.TODO(), &a, 3, "web", "", nil)
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Resource:  `cpumilli of team "tsuruteam"`,
+		Available: 1000,
+		Requested: 1500,
+	})
+}
+
+func (s *S) TestAutoScaleResourceQuotaExceeded(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "autoscaleProv"
+	provisioner := &provisiontest.AutoScaleProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}
+	provision.Register("autoscaleProv", func() (provision.Provisioner, error) {
+		return provisioner, nil
+	})
+	defer provision.Unregister("autoscaleProv")
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	s.setResourceLimits(c, ResourceQuotaPool, s.Pool, -1, 4*1024)
+	err = AutoScale(context.TODO(), &a, provTypes.AutoScaleSpec{Process: "web", MinUnits: 1, MaxUnits: 5})
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Resource:  `memory of pool "pool1"`,
+		Available: 3 * 1024,
+		Requested: 4 * 1024,
+	})
+	err = AutoScale(context.TODO(), &a, provTypes.AutoScaleSpec{Process: "web", MinUnits: 1, MaxUnits: 4})
+	c.Assert(err, check.IsNil)
+	// autoscaled processes reserve their max units
+	q, err := GetResourceQuota(context.TODO(), ResourceQuotaPool, s.Pool)
+	c.Assert(err, check.IsNil)
+	c.Assert(q.Memory.InUse, check.Equals, 4*1024)
+}
+
+func (s *S) TestUpdatePlanResourceQuotaExceeded(c *check.C) {
+	s.plan = appTypes.Plan{Name: "something", Memory: 4096}
+	a := appTypes.App{Name: "my-test-app", Routers: []appTypes.AppRouter{{Name: "fake"}}, TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 2, "web", newSuccessfulAppVersion(c, &a), nil)
+	s.setResourceLimits(c, ResourceQuotaPool, s.Pool, -1, 4096)
+	updateData := appTypes.App{Name: "my-test-app", Plan: appTypes.Plan{Name: "something"}}
+	err = Update(context.TODO(), &a, UpdateAppArgs{UpdateData: &updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Resource:  `memory of pool "pool1"`,
+		Available: 2048,
+		Requested: 2*4096 - 2*1024,
+	})
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Plan.Name, check.Equals, s.defaultPlan.Name)
+}
+
+func (s *S) TestEnsureDeployResourceQuota(c *check.C) {
+	a := appTypes.App{Name: "myapp", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = version.AddData(appTypes.AddVersionDataArgs{
+		Processes: map[string][]string{"web": {"python app.py"}, "worker": {"python worker.py"}},
+	})
+	c.Assert(err, check.IsNil)
+	s.setResourceLimits(c, ResourceQuotaPool, s.Pool, -1, 1024)
+	err = ensureDeployResourceQuota(context.TODO(), &a, version)
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Resource:  `memory of pool "pool1"`,
+		Available: 1024,
+		Requested: 2048,
+	})
+	s.setResourceLimits(c, ResourceQuotaPool, s.Pool, -1, 2048)
+	err = ensureDeployResourceQuota(context.TODO(), &a, version)
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app/sbom"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/streamfmt"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// generateSBOM stores the SBOM of the image built for version when a SBOM
+// generator is configured. Failures are only reported, as the deploy doesn't
+// depend on the SBOM.
+func generateSBOM(ctx context.Context, app *appTypes.App, version appTypes.AppVersion, evt *event.Event) {
+	generator, err := sbom.GetGenerator()
+	if err != nil {
+		streamfmt.FprintlnActionf(evt, "WARNING: unable to generate the SBOM: %v", err)
+		return
+	}
+	if generator == nil {
+		return
+	}
+	imageName, err := version.BaseImageName()
+	if err != nil {
+		streamfmt.FprintlnActionf(evt, "WARNING: unable to generate the SBOM: %v", err)
+		return
+	}
+	streamfmt.FprintlnActionf(evt, "Generating SBOM of image %q", imageName)
+	document, err := generator.Generate(ctx, imageName)
+	var s *sbom.SBOM
+	if err == nil {
+		s, err = sbom.Save(ctx, app.Name, version.Version(), imageName, document)
+	}
+	if err != nil {
+		streamfmt.FprintlnActionf(evt, "WARNING: unable to generate the SBOM of image %q: %v", imageName, err)
+		return
+	}
+	streamfmt.FprintlnActionf(evt, "Stored %s SBOM with %d packages", s.Format, len(s.Packages))
+}
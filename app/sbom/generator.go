@@ -0,0 +1,118 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const httpGeneratorName = "http"
+
+// maxDocumentSize keeps SBOMs below the maximum size of a document in the
+// database.
+const maxDocumentSize = 12 * 1024 * 1024
+
+// Generator produces the SBOM of an image, as a SPDX or CycloneDX JSON
+// document.
+type Generator interface {
+	Generate(ctx context.Context, imageName string) ([]byte, error)
+}
+
+var (
+	generatorsMu sync.RWMutex
+	generators   = map[string]Generator{
+		httpGeneratorName: &httpGenerator{},
+	}
+)
+
+// RegisterGenerator makes a SBOM generator available to be set in
+// sbom:generator.
+func RegisterGenerator(name string, generator Generator) {
+	generatorsMu.Lock()
+	defer generatorsMu.Unlock()
+	generators[name] = generator
+}
+
+// GetGenerator returns the generator set in sbom:generator, which defaults to
+// the http generator when sbom:http:url is set. It returns nil when SBOMs are
+// not generated.
+func GetGenerator() (Generator, error) {
+	name, _ := config.GetString("sbom:generator")
+	if name == "" {
+		if url, _ := config.GetString("sbom:http:url"); url == "" {
+			return nil, nil
+		}
+		name = httpGeneratorName
+	}
+	generatorsMu.RLock()
+	defer generatorsMu.RUnlock()
+	generator, ok := generators[name]
+	if !ok {
+		return nil, errors.Errorf("unknown sbom generator %q", name)
+	}
+	return generator, nil
+}
+
+// ReadDocument reads a SBOM document, failing when it's too large to be
+// stored.
+func ReadDocument(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, errors.Errorf("sbom exceeds the maximum size of %d bytes", maxDocumentSize)
+	}
+	return data, nil
+}
+
+// httpGenerator sends the image name to the endpoint in sbom:http:url, which
+// must reply with the SBOM of the image.
+type httpGenerator struct{}
+
+func (g *httpGenerator) Generate(ctx context.Context, imageName string) ([]byte, error) {
+	url, err := config.GetString("sbom:http:url")
+	if err != nil {
+		return nil, err
+	}
+	timeout, _ := config.GetDuration("sbom:http:timeout")
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]string{"image": imageName})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token, _ := config.GetString("sbom:http:token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rsp, err := tsuruNet.Dial15FullUnlimitedClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to reach the sbom generator")
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return nil, errors.Errorf("invalid status code from the sbom generator %d: %s", rsp.StatusCode, string(data))
+	}
+	return ReadDocument(rsp.Body)
+}
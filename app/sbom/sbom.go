@@ -0,0 +1,282 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sbom stores the software bill of materials of the images built for
+// app versions, allowing the packages shipped by every app to be searched.
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/servicemanager"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+var (
+	ErrSBOMNotFound  = errors.New("sbom not found")
+	ErrInvalidFormat = errors.New("invalid sbom, only SPDX and CycloneDX JSON documents are supported")
+)
+
+// Package is a software package listed in a SBOM.
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty" bson:"purl,omitempty"`
+}
+
+// SBOM is the software bill of materials of the image of an app version.
+// Document holds the SBOM as it was produced, while Packages are extracted
+// from it to be searched.
+type SBOM struct {
+	ID        string `bson:"_id"`
+	App       string
+	Version   int
+	Image     string
+	Format    string
+	Packages  []Package
+	Document  string
+	CreatedAt time.Time
+}
+
+// Filter selects the packages searched, Version is optional and Apps limits
+// the search to the given apps, nil meaning every app.
+type Filter struct {
+	Name    string
+	Version string
+	Apps    []string
+}
+
+// SearchResult is a version of an app shipping a package matching a search.
+type SearchResult struct {
+	App     string  `json:"app"`
+	Version int     `json:"version"`
+	Image   string  `json:"image"`
+	Package Package `json:"package"`
+}
+
+func sbomID(appName string, version int) string {
+	return fmt.Sprintf("%s/%d", appName, version)
+}
+
+// Save parses the SBOM document and stores it for the app version, replacing
+// any SBOM already stored for it.
+func Save(ctx context.Context, appName string, version int, image string, document []byte) (*SBOM, error) {
+	format, packages, err := Parse(document)
+	if err != nil {
+		return nil, err
+	}
+	s := SBOM{
+		ID:        sbomID(appName, version),
+		App:       appName,
+		Version:   version,
+		Image:     image,
+		Format:    format,
+		Packages:  packages,
+		Document:  string(document),
+		CreatedAt: time.Now().UTC(),
+	}
+	collection, err := storagev2.SBOMsCollection()
+	if err != nil {
+		return nil, err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": s.ID}, s, options.Replace().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Get returns the SBOM stored for the app version.
+func Get(ctx context.Context, appName string, version int) (*SBOM, error) {
+	collection, err := storagev2.SBOMsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var s SBOM
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": sbomID(appName, version)}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrSBOMNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// RemoveApp removes the SBOMs of every version of the app.
+func RemoveApp(ctx context.Context, appName string) error {
+	collection, err := storagev2.SBOMsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"app": appName})
+	return err
+}
+
+// Search finds the app versions shipping the package in the filter. Versions
+// that were already removed from the app are not returned.
+func Search(ctx context.Context, filter Filter) ([]SearchResult, error) {
+	if filter.Name == "" {
+		return nil, errors.New("package name is required")
+	}
+	if filter.Apps != nil && len(filter.Apps) == 0 {
+		return nil, nil
+	}
+	match := mongoBSON.M{"name": filter.Name}
+	if filter.Version != "" {
+		match["version"] = filter.Version
+	}
+	query := mongoBSON.M{"packages": mongoBSON.M{"$elemMatch": match}}
+	if filter.Apps != nil {
+		query["app"] = mongoBSON.M{"$in": filter.Apps}
+	}
+	collection, err := storagev2.SBOMsCollection()
+	if err != nil {
+		return nil, err
+	}
+	projection := options.Find().SetProjection(mongoBSON.M{"document": 0})
+	cursor, err := collection.Find(ctx, query, projection)
+	if err != nil {
+		return nil, err
+	}
+	var sboms []SBOM
+	err = cursor.All(ctx, &sboms)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := existingVersions(ctx, sboms)
+	if err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, s := range sboms {
+		if !existing[s.ID] {
+			continue
+		}
+		for _, pkg := range s.Packages {
+			if pkg.Name != filter.Name || (filter.Version != "" && pkg.Version != filter.Version) {
+				continue
+			}
+			results = append(results, SearchResult{App: s.App, Version: s.Version, Image: s.Image, Package: pkg})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].App != results[j].App {
+			return results[i].App < results[j].App
+		}
+		return results[i].Version < results[j].Version
+	})
+	return results, nil
+}
+
+// existingVersions returns the ids of the SBOMs whose versions still exist.
+func existingVersions(ctx context.Context, sboms []SBOM) (map[string]bool, error) {
+	if len(sboms) == 0 {
+		return nil, nil
+	}
+	appNames := make([]string, 0, len(sboms))
+	for _, s := range sboms {
+		appNames = append(appNames, s.App)
+	}
+	allVersions, err := servicemanager.AppVersion.AllAppVersions(ctx, appNames...)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, versions := range allVersions {
+		if versions.MarkedToRemoval {
+			continue
+		}
+		for _, vi := range versions.Versions {
+			if !vi.MarkedToRemoval {
+				existing[sbomID(versions.AppName, vi.Version)] = true
+			}
+		}
+	}
+	return existing, nil
+}
+
+type spdxDocument struct {
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXDocument struct {
+	BOMFormat  string               `json:"bomFormat"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// Parse detects the format of a SPDX or CycloneDX JSON document, listing the
+// packages in it.
+func Parse(document []byte) (string, []Package, error) {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(document, &probe); err != nil {
+		return "", nil, ErrInvalidFormat
+	}
+	switch {
+	case probe.SPDXVersion != "":
+		var doc spdxDocument
+		if err := json.Unmarshal(document, &doc); err != nil {
+			return "", nil, errors.Wrap(err, "invalid SPDX document")
+		}
+		packages := make([]Package, 0, len(doc.Packages))
+		for _, p := range doc.Packages {
+			pkg := Package{Name: p.Name, Version: p.VersionInfo}
+			for _, ref := range p.ExternalRefs {
+				if ref.ReferenceType == "purl" {
+					pkg.PURL = ref.ReferenceLocator
+					break
+				}
+			}
+			packages = append(packages, pkg)
+		}
+		return FormatSPDX, packages, nil
+	case probe.BOMFormat == "CycloneDX":
+		var doc cycloneDXDocument
+		if err := json.Unmarshal(document, &doc); err != nil {
+			return "", nil, errors.Wrap(err, "invalid CycloneDX document")
+		}
+		var packages []Package
+		var walk func([]cycloneDXComponent)
+		walk = func(components []cycloneDXComponent) {
+			for _, c := range components {
+				packages = append(packages, Package{Name: c.Name, Version: c.Version, PURL: c.PURL})
+				walk(c.Components)
+			}
+		}
+		walk(doc.Components)
+		return FormatCycloneDX, packages, nil
+	}
+	return "", nil, ErrInvalidFormat
+}
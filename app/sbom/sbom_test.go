@@ -0,0 +1,152 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+const (
+	spdxData = `{
+	"spdxVersion": "SPDX-2.3",
+	"packages": [
+		{"name": "openssl", "versionInfo": "3.0.2", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:deb/ubuntu/openssl@3.0.2"}]},
+		{"name": "flask", "versionInfo": "2.3.0"}
+	]
+}`
+	cycloneDXData = `{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.5",
+	"components": [
+		{"name": "express", "version": "4.18.2", "purl": "pkg:npm/express@4.18.2", "components": [
+			{"name": "qs", "version": "6.11.0"}
+		]}
+	]
+}`
+)
+
+func newVersion(c *check.C, appName string) appTypes.AppVersion {
+	version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+		App: &appTypes.App{Name: appName},
+	})
+	c.Assert(err, check.IsNil)
+	return version
+}
+
+func (s *S) TestParseSPDX(c *check.C) {
+	format, packages, err := Parse([]byte(spdxData))
+	c.Assert(err, check.IsNil)
+	c.Assert(format, check.Equals, FormatSPDX)
+	c.Assert(packages, check.DeepEquals, []Package{
+		{Name: "openssl", Version: "3.0.2", PURL: "pkg:deb/ubuntu/openssl@3.0.2"},
+		{Name: "flask", Version: "2.3.0"},
+	})
+}
+
+func (s *S) TestParseCycloneDX(c *check.C) {
+	format, packages, err := Parse([]byte(cycloneDXData))
+	c.Assert(err, check.IsNil)
+	c.Assert(format, check.Equals, FormatCycloneDX)
+	c.Assert(packages, check.DeepEquals, []Package{
+		{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+		{Name: "qs", Version: "6.11.0"},
+	})
+}
+
+func (s *S) TestParseInvalid(c *check.C) {
+	_, _, err := Parse([]byte(`{"some": "json"}`))
+	c.Assert(err, check.Equals, ErrInvalidFormat)
+	_, _, err = Parse([]byte(`not json`))
+	c.Assert(err, check.Equals, ErrInvalidFormat)
+}
+
+func (s *S) TestSaveAndGet(c *check.C) {
+	version := newVersion(c, "myapp")
+	_, err := Get(context.TODO(), "myapp", version.Version())
+	c.Assert(err, check.Equals, ErrSBOMNotFound)
+	saved, err := Save(context.TODO(), "myapp", version.Version(), "registry.tsuru.io/tsuru/app-myapp:v1", []byte(spdxData))
+	c.Assert(err, check.IsNil)
+	c.Assert(saved.Packages, check.HasLen, 2)
+	stored, err := Get(context.TODO(), "myapp", version.Version())
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Format, check.Equals, FormatSPDX)
+	c.Assert(stored.Image, check.Equals, "registry.tsuru.io/tsuru/app-myapp:v1")
+	c.Assert(stored.Document, check.Equals, spdxData)
+	_, err = Save(context.TODO(), "myapp", version.Version(), "registry.tsuru.io/tsuru/app-myapp:v1", []byte(cycloneDXData))
+	c.Assert(err, check.IsNil)
+	stored, err = Get(context.TODO(), "myapp", version.Version())
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Format, check.Equals, FormatCycloneDX)
+	err = RemoveApp(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	_, err = Get(context.TODO(), "myapp", version.Version())
+	c.Assert(err, check.Equals, ErrSBOMNotFound)
+}
+
+func (s *S) TestSearch(c *check.C) {
+	v1 := newVersion(c, "app1")
+	v2 := newVersion(c, "app2")
+	v3 := newVersion(c, "app3")
+	_, err := Save(context.TODO(), "app1", v1.Version(), "app1:v1", []byte(spdxData))
+	c.Assert(err, check.IsNil)
+	_, err = Save(context.TODO(), "app2", v2.Version(), "app2:v1", []byte(cycloneDXData))
+	c.Assert(err, check.IsNil)
+	_, err = Save(context.TODO(), "app3", v3.Version(), "app3:v1", []byte(spdxData))
+	c.Assert(err, check.IsNil)
+	err = v3.MarkToRemoval()
+	c.Assert(err, check.IsNil)
+	results, err := Search(context.TODO(), Filter{Name: "openssl"})
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.DeepEquals, []SearchResult{
+		{App: "app1", Version: v1.Version(), Image: "app1:v1", Package: Package{Name: "openssl", Version: "3.0.2", PURL: "pkg:deb/ubuntu/openssl@3.0.2"}},
+	})
+	results, err = Search(context.TODO(), Filter{Name: "qs", Version: "6.11.0"})
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.HasLen, 1)
+	c.Assert(results[0].App, check.Equals, "app2")
+	results, err = Search(context.TODO(), Filter{Name: "qs", Version: "6.10.0"})
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.HasLen, 0)
+	results, err = Search(context.TODO(), Filter{Name: "openssl", Apps: []string{"app2"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.HasLen, 0)
+	_, err = Search(context.TODO(), Filter{})
+	c.Assert(err, check.ErrorMatches, "package name is required")
+}
+
+func (s *S) TestHTTPGenerator(c *check.C) {
+	var received map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(cycloneDXData))
+	}))
+	defer srv.Close()
+	config.Set("sbom:http:url", srv.URL)
+	defer config.Unset("sbom")
+	generator, err := GetGenerator()
+	c.Assert(err, check.IsNil)
+	document, err := generator.Generate(context.TODO(), "tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(document), check.Equals, cycloneDXData)
+	c.Assert(received, check.DeepEquals, map[string]string{"image": "tsuru/app-myapp:v1"})
+}
+
+func (s *S) TestGetGeneratorNotConfigured(c *check.C) {
+	generator, err := GetGenerator()
+	c.Assert(err, check.IsNil)
+	c.Assert(generator, check.IsNil)
+	config.Set("sbom:generator", "unknown")
+	defer config.Unset("sbom")
+	_, err = GetGenerator()
+	c.Assert(err, check.ErrorMatches, `unknown sbom generator "unknown"`)
+}
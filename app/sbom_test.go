@@ -0,0 +1,54 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/sbom"
+	"github.com/tsuru/tsuru/servicemanager"
+	check "gopkg.in/check.v1"
+)
+
+type fakeSBOMGenerator struct {
+	document []byte
+	err      error
+}
+
+func (f *fakeSBOMGenerator) Generate(ctx context.Context, imageName string) ([]byte, error) {
+	return f.document, f.err
+}
+
+func (s *S) TestDeployToProvisionerGeneratesSBOM(c *check.C) {
+	sbom.RegisterGenerator("fake", &fakeSBOMGenerator{
+		document: []byte(`{"bomFormat": "CycloneDX", "components": [{"name": "express", "version": "4.18.2"}]}`),
+	})
+	config.Set("sbom:generator", "fake")
+	defer config.Unset("sbom")
+	opts, evt := s.newScanDeploy(c)
+	_, err := deployToProvisioner(context.TODO(), opts, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Log(), check.Matches, `(?s).*Stored cyclonedx SBOM with 1 packages.*Builder deploy called`)
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), opts.App)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions.Versions, check.HasLen, 1)
+	for number := range versions.Versions {
+		stored, err := sbom.Get(context.TODO(), opts.App.Name, number)
+		c.Assert(err, check.IsNil)
+		c.Assert(stored.Packages, check.DeepEquals, []sbom.Package{{Name: "express", Version: "4.18.2"}})
+	}
+}
+
+func (s *S) TestDeployToProvisionerSBOMFailureDoesNotFailDeploy(c *check.C) {
+	sbom.RegisterGenerator("fake", &fakeSBOMGenerator{err: errors.New("generator unavailable")})
+	config.Set("sbom:generator", "fake")
+	defer config.Unset("sbom")
+	opts, evt := s.newScanDeploy(c)
+	_, err := deployToProvisioner(context.TODO(), opts, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Log(), check.Matches, `(?s).*WARNING: unable to generate the SBOM of image .*: generator unavailable.*Builder deploy called`)
+}
@@ -99,6 +99,7 @@ func (s *S) SetUpSuite(c *check.C) {
 	config.Set("docker:registry", "registry.somewhere")
 	config.Set("routers:fake-tls:type", "fake-tls")
 	config.Set("routers:fake:type", "fake")
+	config.Set("routers:fake-weighted:type", "fake-weighted")
 	config.Set("auth:hash-cost", bcrypt.MinCost)
 
 	storagev2.Reset()
@@ -120,6 +121,7 @@ func (s *S) SetUpTest(c *check.C) {
 	routertest.TLSRouter.Reset()
 	routertest.FakeRouter.Reset()
 	routertest.TLSRouter.Reset()
+	routertest.WeightedRouter.Reset()
 	pool.ResetCache()
 	rebuild.Initialize(func(appName string) (*appTypes.App, error) {
 		a, err := GetByName(context.TODO(), appName)
@@ -0,0 +1,431 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package usage periodically snapshots the resources reserved by apps and
+// aggregates those snapshots into usage reports.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultSnapshotInterval = time.Hour
+	defaultRetention        = 365 * 24 * time.Hour
+
+	// SnapshotEventKind is the kind of the event locking the usage
+	// snapshots, so only one API instance snapshots the apps at a time.
+	SnapshotEventKind = "usage-snapshot"
+
+	GroupByApp     = "app"
+	GroupByTeam    = "team"
+	GroupByPool    = "pool"
+	GroupByCluster = "cluster"
+)
+
+// Snapshot holds the resources reserved by the units of an app during one
+// snapshot interval. CPUMilli and Memory are the sum of the plan reservations
+// of every unit.
+type Snapshot struct {
+	ID       string `bson:"_id"`
+	Time     time.Time
+	Interval time.Duration
+	App      string
+	Team     string
+	Pool     string
+	Cluster  string
+	Plan     string
+	Units    int
+	CPUMilli int64
+	Memory   int64
+	// ExpireAt is when the snapshot is removed by the database, after the
+	// retention period.
+	ExpireAt time.Time
+}
+
+type Filter struct {
+	Team string
+	Pool string
+	// Teams restricts the report to the given teams, nil means every team.
+	Teams   []string
+	From    time.Time
+	To      time.Time
+	GroupBy string
+}
+
+// ReportItem is the usage aggregated for a group in a report. Resources are
+// reported multiplied by the time they were reserved, e.g. 2 units with 500
+// millicores each during 3 hours result in 6 unit hours and 3 cpu hours.
+type ReportItem struct {
+	App            string  `json:"app,omitempty"`
+	Team           string  `json:"team,omitempty"`
+	Pool           string  `json:"pool,omitempty"`
+	Cluster        string  `json:"cluster,omitempty"`
+	UnitHours      float64 `json:"unitHours"`
+	CPUHours       float64 `json:"cpuHours"`
+	MemoryGiBHours float64 `json:"memoryGiBHours"`
+}
+
+func Initialize() error {
+	s := &snapshotter{once: &sync.Once{}}
+	s.start()
+	shutdown.Register(s)
+	return nil
+}
+
+type snapshotter struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (s *snapshotter) start() {
+	s.once.Do(func() {
+		s.stopCh = make(chan struct{})
+		go s.spin()
+	})
+}
+
+func (s *snapshotter) Shutdown(ctx context.Context) error {
+	if s.stopCh == nil {
+		return nil
+	}
+	s.stopCh <- struct{}{}
+	s.stopCh = nil
+	s.once = &sync.Once{}
+	return nil
+}
+
+func (s *snapshotter) spin() {
+	for {
+		err := takeLockedSnapshots(context.Background(), time.Now())
+		if err != nil {
+			log.Errorf("[usage] unable to take usage snapshots: %v", err)
+		}
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(SnapshotInterval()):
+		}
+	}
+}
+
+// SnapshotInterval returns the time between usage snapshots, configured in
+// usage:snapshot-interval.
+func SnapshotInterval() time.Duration {
+	interval, err := config.GetDuration("usage:snapshot-interval")
+	if err != nil || interval <= 0 {
+		return defaultSnapshotInterval
+	}
+	return interval
+}
+
+// Retention returns for how long usage snapshots are kept, configured in
+// usage:retention, which defaults to one year.
+func Retention() time.Duration {
+	retention, err := config.GetDuration("usage:retention")
+	if err != nil || retention <= 0 {
+		return defaultRetention
+	}
+	return retention
+}
+
+// takeLockedSnapshots takes the snapshots of the interval containing now
+// holding an event lock, skipping the interval when another API instance
+// already took them.
+func takeLockedSnapshots(ctx context.Context, now time.Time) error {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGlobal, Value: "usage"},
+		InternalKind: SnapshotEventKind,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxGlobal, "")),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	taken, err := snapshotsTaken(ctx, now)
+	if err == nil && !taken {
+		err = TakeSnapshots(ctx, now)
+	}
+	if err != nil {
+		evt.Done(ctx, err)
+		return err
+	}
+	return evt.Abort(ctx)
+}
+
+func snapshotsTaken(ctx context.Context, now time.Time) (bool, error) {
+	collection, err := storagev2.UsageSnapshotsCollection()
+	if err != nil {
+		return false, err
+	}
+	bucket := now.UTC().Truncate(SnapshotInterval())
+	count, err := collection.CountDocuments(ctx, mongoBSON.M{"time": bucket}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// TakeSnapshots records the usage of every app in the snapshot interval
+// containing now. Snapshots are identified by app and interval, so running it
+// more than once in the same interval, e.g. from multiple API instances, only
+// replaces the previous snapshots.
+func TakeSnapshots(ctx context.Context, now time.Time) error {
+	interval := SnapshotInterval()
+	retention := Retention()
+	bucket := now.UTC().Truncate(interval)
+	apps, err := app.List(ctx, nil)
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.UsageSnapshotsCollection()
+	if err != nil {
+		return err
+	}
+	clusters := map[string]string{}
+	plans := map[string]appTypes.Plan{}
+	multi := tsuruErrors.NewMultiError()
+	for _, a := range apps {
+		snapshot, err := appSnapshot(ctx, a, clusters, plans)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to snapshot usage for app %q", a.Name))
+			continue
+		}
+		snapshot.ID = fmt.Sprintf("%s/%d", a.Name, bucket.Unix())
+		snapshot.Time = bucket
+		snapshot.Interval = interval
+		snapshot.ExpireAt = bucket.Add(retention)
+		_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": snapshot.ID}, snapshot, options.Replace().SetUpsert(true))
+		if err != nil {
+			multi.Add(err)
+		}
+	}
+	return multi.ToError()
+}
+
+func appSnapshot(ctx context.Context, a *appTypes.App, clusters map[string]string, plans map[string]appTypes.Plan) (Snapshot, error) {
+	snapshot := Snapshot{
+		App:  a.Name,
+		Team: a.TeamOwner,
+		Pool: a.Pool,
+		Plan: a.Plan.Name,
+	}
+	cluster, err := clusterForPool(ctx, a.Pool, clusters)
+	if err != nil {
+		return snapshot, err
+	}
+	snapshot.Cluster = cluster
+	units, err := app.AppUnits(ctx, a)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, u := range units {
+		plan, err := planForProcess(ctx, a, u.ProcessName, plans)
+		if err != nil {
+			return snapshot, err
+		}
+		snapshot.Units++
+		snapshot.CPUMilli += int64(plan.GetMilliCPU())
+		snapshot.Memory += plan.GetMemory()
+	}
+	return snapshot, nil
+}
+
+func clusterForPool(ctx context.Context, poolName string, cache map[string]string) (string, error) {
+	if name, ok := cache[poolName]; ok {
+		return name, nil
+	}
+	p, err := pool.GetPoolByName(ctx, poolName)
+	if err != nil {
+		return "", err
+	}
+	prov, err := p.GetProvisioner()
+	if err != nil {
+		return "", err
+	}
+	var name string
+	cluster, err := servicemanager.Cluster.FindByPool(ctx, prov.GetName(), poolName)
+	if err != nil && err != provTypes.ErrNoCluster {
+		return "", err
+	}
+	if cluster != nil {
+		name = cluster.Name
+	}
+	cache[poolName] = name
+	return name, nil
+}
+
+func planForProcess(ctx context.Context, a *appTypes.App, process string, cache map[string]appTypes.Plan) (appTypes.Plan, error) {
+	var planName string
+	for _, p := range a.Processes {
+		if p.Name == process {
+			planName = p.Plan
+		}
+	}
+	if planName == "" {
+		return a.Plan, nil
+	}
+	if plan, ok := cache[planName]; ok {
+		return plan, nil
+	}
+	plan, err := servicemanager.Plan.FindByName(ctx, planName)
+	if err != nil {
+		return appTypes.Plan{}, err
+	}
+	cache[planName] = *plan
+	return *plan, nil
+}
+
+// Report aggregates the snapshots taken between filter.From and filter.To
+// grouping them by app, team, pool or cluster. The aggregation runs in the
+// database, so the snapshots are never loaded in memory.
+func Report(ctx context.Context, filter Filter) ([]ReportItem, error) {
+	groupField, err := reportGroupField(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	query, ok := snapshotsQuery(filter)
+	if !ok {
+		return nil, nil
+	}
+	hours := mongoBSON.M{"$divide": []interface{}{"$interval", float64(time.Hour)}}
+	group := mongoBSON.M{
+		"_id":       "$" + groupField,
+		"unithours": mongoBSON.M{"$sum": mongoBSON.M{"$multiply": []interface{}{"$units", hours}}},
+		"cpuhours": mongoBSON.M{"$sum": mongoBSON.M{"$multiply": []interface{}{
+			mongoBSON.M{"$divide": []interface{}{"$cpumilli", 1000}}, hours,
+		}}},
+		"memorygibhours": mongoBSON.M{"$sum": mongoBSON.M{"$multiply": []interface{}{
+			mongoBSON.M{"$divide": []interface{}{"$memory", 1 << 30}}, hours,
+		}}},
+	}
+	if groupField == GroupByApp {
+		// snapshots are sorted by time, so the app is reported with its
+		// latest team, pool and cluster.
+		for _, field := range []string{"team", "pool", "cluster"} {
+			group[field] = mongoBSON.M{"$last": "$" + field}
+		}
+	}
+	pipeline := []mongoBSON.M{
+		{"$match": query},
+		{"$sort": mongoBSON.M{"time": 1}},
+		{"$group": group},
+		{"$sort": mongoBSON.M{"_id": 1}},
+	}
+	collection, err := storagev2.UsageSnapshotsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Key            string `bson:"_id"`
+		Team           string
+		Pool           string
+		Cluster        string
+		UnitHours      float64
+		CPUHours       float64
+		MemoryGiBHours float64
+	}
+	err = cursor.All(ctx, &groups)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ReportItem, 0, len(groups))
+	for _, g := range groups {
+		item := ReportItem{
+			UnitHours:      g.UnitHours,
+			CPUHours:       g.CPUHours,
+			MemoryGiBHours: g.MemoryGiBHours,
+		}
+		switch groupField {
+		case GroupByTeam:
+			item.Team = g.Key
+		case GroupByPool:
+			item.Pool = g.Key
+		case GroupByCluster:
+			item.Cluster = g.Key
+		default:
+			item.App, item.Team, item.Pool, item.Cluster = g.Key, g.Team, g.Pool, g.Cluster
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func reportGroupField(groupBy string) (string, error) {
+	switch groupBy {
+	case "":
+		return GroupByApp, nil
+	case GroupByApp, GroupByTeam, GroupByPool, GroupByCluster:
+		return groupBy, nil
+	}
+	return "", &tsuruErrors.ValidationError{
+		Message: fmt.Sprintf("invalid group %q, must be one of: app, team, pool, cluster", groupBy),
+	}
+}
+
+// snapshotsQuery returns the query selecting the snapshots in the filter, ok
+// is false when no snapshot can match it.
+func snapshotsQuery(filter Filter) (query mongoBSON.M, ok bool) {
+	query = mongoBSON.M{}
+	timeQuery := mongoBSON.M{}
+	if !filter.From.IsZero() {
+		timeQuery["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timeQuery["$lt"] = filter.To
+	}
+	if len(timeQuery) > 0 {
+		query["time"] = timeQuery
+	}
+	if filter.Teams != nil {
+		query["team"] = mongoBSON.M{"$in": filter.Teams}
+	}
+	if filter.Team != "" {
+		if filter.Teams != nil && !contains(filter.Teams, filter.Team) {
+			return nil, false
+		}
+		query["team"] = filter.Team
+	}
+	if filter.Pool != "" {
+		query["pool"] = filter.Pool
+	}
+	return query, true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+func (s *S) createApp(c *check.C, a *appTypes.App, units map[string]int) {
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	for process, n := range units {
+		for i := 0; i < n; i++ {
+			provisiontest.ProvisionerInstance.AddUnit(a, provTypes.Unit{AppName: a.Name, ProcessName: process})
+		}
+	}
+}
+
+func (s *S) allSnapshots(c *check.C) []Snapshot {
+	collection, err := storagev2.UsageSnapshotsCollection()
+	c.Assert(err, check.IsNil)
+	cursor, err := collection.Find(context.TODO(), mongoBSON.M{})
+	c.Assert(err, check.IsNil)
+	var snapshots []Snapshot
+	err = cursor.All(context.TODO(), &snapshots)
+	c.Assert(err, check.IsNil)
+	return snapshots
+}
+
+func (s *S) TestTakeSnapshots(c *check.C) {
+	a := &appTypes.App{
+		Name:      "myapp",
+		TeamOwner: "team1",
+		Pool:      "pool1",
+		Processes: []appTypes.Process{{Name: "worker", Plan: "large"}},
+	}
+	s.createApp(c, a, map[string]int{"web": 2, "worker": 1})
+	now := time.Date(2026, 5, 10, 14, 35, 0, 0, time.UTC)
+	err := TakeSnapshots(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	snapshots := s.allSnapshots(c)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].Time.Equal(time.Date(2026, 5, 10, 14, 0, 0, 0, time.UTC)), check.Equals, true)
+	c.Assert(snapshots[0].ExpireAt.Equal(time.Date(2027, 5, 10, 14, 0, 0, 0, time.UTC)), check.Equals, true)
+	snapshots[0].Time = time.Time{}
+	snapshots[0].ExpireAt = time.Time{}
+	c.Assert(snapshots[0], check.DeepEquals, Snapshot{
+		ID:       "myapp/1778421600",
+		Interval: time.Hour,
+		App:      "myapp",
+		Team:     "team1",
+		Pool:     "pool1",
+		Cluster:  "c1",
+		Plan:     "default",
+		Units:    3,
+		CPUMilli: 3000,
+		Memory:   6 << 30,
+	})
+	provisiontest.ProvisionerInstance.AddUnit(a, provTypes.Unit{AppName: a.Name, ProcessName: "web"})
+	err = TakeSnapshots(context.TODO(), now.Add(10*time.Minute))
+	c.Assert(err, check.IsNil)
+	snapshots = s.allSnapshots(c)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].Units, check.Equals, 4)
+}
+
+func (s *S) TestTakeSnapshotsCustomInterval(c *check.C) {
+	config.Set("usage:snapshot-interval", "15m")
+	defer config.Unset("usage:snapshot-interval")
+	s.createApp(c, &appTypes.App{Name: "myapp", TeamOwner: "team1", Pool: "pool2"}, map[string]int{"web": 1})
+	now := time.Date(2026, 5, 10, 14, 35, 0, 0, time.UTC)
+	err := TakeSnapshots(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	err = TakeSnapshots(context.TODO(), now.Add(15*time.Minute))
+	c.Assert(err, check.IsNil)
+	snapshots := s.allSnapshots(c)
+	c.Assert(snapshots, check.HasLen, 2)
+	for _, snapshot := range snapshots {
+		c.Assert(snapshot.Interval, check.Equals, 15*time.Minute)
+		c.Assert(snapshot.Cluster, check.Equals, "")
+	}
+}
+
+func (s *S) TestTakeSnapshotsRetention(c *check.C) {
+	config.Set("usage:retention", "720h")
+	defer config.Unset("usage:retention")
+	s.createApp(c, &appTypes.App{Name: "myapp", TeamOwner: "team1", Pool: "pool1"}, map[string]int{"web": 1})
+	now := time.Date(2026, 5, 10, 14, 35, 0, 0, time.UTC)
+	err := TakeSnapshots(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	snapshots := s.allSnapshots(c)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].ExpireAt.Equal(time.Date(2026, 6, 9, 14, 0, 0, 0, time.UTC)), check.Equals, true)
+}
+
+func (s *S) TestTakeLockedSnapshotsOncePerInterval(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: "team1", Pool: "pool1"}
+	s.createApp(c, a, map[string]int{"web": 1})
+	now := time.Date(2026, 5, 10, 14, 35, 0, 0, time.UTC)
+	err := takeLockedSnapshots(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	provisiontest.ProvisionerInstance.AddUnit(a, provTypes.Unit{AppName: a.Name, ProcessName: "web"})
+	err = takeLockedSnapshots(context.TODO(), now.Add(10*time.Minute))
+	c.Assert(err, check.IsNil)
+	snapshots := s.allSnapshots(c)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].Units, check.Equals, 1)
+	err = takeLockedSnapshots(context.TODO(), now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(s.allSnapshots(c), check.HasLen, 2)
+}
+
+func (s *S) TestTakeLockedSnapshotsLocked(c *check.C) {
+	s.createApp(c, &appTypes.App{Name: "myapp", TeamOwner: "team1", Pool: "pool1"}, map[string]int{"web": 1})
+	evt, err := event.NewInternal(context.TODO(), &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGlobal, Value: "usage"},
+		InternalKind: SnapshotEventKind,
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Abort(context.TODO())
+	err = takeLockedSnapshots(context.TODO(), time.Date(2026, 5, 10, 14, 35, 0, 0, time.UTC))
+	c.Assert(err, check.IsNil)
+	c.Assert(s.allSnapshots(c), check.HasLen, 0)
+}
+
+func (s *S) TestReport(c *check.C) {
+	s.createApp(c, &appTypes.App{Name: "app1", TeamOwner: "team1", Pool: "pool1"}, map[string]int{"web": 2})
+	s.createApp(c, &appTypes.App{Name: "app2", TeamOwner: "team1", Pool: "pool2"}, map[string]int{"web": 1})
+	s.createApp(c, &appTypes.App{Name: "app3", TeamOwner: "team2", Pool: "pool1"}, map[string]int{"web": 1})
+	start := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err := TakeSnapshots(context.TODO(), start.Add(time.Duration(i)*time.Hour))
+		c.Assert(err, check.IsNil)
+	}
+	items, err := Report(context.TODO(), Filter{From: start, To: start.Add(2 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.DeepEquals, []ReportItem{
+		{App: "app1", Team: "team1", Pool: "pool1", Cluster: "c1", UnitHours: 4, CPUHours: 2, MemoryGiBHours: 4},
+		{App: "app2", Team: "team1", Pool: "pool2", UnitHours: 2, CPUHours: 1, MemoryGiBHours: 2},
+		{App: "app3", Team: "team2", Pool: "pool1", Cluster: "c1", UnitHours: 2, CPUHours: 1, MemoryGiBHours: 2},
+	})
+	items, err = Report(context.TODO(), Filter{GroupBy: GroupByTeam})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.DeepEquals, []ReportItem{
+		{Team: "team1", UnitHours: 9, CPUHours: 4.5, MemoryGiBHours: 9},
+		{Team: "team2", UnitHours: 3, CPUHours: 1.5, MemoryGiBHours: 3},
+	})
+	items, err = Report(context.TODO(), Filter{GroupBy: GroupByCluster, Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.DeepEquals, []ReportItem{
+		{Cluster: "c1", UnitHours: 9, CPUHours: 4.5, MemoryGiBHours: 9},
+	})
+}
+
+func (s *S) TestReportRestrictedTeams(c *check.C) {
+	s.createApp(c, &appTypes.App{Name: "app1", TeamOwner: "team1", Pool: "pool1"}, map[string]int{"web": 1})
+	s.createApp(c, &appTypes.App{Name: "app2", TeamOwner: "team2", Pool: "pool1"}, map[string]int{"web": 1})
+	err := TakeSnapshots(context.TODO(), time.Now())
+	c.Assert(err, check.IsNil)
+	items, err := Report(context.TODO(), Filter{GroupBy: GroupByPool, Teams: []string{"team2"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.DeepEquals, []ReportItem{
+		{Pool: "pool1", UnitHours: 1, CPUHours: 0.5, MemoryGiBHours: 1},
+	})
+	items, err = Report(context.TODO(), Filter{Team: "team1", Teams: []string{"team2"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 0)
+}
+
+func (s *S) TestReportInvalidGroup(c *check.C) {
+	_, err := Report(context.TODO(), Filter{GroupBy: "planet"})
+	c.Assert(err, check.ErrorMatches, `invalid group "planet", must be one of: app, team, pool, cluster`)
+}
+
+func (s *S) TestSnapshotterStartShutdown(c *check.C) {
+	snap := &snapshotter{once: &sync.Once{}}
+	snap.start()
+	err := snap.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
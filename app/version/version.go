@@ -153,6 +153,20 @@ func (v *appVersionImpl) AddData(args appTypes.AddVersionDataArgs) error {
 	return v.storage.UpdateVersion(v.ctx, v.app.Name, v.versionInfo)
 }
 
+// SetCustomData sets a single key in the custom data of the version, keeping
+// the data added from tsuru.yaml.
+func (v *appVersionImpl) SetCustomData(key string, value interface{}) error {
+	err := v.refresh()
+	if err != nil {
+		return err
+	}
+	if v.versionInfo.CustomData == nil {
+		v.versionInfo.CustomData = map[string]interface{}{}
+	}
+	v.versionInfo.CustomData[key] = value
+	return v.storage.UpdateVersion(v.ctx, v.app.Name, v.versionInfo)
+}
+
 func (v *appVersionImpl) UpdatePastUnits(process string, replicas int) error {
 	err := v.refresh()
 	if err != nil {
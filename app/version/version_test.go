@@ -343,3 +343,22 @@ func (s *S) TestAppVersionImpl_ToggleEnabled(c *check.C) {
 	c.Assert(version.VersionInfo().Disabled, check.Equals, true)
 	c.Assert(version.VersionInfo().DisabledReason, check.Equals, "other reason")
 }
+
+func (s *S) TestAppVersionImpl_SetCustomData(c *check.C) {
+	svc, err := AppVersionService()
+	c.Assert(err, check.IsNil)
+	version, err := svc.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+		App: &appTypes.App{Name: "myapp"},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.AddData(appTypes.AddVersionDataArgs{
+		Processes: map[string][]string{"web": {"python myapp.py"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.SetCustomData("imageScan", map[string]interface{}{"scanner": "fake"})
+	c.Assert(err, check.IsNil)
+	c.Assert(version.VersionInfo().CustomData, check.DeepEquals, map[string]interface{}{
+		"imageScan": map[string]interface{}{"scanner": "fake"},
+	})
+	c.Assert(version.VersionInfo().Processes, check.DeepEquals, map[string][]string{"web": {"python myapp.py"}})
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultLockoutCooldown    = 5 * time.Minute
+	defaultLockoutMaxCooldown = 24 * time.Hour
+)
+
+var lockoutNow = time.Now
+
+// OnUserLock, when set, is called after a user is locked by failed logins.
+// The API sets it to record the lock in an event, as the event package
+// depends on this package in its tests and can't be imported here.
+var OnUserLock func(ctx context.Context, email string, failures, lockouts int, lockedUntil time.Time)
+
+// loginAttempts tracks the failed logins of a user. Failures is reset when
+// the user is locked, Lockouts counts the consecutive locks and is only reset
+// by a successful login or an unlock, doubling the cooldown of each lock.
+type loginAttempts struct {
+	UserEmail   string `bson:"_id"`
+	Failures    int
+	Lockouts    int
+	LockedUntil time.Time
+}
+
+// lockoutMaxAttempts returns the number of consecutive failed logins that
+// lock a user, configured in auth:lockout:max-attempts. Zero disables
+// lockouts.
+func lockoutMaxAttempts() int {
+	attempts, _ := config.GetInt("auth:lockout:max-attempts")
+	if attempts < 0 {
+		return 0
+	}
+	return attempts
+}
+
+// lockoutCooldown returns for how long a user is locked, starting at
+// auth:lockout:cooldown and doubling for each consecutive lock up to
+// auth:lockout:max-cooldown.
+func lockoutCooldown(lockouts int) time.Duration {
+	cooldown, err := config.GetDuration("auth:lockout:cooldown")
+	if err != nil || cooldown <= 0 {
+		cooldown = defaultLockoutCooldown
+	}
+	maxCooldown, err := config.GetDuration("auth:lockout:max-cooldown")
+	if err != nil || maxCooldown <= 0 {
+		maxCooldown = defaultLockoutMaxCooldown
+	}
+	for i := 1; i < lockouts && cooldown < maxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > maxCooldown {
+		cooldown = maxCooldown
+	}
+	return cooldown
+}
+
+// authenticate checks the password of a user that is not locked, counting
+// wrong passwords towards the lockout of the user.
+func authenticate(ctx context.Context, u *auth.User, password string) error {
+	if err := checkLocked(ctx, u.Email); err != nil {
+		return err
+	}
+	err := checkPassword(u.Password, password)
+	if _, ok := err.(auth.AuthenticationFailure); ok {
+		registerLoginFailure(ctx, u.Email)
+	}
+	return err
+}
+
+func checkLocked(ctx context.Context, email string) error {
+	if lockoutMaxAttempts() == 0 {
+		return nil
+	}
+	attempts, err := getLoginAttempts(ctx, email)
+	if err != nil || attempts == nil {
+		return err
+	}
+	remaining := attempts.LockedUntil.Sub(lockoutNow())
+	if remaining <= 0 {
+		return nil
+	}
+	return &errors.NotAuthorizedError{
+		Message: fmt.Sprintf("user is locked after too many failed login attempts, try again in %s", remaining.Round(time.Second)),
+	}
+}
+
+// registerLoginFailure counts a failed login, locking the user when it
+// reaches the configured maximum. Errors are only logged, they must not
+// change the result of the login.
+func registerLoginFailure(ctx context.Context, email string) {
+	maxAttempts := lockoutMaxAttempts()
+	if maxAttempts == 0 {
+		return
+	}
+	collection, err := storagev2.LoginAttemptsCollection()
+	if err != nil {
+		log.Errorf("unable to register failed login for %q: %v", email, err)
+		return
+	}
+	var attempts loginAttempts
+	err = collection.FindOneAndUpdate(ctx, mongoBSON.M{"_id": email}, mongoBSON.M{
+		"$inc": mongoBSON.M{"failures": 1},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&attempts)
+	if err != nil {
+		log.Errorf("unable to register failed login for %q: %v", email, err)
+		return
+	}
+	if attempts.Failures < maxAttempts {
+		return
+	}
+	lockouts := attempts.Lockouts + 1
+	lockedUntil := lockoutNow().Add(lockoutCooldown(lockouts))
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": email, "failures": attempts.Failures}, mongoBSON.M{
+		"$set": mongoBSON.M{"failures": 0, "lockouts": lockouts, "lockeduntil": lockedUntil},
+	})
+	if err != nil {
+		log.Errorf("unable to lock user %q: %v", email, err)
+		return
+	}
+	if result.ModifiedCount == 0 {
+		// locked by a concurrent failure
+		return
+	}
+	if OnUserLock != nil {
+		OnUserLock(ctx, email, attempts.Failures, lockouts, lockedUntil)
+	}
+}
+
+func resetLoginFailures(ctx context.Context, email string) error {
+	if lockoutMaxAttempts() == 0 {
+		return nil
+	}
+	return removeLoginAttempts(ctx, email)
+}
+
+// Unlock removes the lock of the user and resets its failed logins.
+func (s NativeScheme) Unlock(ctx context.Context, user *auth.User) error {
+	return removeLoginAttempts(ctx, user.Email)
+}
+
+// LockedUsers returns the users currently locked and until when.
+func (s NativeScheme) LockedUsers(ctx context.Context) (map[string]time.Time, error) {
+	collection, err := storagev2.LoginAttemptsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"lockeduntil": mongoBSON.M{"$gt": lockoutNow()}})
+	if err != nil {
+		return nil, err
+	}
+	var all []loginAttempts
+	err = cursor.All(ctx, &all)
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[string]time.Time, len(all))
+	for _, attempts := range all {
+		locked[attempts.UserEmail] = attempts.LockedUntil
+	}
+	return locked, nil
+}
+
+func removeLoginAttempts(ctx context.Context, email string) error {
+	collection, err := storagev2.LoginAttemptsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"_id": email})
+	return err
+}
+
+func getLoginAttempts(ctx context.Context, email string) (*loginAttempts, error) {
+	collection, err := storagev2.LoginAttemptsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var attempts loginAttempts
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": email}).Decode(&attempts)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempts, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+var lockoutTestTime = time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func setLockoutNow(t time.Time) {
+	lockoutNow = func() time.Time { return t }
+}
+
+func (s *S) enableLockout(c *check.C) {
+	config.Set("auth:lockout:max-attempts", 3)
+	config.Set("auth:lockout:cooldown", "1m")
+	setLockoutNow(lockoutTestTime)
+}
+
+func (s *S) failLogins(c *check.C, count int) {
+	for i := 0; i < count; i++ {
+		_, err := nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "wrong-password"})
+		c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	}
+}
+
+func (s *S) TestLockoutCooldown(c *check.C) {
+	defer config.Unset("auth:lockout")
+	c.Assert(lockoutCooldown(1), check.Equals, 5*time.Minute)
+	c.Assert(lockoutCooldown(3), check.Equals, 20*time.Minute)
+	c.Assert(lockoutCooldown(20), check.Equals, 24*time.Hour)
+	config.Set("auth:lockout:cooldown", "1h")
+	config.Set("auth:lockout:max-cooldown", "3h")
+	c.Assert(lockoutCooldown(2), check.Equals, 2*time.Hour)
+	c.Assert(lockoutCooldown(3), check.Equals, 3*time.Hour)
+}
+
+func (s *S) TestLoginLocksUser(c *check.C) {
+	s.enableLockout(c)
+	defer config.Unset("auth:lockout")
+	defer func() { lockoutNow = time.Now }()
+	var lockedEmails []string
+	OnUserLock = func(ctx context.Context, email string, failures, lockouts int, lockedUntil time.Time) {
+		lockedEmails = append(lockedEmails, email)
+	}
+	defer func() { OnUserLock = nil }()
+	s.failLogins(c, 3)
+	_, err := nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.DeepEquals, &errors.NotAuthorizedError{
+		Message: "user is locked after too many failed login attempts, try again in 1m0s",
+	})
+	locked, err := nativeScheme.LockedUsers(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(locked, check.HasLen, 1)
+	c.Assert(locked[s.user.Email].Equal(lockoutTestTime.Add(time.Minute)), check.Equals, true)
+	c.Assert(lockedEmails, check.DeepEquals, []string{s.user.Email})
+	// the cooldown doubles for consecutive locks
+	setLockoutNow(lockoutTestTime.Add(time.Minute))
+	s.failLogins(c, 3)
+	locked, err = nativeScheme.LockedUsers(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(locked[s.user.Email].Equal(lockoutTestTime.Add(3*time.Minute)), check.Equals, true)
+}
+
+func (s *S) TestLoginResetsFailures(c *check.C) {
+	s.enableLockout(c)
+	defer config.Unset("auth:lockout")
+	defer func() { lockoutNow = time.Now }()
+	s.failLogins(c, 2)
+	_, err := nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	s.failLogins(c, 2)
+	attempts, err := getLoginAttempts(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(attempts.Failures, check.Equals, 2)
+}
+
+func (s *S) TestLoginLockoutDisabled(c *check.C) {
+	s.failLogins(c, 10)
+	attempts, err := getLoginAttempts(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(attempts, check.IsNil)
+}
+
+func (s *S) TestUnlock(c *check.C) {
+	s.enableLockout(c)
+	defer config.Unset("auth:lockout")
+	defer func() { lockoutNow = time.Now }()
+	s.failLogins(c, 3)
+	err := nativeScheme.Unlock(context.TODO(), s.user)
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	locked, err := nativeScheme.LockedUsers(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(locked, check.HasLen, 0)
+}
@@ -0,0 +1,351 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/errors"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	mfaSecretSize      = 20
+	mfaPeriod          = 30
+	mfaDigits          = 6
+	mfaSkew            = 1
+	recoveryCodesCount = 10
+	recoveryCodeSize   = 5
+	defaultMFAIssuer   = "tsuru"
+)
+
+var (
+	ErrMFAAlreadyEnabled     = &errors.ConflictError{Message: "multi-factor authentication is already enabled"}
+	ErrMFANotEnabled         = &errors.ValidationError{Message: "multi-factor authentication is not enabled"}
+	ErrMFANotEnrolled        = &errors.ValidationError{Message: "there is no multi-factor authentication enrollment to confirm"}
+	ErrMFAEnrollmentRequired = &errors.NotAuthorizedError{Message: "multi-factor authentication is required for this user, enroll before logging in"}
+	ErrMFACannotBeDisabled   = &errors.NotAuthorizedError{Message: "multi-factor authentication is required for this user and cannot be disabled"}
+	ErrInvalidMFACode        = auth.AuthenticationFailure{Message: "invalid one-time code"}
+	mfaNow                   = time.Now
+	mfaEncoding              = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// mfaConfig holds the TOTP (RFC 6238) secret of a user. LastStep is the last
+// time step accepted, codes from it or from earlier steps are rejected to
+// prevent replays. Recovery codes are stored hashed and removed when used.
+type mfaConfig struct {
+	UserEmail     string `bson:"_id"`
+	Secret        string
+	Enabled       bool
+	RecoveryCodes []string
+	LastStep      int64
+	CreatedAt     time.Time
+}
+
+// EnrollMFA starts the enrollment of the user, replacing any enrollment not
+// yet confirmed.
+func (s NativeScheme) EnrollMFA(ctx context.Context, user *auth.User, password string) (*authTypes.MFAEnrollment, error) {
+	if err := authenticate(ctx, user, password); err != nil {
+		return nil, err
+	}
+	current, err := getMFAConfig(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret := make([]byte, mfaSecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+	enrollment := &authTypes.MFAEnrollment{Secret: mfaEncoding.EncodeToString(secret)}
+	cfg := mfaConfig{
+		UserEmail: user.Email,
+		Secret:    enrollment.Secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		enrollment.RecoveryCodes = append(enrollment.RecoveryCodes, code)
+		cfg.RecoveryCodes = append(cfg.RecoveryCodes, hashRecoveryCode(code))
+	}
+	enrollment.URL = mfaURL(user.Email, enrollment.Secret)
+	collection, err := storagev2.MFACollection()
+	if err != nil {
+		return nil, err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": user.Email}, cfg, options.Replace().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// ConfirmMFA enables the pending enrollment of the user. Only codes from
+// the authenticator app are accepted, recovery codes are not.
+func (s NativeScheme) ConfirmMFA(ctx context.Context, user *auth.User, password, code string) error {
+	if err := authenticate(ctx, user, password); err != nil {
+		return err
+	}
+	cfg, err := getMFAConfig(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return ErrMFANotEnrolled
+	}
+	if cfg.Enabled {
+		return ErrMFAAlreadyEnabled
+	}
+	step, ok := matchTOTP(cfg, code)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	collection, err := storagev2.MFACollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": user.Email}, mongoBSON.M{
+		"$set": mongoBSON.M{"enabled": true, "laststep": step},
+	})
+	return err
+}
+
+// DisableMFA removes the second factor of the user, unless it's required by
+// the user roles or teams.
+func (s NativeScheme) DisableMFA(ctx context.Context, user *auth.User, password, code string) error {
+	if err := authenticate(ctx, user, password); err != nil {
+		return err
+	}
+	cfg, err := getMFAConfig(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if cfg == nil || !cfg.Enabled {
+		return ErrMFANotEnabled
+	}
+	required, err := mfaRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFACannotBeDisabled
+	}
+	if err = verifyMFACode(ctx, cfg, code); err != nil {
+		return err
+	}
+	return removeMFAConfig(ctx, user.Email)
+}
+
+// checkMFA verifies the second factor of a user whose password was already
+// checked. Users without an enabled second factor only need one if it's
+// required for them, in which case they must enroll first.
+func checkMFA(ctx context.Context, user *auth.User, code string) error {
+	cfg, err := getMFAConfig(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if cfg == nil || !cfg.Enabled {
+		required, err := mfaRequired(ctx, user)
+		if err != nil {
+			return err
+		}
+		if required {
+			return ErrMFAEnrollmentRequired
+		}
+		return nil
+	}
+	if code == "" {
+		return auth.ErrMFARequired
+	}
+	return verifyMFACode(ctx, cfg, code)
+}
+
+// mfaRequired returns whether the user has one of the roles listed in
+// auth:mfa:required-roles or a role in one of the teams listed in
+// auth:mfa:required-teams, either directly or through a group.
+func mfaRequired(ctx context.Context, user *auth.User) (bool, error) {
+	roles, _ := config.GetList("auth:mfa:required-roles")
+	teams, _ := config.GetList("auth:mfa:required-teams")
+	if len(roles) == 0 && len(teams) == 0 {
+		return false, nil
+	}
+	instances := append([]authTypes.RoleInstance{}, user.Roles...)
+	groups, err := user.UserGroups()
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups {
+		instances = append(instances, group.Roles...)
+	}
+	for _, instance := range instances {
+		if contains(roles, instance.Name) {
+			return true, nil
+		}
+	}
+	if len(teams) == 0 {
+		return false, nil
+	}
+	permissions, err := user.Permissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permissions {
+		if p.Context.CtxType == permTypes.CtxTeam && contains(teams, p.Context.Value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func verifyMFACode(ctx context.Context, cfg *mfaConfig, code string) error {
+	collection, err := storagev2.MFACollection()
+	if err != nil {
+		return err
+	}
+	var result *mongo.UpdateResult
+	if step, ok := matchTOTP(cfg, code); ok {
+		result, err = collection.UpdateOne(ctx, mongoBSON.M{
+			"_id":      cfg.UserEmail,
+			"laststep": mongoBSON.M{"$lt": step},
+		}, mongoBSON.M{"$set": mongoBSON.M{"laststep": step}})
+	} else {
+		hash := hashRecoveryCode(code)
+		if !contains(cfg.RecoveryCodes, hash) {
+			return ErrInvalidMFACode
+		}
+		result, err = collection.UpdateOne(ctx, mongoBSON.M{
+			"_id":           cfg.UserEmail,
+			"recoverycodes": hash,
+		}, mongoBSON.M{"$pull": mongoBSON.M{"recoverycodes": hash}})
+	}
+	if err != nil {
+		return err
+	}
+	// the code was used concurrently by another login
+	if result.ModifiedCount == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// matchTOTP returns the time step matching the code, accepting codes from
+// adjacent steps to tolerate clock drift.
+func matchTOTP(cfg *mfaConfig, code string) (int64, bool) {
+	secret, err := mfaEncoding.DecodeString(cfg.Secret)
+	if err != nil || len(code) != mfaDigits {
+		return 0, false
+	}
+	current := mfaNow().Unix() / mfaPeriod
+	for step := current - mfaSkew; step <= current+mfaSkew; step++ {
+		if step <= cfg.LastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totp(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func totp(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", mfaDigits, value%1000000)
+}
+
+func mfaURL(email, secret string) string {
+	issuer, _ := config.GetString("auth:mfa:issuer")
+	if issuer == "" {
+		issuer = defaultMFAIssuer
+	}
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + email,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+func newRecoveryCode() (string, error) {
+	data := make([]byte, recoveryCodeSize)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(data)
+	return code[:recoveryCodeSize] + "-" + code[recoveryCodeSize:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func getMFAConfig(ctx context.Context, email string) (*mfaConfig, error) {
+	collection, err := storagev2.MFACollection()
+	if err != nil {
+		return nil, err
+	}
+	var cfg mfaConfig
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": email}).Decode(&cfg)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func removeMFAConfig(ctx context.Context, email string) error {
+	collection, err := storagev2.MFACollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"_id": email})
+	return err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
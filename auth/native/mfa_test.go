@@ -0,0 +1,195 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+var mfaTestTime = time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func setMFANow(t time.Time) {
+	mfaNow = func() time.Time { return t }
+}
+
+func currentCode(c *check.C, secret string) string {
+	key, err := mfaEncoding.DecodeString(secret)
+	c.Assert(err, check.IsNil)
+	return totp(key, mfaNow().Unix()/mfaPeriod)
+}
+
+func (s *S) enableMFA(c *check.C) []string {
+	setMFANow(mfaTestTime)
+	enrollment, err := nativeScheme.EnrollMFA(context.TODO(), s.user, "123456")
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ConfirmMFA(context.TODO(), s.user, "123456", currentCode(c, enrollment.Secret))
+	c.Assert(err, check.IsNil)
+	setMFANow(mfaTestTime.Add(mfaPeriod * time.Second))
+	return []string{enrollment.Secret, enrollment.RecoveryCodes[0]}
+}
+
+func (s *S) TestTOTP(c *check.C) {
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+	}
+	for _, tt := range tests {
+		c.Check(totp(secret, tt.time/mfaPeriod), check.Equals, tt.code)
+	}
+}
+
+func (s *S) TestEnrollMFA(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	setMFANow(mfaTestTime)
+	enrollment, err := nativeScheme.EnrollMFA(context.TODO(), s.user, "123456")
+	c.Assert(err, check.IsNil)
+	c.Assert(enrollment.Secret, check.HasLen, 32)
+	c.Assert(enrollment.URL, check.Equals, "otpauth://totp/tsuru:timeredbull@globo.com?issuer=tsuru&secret="+enrollment.Secret)
+	c.Assert(enrollment.RecoveryCodes, check.HasLen, 10)
+	for _, code := range enrollment.RecoveryCodes {
+		c.Assert(code, check.Matches, `[0-9a-f]{5}-[0-9a-f]{5}`)
+	}
+	// the enrollment is not enforced until it's confirmed
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ConfirmMFA(context.TODO(), s.user, "123456", currentCode(c, enrollment.Secret))
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.Equals, auth.ErrMFARequired)
+}
+
+func (s *S) TestEnrollMFAWrongPassword(c *check.C) {
+	_, err := nativeScheme.EnrollMFA(context.TODO(), s.user, "1234567")
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+}
+
+func (s *S) TestEnrollMFAAlreadyEnabled(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	s.enableMFA(c)
+	_, err := nativeScheme.EnrollMFA(context.TODO(), s.user, "123456")
+	c.Assert(err, check.Equals, ErrMFAAlreadyEnabled)
+}
+
+func (s *S) TestConfirmMFAInvalidCode(c *check.C) {
+	_, err := nativeScheme.EnrollMFA(context.TODO(), s.user, "123456")
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ConfirmMFA(context.TODO(), s.user, "123456", "abcdef")
+	c.Assert(err, check.Equals, ErrInvalidMFACode)
+}
+
+func (s *S) TestConfirmMFAWithoutEnrollment(c *check.C) {
+	err := nativeScheme.ConfirmMFA(context.TODO(), s.user, "123456", "123456")
+	c.Assert(err, check.Equals, ErrMFANotEnrolled)
+}
+
+func (s *S) TestLoginWithMFA(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	secret := s.enableMFA(c)[0]
+	params := map[string]string{"email": s.user.Email, "password": "123456", "otp": "000000"}
+	_, err := nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.Equals, ErrInvalidMFACode)
+	params["otp"] = currentCode(c, secret)
+	token, err := nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.GetUserName(), check.Equals, s.user.Email)
+	// codes can't be reused
+	_, err = nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.Equals, ErrInvalidMFACode)
+	setMFANow(mfaTestTime.Add(2 * mfaPeriod * time.Second))
+	params["otp"] = currentCode(c, secret)
+	_, err = nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestLoginWithMFAWrongPassword(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	secret := s.enableMFA(c)[0]
+	params := map[string]string{"email": s.user.Email, "password": "1234567", "otp": currentCode(c, secret)}
+	_, err := nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	c.Assert(err, check.Not(check.Equals), ErrInvalidMFACode)
+}
+
+func (s *S) TestLoginWithRecoveryCode(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	recoveryCode := s.enableMFA(c)[1]
+	params := map[string]string{"email": s.user.Email, "password": "123456", "otp": recoveryCode}
+	_, err := nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(context.TODO(), params)
+	c.Assert(err, check.Equals, ErrInvalidMFACode)
+	cfg, err := getMFAConfig(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(cfg.RecoveryCodes, check.HasLen, 9)
+}
+
+func (s *S) TestDisableMFA(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	secret := s.enableMFA(c)[0]
+	err := nativeScheme.DisableMFA(context.TODO(), s.user, "123456", "000000")
+	c.Assert(err, check.Equals, ErrInvalidMFACode)
+	err = nativeScheme.DisableMFA(context.TODO(), s.user, "123456", currentCode(c, secret))
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.DisableMFA(context.TODO(), s.user, "123456", currentCode(c, secret))
+	c.Assert(err, check.Equals, ErrMFANotEnabled)
+}
+
+func (s *S) TestMFARequiredByRole(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	config.Set("auth:mfa:required-roles", []string{"contractor"})
+	defer config.Unset("auth:mfa")
+	_, err := permission.NewRole(context.TODO(), "contractor", "global", "")
+	c.Assert(err, check.IsNil)
+	err = s.user.AddRole(context.TODO(), "contractor", "")
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.Equals, ErrMFAEnrollmentRequired)
+	secret := s.enableMFA(c)[0]
+	err = nativeScheme.DisableMFA(context.TODO(), s.user, "123456", currentCode(c, secret))
+	c.Assert(err, check.Equals, ErrMFACannotBeDisabled)
+}
+
+func (s *S) TestMFARequiredByTeam(c *check.C) {
+	config.Set("auth:mfa:required-teams", []string{"contractors"})
+	defer config.Unset("auth:mfa")
+	role, err := permission.NewRole(context.TODO(), "team-member", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.read")
+	c.Assert(err, check.IsNil)
+	err = s.user.AddRole(context.TODO(), "team-member", "employees")
+	c.Assert(err, check.IsNil)
+	required, err := mfaRequired(context.TODO(), s.user)
+	c.Assert(err, check.IsNil)
+	c.Assert(required, check.Equals, false)
+	err = s.user.AddRole(context.TODO(), "team-member", "contractors")
+	c.Assert(err, check.IsNil)
+	required, err = mfaRequired(context.TODO(), s.user)
+	c.Assert(err, check.IsNil)
+	c.Assert(required, check.Equals, true)
+}
+
+func (s *S) TestRemoveUserRemovesMFA(c *check.C) {
+	defer func() { mfaNow = time.Now }()
+	s.enableMFA(c)
+	err := nativeScheme.Remove(context.TODO(), s.user)
+	c.Assert(err, check.IsNil)
+	cfg, err := getMFAConfig(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(cfg, check.IsNil)
+}
@@ -31,9 +31,11 @@ func init() {
 }
 
 var (
-	_ auth.Scheme        = &NativeScheme{}
-	_ auth.UserScheme    = &NativeScheme{}
-	_ auth.ManagedScheme = &NativeScheme{}
+	_ auth.Scheme         = &NativeScheme{}
+	_ auth.UserScheme     = &NativeScheme{}
+	_ auth.ManagedScheme  = &NativeScheme{}
+	_ auth.MFAScheme      = &NativeScheme{}
+	_ auth.LockableScheme = &NativeScheme{}
 )
 
 func (s NativeScheme) Login(ctx context.Context, params map[string]string) (auth.Token, error) {
@@ -49,7 +51,7 @@ func (s NativeScheme) Login(ctx context.Context, params map[string]string) (auth
 	if err != nil {
 		return nil, err
 	}
-	token, err := createToken(ctx, user, password)
+	token, err := createToken(ctx, user, password, params["otp"])
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +70,9 @@ func (s NativeScheme) Create(ctx context.Context, user *auth.User) (*auth.User,
 	if !validation.ValidateEmail(user.Email) {
 		return nil, ErrInvalidEmail
 	}
-	if !validation.ValidateLength(user.Password, passwordMinLen, passwordMaxLen) {
-		return nil, ErrInvalidPassword
+	policy := loadPasswordPolicy()
+	if err := policy.validate(user.Password); err != nil {
+		return nil, err
 	}
 	if _, err := auth.GetUserByEmail(ctx, user.Email); err == nil {
 		return nil, ErrEmailRegistered
@@ -80,6 +83,9 @@ func (s NativeScheme) Create(ctx context.Context, user *auth.User) (*auth.User,
 	if err := user.Create(ctx); err != nil {
 		return nil, err
 	}
+	if err := policy.recordPassword(ctx, user.Email, user.Password); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
@@ -91,12 +97,19 @@ func (s NativeScheme) ChangePassword(ctx context.Context, token auth.Token, oldP
 	if err = checkPassword(user.Password, oldPassword); err != nil {
 		return ErrPasswordMismatch
 	}
-	if !validation.ValidateLength(newPassword, passwordMinLen, passwordMaxLen) {
-		return ErrInvalidPassword
+	policy := loadPasswordPolicy()
+	if err = policy.validate(newPassword); err != nil {
+		return err
+	}
+	if err = policy.checkPasswordReuse(ctx, user.Email, user.Password, newPassword); err != nil {
+		return err
 	}
 	user.Password = newPassword
 	hashPassword(user)
-	return user.Update(ctx)
+	if err = user.Update(ctx); err != nil {
+		return err
+	}
+	return policy.recordPassword(ctx, user.Email, user.Password)
 }
 
 func (s NativeScheme) StartPasswordReset(ctx context.Context, user *auth.User) error {
@@ -126,7 +139,8 @@ func (s NativeScheme) ResetPassword(ctx context.Context, user *auth.User, resetT
 	if passToken.UserEmail != user.Email {
 		return auth.ErrInvalidToken
 	}
-	password := generatePassword(12)
+	policy := loadPasswordPolicy()
+	password := policy.generate()
 	user.Password = password
 	hashPassword(user)
 	go sendNewPassword(user, password)
@@ -135,7 +149,10 @@ func (s NativeScheme) ResetPassword(ctx context.Context, user *auth.User, resetT
 	if err != nil {
 		return err
 	}
-	return user.Update(ctx)
+	if err = user.Update(ctx); err != nil {
+		return err
+	}
+	return policy.recordPassword(ctx, user.Email, user.Password)
 }
 
 func (s NativeScheme) Remove(ctx context.Context, u *auth.User) error {
@@ -143,6 +160,18 @@ func (s NativeScheme) Remove(ctx context.Context, u *auth.User) error {
 	if err != nil {
 		return err
 	}
+	err = removeMFAConfig(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	err = removePasswordHistory(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	err = removeLoginAttempts(ctx, u.Email)
+	if err != nil {
+		return err
+	}
 	return u.Delete(ctx)
 }
 
@@ -0,0 +1,180 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/validation"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const generatedPasswordLen = 12
+
+// passwordPolicy holds the rules applied to new passwords, configured under
+// auth:password-policy. History is the number of previous passwords of a
+// user that can't be reused.
+type passwordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	History       int
+}
+
+type passwordHistory struct {
+	UserEmail string `bson:"_id"`
+	Hashes    []string
+}
+
+func loadPasswordPolicy() passwordPolicy {
+	policy := passwordPolicy{MinLength: passwordMinLen, MaxLength: passwordMaxLen}
+	if v, err := config.GetInt("auth:password-policy:min-length"); err == nil && v > 0 {
+		policy.MinLength = v
+	}
+	if v, err := config.GetInt("auth:password-policy:max-length"); err == nil && v >= policy.MinLength {
+		policy.MaxLength = v
+	}
+	policy.RequireUpper, _ = config.GetBool("auth:password-policy:require-uppercase")
+	policy.RequireLower, _ = config.GetBool("auth:password-policy:require-lowercase")
+	policy.RequireDigit, _ = config.GetBool("auth:password-policy:require-digit")
+	policy.RequireSymbol, _ = config.GetBool("auth:password-policy:require-symbol")
+	if v, err := config.GetInt("auth:password-policy:history"); err == nil && v > 0 {
+		policy.History = v
+	}
+	return policy
+}
+
+func (p passwordPolicy) validate(password string) error {
+	if !validation.ValidateLength(password, p.MinLength, p.MaxLength) {
+		if p.MinLength == passwordMinLen && p.MaxLength == passwordMaxLen {
+			return ErrInvalidPassword
+		}
+		return &errors.ValidationError{
+			Message: fmt.Sprintf("password length should be least %d characters and at most %d characters", p.MinLength, p.MaxLength),
+		}
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return &errors.ValidationError{Message: "password must contain " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// checkPasswordReuse returns an error if the password matches the current
+// password of the user or one of the passwords in its history.
+func (p passwordPolicy) checkPasswordReuse(ctx context.Context, email, currentHash, password string) error {
+	if p.History == 0 {
+		return nil
+	}
+	history, err := getPasswordHistory(ctx, email)
+	if err != nil {
+		return err
+	}
+	hashes := history.Hashes
+	if len(hashes) > p.History {
+		hashes = hashes[len(hashes)-p.History:]
+	}
+	for _, hash := range append(hashes, currentHash) {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return &errors.ValidationError{
+				Message: fmt.Sprintf("password must not be one of the last %d passwords", p.History),
+			}
+		}
+	}
+	return nil
+}
+
+// recordPassword adds the hash to the password history of the user, keeping
+// only the number of entries required by the policy.
+func (p passwordPolicy) recordPassword(ctx context.Context, email, hash string) error {
+	if p.History == 0 {
+		return nil
+	}
+	collection, err := storagev2.PasswordHistoryCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": email}, mongoBSON.M{
+		"$push": mongoBSON.M{"hashes": mongoBSON.M{"$each": []string{hash}, "$slice": -p.History}},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// generate returns a random password complying with the policy, it's used
+// when resetting passwords.
+func (p passwordPolicy) generate() string {
+	length := generatedPasswordLen
+	if length < p.MinLength {
+		length = p.MinLength
+	}
+	if length > p.MaxLength {
+		length = p.MaxLength
+	}
+	for {
+		password := generatePassword(length)
+		if p.validate(password) == nil {
+			return password
+		}
+	}
+}
+
+func getPasswordHistory(ctx context.Context, email string) (*passwordHistory, error) {
+	collection, err := storagev2.PasswordHistoryCollection()
+	if err != nil {
+		return nil, err
+	}
+	var history passwordHistory
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": email}).Decode(&history)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	return &history, nil
+}
+
+func removePasswordHistory(ctx context.Context, email string) error {
+	collection, err := storagev2.PasswordHistoryCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"_id": email})
+	return err
+}
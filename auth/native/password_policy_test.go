@@ -0,0 +1,86 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPasswordPolicyDefaults(c *check.C) {
+	policy := loadPasswordPolicy()
+	c.Assert(policy, check.DeepEquals, passwordPolicy{MinLength: passwordMinLen, MaxLength: passwordMaxLen})
+	c.Assert(policy.validate("12345"), check.Equals, ErrInvalidPassword)
+	c.Assert(policy.validate("123456"), check.IsNil)
+}
+
+func (s *S) TestPasswordPolicyValidate(c *check.C) {
+	config.Set("auth:password-policy:min-length", 10)
+	config.Set("auth:password-policy:require-uppercase", true)
+	config.Set("auth:password-policy:require-digit", true)
+	config.Set("auth:password-policy:require-symbol", true)
+	defer config.Unset("auth:password-policy")
+	policy := loadPasswordPolicy()
+	tests := []struct {
+		password string
+		message  string
+	}{
+		{password: "Abc1!", message: "password length should be least 10 characters and at most 50 characters"},
+		{password: "abcdefghij", message: "password must contain an uppercase letter, a digit, a symbol"},
+		{password: "Abcdefghi1", message: "password must contain a symbol"},
+		{password: "Abcdefgh1!"},
+	}
+	for _, tt := range tests {
+		err := policy.validate(tt.password)
+		if tt.message == "" {
+			c.Check(err, check.IsNil)
+			continue
+		}
+		c.Check(err, check.DeepEquals, &errors.ValidationError{Message: tt.message})
+	}
+}
+
+func (s *S) TestPasswordPolicyGenerate(c *check.C) {
+	config.Set("auth:password-policy:min-length", 16)
+	config.Set("auth:password-policy:require-uppercase", true)
+	config.Set("auth:password-policy:require-digit", true)
+	defer config.Unset("auth:password-policy")
+	policy := loadPasswordPolicy()
+	password := policy.generate()
+	c.Assert(password, check.HasLen, 16)
+	c.Assert(policy.validate(password), check.IsNil)
+}
+
+func (s *S) TestCreateEnforcesPasswordPolicy(c *check.C) {
+	config.Set("auth:password-policy:require-digit", true)
+	defer config.Unset("auth:password-policy")
+	_, err := nativeScheme.Create(context.TODO(), &auth.User{Email: "x@x.com", Password: "abcdefg"})
+	c.Assert(err, check.DeepEquals, &errors.ValidationError{Message: "password must contain a digit"})
+}
+
+func (s *S) TestChangePasswordHistory(c *check.C) {
+	config.Set("auth:password-policy:history", 2)
+	defer config.Unset("auth:password-policy")
+	err := nativeScheme.ChangePassword(context.TODO(), s.token, "123456", "1234567")
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ChangePassword(context.TODO(), s.token, "1234567", "123456")
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ChangePassword(context.TODO(), s.token, "123456", "1234567")
+	c.Assert(err, check.DeepEquals, &errors.ValidationError{Message: "password must not be one of the last 2 passwords"})
+	err = nativeScheme.ChangePassword(context.TODO(), s.token, "123456", "12345678")
+	c.Assert(err, check.IsNil)
+	history, err := getPasswordHistory(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(history.Hashes, check.HasLen, 2)
+	err = nativeScheme.Remove(context.TODO(), s.user)
+	c.Assert(err, check.IsNil)
+	history, err = getPasswordHistory(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(history.Hashes, check.HasLen, 0)
+}
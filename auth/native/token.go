@@ -161,7 +161,9 @@ func removeOldTokens(ctx context.Context, userEmail string) error {
 }
 
 func checkPassword(passwordHash string, password string) error {
-	if !validation.ValidateLength(password, passwordMinLen, passwordMaxLen) {
+	// passwords created under a different policy must still be accepted
+	policy := loadPasswordPolicy()
+	if !validation.ValidateLength(password, min(passwordMinLen, policy.MinLength), max(passwordMaxLen, policy.MaxLength)) {
 		return &tsuruErrors.ValidationError{Message: passwordError}
 	}
 	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) == nil {
@@ -170,11 +172,20 @@ func checkPassword(passwordHash string, password string) error {
 	return auth.AuthenticationFailure{Message: "Authentication failed, wrong password."}
 }
 
-func createToken(ctx context.Context, u *auth.User, password string) (*Token, error) {
+func createToken(ctx context.Context, u *auth.User, password, otp string) (*Token, error) {
 	if u.Email == "" {
 		return nil, errors.New("User does not have an email")
 	}
-	if err := checkPassword(u.Password, password); err != nil {
+	if err := authenticate(ctx, u, password); err != nil {
+		return nil, err
+	}
+	if err := checkMFA(ctx, u, otp); err != nil {
+		if _, ok := err.(auth.AuthenticationFailure); ok {
+			registerLoginFailure(ctx, u.Email)
+		}
+		return nil, err
+	}
+	if err := resetLoginFailures(ctx, u.Email); err != nil {
 		return nil, err
 	}
 	collection, err := storagev2.TokensCollection()
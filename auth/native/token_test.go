@@ -221,7 +221,7 @@ func (s *S) TestCreateTokenShouldSaveTheTokenInTheDatabase(c *check.C) {
 	_, err = nativeScheme.Create(ctx, &u)
 	c.Assert(err, check.IsNil)
 	defer u.Delete(context.TODO())
-	_, err = createToken(ctx, &u, "123456")
+	_, err = createToken(ctx, &u, "123456", "")
 	c.Assert(err, check.IsNil)
 	var result Token
 	err = tokensCollection.FindOne(ctx, mongoBSON.M{"useremail": u.Email}).Decode(&result)
@@ -246,7 +246,7 @@ func (s *S) TestCreateTokenRemoveOldTokens(c *check.C) {
 	t2.Token += "aa"
 	_, err = tokensCollection.InsertMany(ctx, []any{t1, t2})
 	c.Assert(err, check.IsNil)
-	_, err = createToken(ctx, &u, "123456")
+	_, err = createToken(ctx, &u, "123456", "")
 	c.Assert(err, check.IsNil)
 	ok := make(chan bool, 1)
 	go func() {
@@ -278,14 +278,14 @@ func (s *S) TestCreateTokenUsesDefaultCostWhenHasCostIsUndefined(c *check.C) {
 	defer u.Delete(context.TODO())
 	cost = 0
 	tokenExpire = 0
-	_, err = createToken(ctx, &u, "123456")
+	_, err = createToken(ctx, &u, "123456", "")
 	c.Assert(err, check.IsNil)
 }
 
 func (s *S) TestCreateTokenShouldReturnErrorIfTheProvidedUserDoesNotHaveEmailDefined(c *check.C) {
 	ctx := context.TODO()
 	u := auth.User{Password: "123"}
-	_, err := createToken(ctx, &u, "123")
+	_, err := createToken(ctx, &u, "123", "")
 	c.Assert(err, check.NotNil)
 	c.Assert(err, check.ErrorMatches, "^User does not have an email$")
 }
@@ -296,7 +296,7 @@ func (s *S) TestCreateTokenShouldValidateThePassword(c *check.C) {
 	_, err := nativeScheme.Create(context.TODO(), &u)
 	c.Assert(err, check.IsNil)
 	defer u.Delete(context.TODO())
-	_, err = createToken(ctx, &u, "123")
+	_, err = createToken(ctx, &u, "123", "")
 	c.Assert(err, check.NotNil)
 }
 
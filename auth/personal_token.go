@@ -0,0 +1,376 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/validation"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultPersonalTokenExpiry    = 30 * 24 * time.Hour
+	defaultPersonalTokenMaxExpiry = 365 * 24 * time.Hour
+)
+
+// personalToken is the stored form of a personal token, the token value is
+// never stored, only its SHA-256 hash.
+type personalToken struct {
+	authTypes.PersonalToken `bson:",inline"`
+	TokenHash               string
+}
+
+var _ authTypes.Token = &personalToken{}
+
+func (t *personalToken) GetValue() string {
+	return t.Token
+}
+
+func (t *personalToken) User(ctx context.Context) (*authTypes.User, error) {
+	return ConvertOldUser(GetUserByEmail(ctx, t.UserEmail))
+}
+
+func (t *personalToken) GetUserName() string {
+	return t.UserEmail
+}
+
+func (t *personalToken) Engine() string {
+	return "personal"
+}
+
+func (t *personalToken) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
+	perms, err := BaseTokenPermission(ctx, t)
+	if err != nil || len(t.Scopes) == 0 {
+		return perms, err
+	}
+	return scopePermissions(ctx, perms, t.Scopes)
+}
+
+// personalTokenExpiry returns the expiry of new personal tokens, configured
+// in auth:personal-token:default-expiry, and the maximum expiry accepted,
+// configured in auth:personal-token:max-expiry.
+func personalTokenExpiry() (time.Duration, time.Duration) {
+	expiry, err := config.GetDuration("auth:personal-token:default-expiry")
+	if err != nil || expiry <= 0 {
+		expiry = defaultPersonalTokenExpiry
+	}
+	maxExpiry, err := config.GetDuration("auth:personal-token:max-expiry")
+	if err != nil || maxExpiry <= 0 {
+		maxExpiry = defaultPersonalTokenMaxExpiry
+	}
+	if expiry > maxExpiry {
+		expiry = maxExpiry
+	}
+	return expiry, maxExpiry
+}
+
+// ParsePersonalTokenScope parses a scope in the format
+// <permission>[:<context type>:<context value>], like app.deploy:app:myapp.
+func ParsePersonalTokenScope(value string) (authTypes.PersonalTokenScope, error) {
+	parts := strings.SplitN(value, ":", 3)
+	scope := authTypes.PersonalTokenScope{Permission: parts[0]}
+	if scope.Permission == "" {
+		return scope, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid scope %q: permission is required", value)}
+	}
+	scheme, err := permission.SafeGet(scope.Permission)
+	if err != nil {
+		return scope, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid scope %q: %v", value, err)}
+	}
+	if len(parts) == 1 {
+		return scope, nil
+	}
+	if len(parts) != 3 || parts[2] == "" {
+		return scope, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid scope %q: context value is required", value)}
+	}
+	ctxType, err := permission.ParseContext(parts[1])
+	if err != nil {
+		return scope, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid scope %q: %v", value, err)}
+	}
+	allowed := false
+	for _, t := range scheme.AllowedContexts() {
+		if t == ctxType && t != permTypes.CtxGlobal {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return scope, &tsuruErrors.ValidationError{
+			Message: fmt.Sprintf("invalid scope %q: permission %q can't be used in context %q", value, scope.Permission, ctxType),
+		}
+	}
+	scope.ContextType = string(ctxType)
+	scope.ContextValue = parts[2]
+	return scope, nil
+}
+
+// CreatePersonalToken creates a token for the user. Each scope must be
+// covered by the current permissions of the user and by the permissions of
+// the token creating it, so a scoped personal token can't be used to create
+// a wider token. Unscoped tokens carry every permission of the user, so the
+// creating token must hold all of them.
+func CreatePersonalToken(ctx context.Context, u *User, args authTypes.PersonalTokenCreateArgs, caller authTypes.Token) (*authTypes.PersonalToken, error) {
+	expiry, maxExpiry := personalTokenExpiry()
+	if args.ExpiresIn < 0 {
+		return nil, &tsuruErrors.ValidationError{Message: "expires_in must be positive"}
+	}
+	if args.ExpiresIn > 0 {
+		expiry = time.Duration(args.ExpiresIn) * time.Second
+	}
+	if expiry > maxExpiry {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("expires_in must be at most %d seconds", int(maxExpiry.Seconds()))}
+	}
+	if pt, ok := caller.(*personalToken); ok && len(pt.Scopes) > 0 && len(args.Scopes) == 0 {
+		return nil, &tsuruErrors.ValidationError{Message: "a scoped personal token can only create scoped tokens"}
+	}
+	userPerms, err := u.Permissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	callerPerms, err := caller.Permissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(args.Scopes) == 0 {
+		covered, err := coversPermissions(ctx, callerPerms, userPerms)
+		if err != nil {
+			return nil, err
+		}
+		if !covered {
+			return nil, &tsuruErrors.ValidationError{Message: "token doesn't have all the permissions of the user, only scoped tokens can be created"}
+		}
+	}
+	var scopes []authTypes.PersonalTokenScope
+	for _, value := range args.Scopes {
+		scope, err := ParsePersonalTokenScope(value)
+		if err != nil {
+			return nil, err
+		}
+		granted, err := scopePermissions(ctx, userPerms, []authTypes.PersonalTokenScope{scope})
+		if err != nil {
+			return nil, err
+		}
+		if len(granted) == 0 {
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("user doesn't have the permissions in scope %q", value)}
+		}
+		covered, err := coversPermissions(ctx, callerPerms, granted)
+		if err != nil {
+			return nil, err
+		}
+		if !covered {
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("token doesn't have the permissions in scope %q", value)}
+		}
+		scopes = append(scopes, scope)
+	}
+	now := time.Now().UTC()
+	t := personalToken{
+		PersonalToken: authTypes.PersonalToken{
+			Token:       generateToken(u.Email, crypto.SHA256),
+			TokenID:     args.TokenID,
+			Description: args.Description,
+			UserEmail:   u.Email,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(expiry),
+			Scopes:      scopes,
+		},
+	}
+	t.TokenHash = hashPersonalToken(t.Token)
+	if t.TokenID == "" {
+		t.TokenID = "token-" + t.TokenHash[:8]
+	}
+	if !validation.ValidateName(t.TokenID) {
+		return nil, &tsuruErrors.ValidationError{Message: "invalid token_id"}
+	}
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	_, err = collection.InsertOne(ctx, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, authTypes.ErrPersonalTokenAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t.PersonalToken, nil
+}
+
+// ListPersonalTokens returns the tokens of the user, without their values.
+func ListPersonalTokens(ctx context.Context, email string) ([]authTypes.PersonalToken, error) {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"useremail": email})
+	if err != nil {
+		return nil, err
+	}
+	var stored []personalToken
+	if err = cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	tokens := make([]authTypes.PersonalToken, len(stored))
+	for i := range stored {
+		tokens[i] = stored[i].PersonalToken
+	}
+	return tokens, nil
+}
+
+func RevokePersonalToken(ctx context.Context, email, tokenID string) error {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"useremail": email, "tokenid": tokenID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return authTypes.ErrPersonalTokenNotFound
+	}
+	return nil
+}
+
+func PersonalTokenAuth(ctx context.Context, header string) (authTypes.Token, error) {
+	value, err := ParseToken(header)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashPersonalToken(value)
+	var t personalToken
+	err = collection.FindOne(ctx, mongoBSON.M{"tokenhash": hash}).Decode(&t)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.ExpiresAt.Before(time.Now()) {
+		return nil, authTypes.ErrPersonalTokenExpired
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"tokenhash": hash}, mongoBSON.M{
+		"$set": mongoBSON.M{"lastaccess": time.Now().UTC()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.Token = value
+	return &t, nil
+}
+
+func removePersonalTokens(ctx context.Context, email string) error {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"useremail": email})
+	return err
+}
+
+func hashPersonalToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// scopePermissions returns the intersection of the permissions of a user
+// with the scopes of a token. Scopes with a context only match permissions
+// of the user covering that context, for apps that includes the permissions
+// in the teams and in the pool of the app.
+func scopePermissions(ctx context.Context, userPerms []permTypes.Permission, scopes []authTypes.PersonalTokenScope) ([]permTypes.Permission, error) {
+	var perms []permTypes.Permission
+	for _, scope := range scopes {
+		scheme, err := permission.SafeGet(scope.Permission)
+		if err != nil {
+			// permissions removed after the token was created grant nothing
+			continue
+		}
+		var covering []permTypes.PermissionContext
+		if scope.ContextType != "" {
+			covering, err = coveringContexts(ctx, permission.Context(permTypes.ContextType(scope.ContextType), scope.ContextValue))
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range userPerms {
+			var granted *permTypes.PermissionScheme
+			switch {
+			case p.Scheme.IsParent(scheme):
+				granted = scheme
+			case scheme.IsParent(p.Scheme):
+				granted = p.Scheme
+			default:
+				continue
+			}
+			if covering == nil {
+				perms = append(perms, permTypes.Permission{Scheme: granted, Context: p.Context})
+				continue
+			}
+			if p.Context.CtxType == permTypes.CtxGlobal || containsContext(covering, p.Context) {
+				perms = append(perms, permTypes.Permission{Scheme: granted, Context: covering[0]})
+			}
+		}
+	}
+	return perms, nil
+}
+
+// coversPermissions reports whether every permission in perms is granted by
+// the permissions in granted.
+func coversPermissions(ctx context.Context, granted, perms []permTypes.Permission) (bool, error) {
+	for _, p := range perms {
+		covering, err := coveringContexts(ctx, p.Context)
+		if err != nil {
+			return false, err
+		}
+		if !permission.CheckFromPermList(granted, p.Scheme, covering...) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// coveringContexts returns the context along with the contexts whose
+// permissions also apply to it.
+func coveringContexts(ctx context.Context, c permTypes.PermissionContext) ([]permTypes.PermissionContext, error) {
+	contexts := []permTypes.PermissionContext{c}
+	if c.CtxType != permTypes.CtxApp {
+		return contexts, nil
+	}
+	a, err := servicemanager.App.GetByName(ctx, c.Value)
+	if err == appTypes.ErrAppNotFound {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	contexts = append(contexts, permission.Contexts(permTypes.CtxTeam, a.Teams)...)
+	return append(contexts, permission.Context(permTypes.CtxPool, a.Pool)), nil
+}
+
+func containsContext(contexts []permTypes.PermissionContext, c permTypes.PermissionContext) bool {
+	for _, other := range contexts {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) addTeamDeployer(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "team-deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.read", "app.deploy")
+	c.Assert(err, check.IsNil)
+	err = s.user.AddRole(context.TODO(), "team-deployer", s.team.Name)
+	c.Assert(err, check.IsNil)
+	servicemanager.App = &appTypes.MockAppService{
+		Apps: []*appTypes.App{
+			{Name: "myapp", Teams: []string{s.team.Name}, Pool: "pool1"},
+			{Name: "otherapp", Teams: []string{"otherteam"}, Pool: "pool1"},
+		},
+	}
+}
+
+// callerToken returns a token with the current permissions of s.user.
+func (s *S) callerToken(c *check.C) *userToken {
+	perms, err := s.user.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	return &userToken{user: s.user, permissions: perms}
+}
+
+func (s *S) TestParsePersonalTokenScope(c *check.C) {
+	tests := []struct {
+		value    string
+		expected authTypes.PersonalTokenScope
+		err      string
+	}{
+		{value: "app.deploy", expected: authTypes.PersonalTokenScope{Permission: "app.deploy"}},
+		{value: "app.deploy:app:myapp", expected: authTypes.PersonalTokenScope{Permission: "app.deploy", ContextType: "app", ContextValue: "myapp"}},
+		{value: "", err: `invalid scope "": permission is required`},
+		{value: "app.fly", err: `invalid scope "app.fly": unregistered permission`},
+		{value: "app.deploy:app", err: `invalid scope "app.deploy:app": context value is required`},
+		{value: "app.deploy:planet:earth", err: `invalid scope "app.deploy:planet:earth": invalid context type "planet"`},
+		{value: "app.deploy:user:me@tsuru.io", err: `invalid scope "app.deploy:user:me@tsuru.io": permission "app.deploy" can't be used in context "user"`},
+	}
+	for _, tt := range tests {
+		scope, err := ParsePersonalTokenScope(tt.value)
+		if tt.err != "" {
+			c.Check(err, check.ErrorMatches, tt.err)
+			continue
+		}
+		c.Check(err, check.IsNil)
+		c.Check(scope, check.DeepEquals, tt.expected)
+	}
+}
+
+func (s *S) TestCreatePersonalToken(c *check.C) {
+	token, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{TokenID: "ci", Description: "ci pipeline"}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Not(check.Equals), "")
+	c.Assert(token.TokenID, check.Equals, "ci")
+	c.Assert(token.UserEmail, check.Equals, s.user.Email)
+	c.Assert(token.ExpiresAt.Sub(token.CreatedAt), check.Equals, 30*24*time.Hour)
+	tokens, err := ListPersonalTokens(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].TokenID, check.Equals, "ci")
+	c.Assert(tokens[0].Token, check.Equals, "")
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{TokenID: "ci"}, s.callerToken(c))
+	c.Assert(err, check.Equals, authTypes.ErrPersonalTokenAlreadyExists)
+}
+
+func (s *S) TestCreatePersonalTokenExpiry(c *check.C) {
+	config.Set("auth:personal-token:max-expiry", "48h")
+	defer config.Unset("auth:personal-token")
+	token, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	c.Assert(token.TokenID, check.Matches, "token-[0-9a-f]{8}")
+	c.Assert(token.ExpiresAt.Sub(token.CreatedAt), check.Equals, 48*time.Hour)
+	token, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{ExpiresIn: 3600}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	c.Assert(token.ExpiresAt.Sub(token.CreatedAt), check.Equals, time.Hour)
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{ExpiresIn: 3 * 86400}, s.callerToken(c))
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "expires_in must be at most 172800 seconds"})
+}
+
+func (s *S) TestCreatePersonalTokenScopeNotGranted(c *check.C) {
+	s.addTeamDeployer(c)
+	_, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app.deploy:app:otherapp"}}, s.callerToken(c))
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: `user doesn't have the permissions in scope "app.deploy:app:otherapp"`})
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app.deploy:app:myapp"}}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCreatePersonalTokenFromScopedToken(c *check.C) {
+	s.addTeamDeployer(c)
+	created, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app.deploy:app:myapp"}}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	caller, err := PersonalTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.IsNil)
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{}, caller)
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "a scoped personal token can only create scoped tokens"})
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app.deploy"}}, caller)
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: `token doesn't have the permissions in scope "app.deploy"`})
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app:app:myapp"}}, caller)
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: `token doesn't have the permissions in scope "app:app:myapp"`})
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{Scopes: []string{"app.deploy:app:myapp"}}, caller)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCreatePersonalTokenUnscopedRequiresUserPermissions(c *check.C) {
+	s.addTeamDeployer(c)
+	caller := &userToken{user: s.user, permissions: []permTypes.Permission{
+		{Scheme: permission.PermUserTokenCreate, Context: permission.Context(permTypes.CtxGlobal, "")},
+	}}
+	_, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{}, caller)
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "token doesn't have all the permissions of the user, only scoped tokens can be created"})
+	_, err = CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPersonalTokenAuth(c *check.C) {
+	created, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{TokenID: "ci"}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	t, err := PersonalTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.GetValue(), check.Equals, created.Token)
+	c.Assert(t.GetUserName(), check.Equals, s.user.Email)
+	c.Assert(t.Engine(), check.Equals, "personal")
+	tokens, err := ListPersonalTokens(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens[0].LastAccess.IsZero(), check.Equals, false)
+	_, err = PersonalTokenAuth(context.TODO(), "bearer invalid")
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestPersonalTokenAuthExpired(c *check.C) {
+	created, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{ExpiresIn: 1}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.PersonalTokensCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"tokenid": created.TokenID}, mongoBSON.M{
+		"$set": mongoBSON.M{"expiresat": time.Now().Add(-time.Minute)},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = PersonalTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.Equals, authTypes.ErrPersonalTokenExpired)
+}
+
+func (s *S) TestRevokePersonalToken(c *check.C) {
+	created, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{TokenID: "ci"}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	err = RevokePersonalToken(context.TODO(), s.user.Email, "ci")
+	c.Assert(err, check.IsNil)
+	_, err = PersonalTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	err = RevokePersonalToken(context.TODO(), s.user.Email, "ci")
+	c.Assert(err, check.Equals, authTypes.ErrPersonalTokenNotFound)
+}
+
+func (s *S) TestPersonalTokenPermissions(c *check.C) {
+	s.addTeamDeployer(c)
+	created, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{
+		Scopes: []string{"app.deploy:app:myapp"},
+	}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	t, err := PersonalTokenAuth(context.TODO(), "bearer "+created.Token)
+	c.Assert(err, check.IsNil)
+	perms, err := t.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(perms, check.DeepEquals, []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxApp, "myapp")},
+	})
+	c.Assert(permission.Check(context.TODO(), t, permission.PermAppDeploy, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, true)
+	c.Assert(permission.Check(context.TODO(), t, permission.PermAppRead, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, false)
+	c.Assert(permission.Check(context.TODO(), t, permission.PermAppDeploy, permission.Context(permTypes.CtxTeam, s.team.Name)), check.Equals, false)
+	dynamic, err := BaseTokenDynamicPermission(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	c.Assert(dynamic, check.HasLen, 0)
+}
+
+func (s *S) TestPersonalTokenPermissionsParentScope(c *check.C) {
+	s.addTeamDeployer(c)
+	token := &personalToken{PersonalToken: authTypes.PersonalToken{
+		UserEmail: s.user.Email,
+		Scopes:    []authTypes.PersonalTokenScope{{Permission: "app"}},
+	}}
+	perms, err := token.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(perms, check.HasLen, 2)
+	for _, p := range perms {
+		c.Assert(p.Context, check.Equals, permission.Context(permTypes.CtxTeam, s.team.Name))
+		c.Assert(p.Scheme == permission.PermAppDeploy || p.Scheme == permission.PermAppRead, check.Equals, true)
+	}
+}
+
+func (s *S) TestDeleteUserRemovesPersonalTokens(c *check.C) {
+	_, err := CreatePersonalToken(context.TODO(), s.user, authTypes.PersonalTokenCreateArgs{TokenID: "ci"}, s.callerToken(c))
+	c.Assert(err, check.IsNil)
+	err = s.user.Delete(context.TODO())
+	c.Assert(err, check.IsNil)
+	tokens, err := ListPersonalTokens(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rotation periodically rotates the team tokens configured with
+// automatic rotation and reports, through events, the team tokens about to
+// expire.
+package rotation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultCheckInterval = 10 * time.Minute
+	defaultExpiryWarning = 7 * 24 * time.Hour
+
+	RotateEventKind   = "team-token.rotate"
+	ExpiringEventKind = "team-token.expiring"
+)
+
+func Initialize() error {
+	r := &rotator{once: &sync.Once{}}
+	r.start()
+	shutdown.Register(r)
+	return nil
+}
+
+type rotator struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (r *rotator) start() {
+	r.once.Do(func() {
+		r.stopCh = make(chan struct{})
+		go r.spin()
+	})
+}
+
+func (r *rotator) Shutdown(ctx context.Context) error {
+	if r.stopCh == nil {
+		return nil
+	}
+	r.stopCh <- struct{}{}
+	r.stopCh = nil
+	r.once = &sync.Once{}
+	return nil
+}
+
+func (r *rotator) spin() {
+	for {
+		err := Check(context.Background(), time.Now())
+		if err != nil {
+			log.Errorf("[team-token-rotation] unable to check team tokens: %v", err)
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(CheckInterval()):
+		}
+	}
+}
+
+// CheckInterval returns the time between checks of the team tokens,
+// configured in auth:team-token:check-interval.
+func CheckInterval() time.Duration {
+	interval, err := config.GetDuration("auth:team-token:check-interval")
+	if err != nil || interval <= 0 {
+		return defaultCheckInterval
+	}
+	return interval
+}
+
+// ExpiryWarning returns how long before its expiration a team token is
+// reported as expiring, configured in auth:team-token:expiry-warning.
+func ExpiryWarning() time.Duration {
+	warning, err := config.GetDuration("auth:team-token:expiry-warning")
+	if err != nil || warning <= 0 {
+		return defaultExpiryWarning
+	}
+	return warning
+}
+
+// Check rotates the team tokens due for an automatic rotation and creates an
+// event for each token expiring within the expiry warning, once for each
+// expiration of the token.
+func Check(ctx context.Context, now time.Time) error {
+	tokens, err := servicemanager.TeamToken.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for _, t := range tokens {
+		if rotationDue(t, now) {
+			if err = rotate(ctx, t, now); err != nil {
+				multi.Add(errors.Wrapf(err, "unable to rotate team token %q", t.TokenID))
+			}
+		}
+		if expiring(t, now) {
+			if err = notifyExpiring(ctx, t); err != nil {
+				multi.Add(errors.Wrapf(err, "unable to report expiration of team token %q", t.TokenID))
+			}
+		}
+	}
+	return multi.ToError()
+}
+
+func rotationDue(t authTypes.TeamToken, now time.Time) bool {
+	if t.RotationInterval <= 0 {
+		return false
+	}
+	last := t.RotatedAt
+	if last.IsZero() {
+		last = t.CreatedAt
+	}
+	return !now.Before(last.Add(time.Duration(t.RotationInterval) * time.Second))
+}
+
+func expiring(t authTypes.TeamToken, now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && t.ExpiresAt.After(now) && t.ExpiresAt.Sub(now) <= ExpiryWarning()
+}
+
+// rotate holds an event locking the token while rotating it, so only one
+// API instance rotates each token.
+func rotate(ctx context.Context, t authTypes.TeamToken, now time.Time) error {
+	evt, err := event.NewInternal(ctx, eventOpts(t, RotateEventKind, nil))
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	current, err := servicemanager.TeamToken.FindByTokenID(ctx, t.TokenID)
+	if err != nil {
+		evt.Done(ctx, err)
+		return err
+	}
+	if !rotationDue(current, now) {
+		// rotated by another API instance
+		return evt.Abort(ctx)
+	}
+	_, err = servicemanager.TeamToken.Rotate(ctx, t.TokenID, auth.TeamTokenGracePeriod())
+	evt.Done(ctx, err)
+	return err
+}
+
+func notifyExpiring(ctx context.Context, t authTypes.TeamToken) error {
+	evts, err := event.List(ctx, &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeTeamToken, Value: t.TokenID},
+		KindNames: []string{ExpiringEventKind},
+		Raw:       mongoBSON.M{"startcustomdata.expiresAt": t.ExpiresAt},
+		Limit:     1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(evts) > 0 {
+		return nil
+	}
+	evt, err := event.NewInternal(ctx, eventOpts(t, ExpiringEventKind, map[string]interface{}{
+		"team":      t.Team,
+		"expiresAt": t.ExpiresAt,
+	}))
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	return evt.Done(ctx, nil)
+}
+
+func eventOpts(t authTypes.TeamToken, kind string, customData interface{}) *event.Opts {
+	return &event.Opts{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeamToken, Value: t.TokenID},
+		ExtraTargets: []eventTypes.ExtraTarget{
+			{Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: t.Team}},
+		},
+		InternalKind: kind,
+		CustomData:   customData,
+		Allowed:      event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, t.Team)),
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rotation
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) tokenEvents(c *check.C, tokenID, kind string) []*event.Event {
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeTeamToken, Value: tokenID},
+		KindNames: []string{kind},
+	})
+	c.Assert(err, check.IsNil)
+	return evts
+}
+
+func (s *S) TestCheckInterval(c *check.C) {
+	c.Assert(CheckInterval(), check.Equals, 10*time.Minute)
+	config.Set("auth:team-token:check-interval", "1m")
+	c.Assert(CheckInterval(), check.Equals, time.Minute)
+}
+
+func (s *S) TestRotationDue(c *check.C) {
+	now := time.Now()
+	c.Assert(rotationDue(authTypes.TeamToken{CreatedAt: now.Add(-2 * time.Hour)}, now), check.Equals, false)
+	t := authTypes.TeamToken{CreatedAt: now.Add(-2 * time.Hour), RotationInterval: 60 * 60}
+	c.Assert(rotationDue(t, now), check.Equals, true)
+	t.RotatedAt = now.Add(-time.Minute)
+	c.Assert(rotationDue(t, now), check.Equals, false)
+}
+
+func (s *S) TestCheckRotatesTokens(c *check.C) {
+	config.Set("auth:team-token:grace-period", "1h")
+	now := time.Now().UTC()
+	err := s.storage.Insert(context.TODO(), authTypes.TeamToken{
+		Token:            "old-value",
+		TokenID:          "t1",
+		Team:             "team1",
+		CreatedAt:        now.Add(-2 * time.Hour),
+		RotationInterval: 60 * 60,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.storage.Insert(context.TODO(), authTypes.TeamToken{
+		Token:     "manual",
+		TokenID:   "t2",
+		Team:      "team1",
+		CreatedAt: now.Add(-2 * time.Hour),
+	})
+	c.Assert(err, check.IsNil)
+	err = Check(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	t1, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "t1")
+	c.Assert(err, check.IsNil)
+	c.Assert(t1.Token, check.Not(check.Equals), "old-value")
+	c.Assert(t1.PreviousToken, check.Equals, "old-value")
+	c.Assert(t1.RotatedAt.IsZero(), check.Equals, false)
+	t2, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "t2")
+	c.Assert(err, check.IsNil)
+	c.Assert(t2.Token, check.Equals, "manual")
+	evts := s.tokenEvents(c, "t1", RotateEventKind)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, "")
+	// the next rotation is only due after the interval
+	err = Check(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	again, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "t1")
+	c.Assert(err, check.IsNil)
+	c.Assert(again.Token, check.Equals, t1.Token)
+}
+
+func (s *S) TestCheckNotifiesExpiringTokens(c *check.C) {
+	config.Set("auth:team-token:expiry-warning", "24h")
+	now := time.Now().UTC()
+	for id, expiresAt := range map[string]time.Time{
+		"expiring": now.Add(time.Hour),
+		"later":    now.Add(48 * time.Hour),
+		"expired":  now.Add(-time.Hour),
+	} {
+		err := s.storage.Insert(context.TODO(), authTypes.TeamToken{
+			Token:     "value-" + id,
+			TokenID:   id,
+			Team:      "team1",
+			CreatedAt: now.Add(-time.Hour),
+			ExpiresAt: expiresAt,
+		})
+		c.Assert(err, check.IsNil)
+	}
+	err := Check(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	err = Check(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	evts := s.tokenEvents(c, "expiring", ExpiringEventKind)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].ExtraTargets, check.DeepEquals, []eventTypes.ExtraTarget{
+		{Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: "team1"}},
+	})
+	c.Assert(s.tokenEvents(c, "later", ExpiringEventKind), check.HasLen, 0)
+	c.Assert(s.tokenEvents(c, "expired", ExpiringEventKind), check.HasLen, 0)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rotation
+
+import (
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	trackerTypes "github.com/tsuru/tsuru/types/tracker"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage authTypes.TeamTokenStorage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "auth_rotation_tests")
+	storagev2.Reset()
+	var err error
+	servicemanager.TeamToken, err = auth.TeamTokenService()
+	c.Assert(err, check.IsNil)
+	servicemanager.InstanceTracker = &trackerTypes.MockInstanceService{}
+	driver, err := storage.GetDefaultDbDriver()
+	c.Assert(err, check.IsNil)
+	s.storage = driver.TeamTokenStorage
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	err := storagev2.ClearAllCollections(nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("auth:team-token")
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	storagev2.ClearAllCollections(nil)
+}
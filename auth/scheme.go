@@ -6,6 +6,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -36,6 +37,26 @@ type ManagedScheme interface {
 	ChangePassword(ctx context.Context, token Token, oldPassword string, newPassword string) error
 }
 
+// MFAScheme is implemented by schemes supporting time-based one-time codes
+// as a second authentication factor. An enrollment is only active after it's
+// confirmed with a valid code.
+type MFAScheme interface {
+	EnrollMFA(ctx context.Context, user *User, password string) (*authTypes.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, user *User, password, code string) error
+	DisableMFA(ctx context.Context, user *User, password, code string) error
+}
+
+// LockableScheme is implemented by schemes locking users after repeated
+// failed logins.
+type LockableScheme interface {
+	LockedUsers(ctx context.Context) (map[string]time.Time, error)
+	Unlock(ctx context.Context, user *User) error
+}
+
+// ErrMFARequired is returned by Login when the credentials are valid but the
+// user must also provide a one-time code.
+var ErrMFARequired = errors.New("mfa required: provide the one-time code in the otp parameter")
+
 type AuthenticationFailure struct {
 	Message string
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/storage"
@@ -26,6 +27,8 @@ func IsEmailFromTeamToken(email string) bool {
 	return strings.HasSuffix(email, fmt.Sprintf("@%s", authTypes.TsuruTokenEmailDomain))
 }
 
+const defaultTeamTokenGracePeriod = 24 * time.Hour
+
 type teamToken authTypes.TeamToken
 
 var (
@@ -92,6 +95,9 @@ func (s *teamTokenService) Authenticate(ctx context.Context, header string) (aut
 		return nil, err
 	}
 	now := time.Now()
+	if storedToken.Token != tokenStr && !storedToken.PreviousTokenExpiresAt.After(now) {
+		return nil, ErrInvalidToken
+	}
 	if !storedToken.ExpiresAt.IsZero() && storedToken.ExpiresAt.Before(now) {
 		return nil, authTypes.ErrTeamTokenExpired
 	}
@@ -100,6 +106,7 @@ func (s *teamTokenService) Authenticate(ctx context.Context, header string) (aut
 		return nil, err
 	}
 	token := teamToken(*storedToken)
+	token.Token = tokenStr
 	return &token, nil
 }
 
@@ -132,14 +139,18 @@ func (s *teamTokenService) Create(ctx context.Context, args authTypes.TeamTokenC
 	if err != nil {
 		return authTypes.TeamToken{}, err
 	}
+	if args.RotationInterval < 0 {
+		return authTypes.TeamToken{}, authTypes.ErrInvalidTeamTokenRotation
+	}
 	now := time.Now().UTC()
 	resultToken := authTypes.TeamToken{
-		Token:        generateToken(args.Team, crypto.SHA256),
-		TokenID:      args.TokenID,
-		Description:  args.Description,
-		Team:         args.Team,
-		CreatedAt:    now,
-		CreatorEmail: u.Email,
+		Token:            generateToken(args.Team, crypto.SHA256),
+		TokenID:          args.TokenID,
+		Description:      args.Description,
+		Team:             args.Team,
+		CreatedAt:        now,
+		CreatorEmail:     u.Email,
+		RotationInterval: args.RotationInterval,
 	}
 	if args.ExpiresIn != 0 {
 		resultToken.ExpiresAt = now.Add(time.Duration(args.ExpiresIn) * time.Second)
@@ -194,6 +205,47 @@ func (s *teamTokenService) RemoveRole(ctx context.Context, tokenID string, roleN
 	return s.storage.Update(ctx, *token)
 }
 
+// Rotate replaces the value of the token, keeping the previous value valid
+// during the grace period.
+func (s *teamTokenService) Rotate(ctx context.Context, tokenID string, gracePeriod time.Duration) (authTypes.TeamToken, error) {
+	token, err := s.storage.FindByTokenID(ctx, tokenID)
+	if err != nil {
+		return authTypes.TeamToken{}, err
+	}
+	rotateTeamToken(token, gracePeriod)
+	err = s.storage.Update(ctx, *token)
+	if err != nil {
+		return authTypes.TeamToken{}, err
+	}
+	return *token, nil
+}
+
+func (s *teamTokenService) FindAll(ctx context.Context) ([]authTypes.TeamToken, error) {
+	return s.storage.FindByTeams(ctx, nil)
+}
+
+// TeamTokenGracePeriod returns for how long the previous value of a rotated
+// team token is accepted, configured in auth:team-token:grace-period.
+func TeamTokenGracePeriod() time.Duration {
+	gracePeriod, err := config.GetDuration("auth:team-token:grace-period")
+	if err != nil || gracePeriod <= 0 {
+		return defaultTeamTokenGracePeriod
+	}
+	return gracePeriod
+}
+
+func rotateTeamToken(token *authTypes.TeamToken, gracePeriod time.Duration) {
+	now := time.Now().UTC()
+	token.PreviousToken = ""
+	token.PreviousTokenExpiresAt = time.Time{}
+	if gracePeriod > 0 {
+		token.PreviousToken = token.Token
+		token.PreviousTokenExpiresAt = now.Add(gracePeriod)
+	}
+	token.Token = generateToken(token.Team, crypto.SHA256)
+	token.RotatedAt = now
+}
+
 func (s *teamTokenService) FindByTokenID(ctx context.Context, tokenID string) (authTypes.TeamToken, error) {
 	t, err := s.storage.FindByTokenID(ctx, tokenID)
 	if err != nil {
@@ -230,8 +282,22 @@ func (s *teamTokenService) Update(ctx context.Context, args authTypes.TeamTokenU
 	} else if args.ExpiresIn < 0 {
 		token.ExpiresAt = time.Time{}
 	}
+	if args.RotationInterval > 0 {
+		token.RotationInterval = args.RotationInterval
+	} else if args.RotationInterval < 0 {
+		token.RotationInterval = 0
+	}
+	if args.GracePeriod < 0 {
+		return authTypes.TeamToken{}, authTypes.ErrInvalidTeamTokenRotation
+	}
 	if args.Regenerate {
-		token.Token = generateToken(token.Team, crypto.SHA256)
+		rotateTeamToken(token, 0)
+	} else if args.Rotate {
+		gracePeriod := time.Duration(args.GracePeriod) * time.Second
+		if gracePeriod == 0 {
+			gracePeriod = TeamTokenGracePeriod()
+		}
+		rotateTeamToken(token, gracePeriod)
 	}
 	err = s.storage.Update(ctx, *token)
 	if err != nil {
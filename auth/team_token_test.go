@@ -354,6 +354,7 @@ func (s *S) Test_TeamTokenService_Update_Regenerate(c *check.C) {
 	}, &userToken{user: s.user})
 	c.Assert(err, check.IsNil)
 	c.Assert(updatedToken.Token, check.Not(check.Equals), token.Token)
+	c.Assert(updatedToken.RotatedAt.IsZero(), check.Equals, false)
 	expected := authTypes.TeamToken{
 		Team:         "cobrateam",
 		Description:  "abc",
@@ -362,11 +363,134 @@ func (s *S) Test_TeamTokenService_Update_Regenerate(c *check.C) {
 		CreatorEmail: s.user.Email,
 		CreatedAt:    token.CreatedAt,
 		ExpiresAt:    time.Time{},
+		RotatedAt:    updatedToken.RotatedAt,
 	}
 	c.Assert(updatedToken, check.DeepEquals, expected)
 	t, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "t1")
 	c.Assert(err, check.IsNil)
+	t.RotatedAt = expected.RotatedAt
 	c.Assert(t, check.DeepEquals, expected)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) Test_TeamTokenService_Update_Rotate(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	updatedToken, err := servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:     "t1",
+		Rotate:      true,
+		GracePeriod: 60 * 60,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(updatedToken.Token, check.Not(check.Equals), token.Token)
+	c.Assert(updatedToken.PreviousToken, check.Equals, token.Token)
+	c.Assert(updatedToken.PreviousTokenExpiresAt.Sub(updatedToken.RotatedAt), check.Equals, time.Hour)
+	for _, value := range []string{token.Token, updatedToken.Token} {
+		t, err := servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+value)
+		c.Assert(err, check.IsNil)
+		c.Assert(t.GetValue(), check.Equals, value)
+		c.Assert(t.GetUserName(), check.Equals, "t1")
+	}
+}
+
+func (s *S) Test_TeamTokenService_Update_RotateDefaultGracePeriod(c *check.C) {
+	_, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	updatedToken, err := servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID: "t1",
+		Rotate:  true,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(updatedToken.PreviousTokenExpiresAt.Sub(updatedToken.RotatedAt), check.Equals, 24*time.Hour)
+}
+
+func (s *S) Test_TeamTokenService_Update_InvalidRotation(c *check.C) {
+	_, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:     "t1",
+		Rotate:      true,
+		GracePeriod: -1,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.Equals, authTypes.ErrInvalidTeamTokenRotation)
+	_, err = servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:             s.team.Name,
+		RotationInterval: -1,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.Equals, authTypes.ErrInvalidTeamTokenRotation)
+}
+
+func (s *S) Test_TeamTokenService_Update_RotationInterval(c *check.C) {
+	_, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:             s.team.Name,
+		TokenID:          "t1",
+		RotationInterval: 60 * 60,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	t, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), "t1")
+	c.Assert(err, check.IsNil)
+	c.Assert(t.RotationInterval, check.Equals, 60*60)
+	updatedToken, err := servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:          "t1",
+		RotationInterval: -1,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(updatedToken.RotationInterval, check.Equals, 0)
+}
+
+func (s *S) Test_TeamTokenService_Rotate(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	rotated, err := servicemanager.TeamToken.Rotate(context.TODO(), "t1", time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Assert(rotated.PreviousToken, check.Equals, token.Token)
+	// a new rotation drops the first value
+	rotatedAgain, err := servicemanager.TeamToken.Rotate(context.TODO(), "t1", time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Assert(rotatedAgain.PreviousToken, check.Equals, rotated.Token)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+rotated.Token)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) Test_TeamTokenService_Authenticate_PreviousTokenExpired(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:    s.team.Name,
+		TokenID: "t1",
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.TeamToken.Rotate(context.TODO(), "t1", time.Millisecond)
+	c.Assert(err, check.IsNil)
+	time.Sleep(10 * time.Millisecond)
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) Test_TeamTokenService_FindAll(c *check.C) {
+	for _, id := range []string{"t1", "t2"} {
+		_, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+			Team:    s.team.Name,
+			TokenID: id,
+		}, &userToken{user: s.user})
+		c.Assert(err, check.IsNil)
+	}
+	tokens, err := servicemanager.TeamToken.FindAll(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 2)
 }
 
 func (s *S) Test_TeamTokenService_Update_Expires(c *check.C) {
@@ -62,6 +62,11 @@ func BaseTokenPermission(ctx context.Context, t Token) ([]permTypes.Permission,
 }
 
 func BaseTokenDynamicPermission(ctx context.Context, t Token) ([]permTypes.Permission, error) {
+	if pt, ok := t.(*personalToken); ok && len(pt.Scopes) > 0 {
+		// scoped personal tokens are restricted to the permissions in their
+		// scopes, which can't be dynamic
+		return nil, nil
+	}
 	u, err := ConvertNewUser(t.User(ctx))
 	if err != nil {
 		return nil, err
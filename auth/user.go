@@ -121,6 +121,10 @@ func (u *User) Delete(ctx context.Context) error {
 	if err != nil {
 		log.Errorf("failed to remove user %q from the database: %s", u.Email, err)
 	}
+	err = removePersonalTokens(ctx, u.Email)
+	if err != nil {
+		log.Errorf("failed to remove personal tokens of user %q from the database: %s", u.Email, err)
+	}
 
 	return nil
 }
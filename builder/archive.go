@@ -0,0 +1,175 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/streamfmt"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	defaultArchiveMemoryLimit = 1 << 30 // 1 GiB
+	archiveChunkSize          = 4 << 20 // 4 MiB
+)
+
+var (
+	ErrArchiveTooLarge = errors.New("archive is too large")
+	ErrArchiveEmpty    = errors.New("archive file is empty")
+
+	archiveMemoryOnce sync.Once
+	archiveMemory     *semaphore.Weighted
+	archiveMemorySize int64
+)
+
+// MaxArchiveSize returns the maximum size, in bytes, of the archives
+// uploaded to builds, set by builder:archive-max-size. Archives are read in
+// memory, so the maximum size defaults to, and is never larger than,
+// builder:archive-memory-limit, which defaults to 1 GiB. Larger uploads and
+// archive-url downloads are refused with ErrArchiveTooLarge.
+func MaxArchiveSize() int64 {
+	_, limit := archiveMemoryLimit()
+	size, _ := config.GetInt("builder:archive-max-size")
+	if size <= 0 || int64(size) > limit {
+		return limit
+	}
+	return int64(size)
+}
+
+func archiveMemoryLimit() (*semaphore.Weighted, int64) {
+	archiveMemoryOnce.Do(func() {
+		limit, err := config.GetInt("builder:archive-memory-limit")
+		if err != nil || limit <= 0 {
+			limit = defaultArchiveMemoryLimit
+		}
+		archiveMemorySize = int64(limit)
+		archiveMemory = semaphore.NewWeighted(archiveMemorySize)
+	})
+	return archiveMemory, archiveMemorySize
+}
+
+// ReadArchive reads the whole archive of the build, which the build service
+// receives in a single request. The memory held by archives being read by
+// concurrent builds is bounded by builder:archive-memory-limit, builds wait
+// for memory to be released before reading their archive. The returned
+// release function must be called once the archive isn't needed anymore.
+//
+// Streaming the archive without buffering it requires the deploy-agent
+// proto to send BuildRequest.data in chunks, which it doesn't support yet.
+func ReadArchive(ctx context.Context, opts BuildOpts, w io.Writer) ([]byte, func(), error) {
+	if opts.ArchiveFile == nil || opts.ArchiveSize <= 0 {
+		return nil, func() {}, nil
+	}
+	if max := MaxArchiveSize(); opts.ArchiveSize > max {
+		return nil, nil, errors.Wrapf(ErrArchiveTooLarge, "archive has %s, limit is %s", formatArchiveSize(opts.ArchiveSize), formatArchiveSize(max))
+	}
+	sem, _ := archiveMemoryLimit()
+	// the archive is smaller than the memory limit, so its whole size is
+	// always acquired
+	weight := opts.ArchiveSize
+	if !sem.TryAcquire(weight) {
+		fmt.Fprintln(w, streamfmt.Action("Waiting for other builds to upload their archives..."))
+		if err := sem.Acquire(ctx, weight); err != nil {
+			return nil, nil, err
+		}
+	}
+	var releaseOnce sync.Once
+	release := func() {
+		releaseOnce.Do(func() { sem.Release(weight) })
+	}
+	data, err := readArchiveChunks(opts.ArchiveFile, opts.ArchiveSize, w)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return data, release, nil
+}
+
+// readArchiveChunks reads exactly size bytes from r, in chunks, reporting the
+// progress to w. Archives shorter than size are reported as truncated.
+func readArchiveChunks(r io.Reader, size int64, w io.Writer) ([]byte, error) {
+	data := make([]byte, size)
+	var read int64
+	nextReport := size / 4
+	for read < size {
+		end := read + archiveChunkSize
+		if end > size {
+			end = size
+		}
+		n, err := io.ReadFull(r, data[read:end])
+		read += int64(n)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.Errorf("archive is truncated: read %d of %d bytes", read, size)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if read >= nextReport && read < size {
+			streamfmt.FprintlnActionf(w, "Uploading archive: %s of %s", formatArchiveSize(read), formatArchiveSize(size))
+			nextReport += size / 4
+		}
+	}
+	streamfmt.FprintlnActionf(w, "Uploaded archive: %s", formatArchiveSize(size))
+	return data, nil
+}
+
+// SpoolArchive copies the archive in r to a temporary file, which is removed
+// when the returned reader is closed, failing when the archive is larger than
+// the maximum archive size.
+func SpoolArchive(r io.Reader) (io.ReadCloser, int64, error) {
+	f, err := os.CreateTemp("", "tsuru-archive-")
+	if err != nil {
+		return nil, 0, err
+	}
+	spool := &spooledArchive{File: f}
+	max := MaxArchiveSize()
+	size, err := io.Copy(f, io.LimitReader(r, max+1))
+	if err == nil && size > max {
+		err = errors.Wrapf(ErrArchiveTooLarge, "limit is %s", formatArchiveSize(max))
+	}
+	if err == nil && size == 0 {
+		err = ErrArchiveEmpty
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spool.Close()
+		return nil, 0, err
+	}
+	return spool, size, nil
+}
+
+type spooledArchive struct {
+	*os.File
+}
+
+func (a *spooledArchive) Close() error {
+	err := a.File.Close()
+	if rmErr := os.Remove(a.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func formatArchiveSize(size int64) string {
+	const unit = 1 << 10
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func resetArchiveMemory() {
+	archiveMemoryOnce = sync.Once{}
+}
+
+// shortReader returns at most one byte per Read call.
+type shortReader struct {
+	r io.Reader
+}
+
+func (r *shortReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return r.r.Read(p)
+}
+
+func (s S) TestReadArchive(c *check.C) {
+	resetArchiveMemory()
+	var output bytes.Buffer
+	data, release, err := ReadArchive(context.TODO(), BuildOpts{
+		ArchiveFile: &shortReader{r: strings.NewReader("my source code")},
+		ArchiveSize: 14,
+	}, &output)
+	c.Assert(err, check.IsNil)
+	defer release()
+	c.Assert(string(data), check.Equals, "my source code")
+	c.Assert(output.String(), check.Matches, `(?s).*Uploaded archive: 14 B.*`)
+}
+
+func (s S) TestReadArchiveNoArchive(c *check.C) {
+	data, release, err := ReadArchive(context.TODO(), BuildOpts{}, io.Discard)
+	c.Assert(err, check.IsNil)
+	c.Assert(data, check.IsNil)
+	release()
+}
+
+func (s S) TestReadArchiveTruncated(c *check.C) {
+	resetArchiveMemory()
+	_, _, err := ReadArchive(context.TODO(), BuildOpts{
+		ArchiveFile: strings.NewReader("short"),
+		ArchiveSize: 10,
+	}, io.Discard)
+	c.Assert(err, check.ErrorMatches, "archive is truncated: read 5 of 10 bytes")
+}
+
+func (s S) TestReadArchiveTooLarge(c *check.C) {
+	config.Set("builder:archive-max-size", 4)
+	defer config.Unset("builder:archive-max-size")
+	_, _, err := ReadArchive(context.TODO(), BuildOpts{
+		ArchiveFile: strings.NewReader("my source code"),
+		ArchiveSize: 14,
+	}, io.Discard)
+	c.Assert(errors.Is(err, ErrArchiveTooLarge), check.Equals, true)
+}
+
+func (s S) TestReadArchiveLargerThanMemoryLimit(c *check.C) {
+	config.Set("builder:archive-memory-limit", 10)
+	defer config.Unset("builder:archive-memory-limit")
+	resetArchiveMemory()
+	defer resetArchiveMemory()
+	_, _, err := ReadArchive(context.TODO(), BuildOpts{
+		ArchiveFile: strings.NewReader("my source code"),
+		ArchiveSize: 14,
+	}, io.Discard)
+	c.Assert(errors.Is(err, ErrArchiveTooLarge), check.Equals, true)
+	c.Assert(err, check.ErrorMatches, "archive has 14 B, limit is 10 B: .*")
+}
+
+func (s S) TestMaxArchiveSize(c *check.C) {
+	config.Set("builder:archive-memory-limit", 10)
+	defer config.Unset("builder:archive-memory-limit")
+	resetArchiveMemory()
+	defer resetArchiveMemory()
+	c.Assert(MaxArchiveSize(), check.Equals, int64(10))
+	config.Set("builder:archive-max-size", 4)
+	defer config.Unset("builder:archive-max-size")
+	c.Assert(MaxArchiveSize(), check.Equals, int64(4))
+	config.Set("builder:archive-max-size", 20)
+	c.Assert(MaxArchiveSize(), check.Equals, int64(10))
+}
+
+func (s S) TestReadArchiveWaitsForMemory(c *check.C) {
+	config.Set("builder:archive-memory-limit", 10)
+	defer config.Unset("builder:archive-memory-limit")
+	resetArchiveMemory()
+	defer resetArchiveMemory()
+	_, release, err := ReadArchive(context.TODO(), BuildOpts{
+		ArchiveFile: strings.NewReader("0123456789"),
+		ArchiveSize: 10,
+	}, io.Discard)
+	c.Assert(err, check.IsNil)
+	var output bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, err = ReadArchive(ctx, BuildOpts{
+		ArchiveFile: strings.NewReader("abc"),
+		ArchiveSize: 3,
+	}, &output)
+	c.Assert(err, check.Equals, context.DeadlineExceeded)
+	c.Assert(output.String(), check.Matches, `(?s).*Waiting for other builds.*`)
+	release()
+	data, release, err := ReadArchive(context.TODO(), BuildOpts{
+		ArchiveFile: strings.NewReader("abc"),
+		ArchiveSize: 3,
+	}, io.Discard)
+	c.Assert(err, check.IsNil)
+	defer release()
+	c.Assert(string(data), check.Equals, "abc")
+}
+
+func (s S) TestSpoolArchive(c *check.C) {
+	r, size, err := SpoolArchive(strings.NewReader("my source code"))
+	c.Assert(err, check.IsNil)
+	c.Assert(size, check.Equals, int64(14))
+	data, err := io.ReadAll(r)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "my source code")
+	name := r.(*spooledArchive).Name()
+	err = r.Close()
+	c.Assert(err, check.IsNil)
+	_, err = os.Stat(name)
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s S) TestSpoolArchiveTooLarge(c *check.C) {
+	config.Set("builder:archive-max-size", 4)
+	defer config.Unset("builder:archive-max-size")
+	_, _, err := SpoolArchive(strings.NewReader("my source code"))
+	c.Assert(errors.Is(err, ErrArchiveTooLarge), check.Equals, true)
+}
+
+func (s S) TestSpoolArchiveEmpty(c *check.C) {
+	_, _, err := SpoolArchive(strings.NewReader(""))
+	c.Assert(err, check.Equals, ErrArchiveEmpty)
+}
@@ -5,7 +5,6 @@
 package builder
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -38,6 +37,9 @@ type BuildOpts struct {
 	Message             string
 	Output              io.Writer
 	Dockerfile          string
+	// NoCache makes the build start from the platform image instead of the
+	// image of the latest successful version of the app.
+	NoCache bool
 }
 
 // Builder is the basic interface of this package.
@@ -118,7 +120,7 @@ func PlatformBuild(ctx context.Context, opts appTypes.PlatformOptions) ([]string
 	return nil, errors.New("No builder available")
 }
 
-func DownloadArchiveFromURL(ctx context.Context, url string) (io.ReadCloser, int, error) {
+func DownloadArchiveFromURL(ctx context.Context, url string) (io.ReadCloser, int64, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, 0, err
@@ -134,15 +136,9 @@ func DownloadArchiveFromURL(ctx context.Context, url string) (io.ReadCloser, int
 		return nil, 0, errors.New("could not download the archive: unexpected status code")
 	}
 
-	var out bytes.Buffer
-	s, err := io.Copy(&out, resp.Body)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	if s == 0 {
-		return nil, 0, errors.New("archive file is empty")
+	if max := MaxArchiveSize(); resp.ContentLength > max {
+		return nil, 0, ErrArchiveTooLarge
 	}
 
-	return io.NopCloser(&out), out.Len(), nil
+	return SpoolArchive(resp.Body)
 }
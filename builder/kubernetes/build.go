@@ -24,6 +24,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/provision"
 	provisionk8s "github.com/tsuru/tsuru/provision/kubernetes"
+	"github.com/tsuru/tsuru/registry"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/streamfmt"
 	apptypes "github.com/tsuru/tsuru/types/app"
@@ -76,7 +77,7 @@ func (b *kubernetesBuilder) Build(ctx context.Context, app *apptypes.App, evt *e
 		defer f.Close()
 
 		opts.ArchiveFile = f
-		opts.ArchiveSize = int64(size)
+		opts.ArchiveSize = size
 	}
 
 	streamfmt.FprintlnSectionf(opts.Output, "Starting container image build for app %q", app.Name)
@@ -110,13 +111,11 @@ func (b *kubernetesBuilder) BuildJob(ctx context.Context, job *jobTypes.Job, opt
 	}
 	defer conn.Close()
 
-	data := make([]byte, opts.ArchiveSize)
-	if opts.ArchiveSize > 0 {
-		_, err = opts.ArchiveFile.Read(data)
-		if err != nil && !errors.Is(err, io.EOF) {
-			return "", err
-		}
+	data, release, err := builder.ReadArchive(ctx, opts, w)
+	if err != nil {
+		return "", err
 	}
+	defer release()
 
 	baseImage := opts.ImageID
 	dstImage, err := servicemanager.Job.BaseImageName(ctx, job)
@@ -149,9 +148,25 @@ func (b *kubernetesBuilder) BuildJob(ctx context.Context, job *jobTypes.Job, opt
 	if err != nil {
 		return "", err
 	}
+	if opts.ImageID == "" && registry.ImageSigningEnabled() {
+		if err = signImage(ctx, dstImage, w); err != nil {
+			return "", err
+		}
+	}
 	return dstImage, nil
 }
 
+// signImage signs the images built by tsuru, images deployed from an
+// existing image are left as they are.
+func signImage(ctx context.Context, imageName string, w io.Writer) error {
+	streamfmt.FprintlnActionf(w, "Signing image %q", imageName)
+	err := registry.SignImage(ctx, imageName)
+	if err != nil {
+		return fmt.Errorf("failed to sign image %q: %w", imageName, err)
+	}
+	return nil
+}
+
 func (b *kubernetesBuilder) PlatformBuild(ctx context.Context, opts apptypes.PlatformOptions) ([]string, error) {
 	if err := ctx.Err(); err != nil { // e.g. context deadline exceeded
 		return nil, err
@@ -298,20 +313,24 @@ func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptyp
 		return nil, err
 	}
 
-	data := make([]byte, opts.ArchiveSize)
-	if opts.ArchiveSize > 0 {
-		_, err = opts.ArchiveFile.Read(data)
-		if err != nil && !errors.Is(err, io.EOF) {
-			return nil, err
-		}
+	data, release, err := builder.ReadArchive(ctx, opts, w)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	envs := make(map[string]string)
 	for k, v := range provision.EnvsForApp(app) {
 		envs[k] = v.Value
 	}
 
-	baseImage, err := image.GetBuildImage(ctx, app)
+	var baseImage string
+	if opts.NoCache {
+		fmt.Fprintln(w, streamfmt.Action("Build cache miss, building from the platform image"))
+		baseImage, err = image.GetPlatformImage(ctx, app)
+	} else {
+		baseImage, err = image.GetBuildImage(ctx, app)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -436,6 +455,12 @@ func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptyp
 		return nil, err
 	}
 
+	if opts.ImageID == "" && registry.ImageSigningEnabled() {
+		if err = signImage(ctx, dstImage, w); err != nil {
+			return nil, err
+		}
+	}
+
 	return appVersion, nil
 }
 
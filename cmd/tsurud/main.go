@@ -28,6 +28,7 @@ func buildManager() *cmd.Manager {
 	m.Register(&tsurudCommand{Command: &migrateCmd{}})
 	m.Register(&tsurudCommand{Command: createRootUserCmd{}})
 	m.Register(&tsurudCommand{Command: &migrationListCmd{}})
+	m.Register(&tsurudCommand{Command: &quotaReconcileCmd{}})
 	return m
 }
 
@@ -47,3 +47,12 @@ func (s *S) TestMigrateCmdIsRegistered(c *check.C) {
 	c.Assert(ok, check.Equals, true)
 	c.Assert(migrate.Command, check.FitsTypeOf, &migrateCmd{})
 }
+
+func (s *S) TestQuotaReconcileCmdIsRegistered(c *check.C) {
+	manager := buildManager()
+	cmd, ok := manager.Commands["quota-reconcile"]
+	c.Assert(ok, check.Equals, true)
+	reconcile, ok := cmd.(*tsurudCommand)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(reconcile.Command, check.FitsTypeOf, &quotaReconcileCmd{})
+}
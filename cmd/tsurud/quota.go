@@ -0,0 +1,75 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tsuru/gnuflag"
+	"github.com/tsuru/tablecli"
+	"github.com/tsuru/tsuru/cmd"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/quota/reconcile"
+)
+
+type quotaReconcileCmd struct {
+	fs  *gnuflag.FlagSet
+	fix bool
+}
+
+func (*quotaReconcileCmd) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "quota-reconcile",
+		Usage: "quota-reconcile [--fix]",
+		Desc: `Recomputes the usage of the app, team and user quotas from the existing
+apps, units and jobs and reports the quotas whose recorded usage differs. The
+recorded usage is only fixed when the --fix flag is informed, creating an
+event for each fixed quota.`,
+	}
+}
+
+func (c *quotaReconcileCmd) Run(cmdContext *cmd.Context) error {
+	err := initializeProvisioner()
+	if err != nil {
+		return err
+	}
+	diffs, err := reconcile.Reconcile(context.Background(), c.fix)
+	if len(diffs) == 0 {
+		if err == nil {
+			fmt.Fprintln(cmdContext.Stdout, "No differences found.")
+		}
+		return err
+	}
+	tbl := tablecli.NewTable()
+	tbl.Headers = tablecli.Row{"Kind", "Name", "Recorded", "Actual", "Fixed?"}
+	for _, d := range diffs {
+		tbl.AddRow(tablecli.Row{d.Kind, d.Name, strconv.Itoa(d.Recorded), strconv.Itoa(d.Actual), strconv.FormatBool(d.Fixed)})
+	}
+	fmt.Fprint(cmdContext.Stdout, tbl.String())
+	return err
+}
+
+func (c *quotaReconcileCmd) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("quota-reconcile", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.fix, "fix", false, "Set the actual usage in the quotas with differences")
+	}
+	return c.fs
+}
+
+// initializeProvisioner initializes the default provisioner, as done by the
+// migrations, so the units of the apps can be counted outside the API.
+func initializeProvisioner() error {
+	p, err := provision.GetDefault()
+	if err != nil {
+		return err
+	}
+	if initializable, ok := p.(provision.InitializableProvisioner); ok {
+		return initializable.Initialize()
+	}
+	return nil
+}
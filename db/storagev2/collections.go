@@ -24,6 +24,10 @@ func PoolConstraintsCollection() (*mongo.Collection, error) {
 	return Collection("pool_constraints")
 }
 
+func ResourceQuotasCollection() (*mongo.Collection, error) {
+	return Collection("resource_quotas")
+}
+
 func EventsCollection() (*mongo.Collection, error) {
 	return Collection("events")
 }
@@ -48,6 +52,10 @@ func JobsCollection() (*mongo.Collection, error) {
 	return Collection("jobs")
 }
 
+func JobRunsCollection() (*mongo.Collection, error) {
+	return Collection("job_runs")
+}
+
 func TokensCollection() (*mongo.Collection, error) {
 	return Collection("tokens")
 }
@@ -56,10 +64,30 @@ func PasswordTokensCollection() (*mongo.Collection, error) {
 	return Collection("password_tokens")
 }
 
+func MFACollection() (*mongo.Collection, error) {
+	return Collection("mfa")
+}
+
+func PasswordHistoryCollection() (*mongo.Collection, error) {
+	return Collection("password_history")
+}
+
+func LoginAttemptsCollection() (*mongo.Collection, error) {
+	return Collection("login_attempts")
+}
+
+func PersonalTokensCollection() (*mongo.Collection, error) {
+	return Collection("personal_tokens")
+}
+
 func UsersCollection() (*mongo.Collection, error) {
 	return Collection("users")
 }
 
+func SBOMsCollection() (*mongo.Collection, error) {
+	return Collection("app_sboms")
+}
+
 func TeamTokensCollection() (*mongo.Collection, error) {
 	return Collection("team_tokens")
 }
@@ -76,6 +104,14 @@ func WebhookCollection() (*mongo.Collection, error) {
 	return Collection("webhook")
 }
 
+func WebhookDeliveriesCollection() (*mongo.Collection, error) {
+	return Collection("webhook_deliveries")
+}
+
+func UsageSnapshotsCollection() (*mongo.Collection, error) {
+	return Collection("usage_snapshots")
+}
+
 func VolumesCollection() (*mongo.Collection, error) {
 	return Collection("volumes")
 }
@@ -104,6 +140,14 @@ func MigrationsCollection() (*mongo.Collection, error) {
 	return Collection("migrations")
 }
 
+func RateLimitsCollection() (*mongo.Collection, error) {
+	return Collection("rate_limits")
+}
+
+func EventRecordingsCollection() (*mongo.Collection, error) {
+	return Collection("event_recordings")
+}
+
 func OAuth2TokensCollection() (*mongo.Collection, error) {
 	collectionName := getOAuthTokensCollectionName()
 	return Collection(collectionName)
@@ -150,6 +150,25 @@ var EnsureIndexes = []EnsureIndex{
 				Keys:    mongoBSON.D{{Key: "token_id", Value: 1}},
 				Options: options.Index().SetUnique(true),
 			},
+
+			{
+				Keys:    mongoBSON.D{{Key: "previous_token", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
+		},
+	},
+
+	{
+		Collection: "personal_tokens",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "tokenhash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "useremail", Value: 1}, {Key: "tokenid", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
 		},
 	},
 
@@ -163,6 +182,26 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "rate_limits",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
+	{
+		Collection: "event_recordings",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
 	{
 		Collection: "webhook",
 		Indexes: []mongo.IndexModel{
@@ -173,6 +212,56 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "webhook_deliveries",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "webhook", Value: 1}, {Key: "timestamp", Value: -1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "timestamp", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60),
+			},
+		},
+	},
+
+	{
+		Collection: "job_runs",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "job", Value: 1}, {Key: "starttime", Value: -1}},
+			},
+		},
+	},
+
+	{
+		Collection: "app_sboms",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "app", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "packages.name", Value: 1}, {Key: "packages.version", Value: 1}},
+			},
+		},
+	},
+
+	{
+		Collection: "usage_snapshots",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "time", Value: 1}, {Key: "team", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "time", Value: 1}, {Key: "pool", Value: 1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
 	{
 		Collection: "auth_groups",
 		Indexes: []mongo.IndexModel{
@@ -7,10 +7,13 @@ package event
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/adhocore/gronx"
 	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -43,6 +46,12 @@ func (e ErrEventBlocked) Error() string {
 	)
 }
 
+// Block prevents events from running while it is active. A block with a
+// StartTime in the future is activated only when StartTime is reached and
+// a block with an EndTime expires on its own. A block with a Schedule is a
+// recurring maintenance window: it is active for Duration every time the
+// cron expression in Schedule ticks, and StartTime and EndTime hold the
+// current or the next window.
 type Block struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty"`
 	StartTime  time.Time
@@ -53,6 +62,8 @@ type Block struct {
 	Conditions map[string]string `bson:"conditions,omitempty"`
 	Reason     string
 	Active     bool
+	Schedule   string        `bson:"schedule,omitempty"`
+	Duration   time.Duration `bson:"duration,omitempty" form:"-"`
 }
 
 type startCustomDataMatch struct {
@@ -105,14 +116,43 @@ func (b *Block) String() string {
 	return fmt.Sprintf("block %s by %s on %s: %s", kind, owner, target, b.Reason)
 }
 
+// nextBlockWindow returns the start of the window of a recurring block that
+// contains now or, if there is none, the start of the next one.
+func nextBlockWindow(schedule string, duration time.Duration, now time.Time) (time.Time, error) {
+	if duration <= 0 {
+		return time.Time{}, &tsuruErrors.ValidationError{Message: "duration is required for scheduled blocks"}
+	}
+	start, err := gronx.NextTickAfter(schedule, now.Add(-duration), false)
+	if err != nil {
+		return time.Time{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid schedule %q: %v", schedule, err)}
+	}
+	return start, nil
+}
+
 func AddBlock(ctx context.Context, b *Block) error {
+	now := time.Now()
+	if b.Schedule != "" {
+		start, err := nextBlockWindow(b.Schedule, b.Duration, now)
+		if err != nil {
+			return err
+		}
+		b.StartTime = start
+		b.EndTime = start.Add(b.Duration)
+	} else if b.StartTime.IsZero() || b.StartTime.Before(now) {
+		b.StartTime = now
+	}
+	if !b.EndTime.IsZero() && !b.EndTime.After(now) {
+		return &tsuruErrors.ValidationError{Message: "block end time must be in the future"}
+	}
+	if !b.EndTime.IsZero() && !b.EndTime.After(b.StartTime) {
+		return &tsuruErrors.ValidationError{Message: "block end time must be after its start time"}
+	}
 	collection, err := storagev2.Collection(eventBlockCollectionName)
 	if err != nil {
 		return err
 	}
-	b.Active = true
+	b.Active = !b.StartTime.After(now)
 	b.ID = primitive.NewObjectID()
-	b.StartTime = time.Now()
 
 	_, err = collection.InsertOne(ctx, b)
 
@@ -128,9 +168,25 @@ func RemoveBlock(ctx context.Context, id primitive.ObjectID) error {
 	if err != nil {
 		return err
 	}
-	query := mongoBSON.M{"_id": id, "active": true}
+	now := time.Now()
+	query := mongoBSON.M{
+		"_id": id,
+		"$or": []mongoBSON.M{
+			{"active": true},
+			{
+				"starttime": mongoBSON.M{"$gt": now},
+				"$or": []mongoBSON.M{
+					{"endtime": mongoBSON.M{"$exists": false}},
+					{"endtime": mongoBSON.M{"$gt": now}},
+				},
+			},
+		},
+	}
 
-	result, err := collection.UpdateOne(ctx, query, mongoBSON.M{"$set": mongoBSON.M{"active": false, "endtime": time.Now()}})
+	result, err := collection.UpdateOne(ctx, query, mongoBSON.M{
+		"$set":   mongoBSON.M{"active": false, "endtime": now},
+		"$unset": mongoBSON.M{"schedule": ""},
+	})
 
 	if err == mongo.ErrNoDocuments {
 		return &ErrActiveEventBlockNotFound{id: id.Hex()}
@@ -155,6 +211,48 @@ func ListBlocks(ctx context.Context, active *bool) ([]Block, error) {
 	return listBlocks(ctx, query)
 }
 
+// ListUpcomingBlocks returns the blocks that are scheduled to be activated
+// in the future, sorted by the time they start. Recurring blocks that are
+// currently active are returned with their next window.
+func ListUpcomingBlocks(ctx context.Context) ([]Block, error) {
+	now := time.Now()
+	blocks, err := listBlocks(ctx, mongoBSON.M{
+		"$or": []mongoBSON.M{
+			{
+				"active":    false,
+				"starttime": mongoBSON.M{"$gt": now},
+				"$or": []mongoBSON.M{
+					{"endtime": mongoBSON.M{"$exists": false}},
+					{"endtime": mongoBSON.M{"$gt": now}},
+				},
+			},
+			{
+				"active":   true,
+				"schedule": mongoBSON.M{"$exists": true, "$ne": ""},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		if !blocks[i].Active {
+			continue
+		}
+		start, err := gronx.NextTickAfter(blocks[i].Schedule, blocks[i].StartTime, false)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i].Active = false
+		blocks[i].StartTime = start
+		blocks[i].EndTime = start.Add(blocks[i].Duration)
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].StartTime.Before(blocks[j].StartTime)
+	})
+	return blocks, nil
+}
+
 func listBlocks(ctx context.Context, query mongoBSON.M) ([]Block, error) {
 	collection, err := storagev2.Collection(eventBlockCollectionName)
 	if err != nil {
@@ -184,7 +282,13 @@ func checkIsBlocked(ctx context.Context, evt *Event) error {
 		return nil
 	}
 
-	blocks, err := listBlocks(ctx, mongoBSON.M{"active": true})
+	blocks, err := listBlocks(ctx, mongoBSON.M{
+		"active": true,
+		"$or": []mongoBSON.M{
+			{"endtime": mongoBSON.M{"$exists": false}},
+			{"endtime": mongoBSON.M{"$gt": time.Now()}},
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -196,3 +300,53 @@ func checkIsBlocked(ctx context.Context, evt *Event) error {
 	}
 	return nil
 }
+
+// updateScheduledBlocks expires the active blocks whose end time has passed,
+// moving recurring blocks to their next window, and activates the blocks
+// whose start time has been reached. Recurring blocks are moved even when
+// they are not active, so a window missed by the scheduler does not leave
+// them behind.
+func updateScheduledBlocks(ctx context.Context, now time.Time) error {
+	collection, err := storagev2.Collection(eventBlockCollectionName)
+	if err != nil {
+		return err
+	}
+	expired, err := listBlocks(ctx, mongoBSON.M{
+		"endtime": mongoBSON.M{"$lte": now},
+		"$or": []mongoBSON.M{
+			{"active": true},
+			{"schedule": mongoBSON.M{"$exists": true, "$ne": ""}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range expired {
+		update := mongoBSON.M{"active": false}
+		if b.Schedule != "" {
+			start, nextErr := nextBlockWindow(b.Schedule, b.Duration, now)
+			if nextErr != nil {
+				return nextErr
+			}
+			update["starttime"] = start
+			update["endtime"] = start.Add(b.Duration)
+		}
+		_, err = collection.UpdateOne(ctx, mongoBSON.M{
+			"_id":     b.ID,
+			"active":  b.Active,
+			"endtime": b.EndTime,
+		}, mongoBSON.M{"$set": update})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = collection.UpdateMany(ctx, mongoBSON.M{
+		"active":    false,
+		"starttime": mongoBSON.M{"$lte": now},
+		"$or": []mongoBSON.M{
+			{"endtime": mongoBSON.M{"$exists": false}},
+			{"endtime": mongoBSON.M{"$gt": now}},
+		},
+	}, mongoBSON.M{"$set": mongoBSON.M{"active": true}})
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/tsuru/tsuru/db/storagev2"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -25,6 +26,47 @@ func (s *S) TestAddBlock(c *check.C) {
 	c.Assert(blocks[0], check.DeepEquals, *block)
 }
 
+func (s *S) TestAddBlockScheduled(c *check.C) {
+	start := time.Now().Add(time.Hour)
+	block := &Block{KindName: "app.deploy", Reason: "maintenance", StartTime: start, EndTime: start.Add(time.Hour)}
+	err := AddBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	c.Assert(block.Active, check.Equals, false)
+	c.Assert(block.StartTime.Equal(start), check.Equals, true)
+	err = checkIsBlocked(context.TODO(), &Event{EventData: eventTypes.EventData{Kind: eventTypes.Kind{Name: "app.deploy"}}})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestAddBlockRecurring(c *check.C) {
+	block := &Block{KindName: "app.deploy", Reason: "maintenance", Schedule: "* * * * *", Duration: 2 * time.Minute}
+	err := AddBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	c.Assert(block.Active, check.Equals, true)
+	c.Assert(block.EndTime.Sub(block.StartTime), check.Equals, 2*time.Minute)
+	c.Assert(block.StartTime.After(time.Now()), check.Equals, false)
+	c.Assert(block.EndTime.After(time.Now()), check.Equals, true)
+}
+
+func (s *S) TestAddBlockInvalid(c *check.C) {
+	now := time.Now()
+	tt := []struct {
+		block *Block
+		err   string
+	}{
+		{&Block{Reason: "r", Schedule: "* * * * *"}, `duration is required for scheduled blocks`},
+		{&Block{Reason: "r", Schedule: "invalid", Duration: time.Hour}, `invalid schedule "invalid": .*`},
+		{&Block{Reason: "r", EndTime: now.Add(-time.Hour)}, `block end time must be in the future`},
+		{&Block{Reason: "r", StartTime: now.Add(2 * time.Hour), EndTime: now.Add(time.Hour)}, `block end time must be after its start time`},
+	}
+	for _, t := range tt {
+		err := AddBlock(context.TODO(), t.block)
+		c.Assert(err, check.ErrorMatches, t.err)
+	}
+	blocks, err := listBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 0)
+}
+
 func (s *S) TestRemoveBlock(c *check.C) {
 	block := &Block{KindName: "app.deploy", Reason: "maintenance"}
 	err := AddBlock(context.TODO(), block)
@@ -40,6 +82,26 @@ func (s *S) TestRemoveBlock(c *check.C) {
 	c.Assert(blocks[0].EndTime.IsZero(), check.Equals, false)
 }
 
+func (s *S) TestRemoveBlockScheduled(c *check.C) {
+	start := time.Now().Add(time.Hour)
+	block := &Block{KindName: "app.deploy", Reason: "maintenance", StartTime: start}
+	err := AddBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	err = RemoveBlock(context.TODO(), block.ID)
+	c.Assert(err, check.IsNil)
+	upcoming, err := ListUpcomingBlocks(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(upcoming, check.HasLen, 0)
+	err = RemoveBlock(context.TODO(), block.ID)
+	c.Assert(err, check.FitsTypeOf, &ErrActiveEventBlockNotFound{})
+	err = updateScheduledBlocks(context.TODO(), start.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	blocks, err := ListBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].Active, check.Equals, false)
+}
+
 func (s *S) TestRemoveBlockNotFound(c *check.C) {
 	err := RemoveBlock(context.TODO(), primitive.NewObjectID())
 	c.Assert(err, check.NotNil)
@@ -77,6 +139,103 @@ func (s *S) TestListBlocks(c *check.C) {
 	}
 }
 
+func (s *S) TestListUpcomingBlocks(c *check.C) {
+	now := time.Now()
+	later := &Block{KindName: "app.deploy", Reason: "later", StartTime: now.Add(2 * time.Hour)}
+	err := AddBlock(context.TODO(), later)
+	c.Assert(err, check.IsNil)
+	sooner := &Block{KindName: "app.deploy", Reason: "sooner", StartTime: now.Add(time.Hour)}
+	err = AddBlock(context.TODO(), sooner)
+	c.Assert(err, check.IsNil)
+	err = AddBlock(context.TODO(), &Block{KindName: "app.deploy", Reason: "now"})
+	c.Assert(err, check.IsNil)
+	blocks, err := ListUpcomingBlocks(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 2)
+	c.Assert(blocks[0].ID, check.Equals, sooner.ID)
+	c.Assert(blocks[1].ID, check.Equals, later.ID)
+}
+
+func (s *S) TestListUpcomingBlocksActiveRecurring(c *check.C) {
+	recurring := &Block{KindName: "app.deploy", Reason: "recurring", Schedule: "* * * * *", Duration: 2 * time.Minute}
+	err := AddBlock(context.TODO(), recurring)
+	c.Assert(err, check.IsNil)
+	c.Assert(recurring.Active, check.Equals, true)
+	blocks, err := ListUpcomingBlocks(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].ID, check.Equals, recurring.ID)
+	c.Assert(blocks[0].Active, check.Equals, false)
+	c.Assert(blocks[0].StartTime.Equal(recurring.StartTime.Add(time.Minute)), check.Equals, true)
+	c.Assert(blocks[0].EndTime.Sub(blocks[0].StartTime), check.Equals, 2*time.Minute)
+}
+
+func (s *S) TestUpdateScheduledBlocks(c *check.C) {
+	now := time.Now()
+	oneShot := &Block{KindName: "app.deploy", Reason: "one shot", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)}
+	err := AddBlock(context.TODO(), oneShot)
+	c.Assert(err, check.IsNil)
+	recurring := &Block{KindName: "app.create", Reason: "recurring", Schedule: "0 * * * *", Duration: 10 * time.Minute}
+	err = AddBlock(context.TODO(), recurring)
+	c.Assert(err, check.IsNil)
+	getBlock := func(id primitive.ObjectID) Block {
+		blocks, listErr := listBlocks(context.TODO(), mongoBSON.M{"_id": id})
+		c.Assert(listErr, check.IsNil)
+		c.Assert(blocks, check.HasLen, 1)
+		return blocks[0]
+	}
+	err = updateScheduledBlocks(context.TODO(), now.Add(90*time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(getBlock(oneShot.ID).Active, check.Equals, true)
+	err = updateScheduledBlocks(context.TODO(), now.Add(3*time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(getBlock(oneShot.ID).Active, check.Equals, false)
+	window := getBlock(recurring.ID)
+	err = updateScheduledBlocks(context.TODO(), window.StartTime)
+	c.Assert(err, check.IsNil)
+	c.Assert(getBlock(recurring.ID).Active, check.Equals, true)
+	err = updateScheduledBlocks(context.TODO(), window.EndTime)
+	c.Assert(err, check.IsNil)
+	next := getBlock(recurring.ID)
+	c.Assert(next.Active, check.Equals, false)
+	c.Assert(next.StartTime.Equal(window.StartTime.Add(time.Hour)), check.Equals, true)
+	c.Assert(next.EndTime.Equal(window.EndTime.Add(time.Hour)), check.Equals, true)
+}
+
+func (s *S) TestUpdateScheduledBlocksMissedWindow(c *check.C) {
+	recurring := &Block{KindName: "app.create", Reason: "recurring", Schedule: "0 * * * *", Duration: 10 * time.Minute}
+	err := AddBlock(context.TODO(), recurring)
+	c.Assert(err, check.IsNil)
+	removed := &Block{KindName: "app.deploy", Reason: "removed", Schedule: "0 * * * *", Duration: 10 * time.Minute}
+	err = AddBlock(context.TODO(), removed)
+	c.Assert(err, check.IsNil)
+	err = RemoveBlock(context.TODO(), removed.ID)
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.Collection(eventBlockCollectionName)
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"_id": recurring.ID}, mongoBSON.M{"$set": mongoBSON.M{"active": false}})
+	c.Assert(err, check.IsNil)
+	blocks, err := listBlocks(context.TODO(), mongoBSON.M{"_id": recurring.ID})
+	c.Assert(err, check.IsNil)
+	window := blocks[0]
+	missed := window.EndTime.Add(2*time.Hour + time.Minute)
+	err = updateScheduledBlocks(context.TODO(), missed)
+	c.Assert(err, check.IsNil)
+	blocks, err = listBlocks(context.TODO(), mongoBSON.M{"_id": recurring.ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks[0].Active, check.Equals, false)
+	c.Assert(blocks[0].StartTime.Equal(window.StartTime.Add(3*time.Hour)), check.Equals, true)
+	err = updateScheduledBlocks(context.TODO(), blocks[0].StartTime)
+	c.Assert(err, check.IsNil)
+	blocks, err = listBlocks(context.TODO(), mongoBSON.M{"_id": recurring.ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks[0].Active, check.Equals, true)
+	blocks, err = listBlocks(context.TODO(), mongoBSON.M{"_id": removed.ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks[0].Active, check.Equals, false)
+	c.Assert(blocks[0].Schedule, check.Equals, "")
+}
+
 func (s *S) TestCheckIsBlocked(c *check.C) {
 	blocks := map[string]*Block{
 		"blockApp":                       {Target: eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "blocked-app"}},
@@ -164,6 +164,7 @@ func Initialize() error {
 		return errors.Wrap(err, "unable to load event throttling")
 	}
 	cleaner.start()
+	scheduler.start()
 	return nil
 }
 
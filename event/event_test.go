@@ -1262,6 +1262,8 @@ func (s *S) TestGetTargetType(c *check.C) {
 		{"service-instance", eventTypes.TargetTypeServiceInstance, nil},
 		{"team", eventTypes.TargetTypeTeam, nil},
 		{"user", eventTypes.TargetTypeUser, nil},
+		{"job-workflow", eventTypes.TargetTypeJobWorkflow, nil},
+		{"team-token", eventTypes.TargetTypeTeamToken, nil},
 		{"invalid", "", eventTypes.ErrInvalidTargetType},
 	}
 	for _, t := range tests {
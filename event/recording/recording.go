@@ -0,0 +1,257 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package recording records interactive sessions, like app shells and app
+// run commands, in the asciicast v2 format and stores them along with the
+// event of the session.
+package recording
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultMaxSize   = 10 << 20
+	defaultRetention = 30 * 24 * time.Hour
+	defaultWidth     = 80
+	defaultHeight    = 24
+
+	// ContentType is the media type of asciicast recordings.
+	ContentType = "application/x-asciicast"
+)
+
+type Stream string
+
+const (
+	Input  Stream = "i"
+	Output Stream = "o"
+)
+
+var (
+	ErrRecordingNotFound = errors.New("recording not found")
+
+	now = time.Now
+)
+
+// Recording is a stored asciicast v2 recording, identified by the unique ID
+// of the event of the recorded session.
+type Recording struct {
+	EventID   primitive.ObjectID `bson:"_id"`
+	Data      []byte
+	Truncated bool
+	ExpireAt  time.Time
+}
+
+type Header struct {
+	Width  int
+	Height int
+	Term   string
+	Title  string
+}
+
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// MaxSize returns the maximum size in bytes of a recording, configured in
+// shell:recording:max-size. Events beyond this size are discarded and the
+// recording is marked as truncated.
+func MaxSize() int {
+	size, err := config.GetInt("shell:recording:max-size")
+	if err != nil || size <= 0 {
+		return defaultMaxSize
+	}
+	return size
+}
+
+// Retention returns for how long recordings are kept, configured in
+// shell:recording:retention.
+func Retention() time.Duration {
+	retention, err := config.GetDuration("shell:recording:retention")
+	if err != nil || retention <= 0 {
+		return defaultRetention
+	}
+	return retention
+}
+
+// Recorder writes the input and output streams of a session as asciicast v2
+// events. It's safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	buf       bytes.Buffer
+	maxSize   int
+	truncated bool
+	pending   map[Stream][]byte
+}
+
+func NewRecorder(h Header) *Recorder {
+	r := &Recorder{
+		start:   now(),
+		maxSize: MaxSize(),
+		pending: map[Stream][]byte{},
+	}
+	header := asciicastHeader{
+		Version:   2,
+		Width:     h.Width,
+		Height:    h.Height,
+		Timestamp: r.start.Unix(),
+		Title:     h.Title,
+	}
+	if header.Width <= 0 {
+		header.Width = defaultWidth
+	}
+	if header.Height <= 0 {
+		header.Height = defaultHeight
+	}
+	if h.Term != "" {
+		header.Env = map[string]string{"TERM": h.Term}
+	}
+	data, _ := json.Marshal(header)
+	r.appendLine(data)
+	return r
+}
+
+// Record adds the data to the stream as an event timestamped with the time
+// elapsed since the recorder was created. Incomplete UTF-8 sequences at the
+// end of data are held until the next call for the same stream.
+func (r *Recorder) Record(stream Stream, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.truncated {
+		return
+	}
+	data = append(r.pending[stream], data...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	r.pending[stream] = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return
+	}
+	elapsed := math.Round(now().Sub(r.start).Seconds()*1e6) / 1e6
+	line, _ := json.Marshal([]interface{}{elapsed, stream, string(data[:cut])})
+	r.appendLine(line)
+}
+
+func (r *Recorder) appendLine(line []byte) {
+	if r.buf.Len()+len(line)+1 > r.maxSize {
+		r.truncated = true
+		return
+	}
+	r.buf.Write(line)
+	r.buf.WriteByte('\n')
+}
+
+// Writer returns a writer recording everything written to it in the stream.
+func (r *Recorder) Writer(stream Stream) io.Writer {
+	return &streamWriter{recorder: r, stream: stream}
+}
+
+// Wrap returns a ReadWriteCloser recording what is read from rwc as input
+// and what is written to it as output.
+func (r *Recorder) Wrap(rwc io.ReadWriteCloser) io.ReadWriteCloser {
+	return &recordedReadWriteCloser{ReadWriteCloser: rwc, recorder: r}
+}
+
+func (r *Recorder) Truncated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.truncated
+}
+
+func (r *Recorder) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]byte(nil), r.buf.Bytes()...)
+}
+
+// Save stores the recording for the event with the given unique ID, it
+// expires after the configured retention.
+func (r *Recorder) Save(ctx context.Context, eventID primitive.ObjectID) error {
+	collection, err := storagev2.EventRecordingsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, Recording{
+		EventID:   eventID,
+		Data:      r.Bytes(),
+		Truncated: r.Truncated(),
+		ExpireAt:  now().Add(Retention()),
+	})
+	return err
+}
+
+func Get(ctx context.Context, eventID primitive.ObjectID) (*Recording, error) {
+	collection, err := storagev2.EventRecordingsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": eventID}).Decode(&rec)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRecordingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+type streamWriter struct {
+	recorder *Recorder
+	stream   Stream
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.recorder.Record(w.stream, p)
+	return len(p), nil
+}
+
+type recordedReadWriteCloser struct {
+	io.ReadWriteCloser
+	recorder *Recorder
+}
+
+func (c *recordedReadWriteCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		c.recorder.Record(Input, p[:n])
+	}
+	return n, err
+}
+
+func (c *recordedReadWriteCloser) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	if n > 0 {
+		c.recorder.Record(Output, p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package recording
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/tsuru/config"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	check "gopkg.in/check.v1"
+)
+
+var startTime = time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func setNow(t time.Time) {
+	now = func() time.Time { return t }
+}
+
+type fakeConn struct {
+	io.Reader
+	bytes.Buffer
+}
+
+func (c *fakeConn) Read(p []byte) (int, error) {
+	return c.Reader.Read(p)
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (s *S) TestRecorder(c *check.C) {
+	setNow(startTime)
+	rec := NewRecorder(Header{Width: 120, Height: 40, Term: "xterm", Title: "ls"})
+	setNow(startTime.Add(500 * time.Millisecond))
+	rec.Record(Input, []byte("ls\r"))
+	setNow(startTime.Add(1500 * time.Millisecond))
+	rec.Writer(Output).Write([]byte("Procfile\r\n"))
+	c.Assert(string(rec.Bytes()), check.Equals, `{"version":2,"width":120,"height":40,"timestamp":1778414400,"title":"ls","env":{"TERM":"xterm"}}
+[0.5,"i","ls\r"]
+[1.5,"o","Procfile\r\n"]
+`)
+	c.Assert(rec.Truncated(), check.Equals, false)
+}
+
+func (s *S) TestRecorderDefaultSize(c *check.C) {
+	setNow(startTime)
+	rec := NewRecorder(Header{})
+	c.Assert(string(rec.Bytes()), check.Equals, `{"version":2,"width":80,"height":24,"timestamp":1778414400}
+`)
+}
+
+func (s *S) TestRecorderSplitUTF8(c *check.C) {
+	setNow(startTime)
+	rec := NewRecorder(Header{})
+	data := []byte("ção")
+	rec.Record(Output, data[:1])
+	rec.Record(Input, []byte("x"))
+	rec.Record(Output, data[1:])
+	c.Assert(string(rec.Bytes()), check.Equals, `{"version":2,"width":80,"height":24,"timestamp":1778414400}
+[0,"i","x"]
+[0,"o","ção"]
+`)
+}
+
+func (s *S) TestRecorderMaxSize(c *check.C) {
+	config.Set("shell:recording:max-size", 100)
+	setNow(startTime)
+	rec := NewRecorder(Header{})
+	rec.Record(Output, []byte("hello"))
+	rec.Record(Output, []byte("this line does not fit"))
+	rec.Record(Output, []byte("x"))
+	c.Assert(string(rec.Bytes()), check.Equals, `{"version":2,"width":80,"height":24,"timestamp":1778414400}
+[0,"o","hello"]
+`)
+	c.Assert(rec.Truncated(), check.Equals, true)
+}
+
+func (s *S) TestRecorderWrap(c *check.C) {
+	setNow(startTime)
+	rec := NewRecorder(Header{})
+	conn := &fakeConn{Reader: bytes.NewBufferString("whoami\r")}
+	wrapped := rec.Wrap(conn)
+	buf := make([]byte, 64)
+	n, err := wrapped.Read(buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(buf[:n]), check.Equals, "whoami\r")
+	_, err = wrapped.Write([]byte("ubuntu\r\n"))
+	c.Assert(err, check.IsNil)
+	c.Assert(conn.String(), check.Equals, "ubuntu\r\n")
+	c.Assert(string(rec.Bytes()), check.Equals, `{"version":2,"width":80,"height":24,"timestamp":1778414400}
+[0,"i","whoami\r"]
+[0,"o","ubuntu\r\n"]
+`)
+}
+
+func (s *S) TestSaveAndGet(c *check.C) {
+	config.Set("shell:recording:retention", "48h")
+	setNow(startTime)
+	rec := NewRecorder(Header{})
+	rec.Record(Output, []byte("hello"))
+	id := primitive.NewObjectID()
+	err := rec.Save(context.TODO(), id)
+	c.Assert(err, check.IsNil)
+	stored, err := Get(context.TODO(), id)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.EventID, check.Equals, id)
+	c.Assert(stored.Data, check.DeepEquals, rec.Bytes())
+	c.Assert(stored.Truncated, check.Equals, false)
+	c.Assert(stored.ExpireAt.Equal(startTime.Add(48*time.Hour)), check.Equals, true)
+}
+
+func (s *S) TestGetNotFound(c *check.C) {
+	_, err := Get(context.TODO(), primitive.NewObjectID())
+	c.Assert(err, check.Equals, ErrRecordingNotFound)
+}
+
+func (s *S) TestConfigDefaults(c *check.C) {
+	c.Assert(MaxSize(), check.Equals, 10<<20)
+	c.Assert(Retention(), check.Equals, 30*24*time.Hour)
+	config.Set("shell:recording:max-size", 1024)
+	config.Set("shell:recording:retention", "1h")
+	c.Assert(MaxSize(), check.Equals, 1024)
+	c.Assert(Retention(), check.Equals, time.Hour)
+}
@@ -7,17 +7,22 @@ package webhook
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/shutdown"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -26,6 +31,7 @@ import (
 	"github.com/tsuru/tsuru/storage"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"github.com/tsuru/tsuru/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -33,6 +39,12 @@ var (
 
 	chanBufferSize   = 1000
 	defaultUserAgent = "tsuru-webhook-client/1.0"
+
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = time.Second
+	deliveriesLimit     = 100
+
+	signatureHeader = "X-Tsuru-Signature"
 )
 
 func WebhookService() (eventTypes.WebhookService, error) {
@@ -43,11 +55,21 @@ func WebhookService() (eventTypes.WebhookService, error) {
 			return nil, err
 		}
 	}
+	maxAttempts, _ := config.GetInt("events:webhooks:max-attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	retryBackoff, _ := config.GetDuration("events:webhooks:retry-backoff")
+	if retryBackoff <= 0 {
+		retryBackoff = defaultRetryBackoff
+	}
 	s := &webhookService{
-		storage: dbDriver.WebhookStorage,
-		evtCh:   make(chan string, chanBufferSize),
-		quitCh:  make(chan struct{}),
-		doneCh:  make(chan struct{}),
+		storage:      dbDriver.WebhookStorage,
+		evtCh:        make(chan string, chanBufferSize),
+		quitCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+		maxAttempts:  maxAttempts,
+		retryBackoff: retryBackoff,
 	}
 	err = s.initMetrics()
 	if err != nil {
@@ -64,6 +86,10 @@ type webhookService struct {
 	quitCh  chan struct{}
 	doneCh  chan struct{}
 
+	maxAttempts  int
+	retryBackoff time.Duration
+	retries      sync.WaitGroup
+
 	webhooksLatency prometheus.Histogram
 	webhooksTotal   prometheus.Counter
 	webhooksError   prometheus.Counter
@@ -109,10 +135,17 @@ func (s *webhookService) Shutdown(ctx context.Context) error {
 	prometheus.Unregister(s.webhooksError)
 	prometheus.Unregister(s.webhooksQueue)
 	close(s.quitCh)
-	select {
-	case <-s.doneCh:
-	case <-ctx.Done():
-		return ctx.Err()
+	retriesDone := make(chan struct{})
+	go func() {
+		s.retries.Wait()
+		close(retriesDone)
+	}()
+	for _, ch := range []chan struct{}{s.doneCh, retriesDone} {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 	return nil
 }
@@ -159,7 +192,7 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 		return err
 	}
 	for _, h := range hooks {
-		err = s.doHook(h, evt)
+		_, err = s.deliver(ctx, h, evt, false)
 		if err != nil {
 			log.Errorf("[webhooks] error calling webhook %q for event %q: %v", h.Name, evtID, err)
 		}
@@ -167,19 +200,103 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 	return nil
 }
 
-func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error) {
+// hookDelivery holds what is needed to retry a delivery.
+type hookDelivery struct {
+	hook     eventTypes.Webhook
+	client   *http.Client
+	body     []byte
+	backoff  time.Duration
+	delivery eventTypes.WebhookDelivery
+}
+
+// deliver calls the webhook for the event and stores the outcome in the
+// webhook delivery history. When the call fails and may be retried, the
+// retries run in the background and the delivery is stored once they are
+// over, so a failing receiver never delays the other deliveries.
+func (s *webhookService) deliver(ctx context.Context, hook eventTypes.Webhook, evt *event.Event, redelivery bool) (eventTypes.WebhookDelivery, error) {
+	d := &hookDelivery{
+		backoff: s.retryBackoff,
+		delivery: eventTypes.WebhookDelivery{
+			ID:         primitive.NewObjectID().Hex(),
+			Webhook:    hook.Name,
+			EventID:    evt.UniqueID.Hex(),
+			Timestamp:  time.Now().UTC(),
+			Redelivery: redelivery,
+		},
+	}
+	err := s.prepareHook(d, hook, evt)
+	if err == nil {
+		err = s.doHook(d)
+		if err != nil && s.shouldRetry(d) {
+			s.scheduleRetry(d, err)
+			return d.delivery, err
+		}
+	}
+	s.finishDelivery(ctx, d, err)
+	return d.delivery, err
+}
+
+func (s *webhookService) finishDelivery(ctx context.Context, d *hookDelivery, err error) {
+	d.delivery.Duration = time.Since(d.delivery.Timestamp)
+	d.delivery.Success = err == nil
+	if err != nil {
+		d.delivery.Error = err.Error()
+	}
+	storeErr := s.storage.InsertDelivery(ctx, d.delivery)
+	if storeErr != nil {
+		log.Errorf("[webhooks] unable to store delivery of event %q to webhook %q: %v", d.delivery.EventID, d.hook.Name, storeErr)
+	}
+}
+
+// shouldRetry reports whether a failed delivery may succeed if retried,
+// either because no response was received or because the receiver is
+// unavailable.
+func (s *webhookService) shouldRetry(d *hookDelivery) bool {
+	statusCode := d.delivery.StatusCode
+	return d.delivery.Attempts < s.maxAttempts &&
+		(statusCode == 0 ||
+			statusCode == http.StatusTooManyRequests ||
+			statusCode >= http.StatusInternalServerError)
+}
+
+// scheduleRetry retries the delivery in its own goroutine, doubling the
+// backoff after every attempt. The last error is stored if the service shuts
+// down before the delivery succeeds.
+func (s *webhookService) scheduleRetry(d *hookDelivery, err error) {
+	s.retries.Add(1)
+	go func() {
+		defer s.retries.Done()
+		for {
+			log.Errorf("[webhooks] attempt %d calling webhook %q failed, retrying in %v: %v", d.delivery.Attempts, d.hook.Name, d.backoff, err)
+			select {
+			case <-time.After(d.backoff):
+			case <-s.quitCh:
+				s.finishDelivery(context.Background(), d, err)
+				return
+			}
+			d.backoff *= 2
+			err = s.doHook(d)
+			if err == nil || !s.shouldRetry(d) {
+				s.finishDelivery(context.Background(), d, err)
+				return
+			}
+		}
+	}()
+}
+
+func webhookBody(hook *eventTypes.Webhook, evt *event.Event) ([]byte, error) {
 	if hook.Body != "" {
 		tpl, err := template.New(hook.Name).Parse(hook.Body)
 		if err != nil {
 			log.Errorf("[webhooks] unable to parse hook body for %q as template, using raw string: %v", hook.Name, err)
-			return strings.NewReader(hook.Body), nil
+			return []byte(hook.Body), nil
 		}
 		buf := bytes.NewBuffer(nil)
 		err = tpl.Execute(buf, evt)
 		if err != nil {
 			return nil, err
 		}
-		return bytes.NewReader(buf.Bytes()), nil
+		return buf.Bytes(), nil
 	}
 	if hook.Method != http.MethodPost &&
 		hook.Method != http.MethodPut &&
@@ -190,33 +307,27 @@ func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error)
 		hook.Headers = make(http.Header)
 	}
 	hook.Headers.Set("Content-Type", "application/json")
-	data, err := json.Marshal(evt)
-	if err != nil {
-		return nil, err
-	}
-	return bytes.NewReader(data), nil
+	return json.Marshal(evt)
 }
 
-func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err error) {
-	defer func() {
-		s.webhooksTotal.Inc()
-		if err != nil {
-			s.webhooksError.Inc()
-		}
-	}()
-	hook.Method = strings.ToUpper(hook.Method)
-	if hook.Method == "" {
-		hook.Method = http.MethodPost
-	}
-	body, err := webhookBody(&hook, evt)
-	if err != nil {
-		return err
+// webhookSignature returns the value of the signature header sent with the
+// webhook body, an HMAC-SHA256 of the body keyed by the webhook secret.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newHookRequest(hook eventTypes.Webhook, body []byte) (*http.Request, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
 	}
-	req, err := http.NewRequest(hook.Method, hook.URL, body)
+	req, err := http.NewRequest(hook.Method, hook.URL, reqBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.Header = hook.Headers
+	req.Header = hook.Headers.Clone()
 
 	if req.Header == nil {
 		req.Header = make(http.Header)
@@ -225,6 +336,21 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	if req.UserAgent() == "" {
 		req.Header.Set("User-Agent", defaultUserAgent)
 	}
+	if hook.Secret != "" {
+		req.Header.Set(signatureHeader, webhookSignature(hook.Secret, body))
+	}
+	return req, nil
+}
+
+func (s *webhookService) prepareHook(d *hookDelivery, hook eventTypes.Webhook, evt *event.Event) error {
+	hook.Method = strings.ToUpper(hook.Method)
+	if hook.Method == "" {
+		hook.Method = http.MethodPost
+	}
+	body, err := webhookBody(&hook, evt)
+	if err != nil {
+		return err
+	}
 	client := tsuruNet.Dial15Full60ClientNoKeepAlive
 	if hook.Insecure {
 		client = tsuruNet.Dial15Full60ClientNoKeepAliveInsecure
@@ -240,18 +366,41 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 			return err
 		}
 	}
+	d.hook = hook
+	d.client = client
+	d.body = body
+	return nil
+}
+
+func (s *webhookService) doHook(d *hookDelivery) error {
+	req, err := newHookRequest(d.hook, d.body)
+	if err != nil {
+		return err
+	}
+	d.delivery.Attempts++
+	d.delivery.StatusCode, err = s.sendHook(d.client, req)
+	return err
+}
+
+func (s *webhookService) sendHook(client *http.Client, req *http.Request) (statusCode int, err error) {
+	defer func() {
+		s.webhooksTotal.Inc()
+		if err != nil {
+			s.webhooksError.Inc()
+		}
+	}()
 	reqStart := time.Now()
 	rsp, err := client.Do(req)
 	s.webhooksLatency.Observe(time.Since(reqStart).Seconds())
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer rsp.Body.Close()
 	if rsp.StatusCode < 200 || rsp.StatusCode >= 400 {
 		data, _ := io.ReadAll(rsp.Body)
-		return errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
+		return rsp.StatusCode, errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
 	}
-	return nil
+	return rsp.StatusCode, nil
 }
 
 func validateURLs(w eventTypes.Webhook) error {
@@ -296,6 +445,15 @@ func (s *webhookService) Update(ctx context.Context, w eventTypes.Webhook) error
 	if err != nil {
 		return err
 	}
+	if w.ClearSecret {
+		w.Secret = ""
+	} else if w.Secret == "" {
+		existing, err := s.storage.FindByName(ctx, w.Name)
+		if err != nil {
+			return err
+		}
+		w.Secret = existing.Secret
+	}
 	return s.storage.Update(ctx, w)
 }
 
@@ -314,3 +472,35 @@ func (s *webhookService) Find(ctx context.Context, name string) (eventTypes.Webh
 func (s *webhookService) List(ctx context.Context, teams []string) ([]eventTypes.Webhook, error) {
 	return s.storage.FindAllByTeams(ctx, teams)
 }
+
+func (s *webhookService) Deliveries(ctx context.Context, name string) ([]eventTypes.WebhookDelivery, error) {
+	_, err := s.storage.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.FindDeliveries(ctx, name, deliveriesLimit)
+}
+
+// Redeliver sends again the event of a previous delivery to the webhook. A
+// failed call is not returned as an error, it's recorded in the returned
+// delivery like any other delivery. Only the first attempt is made before
+// returning, retries are recorded in the delivery history.
+func (s *webhookService) Redeliver(ctx context.Context, name, deliveryID string) (eventTypes.WebhookDelivery, error) {
+	hook, err := s.storage.FindByName(ctx, name)
+	if err != nil {
+		return eventTypes.WebhookDelivery{}, err
+	}
+	previous, err := s.storage.FindDelivery(ctx, name, deliveryID)
+	if err != nil {
+		return eventTypes.WebhookDelivery{}, err
+	}
+	evt, err := event.GetByHexID(ctx, previous.EventID)
+	if err != nil {
+		return eventTypes.WebhookDelivery{}, err
+	}
+	delivery, err := s.deliver(ctx, *hook, evt, true)
+	if err != nil {
+		log.Errorf("[webhooks] error redelivering event %q to webhook %q: %v", previous.EventID, name, err)
+	}
+	return delivery, nil
+}
@@ -11,7 +11,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/db/storagev2"
@@ -248,6 +250,185 @@ func (s *S) TestWebhookServiceNotifyProxy(c *check.C) {
 	c.Assert(receivedReq.Header.Get("Content-Type"), check.Equals, "application/json")
 }
 
+func (s *S) newDoneEvent(c *check.C) *event.Event {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestWebhookServiceSignature(c *check.C) {
+	evt := s.newDoneEvent(c)
+	var receivedReq *http.Request
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedReq = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    srv.URL,
+		Body:   "my body",
+		Secret: "s3cr3t",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(string(receivedBody), check.Equals, "my body")
+	c.Assert(receivedReq.Header.Get("X-Tsuru-Signature"), check.Equals, "sha256=5ce7af9eb158d4f1a8f75ed3448cab68b825d658aa77b8a9aea39182574b6ad5")
+}
+
+func (s *S) TestWebhookServiceRetries(c *check.C) {
+	s.service.retryBackoff = time.Millisecond
+	evt := s.newDoneEvent(c)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	s.service.retries.Wait()
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(3))
+	deliveries, err := s.service.Deliveries(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].EventID, check.Equals, evt.UniqueID.Hex())
+	c.Assert(deliveries[0].Attempts, check.Equals, 3)
+	c.Assert(deliveries[0].StatusCode, check.Equals, http.StatusOK)
+	c.Assert(deliveries[0].Success, check.Equals, true)
+	c.Assert(deliveries[0].Error, check.Equals, "")
+}
+
+func (s *S) TestWebhookServiceRetriesExhausted(c *check.C) {
+	s.service.retryBackoff = time.Millisecond
+	evt := s.newDoneEvent(c)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	s.service.retries.Wait()
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(defaultMaxAttempts))
+	deliveries, err := s.service.Deliveries(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].Attempts, check.Equals, defaultMaxAttempts)
+	c.Assert(deliveries[0].StatusCode, check.Equals, http.StatusBadGateway)
+	c.Assert(deliveries[0].Success, check.Equals, false)
+	c.Assert(deliveries[0].Error, check.Matches, `invalid status code calling hook: 502: .*`)
+}
+
+func (s *S) TestWebhookServiceRetriesDoNotBlockOtherDeliveries(c *check.C) {
+	s.service.retryBackoff = time.Hour
+	evt := s.newDoneEvent(c)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	var calls int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "failing", URL: failing.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "ok", URL: ok.URL})
+	c.Assert(err, check.IsNil)
+	done := make(chan error)
+	go func() {
+		done <- s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	}()
+	select {
+	case err = <-done:
+		c.Assert(err, check.IsNil)
+	case <-time.After(10 * time.Second):
+		c.Fatal("timeout waiting for webhooks to be handled")
+	}
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(1))
+	deliveries, err := s.service.Deliveries(context.TODO(), "ok")
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	deliveries, err = s.service.Deliveries(context.TODO(), "failing")
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 0)
+}
+
+func (s *S) TestWebhookServiceNoRetryOnClientError(c *check.C) {
+	s.service.retryBackoff = time.Millisecond
+	evt := s.newDoneEvent(c)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(1))
+}
+
+func (s *S) TestWebhookServiceRedeliver(c *check.C) {
+	evt := s.newDoneEvent(c)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.Deliveries(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].Success, check.Equals, false)
+	delivery, err := s.service.Redeliver(context.TODO(), "xyz", deliveries[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(delivery.Success, check.Equals, true)
+	c.Assert(delivery.Redelivery, check.Equals, true)
+	c.Assert(delivery.EventID, check.Equals, evt.UniqueID.Hex())
+	deliveries, err = s.service.Deliveries(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 2)
+	c.Assert(deliveries[0].ID, check.Equals, delivery.ID)
+}
+
+func (s *S) TestWebhookServiceRedeliverNotFound(c *check.C) {
+	_, err := s.service.Redeliver(context.TODO(), "xyz", "abc")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
+	err = s.service.Create(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: "http://a"})
+	c.Assert(err, check.IsNil)
+	_, err = s.service.Redeliver(context.TODO(), "xyz", "abc")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
 func (s *S) TestWebhookServiceCreate(c *check.C) {
 	err := s.service.Create(context.TODO(), eventTypes.Webhook{
 		Name: "xyz",
@@ -362,6 +543,42 @@ func (s *S) TestWebhookServiceUpdate(c *check.C) {
 	})
 }
 
+func (s *S) TestWebhookServiceUpdateKeepsSecret(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    "http://a",
+		Secret: "s3cr3t",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  "http://b",
+	})
+	c.Assert(err, check.IsNil)
+	w, err := s.service.Find(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.URL, check.Equals, "http://b")
+	c.Assert(w.Secret, check.Equals, "s3cr3t")
+}
+
+func (s *S) TestWebhookServiceUpdateClearSecret(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    "http://a",
+		Secret: "s3cr3t",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{
+		Name:        "xyz",
+		URL:         "http://a",
+		ClearSecret: true,
+	})
+	c.Assert(err, check.IsNil)
+	w, err := s.service.Find(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Secret, check.Equals, "")
+}
+
 func (s *S) TestWebhookServiceUpdateInvalid(c *check.C) {
 	err := s.service.Update(context.TODO(), eventTypes.Webhook{
 		Name: "xyz",
@@ -19,17 +19,56 @@ import (
 )
 
 var (
-	lockUpdateInterval   = 30 * time.Second
-	lockExpireTimeout    = 5 * time.Minute
-	eventCleanerInterval = 5 * time.Minute
-	updater              = lockUpdater{
+	lockUpdateInterval     = 30 * time.Second
+	lockExpireTimeout      = 5 * time.Minute
+	eventCleanerInterval   = 5 * time.Minute
+	blockSchedulerInterval = time.Minute
+	updater                = lockUpdater{
 		once: &sync.Once{},
 	}
 	cleaner = eventCleaner{
 		once: &sync.Once{},
 	}
+	scheduler = blockScheduler{
+		once: &sync.Once{},
+	}
 )
 
+type blockScheduler struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (l *blockScheduler) start() {
+	l.once.Do(func() {
+		l.stopCh = make(chan struct{})
+		go l.spin()
+	})
+}
+
+func (l *blockScheduler) stop() {
+	if l.stopCh == nil {
+		return
+	}
+	l.stopCh <- struct{}{}
+	l.stopCh = nil
+	l.once = &sync.Once{}
+}
+
+func (l *blockScheduler) spin() {
+	for {
+		err := updateScheduledBlocks(context.Background(), time.Now())
+		if err != nil {
+			log.Errorf("[events] [block scheduler] error updating scheduled blocks: %v", err)
+		}
+		select {
+		case <-l.stopCh:
+			return
+		case <-time.After(blockSchedulerInterval):
+		}
+	}
+}
+
 type eventCleaner struct {
 	once   *sync.Once
 	stopCh chan struct{}
@@ -0,0 +1,52 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// SetExpiration sets the time when the job expires, resetting the expiration
+// warning. A nil expiresAt pins the job, which then never expires.
+func SetExpiration(ctx context.Context, job *jobTypes.Job, expiresAt *time.Time) error {
+	collection, err := storagev2.JobsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"name": job.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"expiresat": expiresAt, "expirationwarned": false},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return jobTypes.ErrJobNotFound
+	}
+	job.ExpiresAt = expiresAt
+	job.ExpirationWarned = false
+	return nil
+}
+
+// MarkExpirationWarned records that the owners of the job were warned about
+// its expiration.
+func MarkExpirationWarned(ctx context.Context, job *jobTypes.Job) error {
+	collection, err := storagev2.JobsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": job.Name}, mongoBSON.M{
+		"$set": mongoBSON.M{"expirationwarned": true},
+	})
+	if err != nil {
+		return err
+	}
+	job.ExpirationWarned = true
+	return nil
+}
@@ -31,6 +31,7 @@ import (
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -105,6 +106,16 @@ func (*jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
 		return jobTypes.ErrJobNotFound
 	}
 
+	err = unbindVolumes(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	err = removeRuns(ctx, job.Name)
+	if err != nil {
+		return err
+	}
+
 	servicemanager.TeamQuota.Inc(ctx, &authTypes.Team{Name: job.TeamOwner}, -1)
 	var user *auth.User
 	if user, err = auth.GetUserByEmail(ctx, job.Owner); err == nil {
@@ -113,6 +124,30 @@ func (*jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
 	return nil
 }
 
+func unbindVolumes(ctx context.Context, job *jobTypes.Job) error {
+	volumes, err := servicemanager.Volume.ListByJob(ctx, job.Name)
+	if err != nil {
+		return errors.Wrap(err, "unable to list volumes for unbind")
+	}
+	for i := range volumes {
+		binds, err := servicemanager.Volume.BindsForJob(ctx, &volumes[i], job.Name)
+		if err != nil {
+			return errors.Wrap(err, "unable to list volume binds for unbind")
+		}
+		for _, b := range binds {
+			err = servicemanager.Volume.UnbindJob(ctx, &volumeTypes.BindOpts{
+				Volume:     &volumes[i],
+				JobName:    job.Name,
+				MountPoint: b.ID.MountPoint,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "unable to unbind volume %q in %q", volumes[i].Name, b.ID.MountPoint)
+			}
+		}
+	}
+	return nil
+}
+
 func (*jobService) RemoveJobProv(ctx context.Context, job *jobTypes.Job) error {
 	prov, err := getProvisioner(ctx, job)
 	if err != nil {
@@ -468,6 +503,9 @@ func filterQuery(f *jobTypes.Filter) mongoBSON.M {
 	if len(tags) > 0 {
 		query["tags"] = mongoBSON.M{"$all": tags}
 	}
+	if f.ExpiresBefore != nil {
+		query["expiresat"] = mongoBSON.M{"$lte": *f.ExpiresBefore}
+	}
 	return query
 }
 
@@ -690,5 +728,38 @@ func validateJob(ctx context.Context, j *jobTypes.Job) error {
 			return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidConcurrencyPolicy.Error()}
 		}
 	}
+	if j.Spec.RunsHistoryLimit != nil && *j.Spec.RunsHistoryLimit < 0 {
+		return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidRunsHistoryLimit.Error()}
+	}
+	return validateTriggers(ctx, j)
+}
+
+// validateTriggers checks that every triggered job exists and that following
+// the triggers never leads back to the job.
+func validateTriggers(ctx context.Context, j *jobTypes.Job) error {
+	visited := map[string]bool{}
+	pending := j.Spec.Triggers.Jobs()
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if name == j.Name {
+			return &tsuruErrors.ValidationError{Message: jobTypes.ErrJobTriggersCycle.Error()}
+		}
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		triggered, err := servicemanager.Job.GetByName(ctx, name)
+		if err == jobTypes.ErrJobNotFound {
+			if set.FromSlice(j.Spec.Triggers.Jobs()).Includes(name) {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid triggers, job %q not found", name)}
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pending = append(pending, triggered.Spec.Triggers.Jobs()...)
+	}
 	return nil
 }
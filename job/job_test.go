@@ -1498,3 +1498,99 @@ func (s *S) TestRemoveInstanceMultipleServicesEnvs(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(createdJob1.Spec.ServiceEnvs, check.DeepEquals, []bindTypes.ServiceEnvVar{})
 }
+
+func (s *S) TestCreateJobWithTriggers(c *check.C) {
+	downstream := jobTypes.Job{
+		Name:      "downstream",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Manual: true,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &downstream, s.user)
+	c.Assert(err, check.IsNil)
+	upstream := jobTypes.Job{
+		Name:      "upstream",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Triggers: &jobTypes.JobTriggers{OnSuccess: []string{"downstream"}},
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err = servicemanager.Job.CreateJob(context.TODO(), &upstream, s.user)
+	c.Assert(err, check.IsNil)
+	myJob, err := servicemanager.Job.GetByName(context.TODO(), upstream.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(myJob.Spec.Triggers, check.DeepEquals, &jobTypes.JobTriggers{OnSuccess: []string{"downstream"}})
+}
+
+func (s *S) TestCreateJobWithTriggersJobNotFound(c *check.C) {
+	newJob := jobTypes.Job{
+		Name:      "upstream",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Triggers: &jobTypes.JobTriggers{OnFailure: []string{"unknown"}},
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &newJob, s.user)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `invalid triggers, job "unknown" not found`)
+}
+
+func (s *S) TestUpdateJobWithTriggersCycle(c *check.C) {
+	first := jobTypes.Job{
+		Name:      "first",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &first, s.user)
+	c.Assert(err, check.IsNil)
+	second := jobTypes.Job{
+		Name:      "second",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Manual:   true,
+			Triggers: &jobTypes.JobTriggers{OnSuccess: []string{"first"}},
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err = servicemanager.Job.CreateJob(context.TODO(), &second, s.user)
+	c.Assert(err, check.IsNil)
+	oldJob, err := servicemanager.Job.GetByName(context.TODO(), first.Name)
+	c.Assert(err, check.IsNil)
+	newJob := jobTypes.Job{
+		Name: first.Name,
+		Spec: jobTypes.JobSpec{
+			Triggers: &jobTypes.JobTriggers{OnFailure: []string{"second"}},
+		},
+	}
+	err = servicemanager.Job.UpdateJob(context.TODO(), &newJob, oldJob, s.user)
+	c.Assert(err, check.ErrorMatches, jobTypes.ErrJobTriggersCycle.Error())
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const defaultRunsHistoryLimit = 20
+
+// RunsHistoryLimit returns how many runs are kept for the job, it may be set
+// in the job spec and defaults to the jobs:runs-history-limit config.
+func RunsHistoryLimit(job *jobTypes.Job) int {
+	if job != nil && job.Spec.RunsHistoryLimit != nil && *job.Spec.RunsHistoryLimit > 0 {
+		return *job.Spec.RunsHistoryLimit
+	}
+	limit, err := config.GetInt("jobs:runs-history-limit")
+	if err != nil || limit <= 0 {
+		return defaultRunsHistoryLimit
+	}
+	return limit
+}
+
+// SaveRun stores the run, replacing any previous record with the same ID,
+// and removes the oldest runs of the job exceeding its history limit.
+func (s *jobService) SaveRun(ctx context.Context, run *jobTypes.JobRun) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": run.ID}, run, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	job, err := s.GetByName(ctx, run.Job)
+	if err != nil && err != jobTypes.ErrJobNotFound {
+		return err
+	}
+	return pruneRuns(ctx, run.Job, RunsHistoryLimit(job))
+}
+
+func pruneRuns(ctx context.Context, jobName string, limit int) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	opts := options.Find().
+		SetSort(mongoBSON.M{"starttime": -1}).
+		SetSkip(int64(limit)).
+		SetProjection(mongoBSON.M{"_id": 1})
+	cursor, err := collection.Find(ctx, mongoBSON.M{"job": jobName}, opts)
+	if err != nil {
+		return err
+	}
+	var old []jobTypes.JobRun
+	err = cursor.All(ctx, &old)
+	if err != nil || len(old) == 0 {
+		return err
+	}
+	ids := make([]string, len(old))
+	for i, run := range old {
+		ids[i] = run.ID
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": ids}})
+	return err
+}
+
+// ListRuns returns the recorded runs of the job, newest first. The logs of
+// each run are not included.
+func (*jobService) ListRuns(ctx context.Context, job *jobTypes.Job) ([]jobTypes.JobRun, error) {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().
+		SetSort(mongoBSON.M{"starttime": -1}).
+		SetProjection(mongoBSON.M{"log": 0})
+	cursor, err := collection.Find(ctx, mongoBSON.M{"job": job.Name}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var runs []jobTypes.JobRun
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (*jobService) GetRun(ctx context.Context, job *jobTypes.Job, id string) (*jobTypes.JobRun, error) {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var run jobTypes.JobRun
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "job": job.Name}).Decode(&run)
+	if err == mongo.ErrNoDocuments {
+		return nil, jobTypes.ErrJobRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func removeRuns(ctx context.Context, jobName string) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"job": jobName})
+	return err
+}
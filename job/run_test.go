@@ -0,0 +1,157 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) createRunsJob(c *check.C, limit *int) *jobTypes.Job {
+	newJob := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule:         "* * * * *",
+			RunsHistoryLimit: limit,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &newJob, s.user)
+	c.Assert(err, check.IsNil)
+	job, err := servicemanager.Job.GetByName(context.TODO(), newJob.Name)
+	c.Assert(err, check.IsNil)
+	return job
+}
+
+func (s *S) saveRuns(c *check.C, job *jobTypes.Job, n int) time.Time {
+	start := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		err := servicemanager.Job.SaveRun(context.TODO(), &jobTypes.JobRun{
+			ID:        fmt.Sprintf("run-%d", i),
+			Job:       job.Name,
+			Unit:      fmt.Sprintf("%s-%d", job.Name, i),
+			Trigger:   jobTypes.RunTriggerCron,
+			Status:    jobTypes.RunStatusSucceeded,
+			StartTime: start.Add(time.Duration(i) * time.Minute),
+			Log:       fmt.Sprintf("log %d", i),
+		})
+		c.Assert(err, check.IsNil)
+	}
+	return start
+}
+
+func (s *S) TestSaveAndListRuns(c *check.C) {
+	job := s.createRunsJob(c, nil)
+	s.saveRuns(c, job, 3)
+	exitCode := int32(1)
+	err := servicemanager.Job.SaveRun(context.TODO(), &jobTypes.JobRun{
+		ID:        "run-2",
+		Job:       job.Name,
+		Unit:      job.Name + "-2",
+		Trigger:   jobTypes.RunTriggerCron,
+		Status:    jobTypes.RunStatusFailed,
+		StartTime: time.Date(2026, 5, 10, 12, 2, 0, 0, time.UTC),
+		ExitCode:  &exitCode,
+		Reason:    "BackoffLimitExceeded",
+		Log:       "boom",
+	})
+	c.Assert(err, check.IsNil)
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), job)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 3)
+	var ids []string
+	for _, run := range runs {
+		ids = append(ids, run.ID)
+		c.Assert(run.Log, check.Equals, "")
+	}
+	c.Assert(ids, check.DeepEquals, []string{"run-2", "run-1", "run-0"})
+	c.Assert(runs[0].Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(*runs[0].ExitCode, check.Equals, int32(1))
+	c.Assert(runs[0].Reason, check.Equals, "BackoffLimitExceeded")
+}
+
+func (s *S) TestGetRun(c *check.C) {
+	job := s.createRunsJob(c, nil)
+	s.saveRuns(c, job, 2)
+	run, err := servicemanager.Job.GetRun(context.TODO(), job, "run-1")
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Unit, check.Equals, "some-job-1")
+	c.Assert(run.Log, check.Equals, "log 1")
+	_, err = servicemanager.Job.GetRun(context.TODO(), job, "run-9")
+	c.Assert(err, check.Equals, jobTypes.ErrJobRunNotFound)
+	_, err = servicemanager.Job.GetRun(context.TODO(), &jobTypes.Job{Name: "other-job"}, "run-1")
+	c.Assert(err, check.Equals, jobTypes.ErrJobRunNotFound)
+}
+
+func (s *S) TestSaveRunPrunesHistory(c *check.C) {
+	limit := 2
+	job := s.createRunsJob(c, &limit)
+	s.saveRuns(c, job, 4)
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), job)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 2)
+	c.Assert(runs[0].ID, check.Equals, "run-3")
+	c.Assert(runs[1].ID, check.Equals, "run-2")
+}
+
+func (s *S) TestSaveRunPrunesHistoryFromConfig(c *check.C) {
+	config.Set("jobs:runs-history-limit", 3)
+	defer config.Unset("jobs:runs-history-limit")
+	job := s.createRunsJob(c, nil)
+	s.saveRuns(c, job, 5)
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), job)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 3)
+	c.Assert(runs[2].ID, check.Equals, "run-2")
+}
+
+func (s *S) TestRunsHistoryLimit(c *check.C) {
+	c.Assert(RunsHistoryLimit(nil), check.Equals, 20)
+	limit := 5
+	c.Assert(RunsHistoryLimit(&jobTypes.Job{Spec: jobTypes.JobSpec{RunsHistoryLimit: &limit}}), check.Equals, 5)
+	config.Set("jobs:runs-history-limit", 10)
+	defer config.Unset("jobs:runs-history-limit")
+	c.Assert(RunsHistoryLimit(&jobTypes.Job{}), check.Equals, 10)
+}
+
+func (s *S) TestCreateJobInvalidRunsHistoryLimit(c *check.C) {
+	limit := -1
+	newJob := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule:         "* * * * *",
+			RunsHistoryLimit: &limit,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+				Command:          []string{"echo", "hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &newJob, s.user)
+	c.Assert(err, check.ErrorMatches, ".*"+jobTypes.ErrInvalidRunsHistoryLimit.Error())
+}
+
+func (s *S) TestRemoveJobRemovesRuns(c *check.C) {
+	job := s.createRunsJob(c, nil)
+	s.saveRuns(c, job, 2)
+	err := servicemanager.Job.RemoveJob(context.TODO(), job)
+	c.Assert(err, check.IsNil)
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), job)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 0)
+}
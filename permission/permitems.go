@@ -14,8 +14,10 @@ var (
 	PermApikeyUpdate                     = PermissionRegistry.get("apikey.update")                       // [global user]
 	PermApp                              = PermissionRegistry.get("app")                                 // [global app team pool]
 	PermAppAdmin                         = PermissionRegistry.get("app.admin")                           // [global app team pool]
+	PermAppAdminExport                   = PermissionRegistry.get("app.admin.export")                    // [global app team pool]
 	PermAppAdminQuota                    = PermissionRegistry.get("app.admin.quota")                     // [global app team pool]
 	PermAppAdminRoutes                   = PermissionRegistry.get("app.admin.routes")                    // [global app team pool]
+	PermAppAdminSkipScan                 = PermissionRegistry.get("app.admin.skip-scan")                 // [global app team pool]
 	PermAppBuild                         = PermissionRegistry.get("app.build")                           // [global app team pool]
 	PermAppCreate                        = PermissionRegistry.get("app.create")                          // [global team]
 	PermAppDelete                        = PermissionRegistry.get("app.delete")                          // [global app team pool]
@@ -29,17 +31,23 @@ var (
 	PermAppDeployUpload                  = PermissionRegistry.get("app.deploy.upload")                   // [global app team pool]
 	PermAppRead                          = PermissionRegistry.get("app.read")                            // [global app team pool]
 	PermAppReadCertificate               = PermissionRegistry.get("app.read.certificate")                // [global app team pool]
+	PermAppReadClone                     = PermissionRegistry.get("app.read.clone")                      // [global app team pool]
 	PermAppReadDeploy                    = PermissionRegistry.get("app.read.deploy")                     // [global app team pool]
 	PermAppReadEnv                       = PermissionRegistry.get("app.read.env")                        // [global app team pool]
 	PermAppReadEvents                    = PermissionRegistry.get("app.read.events")                     // [global app team pool]
+	PermAppReadExport                    = PermissionRegistry.get("app.read.export")                     // [global app team pool]
 	PermAppReadInfo                      = PermissionRegistry.get("app.read.info")                       // [global app team pool]
 	PermAppReadLog                       = PermissionRegistry.get("app.read.log")                        // [global app team pool]
 	PermAppReadRouter                    = PermissionRegistry.get("app.read.router")                     // [global app team pool]
+	PermAppReadSbom                      = PermissionRegistry.get("app.read.sbom")                       // [global app team pool]
 	PermAppRun                           = PermissionRegistry.get("app.run")                             // [global app team pool]
 	PermAppRunShell                      = PermissionRegistry.get("app.run.shell")                       // [global app team pool]
 	PermAppUpdate                        = PermissionRegistry.get("app.update")                          // [global app team pool]
 	PermAppUpdateBind                    = PermissionRegistry.get("app.update.bind")                     // [global app team pool]
 	PermAppUpdateBindVolume              = PermissionRegistry.get("app.update.bind-volume")              // [global app team pool]
+	PermAppUpdateBuildCache              = PermissionRegistry.get("app.update.build-cache")              // [global app team pool]
+	PermAppUpdateBuildCachePurge         = PermissionRegistry.get("app.update.build-cache.purge")        // [global app team pool]
+	PermAppUpdateCanary                  = PermissionRegistry.get("app.update.canary")                   // [global app team pool]
 	PermAppUpdateCertificate             = PermissionRegistry.get("app.update.certificate")              // [global app team pool]
 	PermAppUpdateCertificateSet          = PermissionRegistry.get("app.update.certificate.set")          // [global app team pool]
 	PermAppUpdateCertificateUnset        = PermissionRegistry.get("app.update.certificate.unset")        // [global app team pool]
@@ -53,6 +61,7 @@ var (
 	PermAppUpdateEnvSet                  = PermissionRegistry.get("app.update.env.set")                  // [global app team pool]
 	PermAppUpdateEnvUnset                = PermissionRegistry.get("app.update.env.unset")                // [global app team pool]
 	PermAppUpdateEvents                  = PermissionRegistry.get("app.update.events")                   // [global app team pool]
+	PermAppUpdateExpiration              = PermissionRegistry.get("app.update.expiration")               // [global app team pool]
 	PermAppUpdateGrant                   = PermissionRegistry.get("app.update.grant")                    // [global app team pool]
 	PermAppUpdateImageReset              = PermissionRegistry.get("app.update.image-reset")              // [global app team pool]
 	PermAppUpdateLog                     = PermissionRegistry.get("app.update.log")                      // [global app team pool]
@@ -68,6 +77,7 @@ var (
 	PermAppUpdateRouterAdd               = PermissionRegistry.get("app.update.router.add")               // [global app team pool]
 	PermAppUpdateRouterRemove            = PermissionRegistry.get("app.update.router.remove")            // [global app team pool]
 	PermAppUpdateRouterUpdate            = PermissionRegistry.get("app.update.router.update")            // [global app team pool]
+	PermAppUpdateSbom                    = PermissionRegistry.get("app.update.sbom")                     // [global app team pool]
 	PermAppUpdateStart                   = PermissionRegistry.get("app.update.start")                    // [global app team pool]
 	PermAppUpdateStop                    = PermissionRegistry.get("app.update.stop")                     // [global app team pool]
 	PermAppUpdateTags                    = PermissionRegistry.get("app.update.tags")                     // [global app team pool]
@@ -111,6 +121,7 @@ var (
 	PermJobUnitKill                      = PermissionRegistry.get("job.unit.kill")                       // [global team pool job]
 	PermJobUpdate                        = PermissionRegistry.get("job.update")                          // [global team pool job]
 	PermJobUpdateEvents                  = PermissionRegistry.get("job.update.events")                   // [global team pool job]
+	PermJobUpdateExpiration              = PermissionRegistry.get("job.update.expiration")               // [global team pool job]
 	PermPlan                             = PermissionRegistry.get("plan")                                // [global]
 	PermPlanCreate                       = PermissionRegistry.get("plan.create")                         // [global]
 	PermPlanDelete                       = PermissionRegistry.get("plan.delete")                         // [global]
@@ -129,9 +140,11 @@ var (
 	PermPoolRead                         = PermissionRegistry.get("pool.read")                           // [global pool]
 	PermPoolReadConstraints              = PermissionRegistry.get("pool.read.constraints")               // [global pool]
 	PermPoolReadEvents                   = PermissionRegistry.get("pool.read.events")                    // [global pool]
+	PermPoolReadQuota                    = PermissionRegistry.get("pool.read.quota")                     // [global pool]
 	PermPoolUpdate                       = PermissionRegistry.get("pool.update")                         // [global pool]
 	PermPoolUpdateConstraints            = PermissionRegistry.get("pool.update.constraints")             // [global pool]
 	PermPoolUpdateConstraintsSet         = PermissionRegistry.get("pool.update.constraints.set")         // [global pool]
+	PermPoolUpdateQuota                  = PermissionRegistry.get("pool.update.quota")                   // [global pool]
 	PermPoolUpdateTeam                   = PermissionRegistry.get("pool.update.team")                    // [global pool]
 	PermPoolUpdateTeamAdd                = PermissionRegistry.get("pool.update.team.add")                // [global pool]
 	PermPoolUpdateTeamRemove             = PermissionRegistry.get("pool.update.team.remove")             // [global pool]
@@ -194,6 +207,7 @@ var (
 	PermTeamRead                         = PermissionRegistry.get("team.read")                           // [global team]
 	PermTeamReadEvents                   = PermissionRegistry.get("team.read.events")                    // [global team]
 	PermTeamReadQuota                    = PermissionRegistry.get("team.read.quota")                     // [global team]
+	PermTeamReadUsage                    = PermissionRegistry.get("team.read.usage")                     // [global team]
 	PermTeamToken                        = PermissionRegistry.get("team.token")                          // [global team]
 	PermTeamTokenCreate                  = PermissionRegistry.get("team.token.create")                   // [global team]
 	PermTeamTokenDelete                  = PermissionRegistry.get("team.token.delete")                   // [global team]
@@ -207,10 +221,16 @@ var (
 	PermUserRead                         = PermissionRegistry.get("user.read")                           // [global user]
 	PermUserReadEvents                   = PermissionRegistry.get("user.read.events")                    // [global user]
 	PermUserReadQuota                    = PermissionRegistry.get("user.read.quota")                     // [global user]
+	PermUserToken                        = PermissionRegistry.get("user.token")                          // [global user]
+	PermUserTokenCreate                  = PermissionRegistry.get("user.token.create")                   // [global user]
+	PermUserTokenDelete                  = PermissionRegistry.get("user.token.delete")                   // [global user]
+	PermUserTokenRead                    = PermissionRegistry.get("user.token.read")                     // [global user]
 	PermUserUpdate                       = PermissionRegistry.get("user.update")                         // [global user]
+	PermUserUpdateMfa                    = PermissionRegistry.get("user.update.mfa")                     // [global user]
 	PermUserUpdatePassword               = PermissionRegistry.get("user.update.password")                // [global user]
 	PermUserUpdateQuota                  = PermissionRegistry.get("user.update.quota")                   // [global user]
 	PermUserUpdateReset                  = PermissionRegistry.get("user.update.reset")                   // [global user]
+	PermUserUpdateUnlock                 = PermissionRegistry.get("user.update.unlock")                  // [global user]
 	PermVolume                           = PermissionRegistry.get("volume")                              // [global volume team pool]
 	PermVolumeCreate                     = PermissionRegistry.get("volume.create")                       // [global team pool]
 	PermVolumeDelete                     = PermissionRegistry.get("volume.delete")                       // [global volume team pool]
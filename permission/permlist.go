@@ -16,6 +16,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.create", []permTypes.ContextType{permTypes.CtxTeam},
 ).add(
 	"app.update.description",
+	"app.update.expiration",
 	"app.update.tags",
 	"app.update.log",
 	"app.update.pool",
@@ -40,6 +41,9 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.platform",
 	"app.update.bind",
 	"app.update.bind-volume",
+	"app.update.build-cache",
+	"app.update.build-cache.purge",
+	"app.update.sbom",
 	"app.update.image-reset",
 	"app.update.events",
 	"app.update.processes",
@@ -52,6 +56,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.router.update",
 	"app.update.router.remove",
 	"app.update.routable",
+	"app.update.canary",
 	"app.update.metadata",
 	"app.deploy",
 	"app.deploy.archive-url",
@@ -69,11 +74,16 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.read.log",
 	"app.read.certificate",
 	"app.read.info",
+	"app.read.export",
+	"app.read.clone",
+	"app.read.sbom",
 	"app.delete",
 	"app.run",
 	"app.run.shell",
 	"app.admin.routes",
 	"app.admin.quota",
+	"app.admin.export",
+	"app.admin.skip-scan",
 	"app.build",
 ).addWithCtx(
 	"certissuer", []permTypes.ContextType{permTypes.CtxApp, permTypes.CtxTeam, permTypes.CtxPool},
@@ -93,6 +103,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"team.token.delete",
 	"team.token.update",
 	"team.read.quota",
+	"team.read.usage",
 	"team.update.quota",
 ).addWithCtx(
 	"user", []permTypes.ContextType{permTypes.CtxUser},
@@ -105,6 +116,11 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"user.update.quota",
 	"user.update.password",
 	"user.update.reset",
+	"user.update.mfa",
+	"user.update.unlock",
+	"user.token.read",
+	"user.token.create",
+	"user.token.delete",
 ).addWithCtx(
 	"apikey", []permTypes.ContextType{permTypes.CtxUser},
 ).add(
@@ -172,7 +188,9 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"pool.update.team.add",
 	"pool.update.team.remove",
 	"pool.update.constraints.set",
+	"pool.update.quota",
 	"pool.read.constraints",
+	"pool.read.quota",
 	"pool.delete",
 ).add(
 	"debug",
@@ -218,6 +236,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"job.create", []permTypes.ContextType{permTypes.CtxTeam},
 ).add(
 	"job.update",
+	"job.update.expiration",
 ).add(
 	"job.run",
 ).add(
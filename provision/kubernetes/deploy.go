@@ -35,6 +35,7 @@ import (
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/servicecommon"
 	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/secret"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 	"github.com/tsuru/tsuru/streamfmt"
@@ -69,6 +70,8 @@ const (
 	backendConfigKey        = "cloud.google.com/backend-config"
 	appSecretPrefix         = "tsuru-app-"
 	secretHashAnnotationKey = "tsuru.io/secret-sha256"
+	secretAppLabel          = "tsuru.io/secret-app"
+	secretTeamLabel         = "tsuru.io/secret-team"
 )
 
 func keepAliveSpdyExecutor(config *rest.Config, method string, url *url.URL) (remotecommand.Executor, error) {
@@ -617,7 +620,10 @@ func ensureAppSecret(ctx context.Context, opts *ensureAppSecretOptions) (*apiv1.
 		}
 		oldSecret = nil
 	}
-	envs := appSecretEnvs(opts.app, opts.process, opts.version)
+	envs, err := appSecretEnvs(ctx, opts.app, opts.process, opts.version)
+	if err != nil {
+		return nil, err
+	}
 
 	labels := provision.SecretLabels(provision.SecretLabelsOpts{
 		App:    opts.app,
@@ -1013,7 +1019,19 @@ func appEnvs(a *appTypes.App, process string, secretName string, version appType
 	appEnvs := envsForApp(a, process, version)
 	envs := make([]apiv1.EnvVar, len(appEnvs))
 	for i, envData := range appEnvs {
-		if disableSecrets || envData.Public {
+		if envData.SecretRef != nil && envData.SecretRef.Provider == secret.KubernetesProvider {
+			envs[i] = apiv1.EnvVar{
+				Name: envData.Name,
+				ValueFrom: &apiv1.EnvVarSource{
+					SecretKeyRef: &apiv1.SecretKeySelector{
+						LocalObjectReference: apiv1.LocalObjectReference{
+							Name: envData.SecretRef.Path,
+						},
+						Key: envData.SecretRef.Key,
+					},
+				},
+			}
+		} else if (disableSecrets && envData.SecretRef == nil) || envData.Public {
 			envs[i] = apiv1.EnvVar{
 				Name:  envData.Name,
 				Value: strings.ReplaceAll(envData.Value, "$", "$$"),
@@ -1035,7 +1053,10 @@ func appEnvs(a *appTypes.App, process string, secretName string, version appType
 	return envs
 }
 
-func appSecretEnvs(a *appTypes.App, process string, version appTypes.AppVersion) map[string][]byte {
+// appSecretEnvs returns the private envs of the app, resolving the ones that
+// reference external secret providers. References to kubernetes secrets are
+// mounted directly from the referenced secret, so they're not included.
+func appSecretEnvs(ctx context.Context, a *appTypes.App, process string, version appTypes.AppVersion) (map[string][]byte, error) {
 	appEnvs := envsForApp(a, process, version)
 
 	result := map[string][]byte{}
@@ -1044,9 +1065,19 @@ func appSecretEnvs(a *appTypes.App, process string, version appTypes.AppVersion)
 		if envData.Public {
 			continue
 		}
+		if envData.SecretRef != nil {
+			if envData.SecretRef.Provider == secret.KubernetesProvider {
+				continue
+			}
+			value, err := secret.Resolve(ctx, a, *envData.SecretRef)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to resolve environment variable %q", envData.Name)
+			}
+			envData.Value = value
+		}
 		result[envData.Name] = []byte(envData.Value)
 	}
-	return result
+	return result, nil
 }
 
 type serviceManager struct {
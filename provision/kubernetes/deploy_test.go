@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -24,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/servicecommon"
+	"github.com/tsuru/tsuru/secret/secrettest"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
@@ -3340,6 +3342,108 @@ func (s *S) TestServiceManagerDeployServiceWithEscapedEnvs(c *check.C) {
 	}, secret.Data)
 }
 
+func (s *S) TestServiceManagerDeployServiceWithSecretRefEnvs(c *check.C) {
+	provider := &secrettest.FileProvider{Path: filepath.Join(c.MkDir(), "secrets.json")}
+	err := provider.WriteFile(map[string]map[string]string{
+		"myapp/db": {"password": "s3cr3t"},
+	})
+	require.NoError(s.t, err)
+	config.Set("secret-providers:local:type", "file")
+	config.Set("secret-providers:local:path", provider.Path)
+	config.Set("secret-providers:local:path-prefix", "{app}")
+	defer config.Unset("secret-providers")
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	a.Env = map[string]bindTypes.EnvVar{
+		"DB_PASSWORD": {
+			Name:      "DB_PASSWORD",
+			SecretRef: &bindTypes.SecretRef{Provider: "local", Path: "myapp/db", Key: "password"},
+		},
+		"API_KEY": {
+			Name:      "API_KEY",
+			SecretRef: &bindTypes.SecretRef{Provider: "kubernetes", Path: "team-credentials", Key: "api-key"},
+		},
+	}
+	err = app.CreateApp(context.TODO(), a, s.user)
+	require.NoError(s.t, err)
+	version := newCommittedVersion(c, a, map[string][]string{
+		"p1": {"cm1"},
+	})
+
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:              a,
+		Version:          version,
+		PreserveVersions: true,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true, Restart: true},
+	})
+	require.NoError(s.t, err)
+	waitDep()
+
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	require.NoError(s.t, err)
+
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	require.NoError(s.t, err)
+	envs := map[string]apiv1.EnvVar{}
+	for _, env := range dep.Spec.Template.Spec.Containers[0].Env {
+		envs[env.Name] = env
+	}
+	require.EqualValues(s.t, apiv1.EnvVar{Name: "DB_PASSWORD", ValueFrom: &apiv1.EnvVarSource{
+		SecretKeyRef: &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{
+				Name: appSecretPrefix + "myapp-p1",
+			},
+			Key: "DB_PASSWORD",
+		},
+	}}, envs["DB_PASSWORD"])
+	require.EqualValues(s.t, apiv1.EnvVar{Name: "API_KEY", ValueFrom: &apiv1.EnvVarSource{
+		SecretKeyRef: &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{
+				Name: "team-credentials",
+			},
+			Key: "api-key",
+		},
+	}}, envs["API_KEY"])
+
+	secret, err := s.client.Clientset.CoreV1().Secrets(ns).Get(context.TODO(), appSecretPrefix+"myapp-p1", metav1.GetOptions{})
+	require.NoError(s.t, err)
+	require.EqualValues(s.t, map[string][]byte{
+		"TSURU_SERVICES": []byte("{}"),
+		"DB_PASSWORD":    []byte("s3cr3t"),
+	}, secret.Data)
+}
+
+func (s *S) TestServiceManagerDeployServiceWithUnresolvableSecretRef(c *check.C) {
+	config.Set("secret-providers:local:type", "file")
+	config.Set("secret-providers:local:path", filepath.Join(c.MkDir(), "missing.json"))
+	config.Set("secret-providers:local:path-prefix", "{app}")
+	defer config.Unset("secret-providers")
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	a.Env = map[string]bindTypes.EnvVar{
+		"DB_PASSWORD": {
+			Name:      "DB_PASSWORD",
+			SecretRef: &bindTypes.SecretRef{Provider: "local", Path: "myapp/db", Key: "password"},
+		},
+	}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	require.NoError(s.t, err)
+	version := newCommittedVersion(c, a, map[string][]string{
+		"p1": {"cm1"},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:              a,
+		Version:          version,
+		PreserveVersions: true,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true, Restart: true},
+	})
+	require.ErrorContains(s.t, err, `unable to resolve environment variable "DB_PASSWORD"`)
+}
+
 func (s *S) TestServiceManagerDeployServiceWithVolumes(c *check.C) {
 	config.Set("docker:uid", 1001)
 	defer config.Unset("docker:uid")
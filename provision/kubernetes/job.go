@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -17,9 +18,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	batchv1 "k8s.io/api/batch/v1"
@@ -29,6 +33,8 @@ import (
 	k8sLabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/utils/ptr"
 
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 )
@@ -39,6 +45,10 @@ const (
 	expireTTL     = time.Hour * 24 // 1 day
 
 	jobSecretPrefix = "tsuru-job-"
+
+	tsuruJobTriggerAnnotation  = "tsuru.io/job-trigger"
+	tsuruJobWorkflowAnnotation = "tsuru.io/job-workflow"
+	defaultJobRunLogLines      = 100
 )
 
 var (
@@ -64,7 +74,7 @@ var (
 	}, []string{"job_name"})
 )
 
-func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations map[string]string) (batchv1.JobSpec, error) {
+func buildJobSpec(ctx context.Context, job *jobTypes.Job, client *ClusterClient, labels, annotations map[string]string) (batchv1.JobSpec, error) {
 	disableSecrets := client.disableSecrets(job.Pool)
 
 	jSpec := job.Spec
@@ -121,6 +131,11 @@ func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations
 		}
 	}
 
+	volumes, mounts, err := createVolumesForJob(ctx, client, job)
+	if err != nil {
+		return batchv1.JobSpec{}, err
+	}
+
 	imageURL := jSpec.Container.InternalRegistryImage
 	if imageURL == "" {
 		imageURL = jSpec.Container.OriginalImageSrc
@@ -141,13 +156,15 @@ func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations
 				RestartPolicy: "OnFailure",
 				Containers: []apiv1.Container{
 					{
-						Name:      "job",
-						Image:     imageURL,
-						Command:   jSpec.Container.Command,
-						Resources: requirements,
-						Env:       envs,
+						Name:         "job",
+						Image:        imageURL,
+						Command:      jSpec.Container.Command,
+						Resources:    requirements,
+						Env:          envs,
+						VolumeMounts: mounts,
 					},
 				},
+				Volumes:            volumes,
 				ServiceAccountName: serviceAccountNameForJob(*job),
 			},
 		},
@@ -219,7 +236,7 @@ func findAllCronJobsForJob(ctx context.Context, client *ClusterClient, jobName,
 
 func ensureCronjob(ctx context.Context, client *ClusterClient, job *jobTypes.Job) error {
 	labels, annotations := buildMetadata(ctx, job)
-	jobSpec, err := buildJobSpec(job, client, labels, annotations)
+	jobSpec, err := buildJobSpec(ctx, job, client, labels, annotations)
 	if err != nil {
 		return err
 	}
@@ -460,6 +477,14 @@ func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, job *jobTypes.J
 	} else {
 		cronChild.Annotations["cronjob.kubernetes.io/instantiate"] = "manual"
 	}
+	cronChild.Annotations[tsuruJobTriggerAnnotation] = jobTypes.RunTriggerAPI
+	if job.Spec.Manual {
+		cronChild.Annotations[tsuruJobTriggerAnnotation] = jobTypes.RunTriggerManual
+	}
+	if workflow := jobTypes.WorkflowFromContext(ctx); workflow != "" {
+		cronChild.Annotations[tsuruJobTriggerAnnotation] = jobTypes.RunTriggerWorkflow
+		cronChild.Annotations[tsuruJobWorkflowAnnotation] = workflow
+	}
 	_, err = client.BatchV1().Jobs(cron.Namespace).Create(ctx, &cronChild, metav1.CreateOptions{})
 	if err != nil && k8sErrors.IsAlreadyExists(err) {
 		return errors.Errorf("manual job %q already exists (cronjobs can only be triggered once per minute)", cronChild.Name)
@@ -535,7 +560,7 @@ func (p *kubernetesProvisioner) KillJobUnit(ctx context.Context, job *jobTypes.J
 		return &provision.UnitNotFoundError{ID: unit}
 	}
 
-	pods, err := p.getPodsForJob(ctx, client, k8sJob)
+	pods, err := getPodsForJob(ctx, client, k8sJob)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -575,7 +600,7 @@ func removePodsForJob(ctx context.Context, client *ClusterClient, podList []apiv
 	return nil
 }
 
-func (p *kubernetesProvisioner) getPodsForJob(ctx context.Context, client *ClusterClient, job *batchv1.Job) ([]apiv1.Pod, error) {
+func getPodsForJob(ctx context.Context, client *ClusterClient, job *batchv1.Job) ([]apiv1.Pod, error) {
 	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"job-name": job.Name}}
 	listOptions := metav1.ListOptions{
 		LabelSelector: k8sLabels.Set(labelSelector.MatchLabels).String(),
@@ -596,7 +621,7 @@ func (p *kubernetesProvisioner) jobsToJobUnits(ctx context.Context, client *Clus
 		var status provTypes.UnitStatus
 		var statusReason string
 		var restarts int32
-		pods, err := p.getPodsForJob(ctx, client, &k8sJob)
+		pods, err := getPodsForJob(ctx, client, &k8sJob)
 		if err != nil {
 			return nil, err
 		}
@@ -702,6 +727,11 @@ func createJobEvent(clusterClient *ClusterClient, job *batchv1.Job, evt *apiv1.E
 		Cancelable: false,
 		ExpireAt:   &expire,
 	}
+	if workflow := job.Annotations[tsuruJobWorkflowAnnotation]; workflow != "" {
+		opts.ExtraTargets = []eventTypes.ExtraTarget{
+			{Target: eventTypes.Target{Type: eventTypes.TargetTypeJobWorkflow, Value: workflow}},
+		}
+	}
 	e, err := event.New(ctx, &opts)
 	if err != nil {
 		return
@@ -717,6 +747,188 @@ func createJobEvent(clusterClient *ClusterClient, job *batchv1.Job, evt *apiv1.E
 	e.DoneCustomData(ctx, evtErr, customData)
 }
 
+func recordJobRun(clusterClient *ClusterClient, job *batchv1.Job, evt *apiv1.Event, wg *sync.WaitGroup) {
+	ctx := context.Background()
+	defer wg.Done()
+	switch evt.Reason {
+	case "SuccessfulCreate", "Completed", "BackoffLimitExceeded", "DeadlineExceeded":
+	default:
+		return
+	}
+	run, err := jobRunFromK8s(ctx, clusterClient, job)
+	if err == nil {
+		err = servicemanager.Job.SaveRun(ctx, run)
+	}
+	if err != nil {
+		log.Errorf("[job runs] unable to record run %q of job %q: %v", job.Name, job.Labels[tsuruLabelJobName], err)
+	}
+}
+
+// triggerJobWorkflow triggers the jobs listed in the triggers of the finished
+// job. Every run of a workflow shares the workflow ID, which is the ID of the
+// run that started it, and the trigger events are linked to it as an extra
+// target so the whole workflow can be listed with a single events query.
+func triggerJobWorkflow(job *batchv1.Job, evt *apiv1.Event, wg *sync.WaitGroup) {
+	ctx := context.Background()
+	defer wg.Done()
+	var succeeded bool
+	switch evt.Reason {
+	case "Completed":
+		succeeded = true
+	case "BackoffLimitExceeded", "DeadlineExceeded":
+	default:
+		return
+	}
+	jobName := job.Labels[tsuruLabelJobName]
+	tsuruJob, err := servicemanager.Job.GetByName(ctx, jobName)
+	if err != nil {
+		if err != jobTypes.ErrJobNotFound {
+			log.Errorf("[job workflow] unable to get job %q: %v", jobName, err)
+		}
+		return
+	}
+	if tsuruJob.Spec.Triggers == nil {
+		return
+	}
+	next := tsuruJob.Spec.Triggers.OnFailure
+	if succeeded {
+		next = tsuruJob.Spec.Triggers.OnSuccess
+	}
+	workflow := job.Annotations[tsuruJobWorkflowAnnotation]
+	if workflow == "" {
+		workflow = string(job.UID)
+	}
+	for _, name := range next {
+		err = triggerWorkflowJob(ctx, workflow, job, name)
+		if err != nil {
+			log.Errorf("[job workflow] unable to trigger job %q after %q: %v", name, job.Name, err)
+		}
+	}
+}
+
+func triggerWorkflowJob(ctx context.Context, workflow string, upstream *batchv1.Job, name string) error {
+	workflowTarget := eventTypes.Target{Type: eventTypes.TargetTypeJobWorkflow, Value: workflow}
+	// the informer may see the same kubernetes event more than once, e.g.
+	// after a leader election, so triggers already fired by the upstream run
+	// are skipped.
+	triggered, err := event.List(ctx, &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: name},
+		KindNames: []string{permission.PermJobTrigger.FullName()},
+		Raw: mongoBSON.M{
+			"extratargets.target.value":     workflow,
+			"startcustomdata.upstream-unit": upstream.Name,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if len(triggered) > 0 {
+		return nil
+	}
+	job, err := servicemanager.Job.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: job.Name},
+		ExtraTargets: []eventTypes.ExtraTarget{{Target: workflowTarget}},
+		Kind:         permission.PermJobTrigger,
+		RawOwner:     eventTypes.Owner{Type: eventTypes.OwnerTypeInternal},
+		CustomData: map[string]string{
+			"upstream-job":  upstream.Labels[tsuruLabelJobName],
+			"upstream-unit": upstream.Name,
+		},
+		Allowed: event.Allowed(permission.PermJobReadEvents,
+			permission.Context(permTypes.CtxTeam, job.TeamOwner),
+			permission.Context(permTypes.CtxJob, job.Name),
+			permission.Context(permTypes.CtxPool, job.Pool),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	err = servicemanager.Job.Trigger(jobTypes.ContextWithWorkflow(ctx, workflow), job)
+	evt.Done(ctx, err)
+	return err
+}
+
+// jobRunFromK8s builds the run record from the current state of the
+// Kubernetes job. The logs tail is only fetched once the job has finished.
+func jobRunFromK8s(ctx context.Context, client *ClusterClient, job *batchv1.Job) (*jobTypes.JobRun, error) {
+	run := &jobTypes.JobRun{
+		ID:        string(job.UID),
+		Job:       job.Labels[tsuruLabelJobName],
+		Unit:      job.Name,
+		Trigger:   jobTypes.RunTriggerCron,
+		Status:    jobTypes.RunStatusRunning,
+		StartTime: job.CreationTimestamp.Time.UTC(),
+		Workflow:  job.Annotations[tsuruJobWorkflowAnnotation],
+	}
+	if trigger := job.Annotations[tsuruJobTriggerAnnotation]; trigger != "" {
+		run.Trigger = trigger
+	} else if job.Annotations["cronjob.kubernetes.io/instantiate"] == "manual" {
+		run.Trigger = jobTypes.RunTriggerAPI
+	}
+	if job.Status.StartTime != nil {
+		run.StartTime = job.Status.StartTime.Time.UTC()
+	}
+	switch {
+	case job.Status.Failed > 0 && findJobFailedReason(job) != "":
+		run.Status = jobTypes.RunStatusFailed
+		run.Reason = findJobFailedReason(job)
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed {
+				endTime := condition.LastTransitionTime.Time.UTC()
+				run.EndTime = &endTime
+			}
+		}
+	case job.Status.Succeeded > 0 && job.Status.CompletionTime != nil:
+		run.Status = jobTypes.RunStatusSucceeded
+		endTime := job.Status.CompletionTime.Time.UTC()
+		run.EndTime = &endTime
+	default:
+		return run, nil
+	}
+	pods, err := getPodsForJob(ctx, client, job)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return run, nil
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+	lastPod := pods[len(pods)-1]
+	for _, status := range lastPod.Status.ContainerStatuses {
+		if status.Name == "job" && status.State.Terminated != nil {
+			exitCode := status.State.Terminated.ExitCode
+			run.ExitCode = &exitCode
+		}
+	}
+	run.Log, err = jobRunLogTail(ctx, client, &lastPod)
+	if err != nil {
+		log.Errorf("[job runs] unable to read logs from pod %q: %v", lastPod.Name, err)
+	}
+	return run, nil
+}
+
+func jobRunLogTail(ctx context.Context, client *ClusterClient, pod *apiv1.Pod) (string, error) {
+	lines, err := config.GetInt("jobs:runs-log-lines")
+	if err != nil || lines <= 0 {
+		lines = defaultJobRunLogLines
+	}
+	request := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &apiv1.PodLogOptions{
+		Container: "job",
+		TailLines: ptr.To(int64(lines)),
+	})
+	data, err := request.DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 func ensureServiceAccountForJob(ctx context.Context, client *ClusterClient, job jobTypes.Job) error {
 	labels := provision.ServiceAccountLabels(provision.ServiceAccountLabelsOpts{
 		Job:    &job,
@@ -14,6 +14,7 @@ import (
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/types/app"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	eventTypes "github.com/tsuru/tsuru/types/event"
@@ -756,7 +757,10 @@ func (s *S) TestProvisionerTriggerCron(c *check.C) {
 							"tsuru.io/job-manual":          "false",
 							"tsuru.io/is-build":            "false",
 						},
-						Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+						Annotations: map[string]string{
+							"cronjob.kubernetes.io/instantiate": "manual",
+							"tsuru.io/job-trigger":              "api",
+						},
 						OwnerReferences: []metav1.OwnerReference{
 							{
 								Name:       cronParent.Name,
@@ -886,6 +890,111 @@ func (s *S) TestProvisionerTriggerCronDuplicate(c *check.C) {
 	c.Assert(err.Error(), check.Matches, `.*manual job .* already exists.*once per minute.*`)
 }
 
+func (s *S) TestProvisionerTriggerCronWorkflow(c *check.C) {
+	waitCron := s.mock.CronJobReactions(c)
+	defer waitCron()
+	cj := jobTypes.Job{
+		Name:      "myjob",
+		TeamOwner: s.team.Name,
+		Pool:      "pool1",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+				Command:          []string{"echo", "hello world"},
+			},
+		},
+	}
+	err := s.p.EnsureJob(context.TODO(), &cj)
+	waitCron()
+	require.NoError(s.t, err)
+	err = s.p.TriggerCron(jobTypes.ContextWithWorkflow(context.TODO(), "wf-1"), &cj, "test-default")
+	require.NoError(s.t, err)
+	waitCron()
+	jobs, err := s.client.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(s.t, err)
+	require.Len(s.t, jobs.Items, 1)
+	require.Equal(s.t, "workflow", jobs.Items[0].Annotations["tsuru.io/job-trigger"])
+	require.Equal(s.t, "wf-1", jobs.Items[0].Annotations["tsuru.io/job-workflow"])
+}
+
+func (s *S) TestTriggerJobWorkflow(c *check.C) {
+	jobs := map[string]*jobTypes.Job{
+		"first": {
+			Name:      "first",
+			TeamOwner: s.team.Name,
+			Pool:      "pool1",
+			Spec: jobTypes.JobSpec{
+				Triggers: &jobTypes.JobTriggers{OnSuccess: []string{"second"}, OnFailure: []string{"cleanup"}},
+			},
+		},
+		"second":  {Name: "second", TeamOwner: s.team.Name, Pool: "pool1"},
+		"cleanup": {Name: "cleanup", TeamOwner: s.team.Name, Pool: "pool1"},
+	}
+	var triggered []string
+	oldJobService := servicemanager.Job
+	defer func() { servicemanager.Job = oldJobService }()
+	servicemanager.Job = &jobTypes.MockJobService{
+		OnGetByName: func(name string) (*jobTypes.Job, error) {
+			if j, ok := jobs[name]; ok {
+				return j, nil
+			}
+			return nil, jobTypes.ErrJobNotFound
+		},
+		OnTrigger: func(j *jobTypes.Job) error {
+			triggered = append(triggered, j.Name)
+			return nil
+		},
+	}
+	k8sJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "first-manual-job-1",
+			Namespace: "default",
+			UID:       "uid-1",
+			Labels:    map[string]string{tsuruLabelJobName: "first"},
+		},
+	}
+	for _, reason := range []string{"SuccessfulCreate", "Completed", "Completed"} {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		triggerJobWorkflow(k8sJob, &corev1.Event{Reason: reason}, wg)
+	}
+	require.Equal(s.t, []string{"second"}, triggered)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeJobWorkflow, Value: "uid-1"},
+	})
+	require.NoError(s.t, err)
+	require.Len(s.t, evts, 1)
+	require.Equal(s.t, eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "second"}, evts[0].Target)
+	require.Equal(s.t, permission.PermJobTrigger.FullName(), evts[0].Kind.Name)
+	require.False(s.t, evts[0].Running)
+
+	downstream := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "second-manual-job-1",
+			Namespace:   "default",
+			UID:         "uid-2",
+			Labels:      map[string]string{tsuruLabelJobName: "second"},
+			Annotations: map[string]string{tsuruJobWorkflowAnnotation: "uid-1"},
+		},
+	}
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	triggerJobWorkflow(downstream, &corev1.Event{Reason: "BackoffLimitExceeded"}, wg)
+	require.Equal(s.t, []string{"second"}, triggered)
+
+	jobs["second"].Spec.Triggers = &jobTypes.JobTriggers{OnFailure: []string{"cleanup"}}
+	wg = &sync.WaitGroup{}
+	wg.Add(1)
+	triggerJobWorkflow(downstream, &corev1.Event{Reason: "BackoffLimitExceeded"}, wg)
+	require.Equal(s.t, []string{"second", "cleanup"}, triggered)
+	evts, err = event.List(context.TODO(), &event.Filter{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeJobWorkflow, Value: "uid-1"},
+	})
+	require.NoError(s.t, err)
+	require.Len(s.t, evts, 2)
+}
+
 func (s *S) TestBackwardCompatibilityOldNaming(c *check.C) {
 	waitCron := s.mock.CronJobReactions(c)
 	defer waitCron()
@@ -1728,6 +1837,74 @@ func (s *S) TestFindJobFailedReason(c *check.C) {
 	}
 }
 
+func (s *S) TestJobRunFromK8s(c *check.C) {
+	start := metav1.NewTime(time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(time.Minute))
+	testCases := []struct {
+		name     string
+		job      *batchv1.Job
+		expected jobTypes.JobRun
+	}{
+		{
+			name: "running cron job",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myjob-123",
+					Namespace: "default",
+					UID:       "uid-1",
+					Labels:    map[string]string{tsuruLabelJobName: "myjob"},
+				},
+				Status: batchv1.JobStatus{StartTime: &start},
+			},
+			expected: jobTypes.JobRun{ID: "uid-1", Job: "myjob", Unit: "myjob-123", Trigger: jobTypes.RunTriggerCron, Status: jobTypes.RunStatusRunning, StartTime: start.Time},
+		},
+		{
+			name: "succeeded manual job",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "myjob-456",
+					Namespace:   "default",
+					UID:         "uid-2",
+					Labels:      map[string]string{tsuruLabelJobName: "myjob"},
+					Annotations: map[string]string{tsuruJobTriggerAnnotation: jobTypes.RunTriggerManual},
+				},
+				Status: batchv1.JobStatus{StartTime: &start, CompletionTime: &end, Succeeded: 1},
+			},
+			expected: jobTypes.JobRun{ID: "uid-2", Job: "myjob", Unit: "myjob-456", Trigger: jobTypes.RunTriggerManual, Status: jobTypes.RunStatusSucceeded, StartTime: start.Time, EndTime: &end.Time},
+		},
+		{
+			name: "failed job instantiated from cronjob",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "myjob-789",
+					Namespace:   "default",
+					UID:         "uid-3",
+					Labels:      map[string]string{tsuruLabelJobName: "myjob"},
+					Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+				},
+				Status: batchv1.JobStatus{
+					StartTime: &start,
+					Failed:    1,
+					Conditions: []batchv1.JobCondition{
+						{
+							Type:               batchv1.JobFailed,
+							Status:             corev1.ConditionTrue,
+							Reason:             "BackoffLimitExceeded",
+							LastTransitionTime: end,
+						},
+					},
+				},
+			},
+			expected: jobTypes.JobRun{ID: "uid-3", Job: "myjob", Unit: "myjob-789", Trigger: jobTypes.RunTriggerAPI, Status: jobTypes.RunStatusFailed, StartTime: start.Time, EndTime: &end.Time, Reason: "BackoffLimitExceeded"},
+		},
+	}
+	for _, tc := range testCases {
+		run, err := jobRunFromK8s(context.TODO(), s.clusterClient, tc.job)
+		require.NoError(s.t, err, tc.name)
+		require.Equal(s.t, tc.expected, *run, tc.name)
+	}
+}
+
 func (s *S) TestKillJobUnitWithPodCleanup(c *check.C) {
 	waitCron := s.mock.CronJobReactions(c)
 	defer waitCron()
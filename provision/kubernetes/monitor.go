@@ -170,9 +170,11 @@ func (c *clusterController) startJobInformer() error {
 				return
 			}
 			wg := &sync.WaitGroup{}
-			wg.Add(2)
+			wg.Add(4)
 			go createJobEvent(c.cluster, job, evt, wg)
 			go incrementJobMetrics(job, evt, wg)
+			go recordJobRun(c.cluster, job, evt, wg)
+			go triggerJobWorkflow(job, evt, wg)
 			wg.Wait()
 		},
 	})
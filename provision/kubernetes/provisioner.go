@@ -98,6 +98,7 @@ var (
 	_ provision.MultiRegistryProvisioner = &kubernetesProvisioner{}
 	_ provision.KillUnitProvisioner      = &kubernetesProvisioner{}
 	_ provision.JobProvisioner           = &kubernetesProvisioner{}
+	_ provision.SecretRefProvisioner     = &kubernetesProvisioner{}
 
 	mainKubernetesProvisioner *kubernetesProvisioner
 )
@@ -1336,6 +1337,36 @@ func (p *kubernetesProvisioner) ValidateVolume(ctx context.Context, vol *volumeT
 	return err
 }
 
+// ValidateSecretRef checks that the Secret referenced by the app exists in
+// the app namespace and was granted either to the app or to its team owner,
+// using the tsuru.io/secret-app and tsuru.io/secret-team labels. Secrets
+// managed by tsuru never have these labels, so the private envs of an app
+// can't be mounted by other apps sharing the namespace.
+func (p *kubernetesProvisioner) ValidateSecretRef(ctx context.Context, a *appTypes.App, ref bindTypes.SecretRef) error {
+	client, err := clusterForPool(ctx, a.Pool)
+	if err != nil {
+		return err
+	}
+	ns, err := client.AppNamespace(ctx, a)
+	if err != nil {
+		return err
+	}
+	k8sSecret, err := client.CoreV1().Secrets(ns).Get(ctx, ref.Path, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return errors.Errorf("secret %q not found in namespace %q", ref.Path, ns)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if k8sSecret.Labels[secretAppLabel] != a.Name && k8sSecret.Labels[secretTeamLabel] != a.TeamOwner {
+		return errors.Errorf("secret %q must have either the label %s=%s or %s=%s", ref.Path, secretAppLabel, a.Name, secretTeamLabel, a.TeamOwner)
+	}
+	if _, ok := k8sSecret.Data[ref.Key]; !ok {
+		return errors.Errorf("key %q not found in secret %q", ref.Key, ref.Path)
+	}
+	return nil
+}
+
 func (p *kubernetesProvisioner) UpdateApp(ctx context.Context, old, new *appTypes.App, w io.Writer) error {
 	if old.Pool == new.Pool {
 		return nil
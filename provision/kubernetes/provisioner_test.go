@@ -341,6 +341,44 @@ func (s *S) TestUnitsMultipleAppsNodes(c *check.C) {
 	}, units)
 }
 
+func (s *S) TestValidateSecretRef(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	require.NoError(s.t, err)
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	require.NoError(s.t, err)
+	for _, secret := range []*apiv1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-credentials", Labels: map[string]string{"tsuru.io/secret-team": s.team.Name}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "app-credentials", Labels: map[string]string{"tsuru.io/secret-app": "myapp"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other-credentials", Labels: map[string]string{"tsuru.io/secret-team": "otherteam"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: appSecretPrefix + "otherapp-web", Labels: map[string]string{"tsuru.io/app-name": "otherapp"}}},
+	} {
+		secret.Data = map[string][]byte{"password": []byte("s3cr3t")}
+		_, err = s.client.CoreV1().Secrets(ns).Create(context.TODO(), secret, metav1.CreateOptions{})
+		require.NoError(s.t, err)
+	}
+	tests := []struct {
+		ref bindTypes.SecretRef
+		err string
+	}{
+		{ref: bindTypes.SecretRef{Provider: "kubernetes", Path: "team-credentials", Key: "password"}},
+		{ref: bindTypes.SecretRef{Provider: "kubernetes", Path: "app-credentials", Key: "password"}},
+		{ref: bindTypes.SecretRef{Provider: "kubernetes", Path: "app-credentials", Key: "user"}, err: `key "user" not found in secret "app-credentials"`},
+		{ref: bindTypes.SecretRef{Provider: "kubernetes", Path: "other-credentials", Key: "password"}, err: `secret "other-credentials" must have either the label .*`},
+		{ref: bindTypes.SecretRef{Provider: "kubernetes", Path: appSecretPrefix + "otherapp-web", Key: "password"}, err: `secret "tsuru-app-otherapp-web" must have either the label .*`},
+		{ref: bindTypes.SecretRef{Provider: "kubernetes", Path: "missing", Key: "password"}, err: `secret "missing" not found in namespace .*`},
+	}
+	for _, tt := range tests {
+		err = s.p.ValidateSecretRef(context.TODO(), a, tt.ref)
+		if tt.err == "" {
+			require.NoError(s.t, err, tt.ref.Path)
+		} else {
+			require.Error(s.t, err, tt.ref.Path)
+			require.Regexp(s.t, tt.err, err.Error())
+		}
+	}
+}
+
 func (s *S) TestUnitsSkipTerminating(c *check.C) {
 	a, wait, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
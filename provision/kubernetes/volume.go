@@ -14,6 +14,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	"github.com/ugorji/go/codec"
 	apiv1 "k8s.io/api/core/v1"
@@ -41,6 +42,22 @@ func createVolumesForApp(ctx context.Context, client *ClusterClient, app *appTyp
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
+	return createVolumes(ctx, client, volumes, func(v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error) {
+		return servicemanager.Volume.BindsForApp(ctx, v, app.Name)
+	})
+}
+
+func createVolumesForJob(ctx context.Context, client *ClusterClient, job *jobTypes.Job) ([]apiv1.Volume, []apiv1.VolumeMount, error) {
+	volumes, err := servicemanager.Volume.ListByJob(ctx, job.Name)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return createVolumes(ctx, client, volumes, func(v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error) {
+		return servicemanager.Volume.BindsForJob(ctx, v, job.Name)
+	})
+}
+
+func createVolumes(ctx context.Context, client *ClusterClient, volumes []volumeTypes.Volume, bindsFn func(*volumeTypes.Volume) ([]volumeTypes.VolumeBind, error)) ([]apiv1.Volume, []apiv1.VolumeMount, error) {
 	var kubeVolumes []apiv1.Volume
 	var kubeMounts []apiv1.VolumeMount
 	for i := range volumes {
@@ -54,7 +71,11 @@ func createVolumesForApp(ctx context.Context, client *ClusterClient, app *appTyp
 				return nil, nil, err
 			}
 		}
-		volume, mounts, err := bindsForVolume(ctx, &volumes[i], opts, app.Name)
+		binds, err := bindsFn(&volumes[i])
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		volume, mounts, err := bindsForVolume(&volumes[i], opts, binds)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -64,12 +85,9 @@ func createVolumesForApp(ctx context.Context, client *ClusterClient, app *appTyp
 	return kubeVolumes, kubeMounts, nil
 }
 
-func bindsForVolume(ctx context.Context, v *volumeTypes.Volume, opts *volumeOptions, appName string) (*apiv1.Volume, []apiv1.VolumeMount, error) {
+func bindsForVolume(v *volumeTypes.Volume, opts *volumeOptions, binds []volumeTypes.VolumeBind) (*apiv1.Volume, []apiv1.VolumeMount, error) {
 	var kubeMounts []apiv1.VolumeMount
-	binds, err := servicemanager.Volume.BindsForApp(ctx, v, appName)
-	if err != nil {
-		return nil, nil, errors.WithStack(err)
-	}
+	var err error
 	allReadOnly := true
 	for _, b := range binds {
 		kubeMounts = append(kubeMounts, apiv1.VolumeMount{
@@ -320,9 +338,12 @@ func getNamespaceForVolume(ctx context.Context, client *ClusterClient, v *volume
 	}
 	var namespace string
 	for _, b := range binds {
-		ns, err := client.appNamespaceByName(ctx, b.ID.App)
-		if err != nil {
-			return "", err
+		ns := client.PoolNamespace(v.Pool)
+		if b.ID.Job == "" {
+			ns, err = client.appNamespaceByName(ctx, b.ID.App)
+			if err != nil {
+				return "", err
+			}
 		}
 		if namespace == "" {
 			namespace = ns
@@ -11,8 +11,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tsuru/config"
 	tsuruv1 "github.com/tsuru/tsuru/provision/kubernetes/pkg/apis/tsuru/v1"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	check "gopkg.in/check.v1"
 	apiv1 "k8s.io/api/core/v1"
@@ -459,6 +461,66 @@ func (s *S) TestCreateVolumeAppNamespace(_ *check.C) {
 	}, pvc.ObjectMeta)
 }
 
+func (s *S) TestCreateVolumesForJob(_ *check.C) {
+	config.Set("volume-plans:p1:kubernetes:plugin", "nfs")
+	defer config.Unset("volume-plans")
+	err := pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: "test-default", Field: pool.ConstraintTypeVolumePlan, Values: []string{"p1"}})
+	require.NoError(s.t, err)
+	job := &jobTypes.Job{Name: "myjob", Pool: "test-default", TeamOwner: "admin"}
+	oldJobService := servicemanager.Job
+	defer func() { servicemanager.Job = oldJobService }()
+	servicemanager.Job = &jobTypes.MockJobService{
+		OnGetByName: func(name string) (*jobTypes.Job, error) {
+			return job, nil
+		},
+	}
+	v := volumeTypes.Volume{
+		Name: "v1",
+		Opts: map[string]string{
+			"path":         "/exports",
+			"server":       "192.168.1.1",
+			"capacity":     "20Gi",
+			"access-modes": string(apiv1.ReadWriteMany),
+		},
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "test-default",
+		TeamOwner: "admin",
+	}
+	err = servicemanager.Volume.Create(context.TODO(), &v)
+	require.NoError(s.t, err)
+	err = servicemanager.Volume.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		JobName:    job.Name,
+		MountPoint: "/data",
+		ReadOnly:   true,
+	})
+	require.NoError(s.t, err)
+	volumes, mounts, err := createVolumesForJob(context.TODO(), s.clusterClient, job)
+	require.NoError(s.t, err)
+	require.EqualValues(s.t, []apiv1.Volume{{
+		Name: volumeName(v.Name),
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{
+				ClaimName: volumeClaimName(v.Name),
+				ReadOnly:  true,
+			},
+		},
+	}}, volumes)
+	require.EqualValues(s.t, []apiv1.VolumeMount{{
+		Name:      volumeName(v.Name),
+		MountPath: "/data",
+		ReadOnly:  true,
+	}}, mounts)
+	ns := s.clusterClient.PoolNamespace(job.Pool)
+	_, err = s.client.CoreV1().PersistentVolumeClaims(ns).Get(context.TODO(), volumeClaimName(v.Name), metav1.GetOptions{})
+	require.NoError(s.t, err)
+	labels, annotations := buildMetadata(context.TODO(), job)
+	jobSpec, err := buildJobSpec(context.TODO(), job, s.clusterClient, labels, annotations)
+	require.NoError(s.t, err)
+	require.EqualValues(s.t, volumes, jobSpec.Template.Spec.Volumes)
+	require.EqualValues(s.t, mounts, jobSpec.Template.Spec.Containers[0].VolumeMounts)
+}
+
 func (s *S) TestCreateVolumeMultipleNamespacesFail(_ *check.C) {
 	config.Set("kubernetes:use-pool-namespaces", true)
 	defer config.Unset("kubernetes:use-pool-namespaces")
@@ -6,6 +6,7 @@ package pool
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -20,7 +22,7 @@ import (
 
 var (
 	ErrInvalidConstraintType = errors.Errorf("invalid constraint type. Valid types are: %s", validConstraintTypes)
-	validConstraintTypes     = []PoolConstraintType{ConstraintTypeTeam, ConstraintTypeService, ConstraintTypeRouter, ConstraintTypePlan, ConstraintTypeVolumePlan, ConstraintTypeCertIssuer}
+	validConstraintTypes     = []PoolConstraintType{ConstraintTypeTeam, ConstraintTypeService, ConstraintTypeRouter, ConstraintTypePlan, ConstraintTypeVolumePlan, ConstraintTypeCertIssuer, ConstraintTypeImageSignature, ConstraintTypeImageSignatureMode}
 )
 
 type PoolConstraintType string
@@ -32,6 +34,22 @@ const (
 	ConstraintTypePlan       = PoolConstraintType("plan")
 	ConstraintTypeVolumePlan = PoolConstraintType("volume-plan")
 	ConstraintTypeCertIssuer = PoolConstraintType("cert-issuer")
+
+	// ConstraintTypeImageSignature lists the names of the keys trusted to
+	// sign the images deployed to the pool.
+	ConstraintTypeImageSignature = PoolConstraintType("image-signature")
+	// ConstraintTypeImageSignatureMode sets whether images failing the
+	// signature verification are refused (enforce) or only reported (audit).
+	ConstraintTypeImageSignatureMode = PoolConstraintType("image-signature-mode")
+)
+
+const (
+	// ImageSignatureModeAudit deploys images failing the signature
+	// verification, only reporting the failure. It's the default mode.
+	ImageSignatureModeAudit = "audit"
+	// ImageSignatureModeEnforce refuses to deploy images failing the
+	// signature verification.
+	ImageSignatureModeEnforce = "enforce"
 )
 
 type regexpCache struct {
@@ -121,6 +139,10 @@ func SetPoolConstraint(ctx context.Context, c *PoolConstraint) error {
 	if !isValid {
 		return ErrInvalidConstraintType
 	}
+	err = validateConstraintValues(c)
+	if err != nil {
+		return err
+	}
 	if len(c.Values) == 0 || (len(c.Values) == 1 && c.Values[0] == "") {
 		result, errRem := collection.DeleteMany(ctx, mongoBSON.M{"poolexpr": c.PoolExpr, "field": c.Field})
 		if errRem != mongo.ErrNoDocuments {
@@ -142,6 +164,10 @@ func AppendPoolConstraint(ctx context.Context, c *PoolConstraint) error {
 	if !isValid {
 		return ErrInvalidConstraintType
 	}
+	err := validateConstraintValues(c)
+	if err != nil {
+		return err
+	}
 	return appendPoolConstraint(ctx, c.PoolExpr, c.Field, c.Values...)
 }
 
@@ -154,6 +180,18 @@ func validateConstraintType(c PoolConstraintType) bool {
 	return false
 }
 
+func validateConstraintValues(c *PoolConstraint) error {
+	if c.Field != ConstraintTypeImageSignatureMode {
+		return nil
+	}
+	for _, v := range c.Values {
+		if v != "" && v != ImageSignatureModeEnforce && v != ImageSignatureModeAudit {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid image signature mode %q, valid modes are: %s, %s", v, ImageSignatureModeEnforce, ImageSignatureModeAudit)}
+		}
+	}
+	return nil
+}
+
 func appendPoolConstraint(ctx context.Context, poolExpr string, field PoolConstraintType, values ...string) error {
 	collection, err := storagev2.PoolConstraintsCollection()
 	if err != nil {
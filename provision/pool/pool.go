@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -20,6 +21,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
 	provisionTypes "github.com/tsuru/tsuru/types/provision"
 	"github.com/tsuru/tsuru/validation"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
@@ -43,7 +45,8 @@ var (
 )
 
 const (
-	affinityKey = "affinity"
+	affinityKey           = "affinity"
+	imageScanThresholdKey = "image-scan-threshold"
 )
 
 type Pool struct {
@@ -91,6 +94,39 @@ func (p *Pool) GetAffinity() (*apiv1.Affinity, error) {
 	return nil, nil
 }
 
+// GetImageScanThreshold returns the maximum number of vulnerabilities by
+// severity allowed in the images deployed to the pool, set in the
+// image-scan-threshold label as in "critical=0,high=10". It returns nil when
+// the pool doesn't limit vulnerabilities.
+func (p *Pool) GetImageScanThreshold() (map[string]int, error) {
+	raw, ok := p.Labels[imageScanThresholdKey]
+	if !ok {
+		return nil, nil
+	}
+	return parseImageScanThreshold(raw)
+}
+
+func parseImageScanThreshold(raw string) (map[string]int, error) {
+	threshold := map[string]int{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		severity, value, _ := strings.Cut(part, "=")
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if !contains(imgTypes.Severities, severity) {
+			return nil, errors.Errorf("invalid %s label: unknown severity %q, valid severities are: %s", imageScanThresholdKey, severity, strings.Join(imgTypes.Severities, ", "))
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			return nil, errors.Errorf("invalid %s label: %q must be a non negative number", imageScanThresholdKey, part)
+		}
+		threshold[severity] = limit
+	}
+	return threshold, nil
+}
+
 func (p *Pool) GetProvisioner() (provision.Provisioner, error) {
 	if p.Provisioner != "" {
 		return provision.Get(p.Provisioner)
@@ -132,6 +168,34 @@ func (p *Pool) GetCertIssuers(ctx context.Context) (*PoolConstraint, error) {
 	return certIssuerConstraint, nil
 }
 
+// GetImageSignatureKeys returns the names of the keys trusted to sign the
+// images deployed to the pool, which are empty when the pool doesn't require
+// signed images.
+func (p *Pool) GetImageSignatureKeys(ctx context.Context) ([]string, error) {
+	constraints, err := getConstraintsForPool(ctx, p.Name, ConstraintTypeImageSignature)
+	if err != nil {
+		return nil, err
+	}
+	c, exists := constraints[ConstraintTypeImageSignature]
+	if !exists || c.Blacklist {
+		return nil, nil
+	}
+	return c.Values, nil
+}
+
+// GetImageSignatureMode returns how the pool handles images failing the
+// signature verification against its trusted keys, which defaults to audit.
+func (p *Pool) GetImageSignatureMode(ctx context.Context) (string, error) {
+	constraints, err := getConstraintsForPool(ctx, p.Name, ConstraintTypeImageSignatureMode)
+	if err != nil {
+		return "", err
+	}
+	if c := constraints[ConstraintTypeImageSignatureMode]; c.checkExact(ImageSignatureModeEnforce) {
+		return ImageSignatureModeEnforce, nil
+	}
+	return ImageSignatureModeAudit, nil
+}
+
 func (p *Pool) GetVolumePlans(ctx context.Context) ([]string, error) {
 	allowedValues, err := p.allowedValues(ctx)
 	if err != nil {
@@ -383,6 +447,11 @@ func validateLabels(labels map[string]string) error {
 			return err
 		}
 	}
+	if threshold, ok := labels[imageScanThresholdKey]; ok {
+		if _, err := parseImageScanThreshold(threshold); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -281,6 +281,22 @@ func (s *S) TestAddPoolValidateLabels(c *check.C) {
 			},
 			expectedErr: "invalid character 'i' looking for beginning of value",
 		},
+		{
+			testName: "image scan threshold with unknown severity",
+			opts: AddPoolOptions{
+				Name:   "pool3",
+				Labels: map[string]string{imageScanThresholdKey: "critical=0,severe=1"},
+			},
+			expectedErr: `invalid image-scan-threshold label: unknown severity "severe", valid severities are: critical, high, medium, low, unknown`,
+		},
+		{
+			testName: "image scan threshold with invalid number",
+			opts: AddPoolOptions{
+				Name:   "pool4",
+				Labels: map[string]string{imageScanThresholdKey: "high=-1"},
+			},
+			expectedErr: `invalid image-scan-threshold label: "high=-1" must be a non negative number`,
+		},
 	}
 
 	for _, t := range tt {
@@ -764,6 +780,59 @@ func (s *S) TestGetDefaultRouterFromConstraint(c *check.C) {
 	c.Assert(r, check.Equals, "router2")
 }
 
+func (s *S) TestGetImageSignatureKeys(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	pool, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	keys, err := pool.GetImageSignatureKeys(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.IsNil)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool*", Field: ConstraintTypeImageSignature, Values: []string{"team-key", "ci-key"}})
+	c.Assert(err, check.IsNil)
+	keys, err = pool.GetImageSignatureKeys(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, []string{"team-key", "ci-key"})
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool*", Field: ConstraintTypeImageSignature, Values: []string{"team-key"}, Blacklist: true})
+	c.Assert(err, check.IsNil)
+	keys, err = pool.GetImageSignatureKeys(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.IsNil)
+}
+
+func (s *S) TestGetImageSignatureMode(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	pool, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	mode, err := pool.GetImageSignatureMode(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, ImageSignatureModeAudit)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool*", Field: ConstraintTypeImageSignatureMode, Values: []string{"enforce"}})
+	c.Assert(err, check.IsNil)
+	mode, err = pool.GetImageSignatureMode(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, ImageSignatureModeEnforce)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeImageSignatureMode, Values: []string{"audit"}})
+	c.Assert(err, check.IsNil)
+	mode, err = pool.GetImageSignatureMode(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, ImageSignatureModeAudit)
+	err = SetPoolConstraint(context.TODO(), &PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeImageSignatureMode, Values: []string{"strict"}})
+	c.Assert(err, check.ErrorMatches, `invalid image signature mode "strict", valid modes are: enforce, audit`)
+}
+
+func (s *S) TestGetImageScanThreshold(c *check.C) {
+	pool := Pool{Name: "pool1"}
+	threshold, err := pool.GetImageScanThreshold()
+	c.Assert(err, check.IsNil)
+	c.Assert(threshold, check.IsNil)
+	pool.Labels = map[string]string{imageScanThresholdKey: "Critical=0, high=10"}
+	threshold, err = pool.GetImageScanThreshold()
+	c.Assert(err, check.IsNil)
+	c.Assert(threshold, check.DeepEquals, map[string]int{"critical": 0, "high": 10})
+}
+
 func (s *S) TestGetDefaultRouterNoDefault(c *check.C) {
 	config.Set("routers:router1:type", "hipache")
 	config.Set("routers:router2:type", "hipache")
@@ -18,6 +18,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	imgTypes "github.com/tsuru/tsuru/types/app/image"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	logTypes "github.com/tsuru/tsuru/types/log"
 	provTypes "github.com/tsuru/tsuru/types/provision"
@@ -281,6 +282,12 @@ type AutoScaleProvisioner interface {
 	SwapAutoScale(ctx context.Context, a *appTypes.App, versionStr string) error
 }
 
+// SecretRefProvisioner is a provisioner that mounts secret references from
+// its own secret store, checking that the app is allowed to use them.
+type SecretRefProvisioner interface {
+	ValidateSecretRef(ctx context.Context, a *appTypes.App, ref bindTypes.SecretRef) error
+}
+
 type MultiRegistryProvisioner interface {
 	RegistryForPool(ctx context.Context, pool string) (imgTypes.ImageRegistry, error)
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/routertest"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	logTypes "github.com/tsuru/tsuru/types/log"
 	provTypes "github.com/tsuru/tsuru/types/provision"
@@ -42,6 +43,7 @@ var (
 	_ provision.VolumeProvisioner     = &FakeProvisioner{}
 	_ provision.AppFilterProvisioner  = &FakeProvisioner{}
 	_ provision.ExecutableProvisioner = &FakeProvisioner{}
+	_ provision.SecretRefProvisioner  = &FakeProvisioner{}
 )
 
 func init() {
@@ -113,6 +115,7 @@ type FakeProvisioner struct {
 	mut         sync.RWMutex
 	execs       map[string][]provision.ExecOptions
 	execsMut    sync.Mutex
+	secrets     map[string]string
 }
 
 func NewFakeProvisioner() *FakeProvisioner {
@@ -122,6 +125,7 @@ func NewFakeProvisioner() *FakeProvisioner {
 	p.apps = make(map[string]provisionedApp)
 	p.jobs = make(map[string]*provisionedJob)
 	p.execs = make(map[string][]provision.ExecOptions)
+	p.secrets = make(map[string]string)
 	return &p
 }
 
@@ -266,6 +270,7 @@ func (p *FakeProvisioner) Reset() {
 
 	p.mut.Lock()
 	p.jobs = make(map[string]*provisionedJob)
+	p.secrets = make(map[string]string)
 	p.mut.Unlock()
 
 	p.execsMut.Lock()
@@ -284,6 +289,27 @@ func (p *FakeProvisioner) Reset() {
 	}
 }
 
+// AddSecret adds a secret owned by the team, which apps of the team are
+// allowed to reference.
+func (p *FakeProvisioner) AddSecret(name, team string) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.secrets[name] = team
+}
+
+func (p *FakeProvisioner) ValidateSecretRef(ctx context.Context, a *appTypes.App, ref bindTypes.SecretRef) error {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	team, ok := p.secrets[ref.Path]
+	if !ok {
+		return errors.Errorf("secret %q not found", ref.Path)
+	}
+	if team != a.TeamOwner {
+		return errors.Errorf("secret %q is not owned by team %q", ref.Path, a.TeamOwner)
+	}
+	return nil
+}
+
 func (p *FakeProvisioner) Deploy(ctx context.Context, args provision.DeployArgs) (string, error) {
 	if err := p.getError("Deploy"); err != nil {
 		return "", err
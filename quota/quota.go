@@ -62,6 +62,24 @@ func (s *QuotaService[I]) SetLimit(ctx context.Context, item I, limit int) error
 // smaller than or equal to the current limit. It also must be a non negative
 // number.
 func (s *QuotaService[I]) Set(ctx context.Context, item I, inUse int) error {
+	err := s.checkInUse(ctx, item, inUse)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Set(ctx, item.GetName(), inUse)
+}
+
+// CompareAndSet redefines the inuse value for the named resource, like Set,
+// only if the recorded inuse value is still old.
+func (s *QuotaService[I]) CompareAndSet(ctx context.Context, item I, old, inUse int) error {
+	err := s.checkInUse(ctx, item, inUse)
+	if err != nil {
+		return err
+	}
+	return s.Storage.CompareAndSet(ctx, item.GetName(), old, inUse)
+}
+
+func (s *QuotaService[I]) checkInUse(ctx context.Context, item I, inUse int) error {
 	q, err := s.Storage.Get(ctx, item.GetName())
 	if err != nil {
 		return err
@@ -75,7 +93,7 @@ func (s *QuotaService[I]) Set(ctx context.Context, item I, inUse int) error {
 			Available: uint(q.Limit),
 		}
 	}
-	return s.Storage.Set(ctx, item.GetName(), inUse)
+	return nil
 }
 
 func (s *QuotaService[I]) Get(ctx context.Context, item I) (*quota.Quota, error) {
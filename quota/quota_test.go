@@ -223,3 +223,23 @@ func (s *S) TestSetLimitAppNotFound(c *check.C) {
 	c.Assert(err, check.NotNil)
 	c.Assert(err, check.Equals, myerr)
 }
+
+func (s *S) TestCompareAndSet(c *check.C) {
+	qs := &QuotaService[quota.QuotaItem]{
+		Storage: &quota.MockQuotaStorage{
+			OnCompareAndSet: func(name string, old, quantity int) error {
+				c.Assert(name, check.Equals, "myname")
+				c.Assert(old, check.Equals, 2)
+				c.Assert(quantity, check.Equals, 4)
+				return quota.ErrQuotaChanged
+			},
+			OnGet: func(name string) (*quota.Quota, error) {
+				return &quota.Quota{Limit: 5, InUse: 3}, nil
+			},
+		},
+	}
+	err := qs.CompareAndSet(context.TODO(), namedItem("myname"), 2, 4)
+	c.Assert(err, check.Equals, quota.ErrQuotaChanged)
+	err = qs.CompareAndSet(context.TODO(), namedItem("myname"), 2, 6)
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{Requested: 6, Available: 5})
+}
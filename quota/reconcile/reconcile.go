@@ -0,0 +1,225 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reconcile recomputes the usage of the app, team and user quotas
+// from the existing apps, units and jobs, reporting and optionally fixing
+// the quotas whose recorded usage drifted.
+package reconcile
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
+)
+
+const (
+	KindApp  = "app"
+	KindTeam = "team"
+	KindUser = "user"
+
+	EventKind = "quota.reconcile"
+)
+
+// Difference is a quota whose recorded usage differs from the usage
+// computed from the apps, units and jobs.
+type Difference struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Recorded int    `json:"recorded"`
+	Actual   int    `json:"actual"`
+	Fixed    bool   `json:"fixed"`
+}
+
+// Reconcile returns the quotas whose recorded usage differs from the actual
+// usage, setting the actual usage on each of them when fix is true. Quotas
+// that can't be computed or fixed are reported in the returned error, along
+// with the differences found in the other quotas.
+func Reconcile(ctx context.Context, fix bool) ([]Difference, error) {
+	multi := tsuruErrors.NewMultiError()
+	diffs, err := differences(ctx, multi)
+	if err != nil {
+		return nil, err
+	}
+	if fix {
+		for i := range diffs {
+			err = correct(ctx, &diffs[i])
+			if err != nil {
+				multi.Add(errors.Wrapf(err, "unable to fix %s quota of %q", diffs[i].Kind, diffs[i].Name))
+			}
+		}
+	}
+	return diffs, multi.ToError()
+}
+
+func differences(ctx context.Context, multi *tsuruErrors.MultiError) ([]Difference, error) {
+	apps, err := app.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := servicemanager.Job.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := servicemanager.Team.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := auth.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []Difference
+	teamUsage := map[string]int{}
+	userUsage := map[string]int{}
+	for _, a := range apps {
+		teamUsage[a.TeamOwner]++
+		userUsage[a.Owner]++
+		units, err := app.GetQuotaInUse(ctx, a)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to count units of app %q", a.Name))
+			continue
+		}
+		if units != a.Quota.InUse {
+			diffs = append(diffs, Difference{Kind: KindApp, Name: a.Name, Recorded: a.Quota.InUse, Actual: units})
+		}
+	}
+	for _, j := range jobs {
+		teamUsage[j.TeamOwner]++
+		userUsage[j.Owner]++
+	}
+	for _, t := range teams {
+		if t.Quota.InUse != teamUsage[t.Name] {
+			diffs = append(diffs, Difference{Kind: KindTeam, Name: t.Name, Recorded: t.Quota.InUse, Actual: teamUsage[t.Name]})
+		}
+	}
+	for _, u := range users {
+		if u.Quota.InUse != userUsage[u.Email] {
+			diffs = append(diffs, Difference{Kind: KindUser, Name: u.Email, Recorded: u.Quota.InUse, Actual: userUsage[u.Email]})
+		}
+	}
+	return diffs, nil
+}
+
+// correct sets the actual usage in the quota, holding an event locking its
+// target. The usage is only set if the recorded usage didn't change since it
+// was read. Targets locked by other operations or whose usage changed are
+// left to the next reconciliation.
+func correct(ctx context.Context, d *Difference) error {
+	evt, err := event.NewInternal(ctx, eventOpts(d))
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	switch d.Kind {
+	case KindApp:
+		err = servicemanager.AppQuota.CompareAndSet(ctx, &appTypes.App{Name: d.Name}, d.Recorded, d.Actual)
+	case KindTeam:
+		err = servicemanager.TeamQuota.CompareAndSet(ctx, &authTypes.Team{Name: d.Name}, d.Recorded, d.Actual)
+	case KindUser:
+		err = servicemanager.UserQuota.CompareAndSet(ctx, &auth.User{Email: d.Name}, d.Recorded, d.Actual)
+	}
+	if err == quotaTypes.ErrQuotaChanged {
+		evt.Abort(ctx)
+		return nil
+	}
+	evt.Done(ctx, err)
+	d.Fixed = err == nil
+	return err
+}
+
+func eventOpts(d *Difference) *event.Opts {
+	opts := &event.Opts{
+		InternalKind: EventKind,
+		CustomData: map[string]interface{}{
+			"recorded": d.Recorded,
+			"actual":   d.Actual,
+		},
+	}
+	switch d.Kind {
+	case KindApp:
+		opts.Target = eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: d.Name}
+		opts.Allowed = event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, d.Name))
+	case KindTeam:
+		opts.Target = eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: d.Name}
+		opts.Allowed = event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, d.Name))
+	case KindUser:
+		opts.Target = eventTypes.Target{Type: eventTypes.TargetTypeUser, Value: d.Name}
+		opts.Allowed = event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, d.Name))
+	}
+	return opts
+}
+
+// Initialize starts the periodic reconciliation of the quotas, enabled by
+// setting quota:reconcile:interval. The differences are only fixed when
+// quota:reconcile:fix is true.
+func Initialize() error {
+	interval, _ := config.GetDuration("quota:reconcile:interval")
+	if interval <= 0 {
+		return nil
+	}
+	fix, _ := config.GetBool("quota:reconcile:fix")
+	r := &reconciler{once: &sync.Once{}, interval: interval, fix: fix}
+	r.start()
+	shutdown.Register(r)
+	return nil
+}
+
+type reconciler struct {
+	once     *sync.Once
+	stopCh   chan struct{}
+	interval time.Duration
+	fix      bool
+}
+
+func (r *reconciler) start() {
+	r.once.Do(func() {
+		r.stopCh = make(chan struct{})
+		go r.spin()
+	})
+}
+
+func (r *reconciler) Shutdown(ctx context.Context) error {
+	if r.stopCh == nil {
+		return nil
+	}
+	r.stopCh <- struct{}{}
+	r.stopCh = nil
+	r.once = &sync.Once{}
+	return nil
+}
+
+func (r *reconciler) spin() {
+	for {
+		diffs, err := Reconcile(context.Background(), r.fix)
+		if err != nil {
+			log.Errorf("[quota-reconcile] unable to reconcile quotas: %v", err)
+		}
+		for _, d := range diffs {
+			log.Errorf("[quota-reconcile] %s quota of %q records %d in use, actual usage is %d (fixed: %v)", d.Kind, d.Name, d.Recorded, d.Actual, d.Fixed)
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createApp(c *check.C, name string, units int) *appTypes.App {
+	a := &appTypes.App{Name: name, TeamOwner: "team1", Pool: "pool1", Quota: quota.UnlimitedQuota}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	for i := 0; i < units; i++ {
+		provisiontest.ProvisionerInstance.AddUnit(a, provTypes.Unit{AppName: a.Name, ProcessName: "web", Status: provTypes.UnitStatusStarted})
+	}
+	return a
+}
+
+func (s *S) TestReconcile(c *check.C) {
+	s.createApp(c, "app1", 2)
+	s.createApp(c, "app2", 0)
+	s.mockService.JobService.OnList = func(_ *jobTypes.Filter) ([]jobTypes.Job, error) {
+		return []jobTypes.Job{{Name: "job1", TeamOwner: "team1", Owner: s.user.Email}}, nil
+	}
+	s.teams = []authTypes.Team{
+		{Name: "team1", Quota: quota.Quota{Limit: -1, InUse: 5}},
+		{Name: "team2", Quota: quota.Quota{Limit: -1, InUse: 0}},
+	}
+	s.mockService.AppQuota.OnCompareAndSet = func(_ *appTypes.App, _, _ int) error {
+		c.Fatal("quota must not be fixed")
+		return nil
+	}
+	diffs, err := Reconcile(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	c.Assert(diffs, check.DeepEquals, []Difference{
+		{Kind: KindApp, Name: "app1", Recorded: 0, Actual: 2},
+		{Kind: KindTeam, Name: "team1", Recorded: 5, Actual: 3},
+		{Kind: KindUser, Name: s.user.Email, Recorded: 0, Actual: 3},
+	})
+}
+
+func (s *S) TestReconcileFix(c *check.C) {
+	s.createApp(c, "app1", 1)
+	s.teams = []authTypes.Team{{Name: "team1", Quota: quota.Quota{Limit: -1, InUse: 0}}}
+	fixed := map[string]int{}
+	s.mockService.AppQuota.OnCompareAndSet = func(a *appTypes.App, old, inUse int) error {
+		c.Check(old, check.Equals, 0)
+		fixed["app/"+a.Name] = inUse
+		return nil
+	}
+	s.mockService.TeamQuota.OnCompareAndSet = func(t *authTypes.Team, old, inUse int) error {
+		c.Check(old, check.Equals, 0)
+		fixed["team/"+t.Name] = inUse
+		return nil
+	}
+	s.mockService.UserQuota.OnCompareAndSet = func(u quota.QuotaItem, old, inUse int) error {
+		fixed["user/"+u.GetName()] = inUse
+		return errors.New("user quota failure")
+	}
+	diffs, err := Reconcile(context.TODO(), true)
+	c.Assert(err, check.ErrorMatches, `(?s).*unable to fix user quota of "reconcile@tsuru.io": user quota failure.*`)
+	c.Assert(diffs, check.DeepEquals, []Difference{
+		{Kind: KindApp, Name: "app1", Recorded: 0, Actual: 1, Fixed: true},
+		{Kind: KindTeam, Name: "team1", Recorded: 0, Actual: 1, Fixed: true},
+		{Kind: KindUser, Name: s.user.Email, Recorded: 0, Actual: 1},
+	})
+	c.Assert(fixed, check.DeepEquals, map[string]int{
+		"app/app1":                1,
+		"team/team1":              1,
+		"user/reconcile@tsuru.io": 1,
+	})
+	evts, err := event.List(context.TODO(), &event.Filter{KindNames: []string{EventKind}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 3)
+	targets := map[eventTypes.Target]string{}
+	for _, evt := range evts {
+		targets[evt.Target] = evt.Error
+	}
+	c.Assert(targets, check.DeepEquals, map[eventTypes.Target]string{
+		{Type: eventTypes.TargetTypeApp, Value: "app1"}:                "",
+		{Type: eventTypes.TargetTypeTeam, Value: "team1"}:              "",
+		{Type: eventTypes.TargetTypeUser, Value: "reconcile@tsuru.io"}: "user quota failure",
+	})
+}
+
+func (s *S) TestReconcileFixSkipsLockedTargets(c *check.C) {
+	a := s.createApp(c, "app1", 1)
+	s.teams = []authTypes.Team{{Name: "team1", Quota: quota.Quota{Limit: -1, InUse: 1}}}
+	s.user.Quota.InUse = 1
+	err := s.user.Update(context.TODO())
+	c.Assert(err, check.IsNil)
+	evt, err := event.NewInternal(context.TODO(), &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: "deploy",
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(context.TODO(), nil)
+	s.mockService.AppQuota.OnCompareAndSet = func(_ *appTypes.App, _, _ int) error {
+		c.Fatal("locked app quota must not be fixed")
+		return nil
+	}
+	diffs, err := Reconcile(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	c.Assert(diffs, check.DeepEquals, []Difference{
+		{Kind: KindApp, Name: "app1", Recorded: 0, Actual: 1},
+	})
+}
+
+func (s *S) TestReconcileFixSkipsChangedQuotas(c *check.C) {
+	s.createApp(c, "app1", 1)
+	s.teams = []authTypes.Team{{Name: "team1", Quota: quota.Quota{Limit: -1, InUse: 1}}}
+	s.user.Quota.InUse = 1
+	err := s.user.Update(context.TODO())
+	c.Assert(err, check.IsNil)
+	s.mockService.AppQuota.OnCompareAndSet = func(_ *appTypes.App, old, _ int) error {
+		c.Check(old, check.Equals, 0)
+		return quota.ErrQuotaChanged
+	}
+	diffs, err := Reconcile(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	c.Assert(diffs, check.DeepEquals, []Difference{
+		{Kind: KindApp, Name: "app1", Recorded: 0, Actual: 1},
+	})
+	evts, err := event.List(context.TODO(), &event.Filter{KindNames: []string{EventKind}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 0)
+}
+
+func (s *S) TestInitializeDisabled(c *check.C) {
+	err := Initialize()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestReconcilerStartShutdown(c *check.C) {
+	r := &reconciler{once: &sync.Once{}, interval: time.Hour}
+	r.start()
+	err := r.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
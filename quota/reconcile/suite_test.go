@@ -0,0 +1,95 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/quota"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	user        *auth.User
+	defaultPlan appTypes.Plan
+	teams       []authTypes.Team
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "quota_reconcile_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("docker:router", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	storagev2.Reset()
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	pool.ResetCache()
+	err := storagev2.ClearAllCollections(nil)
+	c.Assert(err, check.IsNil)
+	s.user = &auth.User{Email: "reconcile@tsuru.io", Quota: quota.UnlimitedQuota}
+	err = s.user.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1", Public: true})
+	c.Assert(err, check.IsNil)
+	s.defaultPlan = appTypes.Plan{Name: "default", Memory: 1 << 30, Default: true}
+	s.teams = []authTypes.Team{{Name: "team1", Quota: quota.UnlimitedQuota}}
+	servicemock.SetMockService(&s.mockService)
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	s.mockService.Team.OnFindByNames = func(names []string) ([]authTypes.Team, error) {
+		var teams []authTypes.Team
+		for _, name := range names {
+			teams = append(teams, authTypes.Team{Name: name})
+		}
+		return teams, nil
+	}
+	s.mockService.Team.OnList = func() ([]authTypes.Team, error) {
+		return s.teams, nil
+	}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{s.defaultPlan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &s.defaultPlan, nil
+	}
+	s.mockService.AppQuota.OnGet = func(_ *appTypes.App) (*quota.Quota, error) {
+		return &quota.UnlimitedQuota, nil
+	}
+	s.mockService.TeamQuota.OnGet = func(_ *authTypes.Team) (*quota.Quota, error) {
+		return &quota.UnlimitedQuota, nil
+	}
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	storagev2.ClearAllCollections(nil)
+}
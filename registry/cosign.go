@@ -0,0 +1,344 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/image"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	ociIndexMediaType       = "application/vnd.oci.image.index.v1+json"
+	ociConfigMediaType      = "application/vnd.oci.image.config.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerListMediaType     = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	simpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	signatureAnnotation    = "dev.cosignproject.cosign/signature"
+
+	// maxManifestSize is the limit on manifests read to compute their
+	// digest, the same enforced by most registries.
+	maxManifestSize = 4 << 20
+)
+
+var manifestMediaTypes = strings.Join([]string{
+	ociManifestMediaType, ociIndexMediaType, dockerManifestMediaType, dockerListMediaType,
+}, ", ")
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Size        int64             `json:"size"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type signatureManifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        descriptor   `json:"config"`
+	Layers        []descriptor `json:"layers"`
+}
+
+// imageReference holds the parts of an image name used to reach it in the
+// registry, ref is either a tag or a digest.
+type imageReference struct {
+	registry   string
+	repository string
+	ref        string
+}
+
+func parseImageReference(imageName string) imageReference {
+	name, digest, hasDigest := strings.Cut(imageName, "@")
+	registry, repository, tag := image.ParseImageParts(name)
+	ref := tag
+	if hasDigest {
+		ref = digest
+	} else if ref == "" {
+		ref = image.LatestTag
+	}
+	if registry == "" {
+		registry = dockerHubRegistry
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	}
+	return imageReference{registry: registry, repository: repository, ref: ref}
+}
+
+func (i imageReference) name() string {
+	return i.registry + "/" + i.repository
+}
+
+// signatureTag returns the tag where cosign stores the signatures of the
+// image with the given digest.
+func signatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+func newRegistryClient(ctx context.Context, ref imageReference) (*dockerRegistry, error) {
+	r := &dockerRegistry{registry: ref.registry}
+	err := r.registryAuth(ctx, ref.name())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get auth for %s registry", r.registry)
+	}
+	return r, nil
+}
+
+// VerifiedImage is an image with a signature made by a trusted key.
+type VerifiedImage struct {
+	// Name is the image name pinned to the verified digest, as in
+	// "registry/repository@sha256:...".
+	Name string
+	Key  string
+}
+
+// pinnedImageName replaces the tag or the digest of the image with digest.
+func pinnedImageName(imageName, digest string) string {
+	name, _, _ := strings.Cut(imageName, "@")
+	registry, repository, _ := image.ParseImageParts(name)
+	if registry != "" {
+		repository = registry + "/" + repository
+	}
+	return repository + "@" + digest
+}
+
+// VerifyImageSignature checks whether the image has a cosign compatible
+// signature made by one of the keys. The returned image is pinned to the
+// verified digest, so it must be the one deployed.
+func VerifyImageSignature(ctx context.Context, imageName string, keys []VerificationKey) (*VerifiedImage, error) {
+	ref := parseImageReference(imageName)
+	r, err := newRegistryClient(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := r.resolveDigest(ctx, ref.repository, ref.ref)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := r.getSignatureManifest(ctx, ref.repository, signatureTag(digest))
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, ErrImageNotSigned
+	}
+	for _, layer := range manifest.Layers {
+		encoded, ok := layer.Annotations[signatureAnnotation]
+		if layer.MediaType != simpleSigningMediaType || !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		payload, err := r.getBlob(ctx, ref.repository, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := verifySimpleSigning(keys, digest, payload, signature); ok {
+			return &VerifiedImage{Name: pinnedImageName(imageName, digest), Key: name}, nil
+		}
+	}
+	return nil, ErrInvalidImageSignature
+}
+
+// SignImage pushes a cosign compatible signature of the image, made with the
+// key in image-signature:signing-key, keeping the existing signatures.
+func SignImage(ctx context.Context, imageName string) error {
+	signer, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	ref := parseImageReference(imageName)
+	r, err := newRegistryClient(ctx, ref)
+	if err != nil {
+		return err
+	}
+	digest, err := r.resolveDigest(ctx, ref.repository, ref.ref)
+	if err != nil {
+		return err
+	}
+	sigTag := signatureTag(digest)
+	manifest, err := r.getSignatureManifest(ctx, ref.repository, sigTag)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		manifest = &signatureManifest{SchemaVersion: 2, MediaType: ociManifestMediaType}
+	}
+	payload, err := newSimpleSigningPayload(ref.name(), digest)
+	if err != nil {
+		return err
+	}
+	signature, err := signPayload(signer, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign image")
+	}
+	// pushing requires a token with a different scope than the one used to
+	// pull the manifests above
+	pusher, err := newRegistryClient(ctx, ref)
+	if err != nil {
+		return err
+	}
+	layer, err := pusher.uploadBlob(ctx, ref.repository, simpleSigningMediaType, payload)
+	if err != nil {
+		return err
+	}
+	layer.Annotations = map[string]string{signatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+	manifest.Layers = append(manifest.Layers, layer)
+	diffIDs := make([]string, len(manifest.Layers))
+	for i, l := range manifest.Layers {
+		diffIDs[i] = l.Digest
+	}
+	imageConfig, err := json.Marshal(map[string]interface{}{
+		"architecture": "",
+		"os":           "",
+		"config":       map[string]interface{}{},
+		"rootfs":       map[string]interface{}{"type": "layers", "diff_ids": diffIDs},
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Config, err = pusher.uploadBlob(ctx, ref.repository, ociConfigMediaType, imageConfig)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return pusher.putManifest(ctx, ref.repository, sigTag, manifest.MediaType, data)
+}
+
+// resolveDigest returns the digest of the manifest referenced by ref, which
+// is the digest signed by cosign, either of an image or of an image index.
+// The digest is computed from the manifest instead of trusting the one
+// reported by the registry.
+func (r *dockerRegistry) resolveDigest(ctx context.Context, repository, ref string) (string, error) {
+	if strings.HasPrefix(ref, "sha256:") {
+		return ref, nil
+	}
+	path := fmt.Sprintf("/v2/%s/manifests/%s", repository, ref)
+	resp, err := r.doRequest(ctx, http.MethodGet, path, map[string]string{"Accept": manifestMediaTypes})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrImageNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", errors.Errorf("invalid status reading manifest for %v:%v: %v", repository, ref, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxManifestSize {
+		return "", errors.Errorf("manifest for %v:%v is larger than %d bytes", repository, ref, maxManifestSize)
+	}
+	return blobDigest(data), nil
+}
+
+// getSignatureManifest returns nil when there are no signatures in tag.
+func (r *dockerRegistry) getSignatureManifest(ctx context.Context, repository, tag string) (*signatureManifest, error) {
+	path := fmt.Sprintf("/v2/%s/manifests/%s", repository, tag)
+	resp, err := r.doRequest(ctx, http.MethodGet, path, map[string]string{"Accept": ociManifestMediaType + ", " + dockerManifestMediaType})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("invalid status reading signatures for %v:%v: %v", repository, tag, resp.StatusCode)
+	}
+	var manifest signatureManifest
+	err = json.NewDecoder(resp.Body).Decode(&manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid signature manifest for %v:%v", repository, tag)
+	}
+	return &manifest, nil
+}
+
+func (r *dockerRegistry) getBlob(ctx context.Context, repository, digest string) ([]byte, error) {
+	path := fmt.Sprintf("/v2/%s/blobs/%s", repository, digest)
+	resp, err := r.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("invalid status reading blob %v from %v: %v", digest, repository, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if blobDigest(data) != digest {
+		return nil, errors.Errorf("digest mismatch reading blob %v from %v", digest, repository)
+	}
+	return data, nil
+}
+
+// uploadBlob pushes data to the repository with a monolithic upload.
+func (r *dockerRegistry) uploadBlob(ctx context.Context, repository, mediaType string, data []byte) (descriptor, error) {
+	desc := descriptor{MediaType: mediaType, Size: int64(len(data)), Digest: blobDigest(data)}
+	resp, err := r.doRequest(ctx, http.MethodPost, fmt.Sprintf("/v2/%s/blobs/uploads/", repository), nil)
+	if err != nil {
+		return desc, err
+	}
+	closeRespBody(resp)
+	if resp.StatusCode != http.StatusAccepted {
+		return desc, errors.Errorf("invalid status starting blob upload to %v: %v", repository, resp.StatusCode)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return desc, errors.Wrapf(err, "invalid blob upload location for %v", repository)
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest)
+	location.RawQuery = query.Encode()
+	resp, err = r.doRequestWithBody(ctx, http.MethodPut, location.String(), map[string]string{"Content-Type": "application/octet-stream"}, data)
+	if err != nil {
+		return desc, err
+	}
+	closeRespBody(resp)
+	if resp.StatusCode != http.StatusCreated {
+		return desc, errors.Errorf("invalid status uploading blob to %v: %v", repository, resp.StatusCode)
+	}
+	return desc, nil
+}
+
+func (r *dockerRegistry) putManifest(ctx context.Context, repository, tag, mediaType string, data []byte) error {
+	path := fmt.Sprintf("/v2/%s/manifests/%s", repository, tag)
+	resp, err := r.doRequestWithBody(ctx, http.MethodPut, path, map[string]string{"Content-Type": mediaType}, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("invalid status pushing manifest %v:%v (%d): %s", repository, tag, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func blobDigest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
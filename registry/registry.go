@@ -5,6 +5,7 @@
 package registry
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -168,6 +169,12 @@ func (r dockerRegistry) removeImagePath(ctx context.Context, path string) error
 }
 
 func (r *dockerRegistry) doRequest(ctx context.Context, method, path string, headers map[string]string) (*http.Response, error) {
+	return r.doRequestWithBody(ctx, method, path, headers, nil)
+}
+
+// doRequestWithBody sends a request to the registry, path may also be an
+// absolute URL, like the upload locations returned by the registry.
+func (r *dockerRegistry) doRequestWithBody(ctx context.Context, method, path string, headers map[string]string, body []byte) (*http.Response, error) {
 	var err error
 	if r.client == nil {
 		server := getServerFromRegistry(r.registry)
@@ -180,7 +187,7 @@ func (r *dockerRegistry) doRequest(ctx context.Context, method, path string, hea
 	maxTries := 5
 	for attemptNum := 0; attemptNum < maxTries; attemptNum++ {
 		for _, scheme := range []string{"https", "http"} {
-			resp, err := r.attemptRequest(ctx, method, path, headers, scheme)
+			resp, err := r.attemptRequest(ctx, method, path, headers, body, scheme)
 
 			if _, ok := err.(net.Error); ok {
 				continue
@@ -210,11 +217,18 @@ func (r *dockerRegistry) doRequest(ctx context.Context, method, path string, hea
 	return nil, errors.New("exceeded maximum request attempts")
 }
 
-func (r *dockerRegistry) attemptRequest(ctx context.Context, method, path string, headers map[string]string, scheme string) (*http.Response, error) {
-	server := getServerFromRegistry(r.registry)
-	endpoint := fmt.Sprintf("%s://%s%s", scheme, server, path)
+func (r *dockerRegistry) attemptRequest(ctx context.Context, method, path string, headers map[string]string, body []byte, scheme string) (*http.Response, error) {
+	endpoint := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		server := getServerFromRegistry(r.registry)
+		endpoint = fmt.Sprintf("%s://%s%s", scheme, server, path)
+	}
 
-	req, err := http.NewRequest(method, endpoint, nil)
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, endpoint, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,190 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const simpleSigningType = "cosign container image signature"
+
+var (
+	ErrImageNotSigned        = errors.New("image is not signed")
+	ErrInvalidImageSignature = errors.New("no valid signature found for the trusted keys")
+)
+
+// VerificationKey is a public key trusted to sign images, configured in
+// image-signature:keys:<name>.
+type VerificationKey struct {
+	Name string
+	Key  crypto.PublicKey
+}
+
+// simpleSigning is the payload signed by cosign compatible signatures.
+type simpleSigning struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// ImageSigningEnabled tells whether the images built by tsuru are signed,
+// with the private key in image-signature:signing-key.
+func ImageSigningEnabled() bool {
+	path, _ := config.GetString("image-signature:signing-key")
+	return path != ""
+}
+
+// LoadVerificationKeys loads the public keys with the given names, in the PEM
+// files set in image-signature:keys. The name "*" loads every configured key.
+func LoadVerificationKeys(names []string) ([]VerificationKey, error) {
+	paths := map[string]string{}
+	configured, _ := config.Get("image-signature:keys")
+	if m, ok := configured.(map[interface{}]interface{}); ok {
+		for k, v := range m {
+			name, _ := k.(string)
+			path, _ := v.(string)
+			paths[name] = path
+		}
+	}
+	for _, name := range names {
+		if name != "*" {
+			continue
+		}
+		names = make([]string, 0, len(paths))
+		for n := range paths {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		break
+	}
+	keys := make([]VerificationKey, 0, len(names))
+	for _, name := range names {
+		path, ok := paths[name]
+		if !ok {
+			return nil, errors.Errorf("image signature key %q is not configured", name)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read image signature key %q", name)
+		}
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid image signature key %q", name)
+		}
+		keys = append(keys, VerificationKey{Name: name, Key: key})
+	}
+	return keys, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func loadSigningKey() (crypto.Signer, error) {
+	path, err := config.GetString("image-signature:signing-key")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read image signing key")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid image signing key: no PEM data found")
+	}
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid image signing key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("invalid image signing key: unsupported key type %T", key)
+	}
+	return signer, nil
+}
+
+func newSimpleSigningPayload(repository, digest string) ([]byte, error) {
+	var payload simpleSigning
+	payload.Critical.Identity.DockerReference = repository
+	payload.Critical.Image.DockerManifestDigest = digest
+	payload.Critical.Type = simpleSigningType
+	return json.Marshal(payload)
+}
+
+// signPayload signs the payload the same way cosign does, hashing it with
+// SHA-256 except for ed25519 keys, which sign the payload itself.
+func signPayload(signer crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(payload)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func verifyPayload(key crypto.PublicKey, payload, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil ||
+			rsa.VerifyPSS(k, crypto.SHA256, digest[:], signature, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	}
+	return false
+}
+
+// verifySimpleSigning returns the name of the key that signed the payload,
+// checking that the payload refers to the image digest.
+func verifySimpleSigning(keys []VerificationKey, digest string, payload, signature []byte) (string, bool) {
+	for _, key := range keys {
+		if !verifyPayload(key.Key, payload, signature) {
+			continue
+		}
+		var signed simpleSigning
+		if err := json.Unmarshal(payload, &signed); err != nil {
+			return "", false
+		}
+		if signed.Critical.Type != simpleSigningType || signed.Critical.Image.DockerManifestDigest != digest {
+			return "", false
+		}
+		return key.Name, true
+	}
+	return "", false
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	"github.com/tsuru/config"
+	registrytest "github.com/tsuru/tsuru/registry/testing"
+	check "gopkg.in/check.v1"
+)
+
+// writeKeyPair generates an ecdsa key pair, returning the paths of the PEM
+// files with the private and the public keys.
+func writeKeyPair(c *check.C, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	privData, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, check.IsNil)
+	pubData, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	c.Assert(err, check.IsNil)
+	dir := c.MkDir()
+	privPath := filepath.Join(dir, name+".key")
+	pubPath := filepath.Join(dir, name+".pub")
+	err = os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privData}), 0600)
+	c.Assert(err, check.IsNil)
+	err = os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubData}), 0600)
+	c.Assert(err, check.IsNil)
+	return privPath, pubPath
+}
+
+func (s *S) TestLoadVerificationKeys(c *check.C) {
+	defer config.Unset("image-signature")
+	_, pub1 := writeKeyPair(c, "key1")
+	_, pub2 := writeKeyPair(c, "key2")
+	config.Set("image-signature:keys:key1", pub1)
+	config.Set("image-signature:keys:key2", pub2)
+	keys, err := LoadVerificationKeys([]string{"key2"})
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.HasLen, 1)
+	c.Assert(keys[0].Name, check.Equals, "key2")
+	keys, err = LoadVerificationKeys([]string{"*"})
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.HasLen, 2)
+	c.Assert(keys[0].Name, check.Equals, "key1")
+	c.Assert(keys[1].Name, check.Equals, "key2")
+	_, err = LoadVerificationKeys([]string{"unknown"})
+	c.Assert(err, check.ErrorMatches, `image signature key "unknown" is not configured`)
+}
+
+// addImage stores manifest as the image tagged in the repository, returning
+// its digest.
+func (s *S) addImage(repository string, tags map[string]string) map[string]string {
+	digests := map[string]string{}
+	for tag, manifest := range tags {
+		digest := blobDigest([]byte(manifest))
+		s.server.SetManifest(repository, digest, []byte(manifest))
+		digests[tag] = digest
+	}
+	s.server.AddRepo(registrytest.Repository{Name: repository, Tags: digests})
+	return digests
+}
+
+func (s *S) TestSignAndVerifyImage(c *check.C) {
+	defer config.Unset("image-signature")
+	priv, pub := writeKeyPair(c, "signer")
+	_, otherPub := writeKeyPair(c, "other")
+	config.Set("image-signature:signing-key", priv)
+	config.Set("image-signature:keys:signer", pub)
+	config.Set("image-signature:keys:other", otherPub)
+	digests := s.addImage("tsuru/app-test", map[string]string{"v1": `{"layers": ["v1"]}`, "v2": `{"layers": ["v2"]}`})
+	c.Assert(ImageSigningEnabled(), check.Equals, true)
+	err := SignImage(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1")
+	c.Assert(err, check.IsNil)
+	keys, err := LoadVerificationKeys([]string{"*"})
+	c.Assert(err, check.IsNil)
+	verified, err := VerifyImageSignature(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1", keys)
+	c.Assert(err, check.IsNil)
+	c.Assert(verified, check.DeepEquals, &VerifiedImage{Name: s.server.Addr() + "/tsuru/app-test@" + digests["v1"], Key: "signer"})
+	verified, err = VerifyImageSignature(context.TODO(), s.server.Addr()+"/tsuru/app-test@"+digests["v1"], keys)
+	c.Assert(err, check.IsNil)
+	c.Assert(verified, check.DeepEquals, &VerifiedImage{Name: s.server.Addr() + "/tsuru/app-test@" + digests["v1"], Key: "signer"})
+	keys, err = LoadVerificationKeys([]string{"other"})
+	c.Assert(err, check.IsNil)
+	_, err = VerifyImageSignature(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1", keys)
+	c.Assert(err, check.Equals, ErrInvalidImageSignature)
+	_, err = VerifyImageSignature(context.TODO(), s.server.Addr()+"/tsuru/app-test:v2", keys)
+	c.Assert(err, check.Equals, ErrImageNotSigned)
+}
+
+func (s *S) TestVerifyImageSignatureTamperedManifest(c *check.C) {
+	defer config.Unset("image-signature")
+	priv, pub := writeKeyPair(c, "signer")
+	config.Set("image-signature:signing-key", priv)
+	config.Set("image-signature:keys:signer", pub)
+	digests := s.addImage("tsuru/app-test", map[string]string{"v1": `{"layers": ["v1"]}`})
+	err := SignImage(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1")
+	c.Assert(err, check.IsNil)
+	// the registry keeps reporting the signed digest for a different manifest
+	s.server.SetManifest("tsuru/app-test", digests["v1"], []byte(`{"layers": ["evil"]}`))
+	keys, err := LoadVerificationKeys([]string{"signer"})
+	c.Assert(err, check.IsNil)
+	_, err = VerifyImageSignature(context.TODO(), s.server.Addr()+"/tsuru/app-test:v1", keys)
+	c.Assert(err, check.Equals, ErrImageNotSigned)
+}
+
+func (s *S) TestPinnedImageName(c *check.C) {
+	c.Assert(pinnedImageName("myregistry.io:5000/tsuru/app:v1", "sha256:abc"), check.Equals, "myregistry.io:5000/tsuru/app@sha256:abc")
+	c.Assert(pinnedImageName("tsuru/app@sha256:def", "sha256:abc"), check.Equals, "tsuru/app@sha256:abc")
+	c.Assert(pinnedImageName("nginx", "sha256:abc"), check.Equals, "nginx@sha256:abc")
+}
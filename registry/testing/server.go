@@ -7,9 +7,11 @@
 package testing
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strings"
@@ -48,6 +50,8 @@ type RegistryServer struct {
 	storageDelete bool
 	tokenAuth     bool
 	tokenRenew    bool
+	manifests     map[string][]byte
+	blobs         map[string][]byte
 }
 
 type TokenResponse struct {
@@ -85,6 +89,8 @@ func (s *RegistryServer) Reset() {
 	s.reposLock.Lock()
 	s.Repos = nil
 	s.storageDelete = true
+	s.manifests = nil
+	s.blobs = nil
 	s.reposLock.Unlock()
 }
 
@@ -97,6 +103,18 @@ func (s *RegistryServer) AddRepo(r Repository) {
 	s.Repos = append(s.Repos, r)
 }
 
+// SetManifest stores data as the manifest with the given digest in the
+// repository, without checking the digest, so tests can simulate a registry
+// serving tampered manifests.
+func (s *RegistryServer) SetManifest(name, digest string, data []byte) {
+	s.reposLock.Lock()
+	defer s.reposLock.Unlock()
+	if s.manifests == nil {
+		s.manifests = map[string][]byte{}
+	}
+	s.manifests[name+"@"+digest] = data
+}
+
 func (s *RegistryServer) SetStorageDelete(sd bool) {
 	s.reposLock.Lock()
 	s.storageDelete = sd
@@ -117,6 +135,11 @@ func (s *RegistryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (s *RegistryServer) buildMuxer() {
 	s.muxer = mux.NewRouter()
 	s.muxer.Path("/v2/{name:.*}/manifests/{tag:.*}").Methods("HEAD").HandlerFunc(s.getDigest)
+	s.muxer.Path("/v2/{name:.*}/manifests/{tag:.*}").Methods("GET").HandlerFunc(s.getManifest)
+	s.muxer.Path("/v2/{name:.*}/manifests/{tag:.*}").Methods("PUT").HandlerFunc(s.putManifest)
+	s.muxer.Path("/v2/{name:.*}/blobs/uploads/").Methods("POST").HandlerFunc(s.startUpload)
+	s.muxer.Path("/v2/{name:.*}/blobs/uploads/{uuid}").Methods("PUT").HandlerFunc(s.finishUpload)
+	s.muxer.Path("/v2/{name:.*}/blobs/{digest}").Methods("GET").HandlerFunc(s.getBlob)
 	s.muxer.Path("/v2/{name:.*}/manifests/{digest:.*}").Methods("DELETE").HandlerFunc(s.removeTag)
 	s.muxer.Path("/v2/{name:.*}/tags/list").Methods("GET").HandlerFunc(s.listTags)
 	s.muxer.Path("/token/{name:.*}").Methods("GET").HandlerFunc(s.getToken)
@@ -262,6 +285,117 @@ func (s *RegistryServer) listTags(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *RegistryServer) getManifest(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	repo, index := s.findRepository(name)
+	err := s.auth(r)
+	if err != nil {
+		s.handleAuthError(w, err, name)
+		return
+	}
+	if index < 0 {
+		http.Error(w, fmt.Sprintf("unknown repository name=%s", name), http.StatusNotFound)
+		return
+	}
+	tag := mux.Vars(r)["tag"]
+	s.reposLock.RLock()
+	defer s.reposLock.RUnlock()
+	digest, ok := repo.Tags[tag]
+	if !ok {
+		digest = tag
+	}
+	data, ok := s.manifests[name+"@"+digest]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown manifest=%s", tag), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.Write(data)
+}
+
+func (s *RegistryServer) putManifest(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	repo, index := s.findRepository(name)
+	err := s.auth(r)
+	if err != nil {
+		s.handleAuthError(w, err, name)
+		return
+	}
+	if index < 0 {
+		http.Error(w, fmt.Sprintf("unknown repository name=%s", name), http.StatusNotFound)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	s.reposLock.Lock()
+	defer s.reposLock.Unlock()
+	if s.manifests == nil {
+		s.manifests = map[string][]byte{}
+	}
+	s.manifests[name+"@"+digest] = data
+	repo.Tags[mux.Vars(r)["tag"]] = digest
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *RegistryServer) startUpload(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	err := s.auth(r)
+	if err != nil {
+		s.handleAuthError(w, err, name)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", name, time.Now().UnixNano()))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *RegistryServer) finishUpload(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	err := s.auth(r)
+	if err != nil {
+		s.handleAuthError(w, err, name)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	digest := r.URL.Query().Get("digest")
+	if digest != fmt.Sprintf("sha256:%x", sha256.Sum256(data)) {
+		http.Error(w, "digest mismatch", http.StatusBadRequest)
+		return
+	}
+	s.reposLock.Lock()
+	defer s.reposLock.Unlock()
+	if s.blobs == nil {
+		s.blobs = map[string][]byte{}
+	}
+	s.blobs[name+"@"+digest] = data
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *RegistryServer) getBlob(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	err := s.auth(r)
+	if err != nil {
+		s.handleAuthError(w, err, name)
+		return
+	}
+	s.reposLock.RLock()
+	defer s.reposLock.RUnlock()
+	data, ok := s.blobs[name+"@"+mux.Vars(r)["digest"]]
+	if !ok {
+		http.Error(w, "unknown blob", http.StatusNotFound)
+		return
+	}
+	w.Write(data)
+}
+
 func (s *RegistryServer) findRepository(name string) (Repository, int) {
 	s.reposLock.RLock()
 	defer s.reposLock.RUnlock()
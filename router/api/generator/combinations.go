@@ -16,7 +16,8 @@ import (
 )
 
 var capMap = map[string][]string{
-	"tls": {"router.TLSRouter", "apiRouterWithTLSSupport"},
+	"tls":      {"router.TLSRouter", "apiRouterWithTLSSupport"},
+	"weighted": {"router.WeightedRouter", "apiRouterWithWeightSupport"},
 }
 
 var fileTpl = `// AUTOMATICALLY GENERATED FILE - DO NOT EDIT!
@@ -27,8 +27,9 @@ import (
 const routerType = "api"
 
 var (
-	_ router.Router    = &apiRouter{}
-	_ router.TLSRouter = &apiRouterWithTLSSupport{}
+	_ router.Router         = &apiRouter{}
+	_ router.TLSRouter      = &apiRouterWithTLSSupport{}
+	_ router.WeightedRouter = &apiRouterWithWeightSupport{}
 )
 
 type apiRouter struct {
@@ -44,6 +45,8 @@ type apiRouter struct {
 
 type apiRouterWithTLSSupport struct{ *apiRouter }
 
+type apiRouterWithWeightSupport struct{ *apiRouter }
+
 type routesReq struct {
 	Prefix    string            `json:"prefix"`
 	Addresses []string          `json:"addresses"`
@@ -69,6 +72,10 @@ type certData struct {
 	Key         string `json:"key"`
 }
 
+type weightsReq struct {
+	Weights []router.BackendWeight `json:"weights"`
+}
+
 type backendResp struct {
 	Address   string   `json:"address"`
 	Addresses []string `json:"addresses"`
@@ -77,9 +84,10 @@ type backendResp struct {
 type capability string
 
 var (
-	capTLS = capability("tls")
+	capTLS      = capability("tls")
+	capWeighted = capability("weighted")
 
-	allCaps = []capability{capTLS}
+	allCaps = []capability{capTLS, capWeighted}
 )
 
 func init() {
@@ -288,6 +296,22 @@ func (r *apiRouterWithTLSSupport) GetCertificate(ctx context.Context, app *appTy
 	return "", err
 }
 
+func (r *apiRouterWithWeightSupport) SetBackendWeights(ctx context.Context, app *appTypes.App, weights []router.BackendWeight) error {
+	b, err := json.Marshal(weightsReq{Weights: weights})
+	if err != nil {
+		return err
+	}
+	headers, err := r.getExtraHeadersFromApp(ctx, app)
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(ctx, http.MethodPut, fmt.Sprintf("backend/%s/weights", app.Name), headers, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
 func (r *apiRouter) GetInfo(ctx context.Context) (map[string]string, error) {
 	data, _, err := r.do(ctx, http.MethodGet, "info", nil, nil)
 	if err != nil {
@@ -138,6 +138,20 @@ func (s *S) TestGetCertificateNotFound(c *check.C) {
 	c.Assert(cert, check.DeepEquals, "")
 }
 
+func (s *S) TestSetBackendWeights(c *check.C) {
+	weightedRouter := &apiRouterWithWeightSupport{s.testRouter}
+	weights := []router.BackendWeight{{Version: 1, Weight: 75}, {Version: 2, Weight: 25}}
+	err := weightedRouter.SetBackendWeights(context.TODO(), &appTypes.App{Name: "mybackend"}, weights)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].weights, check.DeepEquals, weights)
+}
+
+func (s *S) TestSetBackendWeightsBackendNotFound(c *check.C) {
+	weightedRouter := &apiRouterWithWeightSupport{s.testRouter}
+	err := weightedRouter.SetBackendWeights(context.TODO(), &appTypes.App{Name: "unknown"}, []router.BackendWeight{{Version: 1, Weight: 100}})
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
 func (s *S) TestEnsureBackend(c *check.C) {
 	routerV2 := s.testRouter
 	app := appTypes.App{Name: "myapp", Pool: "mypool", Teams: []string{"team01", "team02"}, TeamOwner: "team03"}
@@ -191,8 +205,9 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 		expectCname bool
 		expectTLS   bool
 		expectHC    bool
+		expectW     bool
 	}{
-		{nil, false, false, false},
+		{nil, false, false, false, false},
 		{features: map[string]bool{"cname": true}, expectCname: true},
 		{features: map[string]bool{"tls": true}, expectTLS: true},
 		{features: map[string]bool{"healthcheck": true}, expectHC: true},
@@ -200,6 +215,8 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 		{features: map[string]bool{"cname": true, "tls": true, "healthcheck": true}, expectCname: true, expectTLS: true, expectHC: true},
 		{features: map[string]bool{"cname": true, "healthcheck": true}, expectCname: true, expectHC: true},
 		{features: map[string]bool{"tls": true, "healthcheck": true}, expectTLS: true, expectHC: true},
+		{features: map[string]bool{"weighted": true}, expectW: true},
+		{features: map[string]bool{"tls": true, "weighted": true}, expectTLS: true, expectW: true},
 	}
 	var i int
 	s.apiRouter.router.HandleFunc("/support/{name}", func(w http.ResponseWriter, r *http.Request) {
@@ -221,6 +238,8 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 		c.Assert(err, check.IsNil, comment)
 		_, ok := r.(router.TLSRouter)
 		c.Assert(ok, check.Equals, tt[i].expectTLS, comment)
+		_, ok = r.(router.WeightedRouter)
+		c.Assert(ok, check.Equals, tt[i].expectW, comment)
 	}
 }
 
@@ -290,6 +309,7 @@ func newFakeRouter(c *check.C) *fakeRouterAPI {
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.addCertificate).Methods(http.MethodPut)
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.removeCertificate).Methods(http.MethodDelete)
 	r.HandleFunc("/backend/{name}/status", api.getStatusBackend).Methods(http.MethodGet)
+	r.HandleFunc("/backend/{name}/weights", api.setWeights).Methods(http.MethodPut)
 	r.HandleFunc("/info", api.getInfo).Methods(http.MethodGet)
 
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -316,6 +336,7 @@ type backend struct {
 	healthcheck routerTypes.HealthcheckData
 	opts        map[string]interface{}
 	prefixAddrs map[string]routesReq
+	weights     []router.BackendWeight
 }
 
 type fakeRouterAPI struct {
@@ -337,6 +358,21 @@ func (f *fakeRouterAPI) getStatusBackend(w http.ResponseWriter, r *http.Request)
 	w.Write([]byte(`{"status": "ready", "detail": "anaander"}`))
 }
 
+func (f *fakeRouterAPI) setWeights(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	backend, ok := f.backends[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req weightsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	backend.weights = req.Weights
+}
+
 func (f *fakeRouterAPI) getBackend(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	name := vars["name"]
@@ -1,7 +1,7 @@
 // AUTOMATICALLY GENERATED FILE - DO NOT EDIT!
 // Please run 'go generate' to update this file.
 //
-// Copyright 2023 tsuru authors. All rights reserved.
+// Copyright 2026 tsuru authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
@@ -13,15 +13,16 @@ import (
 
 func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.Router {
 	apiRouterWithTLSSupportInst := &apiRouterWithTLSSupport{base}
+	apiRouterWithWeightSupportInst := &apiRouterWithWeightSupport{base}
 
-	if !supports["tls"] {
+	if !supports["tls"] && !supports["weighted"] {
 		return &struct {
 			router.Router
 		}{
 			base,
 		}
 	}
-	if supports["tls"] {
+	if supports["tls"] && !supports["weighted"] {
 		return &struct {
 			router.Router
 			router.TLSRouter
@@ -30,5 +31,25 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			apiRouterWithTLSSupportInst,
 		}
 	}
+	if !supports["tls"] && supports["weighted"] {
+		return &struct {
+			router.Router
+			router.WeightedRouter
+		}{
+			base,
+			apiRouterWithWeightSupportInst,
+		}
+	}
+	if supports["tls"] && supports["weighted"] {
+		return &struct {
+			router.Router
+			router.TLSRouter
+			router.WeightedRouter
+		}{
+			base,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithWeightSupportInst,
+		}
+	}
 	return nil
 }
@@ -164,6 +164,19 @@ type TLSRouter interface {
 	GetCertificate(ctx context.Context, app *appTypes.App, cname string) (string, error)
 }
 
+// WeightedRouter is a router that supports splitting the traffic of a
+// backend between the versions of an app.
+type WeightedRouter interface {
+	SetBackendWeights(ctx context.Context, app *appTypes.App, weights []BackendWeight) error
+}
+
+// BackendWeight is the share of the traffic, from 0 to 100, that must be
+// sent to a given app version.
+type BackendWeight struct {
+	Version int `json:"version"`
+	Weight  int `json:"weight"`
+}
+
 type BackendStatus string
 
 var (
@@ -25,11 +25,17 @@ var TLSRouter = tlsRouter{
 	Keys:       make(map[string]string),
 }
 
+var WeightedRouter = weightedRouter{
+	fakeRouter: newFakeRouter(),
+	Weights:    make(map[string][]router.BackendWeight),
+}
+
 var ErrForcedFailure = errors.New("Forced failure")
 
 func init() {
 	router.Register("fake", createRouter)
 	router.Register("fake-tls", createTLSRouter)
+	router.Register("fake-weighted", createWeightedRouter)
 }
 
 func createRouter(name string, config router.ConfigGetter) (router.Router, error) {
@@ -40,6 +46,10 @@ func createTLSRouter(name string, config router.ConfigGetter) (router.Router, er
 	return &TLSRouter, nil
 }
 
+func createWeightedRouter(name string, config router.ConfigGetter) (router.Router, error) {
+	return &WeightedRouter, nil
+}
+
 func newFakeRouter() fakeRouter {
 	return fakeRouter{
 		cnames:      make(map[string]string),
@@ -293,3 +303,35 @@ func (r *tlsRouter) Reset() {
 	r.Certs = make(map[string]string)
 	r.Keys = make(map[string]string)
 }
+
+type weightedRouter struct {
+	fakeRouter
+	Weights map[string][]router.BackendWeight
+}
+
+var _ router.WeightedRouter = &weightedRouter{}
+
+func (r *weightedRouter) GetName() string {
+	return "fake-weighted"
+}
+
+func (r *weightedRouter) GetType() string {
+	return "fake-weighted"
+}
+
+func (r *weightedRouter) SetBackendWeights(ctx context.Context, app *appTypes.App, weights []router.BackendWeight) error {
+	if !r.HasBackend(app.Name) {
+		return router.ErrBackendNotFound
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Weights[app.Name] = weights
+	return nil
+}
+
+func (r *weightedRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Weights = make(map[string][]router.BackendWeight)
+}
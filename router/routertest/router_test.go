@@ -99,6 +99,25 @@ func (s *S) TestReset(c *check.C) {
 	c.Assert(r.HasBackend("name"), check.Equals, false)
 }
 
+func (s *S) TestSetBackendWeights(c *check.C) {
+	r := &WeightedRouter
+	defer r.Reset()
+	app := &appTypes.App{Name: "myapp"}
+	err := r.EnsureBackend(context.TODO(), app, router.EnsureBackendOpts{})
+	c.Assert(err, check.IsNil)
+	weights := []router.BackendWeight{{Version: 1, Weight: 95}, {Version: 2, Weight: 5}}
+	err = r.SetBackendWeights(context.TODO(), app, weights)
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Weights["myapp"], check.DeepEquals, weights)
+}
+
+func (s *S) TestSetBackendWeightsUnknownBackend(c *check.C) {
+	r := &WeightedRouter
+	defer r.Reset()
+	err := r.SetBackendWeights(context.TODO(), &appTypes.App{Name: "myapp"}, nil)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
 func (s *S) TestAddCertificate(c *check.C) {
 	r := TLSRouter
 	err := r.AddCertificate(context.TODO(), &appTypes.App{Name: "myapp"}, "example.com", "cert", "key")
@@ -0,0 +1,132 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package secret provides the interface secret providers must satisfy to
+// resolve environment variables referencing external secret stores.
+package secret
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+const (
+	// KubernetesProvider is the name of the built-in provider for Secrets
+	// living in the app namespace. Its references are mounted in the units
+	// instead of resolved by tsuru, so their ownership is checked by the
+	// provisioner.
+	KubernetesProvider = "kubernetes"
+
+	defaultPathPrefix = "{team}"
+)
+
+var (
+	ErrSecretNotFound = errors.New("secret not found")
+
+	providers = map[string]providerFactory{}
+)
+
+type ErrProviderNotFound struct {
+	Name string
+}
+
+func (e *ErrProviderNotFound) Error() string {
+	return fmt.Sprintf("secret provider %q not found", e.Name)
+}
+
+// Provider resolves references to values kept in a secret store.
+type Provider interface {
+	Resolve(ctx context.Context, ref bindTypes.SecretRef) (string, error)
+}
+
+type providerFactory func(name, prefix string) (Provider, error)
+
+// Register registers a new secret provider type.
+func Register(providerType string, factory providerFactory) {
+	providers[providerType] = factory
+}
+
+func Unregister(providerType string) {
+	delete(providers, providerType)
+}
+
+// Get returns the provider configured in secret-providers:<name>.
+func Get(name string) (Provider, error) {
+	prefix := "secret-providers:" + name
+	providerType, err := config.GetString(prefix + ":type")
+	if err != nil {
+		return nil, &ErrProviderNotFound{Name: name}
+	}
+	factory, ok := providers[providerType]
+	if !ok {
+		return nil, errors.Errorf("unknown secret provider type: %q", providerType)
+	}
+	return factory(name, prefix)
+}
+
+// Validate checks whether the reference is complete and points to a known
+// provider. References to external providers must be under the path prefix
+// of the app, so apps are never able to read secrets of other teams.
+func Validate(a *appTypes.App, ref bindTypes.SecretRef) error {
+	if ref.Path == "" || ref.Key == "" {
+		return errors.New("secret reference requires both path and key")
+	}
+	if ref.Provider == KubernetesProvider {
+		return nil
+	}
+	_, err := Get(ref.Provider)
+	if err != nil {
+		return err
+	}
+	return checkPath(a, ref)
+}
+
+// Resolve returns the value referenced by ref for the app.
+func Resolve(ctx context.Context, a *appTypes.App, ref bindTypes.SecretRef) (string, error) {
+	if ref.Provider == KubernetesProvider {
+		return "", errors.New("kubernetes secret references are mounted, not resolved")
+	}
+	p, err := Get(ref.Provider)
+	if err != nil {
+		return "", err
+	}
+	err = checkPath(a, ref)
+	if err != nil {
+		return "", err
+	}
+	value, err := p.Resolve(ctx, ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve key %q of secret %q from provider %q", ref.Key, ref.Path, ref.Provider)
+	}
+	return value, nil
+}
+
+// PathPrefix returns the prefix every path referenced by the app in the
+// provider must start with. It's set in secret-providers:<name>:path-prefix,
+// where {team} and {app} are replaced by the team owner and the name of the
+// app, and defaults to {team}.
+func PathPrefix(providerName string, a *appTypes.App) string {
+	prefix, _ := config.GetString("secret-providers:" + providerName + ":path-prefix")
+	if prefix == "" {
+		prefix = defaultPathPrefix
+	}
+	prefix = strings.NewReplacer("{team}", a.TeamOwner, "{app}", a.Name).Replace(prefix)
+	return strings.Trim(prefix, "/") + "/"
+}
+
+func checkPath(a *appTypes.App, ref bindTypes.SecretRef) error {
+	prefix := PathPrefix(ref.Provider, a)
+	refPath := strings.Trim(ref.Path, "/")
+	if path.Clean("/"+refPath) != "/"+refPath || !strings.HasPrefix(refPath, prefix) {
+		return errors.Errorf("secret path %q is not allowed, paths of app %q in provider %q must be under %q", ref.Path, a.Name, ref.Provider, prefix)
+	}
+	return nil
+}
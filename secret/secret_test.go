@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestGet(c *check.C) {
+	p, err := Get("myfake")
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.FitsTypeOf, &fakeProvider{})
+	_, err = Get("missing")
+	c.Assert(err, check.DeepEquals, &ErrProviderNotFound{Name: "missing"})
+	_, err = Get("broken")
+	c.Assert(err, check.ErrorMatches, `unknown secret provider type: "unknown"`)
+}
+
+func (s *S) TestValidate(c *check.C) {
+	tests := []struct {
+		ref bindTypes.SecretRef
+		err string
+	}{
+		{ref: bindTypes.SecretRef{Provider: "myfake", Path: "myteam/db", Key: "password"}},
+		{ref: bindTypes.SecretRef{Provider: KubernetesProvider, Path: "db-credentials", Key: "password"}},
+		{ref: bindTypes.SecretRef{Provider: "myfake", Path: "myteam/db"}, err: "secret reference requires both path and key"},
+		{ref: bindTypes.SecretRef{Provider: "missing", Path: "myteam/db", Key: "password"}, err: `secret provider "missing" not found`},
+		{ref: bindTypes.SecretRef{Provider: "myfake", Path: "otherteam/db", Key: "password"}, err: `secret path "otherteam/db" is not allowed, paths of app "myapp" in provider "myfake" must be under "myteam/"`},
+		{ref: bindTypes.SecretRef{Provider: "myfake", Path: "myteam/../otherteam/db", Key: "password"}, err: `secret path .* is not allowed, .*`},
+		{ref: bindTypes.SecretRef{Provider: "myfake", Path: "myteamx/db", Key: "password"}, err: `secret path .* is not allowed, .*`},
+	}
+	a := &appTypes.App{Name: "myapp", TeamOwner: "myteam"}
+	for _, tt := range tests {
+		err := Validate(a, tt.ref)
+		if tt.err == "" {
+			c.Check(err, check.IsNil)
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err)
+		}
+	}
+}
+
+func (s *S) TestResolve(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: "myteam"}
+	value, err := Resolve(context.TODO(), a, bindTypes.SecretRef{Provider: "myfake", Path: "myteam/db", Key: "password"})
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "s3cr3t")
+	_, err = Resolve(context.TODO(), a, bindTypes.SecretRef{Provider: "myfake", Path: "myteam/db", Key: "user"})
+	c.Assert(err, check.ErrorMatches, `unable to resolve key "user" of secret "myteam/db" from provider "myfake": secret not found`)
+	_, err = Resolve(context.TODO(), a, bindTypes.SecretRef{Provider: KubernetesProvider, Path: "db-credentials", Key: "password"})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestResolveOutsidePathPrefix(c *check.C) {
+	config.Set("secret-providers:myfake:path-prefix", "apps/{app}")
+	a := &appTypes.App{Name: "otherapp", TeamOwner: "myteam"}
+	_, err := Resolve(context.TODO(), a, bindTypes.SecretRef{Provider: "myfake", Path: "myteam/db", Key: "password"})
+	c.Assert(err, check.ErrorMatches, `secret path "myteam/db" is not allowed, paths of app "otherapp" in provider "myfake" must be under "apps/otherapp/"`)
+}
+
+func (s *S) TestPathPrefix(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: "myteam"}
+	c.Assert(PathPrefix("myfake", a), check.Equals, "myteam/")
+	config.Set("secret-providers:myfake:path-prefix", "/tsuru/{team}/{app}/")
+	c.Assert(PathPrefix("myfake", a), check.Equals, "tsuru/myteam/myapp/")
+}
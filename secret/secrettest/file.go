@@ -0,0 +1,65 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package secrettest provides a secret provider reading values from a local
+// JSON file, meant for tests and development environments.
+package secrettest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/secret"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+const providerType = "file"
+
+func init() {
+	secret.Register(providerType, createProvider)
+}
+
+// FileProvider resolves references from a JSON file mapping each secret path
+// to its keys and values, e.g. {"myapp/db": {"password": "s3cr3t"}}. The file
+// is read on every resolution so tests can change it at will.
+type FileProvider struct {
+	Path string
+}
+
+func createProvider(name, prefix string) (secret.Provider, error) {
+	path, err := config.GetString(prefix + ":path")
+	if err != nil {
+		return nil, errors.Errorf("file secret provider %q requires a path", name)
+	}
+	return &FileProvider{Path: path}, nil
+}
+
+func (p *FileProvider) Resolve(ctx context.Context, ref bindTypes.SecretRef) (string, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", err
+	}
+	var secrets map[string]map[string]string
+	err = json.Unmarshal(data, &secrets)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[ref.Path][ref.Key]
+	if !ok {
+		return "", secret.ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// WriteFile stores secrets in the file read by the provider.
+func (p *FileProvider) WriteFile(secrets map[string]map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.Path, data, 0600)
+}
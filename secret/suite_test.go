@@ -0,0 +1,45 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type fakeProvider struct {
+	values map[string]string
+}
+
+func (p *fakeProvider) Resolve(ctx context.Context, ref bindTypes.SecretRef) (string, error) {
+	value, ok := p.values[ref.Path+"/"+ref.Key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	Register("fake", func(name, prefix string) (Provider, error) {
+		return &fakeProvider{values: map[string]string{"myteam/db/password": "s3cr3t"}}, nil
+	})
+	config.Set("secret-providers:myfake:type", "fake")
+	config.Set("secret-providers:broken:type", "unknown")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	Unregister("fake")
+	config.Unset("secret-providers")
+}
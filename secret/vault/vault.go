@@ -0,0 +1,137 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vault implements a secret provider reading values from the KV
+// secrets engine of a Vault compatible HTTP API.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/secret"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+const (
+	providerType = "vault"
+	defaultMount = "secret"
+)
+
+func init() {
+	secret.Register(providerType, createProvider)
+}
+
+type vaultProvider struct {
+	address   string
+	token     string
+	namespace string
+	mount     string
+	kvVersion int
+	client    *http.Client
+}
+
+func createProvider(name, prefix string) (secret.Provider, error) {
+	address, err := config.GetString(prefix + ":address")
+	if err != nil {
+		return nil, errors.Errorf("vault secret provider %q requires an address", name)
+	}
+	token, err := config.GetString(prefix + ":token")
+	if err != nil {
+		return nil, errors.Errorf("vault secret provider %q requires a token", name)
+	}
+	mount, _ := config.GetString(prefix + ":mount")
+	if mount == "" {
+		mount = defaultMount
+	}
+	kvVersion, _ := config.GetInt(prefix + ":kv-version")
+	if kvVersion == 0 {
+		kvVersion = 2
+	}
+	if kvVersion != 1 && kvVersion != 2 {
+		return nil, errors.Errorf("invalid kv-version %d for vault secret provider %q, must be 1 or 2", kvVersion, name)
+	}
+	namespace, _ := config.GetString(prefix + ":namespace")
+	return &vaultProvider{
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		namespace: namespace,
+		mount:     strings.Trim(mount, "/"),
+		kvVersion: kvVersion,
+		client:    tsuruNet.Dial15Full60ClientWithPool,
+	}, nil
+}
+
+func (p *vaultProvider) secretURL(path string) string {
+	path = strings.Trim(path, "/")
+	if p.kvVersion == 1 {
+		return fmt.Sprintf("%s/v1/%s/%s", p.address, p.mount, path)
+	}
+	return fmt.Sprintf("%s/v1/%s/data/%s", p.address, p.mount, path)
+}
+
+func (p *vaultProvider) Resolve(ctx context.Context, ref bindTypes.SecretRef) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.secretURL(ref.Path), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	rsp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNotFound {
+		return "", secret.ErrSecretNotFound
+	}
+	if rsp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(rsp.Body)
+		return "", errors.Errorf("invalid response from vault (%d): %s", rsp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	var result struct {
+		Data json.RawMessage `json:"data"`
+	}
+	err = json.NewDecoder(rsp.Body).Decode(&result)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decode vault response")
+	}
+	data := result.Data
+	if p.kvVersion == 2 {
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		err = json.Unmarshal(data, &versioned)
+		if err != nil {
+			return "", errors.Wrap(err, "unable to decode vault response")
+		}
+		data = versioned.Data
+	}
+	var values map[string]interface{}
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decode vault response")
+	}
+	value, ok := values[ref.Key]
+	if !ok {
+		return "", secret.ErrSecretNotFound
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
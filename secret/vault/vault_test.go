@@ -0,0 +1,106 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/secret"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	server   *httptest.Server
+	requests []*http.Request
+}
+
+var _ = check.Suite(&S{})
+
+var testApp = &appTypes.App{Name: "myapp", TeamOwner: "myteam"}
+
+func (s *S) SetUpTest(c *check.C) {
+	s.requests = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp/db":
+			w.Write([]byte(`{"data": {"data": {"password": "s3cr3t", "port": 5432}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/myapp/db":
+			w.Write([]byte(`{"data": {"password": "v1-s3cr3t"}}`))
+		case "/v1/secret/data/myapp/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	config.Set("secret-providers:vault:type", "vault")
+	config.Set("secret-providers:vault:address", s.server.URL+"/")
+	config.Set("secret-providers:vault:token", "mytoken")
+	config.Set("secret-providers:vault:path-prefix", "{app}")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.server.Close()
+	config.Unset("secret-providers")
+}
+
+func (s *S) TestResolveKVv2(c *check.C) {
+	config.Set("secret-providers:vault:namespace", "team1")
+	value, err := secret.Resolve(context.TODO(), testApp, bindTypes.SecretRef{Provider: "vault", Path: "/myapp/db", Key: "password"})
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "s3cr3t")
+	c.Assert(s.requests, check.HasLen, 1)
+	c.Assert(s.requests[0].Header.Get("X-Vault-Token"), check.Equals, "mytoken")
+	c.Assert(s.requests[0].Header.Get("X-Vault-Namespace"), check.Equals, "team1")
+	value, err = secret.Resolve(context.TODO(), testApp, bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "port"})
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "5432")
+}
+
+func (s *S) TestResolveKVv1(c *check.C) {
+	config.Set("secret-providers:vault:mount", "kv")
+	config.Set("secret-providers:vault:kv-version", 1)
+	value, err := secret.Resolve(context.TODO(), testApp, bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "password"})
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "v1-s3cr3t")
+	c.Assert(s.requests[0].Header.Get("X-Vault-Namespace"), check.Equals, "")
+}
+
+func (s *S) TestResolveNotFound(c *check.C) {
+	_, err := secret.Resolve(context.TODO(), testApp, bindTypes.SecretRef{Provider: "vault", Path: "myapp/cache", Key: "password"})
+	c.Assert(err, check.ErrorMatches, `.*: secret not found`)
+	_, err = secret.Resolve(context.TODO(), testApp, bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "user"})
+	c.Assert(err, check.ErrorMatches, `.*: secret not found`)
+}
+
+func (s *S) TestResolveError(c *check.C) {
+	_, err := secret.Resolve(context.TODO(), testApp, bindTypes.SecretRef{Provider: "vault", Path: "myapp/forbidden", Key: "password"})
+	c.Assert(err, check.ErrorMatches, `.*invalid response from vault \(403\): {"errors": \["permission denied"\]}`)
+}
+
+func (s *S) TestResolveOtherApp(c *check.C) {
+	_, err := secret.Resolve(context.TODO(), &appTypes.App{Name: "otherapp", TeamOwner: "myteam"}, bindTypes.SecretRef{Provider: "vault", Path: "myapp/db", Key: "password"})
+	c.Assert(err, check.ErrorMatches, `secret path "myapp/db" is not allowed, .*`)
+	c.Assert(s.requests, check.HasLen, 0)
+}
+
+func (s *S) TestCreateProviderInvalidConfig(c *check.C) {
+	config.Unset("secret-providers:vault:token")
+	_, err := secret.Get("vault")
+	c.Assert(err, check.ErrorMatches, `vault secret provider "vault" requires a token`)
+	config.Set("secret-providers:vault:token", "mytoken")
+	config.Set("secret-providers:vault:kv-version", 3)
+	_, err = secret.Get("vault")
+	c.Assert(err, check.ErrorMatches, `invalid kv-version 3 for vault secret provider "vault", must be 1 or 2`)
+}
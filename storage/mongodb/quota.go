@@ -86,6 +86,41 @@ func (s *quotaStorage) Set(ctx context.Context, name string, inUse int) error {
 	return nil
 }
 
+func (s *quotaStorage) CompareAndSet(ctx context.Context, name string, old, inUse int) error {
+	query := s.query(name)
+	query["quota.inuse"] = old
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, s.collection)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	collection, err := storagev2.Collection(s.collection)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx,
+		query,
+		mongoBSON.M{"$set": mongoBSON.M{"quota.inuse": inUse}},
+	)
+
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		_, err = s.Get(ctx, name)
+		if err != nil {
+			return err
+		}
+		return quota.ErrQuotaChanged
+	}
+
+	return nil
+}
+
 func (s *quotaStorage) Get(ctx context.Context, name string) (*quota.Quota, error) {
 	query := s.query(name)
 	span := newMongoDBSpan(ctx, mongoSpanFind, s.collection)
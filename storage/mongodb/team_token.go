@@ -26,6 +26,11 @@ type teamToken struct {
 	CreatorEmail string    `bson:"creator_email"`
 	Team         string
 	Roles        []auth.RoleInstance `bson:",omitempty"`
+
+	PreviousToken          string    `bson:"previous_token,omitempty"`
+	PreviousTokenExpiresAt time.Time `bson:"previous_token_expires_at,omitempty"`
+	RotatedAt              time.Time `bson:"rotated_at,omitempty"`
+	RotationInterval       int       `bson:"rotation_interval,omitempty"`
 }
 
 var _ auth.TeamTokenStorage = &teamTokenStorage{}
@@ -61,7 +66,11 @@ func (s *teamTokenStorage) findOne(ctx context.Context, query mongoBSON.M) (*aut
 }
 
 func (s *teamTokenStorage) FindByToken(ctx context.Context, token string) (*auth.TeamToken, error) {
-	return s.findOne(ctx, mongoBSON.M{"token": token})
+	return s.findOne(ctx, tokenValueQuery(token))
+}
+
+func tokenValueQuery(token string) mongoBSON.M {
+	return mongoBSON.M{"$or": []mongoBSON.M{{"token": token}, {"previous_token": token}}}
 }
 
 func (s *teamTokenStorage) FindByTokenID(ctx context.Context, tokenID string) (*auth.TeamToken, error) {
@@ -114,9 +123,7 @@ func (s *teamTokenStorage) UpdateLastAccess(ctx context.Context, token string) e
 	span := newMongoDBSpan(ctx, mongoSpanUpdate, collection.Name())
 	defer span.Finish()
 
-	result, err := collection.UpdateOne(ctx, mongoBSON.M{
-		"token": token,
-	}, mongoBSON.M{
+	result, err := collection.UpdateOne(ctx, tokenValueQuery(token), mongoBSON.M{
 		"$set": mongoBSON.M{"last_access": time.Now().UTC()},
 	})
 	if err == mongo.ErrNoDocuments {
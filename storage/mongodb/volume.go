@@ -235,6 +235,38 @@ func (*volumeStorage) BindsForApp(ctx context.Context, volumeName, appName strin
 
 	return binds, nil
 }
+
+func (*volumeStorage) BindsForJob(ctx context.Context, volumeName, jobName string) ([]volume.VolumeBind, error) {
+	collection, err := storagev2.VolumeBindsCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	var binds []volume.VolumeBind
+	query := mongoBSON.M{"_id.job": jobName}
+	if volumeName != "" {
+		query["_id.volume"] = volumeName
+	}
+	span.SetQueryStatement(query)
+
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &binds)
+	if err != nil {
+		span.SetError(err)
+		return nil, errors.WithStack(err)
+	}
+
+	return binds, nil
+}
+
 func (*volumeStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
 	collection, err := storagev2.VolumesCollection()
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/tsuru/tsuru/types/event"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type webhookStorage struct{}
@@ -139,3 +140,49 @@ func (s *webhookStorage) Delete(ctx context.Context, name string) error {
 
 	return nil
 }
+
+func (s *webhookStorage) InsertDelivery(ctx context.Context, d event.WebhookDelivery) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, d)
+	return err
+}
+
+func (s *webhookStorage) FindDeliveries(ctx context.Context, name string, limit int) ([]event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(mongoBSON.M{"timestamp": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"webhook": name}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []event.WebhookDelivery
+	err = cursor.All(ctx, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *webhookStorage) FindDelivery(ctx context.Context, name, id string) (*event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var result event.WebhookDelivery
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "webhook": name}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = event.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
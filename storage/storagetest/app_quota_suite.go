@@ -71,3 +71,21 @@ func (s *AppQuotaSuite) TestSetNotFound(c *check.C) {
 	c.Assert(err, check.NotNil)
 	c.Assert(err, check.Equals, quota.ErrQuotaNotFound)
 }
+
+func (s *AppQuotaSuite) TestCompareAndSet(c *check.C) {
+	app := &appTypes.App{Name: "myapp", Quota: quota.Quota{Limit: 5, InUse: 1}}
+	s.AppStorage.Create(context.TODO(), app)
+	err := s.AppQuotaStorage.CompareAndSet(context.TODO(), "myapp", 2, 3)
+	c.Assert(err, check.Equals, quota.ErrQuotaChanged)
+	err = s.AppQuotaStorage.CompareAndSet(context.TODO(), "myapp", 1, 3)
+	c.Assert(err, check.IsNil)
+	quota, err := s.AppQuotaStorage.Get(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(quota.InUse, check.Equals, 3)
+	c.Assert(quota.Limit, check.Equals, 5)
+}
+
+func (s *AppQuotaSuite) TestCompareAndSetNotFound(c *check.C) {
+	err := s.AppQuotaStorage.CompareAndSet(context.TODO(), "myapp", 0, 1)
+	c.Assert(err, check.Equals, quota.ErrQuotaNotFound)
+}
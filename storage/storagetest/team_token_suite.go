@@ -56,6 +56,21 @@ func (s *TeamTokenSuite) TestFindTeamTokenByToken(c *check.C) {
 	c.Assert(token.Token, check.Equals, t.Token)
 }
 
+func (s *TeamTokenSuite) TestFindTeamTokenByPreviousToken(c *check.C) {
+	t := auth.TeamToken{Token: "5678", TokenID: "1", PreviousToken: "1234", PreviousTokenExpiresAt: time.Now().Add(time.Hour)}
+	err := s.TeamTokenStorage.Insert(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	token, err := s.TeamTokenStorage.FindByToken(context.TODO(), "1234")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Equals, "5678")
+	c.Assert(token.PreviousToken, check.Equals, "1234")
+	err = s.TeamTokenStorage.UpdateLastAccess(context.TODO(), "1234")
+	c.Assert(err, check.IsNil)
+	token, err = s.TeamTokenStorage.FindByTokenID(context.TODO(), "1")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.LastAccess.IsZero(), check.Equals, false)
+}
+
 func (s *TeamTokenSuite) TestFindTeamTokenByTokenNotFound(c *check.C) {
 	token, err := s.TeamTokenStorage.FindByToken(context.TODO(), "wat")
 	c.Assert(err, check.Equals, auth.ErrTeamTokenNotFound)
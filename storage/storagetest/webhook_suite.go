@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	check "gopkg.in/check.v1"
@@ -240,3 +241,31 @@ func (s *WebhookSuite) TestFindByNameNotFound(c *check.C) {
 	_, err := s.WebhookStorage.FindByName(context.TODO(), "wh1")
 	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
 }
+
+func (s *WebhookSuite) TestInsertAndFindDeliveries(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	deliveries := []eventTypes.WebhookDelivery{
+		{ID: "d1", Webhook: "wh1", EventID: "e1", Timestamp: now.Add(-2 * time.Minute), Attempts: 3, StatusCode: 500, Error: "invalid status code"},
+		{ID: "d2", Webhook: "wh1", EventID: "e2", Timestamp: now.Add(-time.Minute), Attempts: 1, StatusCode: 200, Success: true},
+		{ID: "d3", Webhook: "wh2", EventID: "e2", Timestamp: now, Attempts: 1, StatusCode: 200, Success: true},
+	}
+	for _, d := range deliveries {
+		err := s.WebhookStorage.InsertDelivery(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.WebhookStorage.FindDeliveries(context.TODO(), "wh1", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 2)
+	c.Assert(result[0].ID, check.Equals, "d2")
+	c.Assert(result[1].ID, check.Equals, "d1")
+	result, err = s.WebhookStorage.FindDeliveries(context.TODO(), "wh1", 1)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].ID, check.Equals, "d2")
+	delivery, err := s.WebhookStorage.FindDelivery(context.TODO(), "wh1", "d1")
+	c.Assert(err, check.IsNil)
+	delivery.Timestamp = delivery.Timestamp.UTC()
+	c.Assert(*delivery, check.DeepEquals, deliveries[0])
+	_, err = s.WebhookStorage.FindDelivery(context.TODO(), "wh2", "d1")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
@@ -4,7 +4,11 @@
 
 package api
 
-import "errors"
+import (
+	"errors"
+
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
 
 var ErrWriteProtectedEnvVar = errors.New("write-protected environment variable")
 var ErrInvalidEnvVarName = errors.New("invalid environment variable name")
@@ -23,6 +27,7 @@ type Env struct {
 	Name      string
 	Value     string
 	Alias     string
-	Private   *bool  `json:"private,omitempty"`
-	ManagedBy string `json:"-" bson:"managedBy"`
+	Private   *bool                `json:"private,omitempty"`
+	ManagedBy string               `json:"-" bson:"managedBy"`
+	SecretRef *bindTypes.SecretRef `json:"secretRef,omitempty"`
 }
@@ -7,6 +7,7 @@ package app
 import (
 	"context"
 	"net/url"
+	"time"
 
 	"github.com/tsuru/tsuru/types/app/image"
 	"github.com/tsuru/tsuru/types/bind"
@@ -47,6 +48,13 @@ type App struct {
 	UUID string
 
 	Quota quota.Quota
+
+	// ExpiresAt is the time when the app is deleted by the expiration
+	// reaper, apps without it never expire.
+	ExpiresAt        *time.Time
+	ExpirationWarned bool
+
+	BuildCache BuildCache
 }
 
 var CertIssuerDotReplacement = "_dot_"
@@ -84,6 +92,8 @@ type Filter struct {
 	Statuses    []string
 	Tags        []string
 	Extra       map[string][]string
+
+	ExpiresBefore *time.Time
 }
 
 type AppService interface {
@@ -131,7 +141,8 @@ type AppInfo struct {
 	Quota      *quota.Quota      `json:"quota,omitempty"`
 	Error      string            `json:"error,omitempty"`
 
-	DashboardURL string `json:"dashboardURL,omitempty"`
+	DashboardURL string     `json:"dashboardURL,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
 }
 
 type AppInternalAddress struct {
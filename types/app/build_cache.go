@@ -0,0 +1,31 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "time"
+
+// BuildCache holds the build cache settings of an app. Platform builds reuse
+// the image of the latest successful version of the app as their cache,
+// starting over from the platform image on cache misses. The zero value
+// enables the cache, sharing it across versions, without a maximum age.
+type BuildCache struct {
+	// Disabled makes every build start from the platform image.
+	Disabled bool
+	// Isolated keeps builds of new versions, deployed alongside the current
+	// ones, from reusing the cache of the other versions.
+	Isolated bool
+	// MaxAge is how long the cache is reused after it starts over.
+	MaxAge time.Duration
+	// CreatedAt is when the cache last started over from the platform image.
+	CreatedAt *time.Time
+}
+
+// BuildCacheStatus reports whether a build reused the cache of a previous
+// version, and the reason why it didn't.
+type BuildCacheStatus struct {
+	Hit     bool   `json:"hit"`
+	Version int    `json:"version,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
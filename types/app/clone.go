@@ -0,0 +1,28 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+const (
+	// CloneServicesSame binds the clone to the service instances bound to
+	// the source app.
+	CloneServicesSame = "same"
+	// CloneServicesNew binds the clone to new service instances, created
+	// with the same plans of the instances bound to the source app.
+	CloneServicesNew = "new"
+)
+
+// CloneOptions describes the app created by cloning an existing app. Pool and
+// TeamOwner default to the ones of the source app, env vars in Env override
+// the ones copied from the source app. The clone isn't bound to any service
+// instance unless Services is either CloneServicesSame or CloneServicesNew.
+// The clone expires after TTL, when set.
+type CloneOptions struct {
+	Name      string        `json:"name"`
+	Pool      string        `json:"pool,omitempty"`
+	TeamOwner string        `json:"teamOwner,omitempty"`
+	Env       []ManifestEnv `json:"env,omitempty"`
+	Services  string        `json:"services,omitempty"`
+	TTL       string        `json:"ttl,omitempty"`
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "time"
+
+const AppExportFormatVersion = 1
+
+// AppExport is a portable definition of an app, used to recreate the app in
+// another tsuru installation. The configuration of the app is described by
+// the embedded manifest, the values of private env vars are either encrypted
+// with a passphrase, as described by EnvEncryption, or omitted and listed in
+// OmittedEnvs.
+type AppExport struct {
+	FormatVersion   int            `json:"formatVersion"`
+	ExportedAt      time.Time      `json:"exportedAt"`
+	Name            string         `json:"name"`
+	Platform        string         `json:"platform,omitempty"`
+	PlatformVersion string         `json:"platformVersion,omitempty"`
+	TeamOwner       string         `json:"teamOwner"`
+	Teams           []string       `json:"teams,omitempty"`
+	Pool            string         `json:"pool"`
+	Image           string         `json:"image,omitempty"`
+	EnvEncryption   *EnvEncryption `json:"envEncryption,omitempty"`
+	OmittedEnvs     []string       `json:"omittedEnvs,omitempty"`
+	Manifest        Manifest       `json:"manifest"`
+}
+
+// EnvEncryption describes how the values of private env vars of an export
+// were encrypted: using AES-256-GCM with a key derived from the passphrase
+// with scrypt, using Salt.
+type EnvEncryption struct {
+	Algorithm string `json:"algorithm"`
+	Salt      []byte `json:"salt"`
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image
+
+import "context"
+
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityUnknown  = "unknown"
+)
+
+// Severities lists the severities of vulnerabilities, from the most to the
+// least severe.
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityUnknown}
+
+// ScanResult summarizes the vulnerabilities found in an image, counting
+// them by severity.
+type ScanResult struct {
+	Scanner         string         `json:"scanner"`
+	Vulnerabilities map[string]int `json:"vulnerabilities"`
+}
+
+// ImageScanner scans images for known vulnerabilities.
+type ImageScanner interface {
+	Scan(ctx context.Context, imageName string) (*ScanResult, error)
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/provision"
+)
+
+const (
+	ManifestFieldDescription = "description"
+	ManifestFieldPlan        = "plan"
+	ManifestFieldTags        = "tags"
+	ManifestFieldMetadata    = "metadata"
+	ManifestFieldProcess     = "process"
+	ManifestFieldEnv         = "env"
+	ManifestFieldCName       = "cname"
+	ManifestFieldRouter      = "router"
+	ManifestFieldAutoscale   = "autoscale"
+	ManifestFieldService     = "service"
+	ManifestFieldVolume      = "volume"
+
+	ManifestActionAdd    = "add"
+	ManifestActionUpdate = "update"
+	ManifestActionRemove = "remove"
+)
+
+// Manifest describes the desired configuration of an app. Empty fields are
+// not managed by the manifest and are left untouched when it is applied, while
+// an empty list (e.g. `cnames: []`) removes every existing item.
+type Manifest struct {
+	Description string                    `json:"description,omitempty"`
+	Plan        string                    `json:"plan,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Metadata    *Metadata                 `json:"metadata,omitempty"`
+	Processes   []Process                 `json:"processes,omitempty"`
+	Env         []ManifestEnv             `json:"env,omitempty"`
+	CNames      []string                  `json:"cnames,omitempty"`
+	Routers     []AppRouter               `json:"routers,omitempty"`
+	Autoscale   []provision.AutoScaleSpec `json:"autoscale,omitempty"`
+	Services    []ManifestService         `json:"services,omitempty"`
+	Volumes     []ManifestVolume          `json:"volumes,omitempty"`
+}
+
+type ManifestEnv struct {
+	Name      string          `json:"name"`
+	Value     string          `json:"value"`
+	Alias     string          `json:"alias,omitempty"`
+	Private   bool            `json:"private,omitempty"`
+	SecretRef *bind.SecretRef `json:"secretRef,omitempty"`
+}
+
+type ManifestService struct {
+	Service  string `json:"service"`
+	Instance string `json:"instance"`
+}
+
+type ManifestVolume struct {
+	Name       string `json:"name"`
+	MountPoint string `json:"mountPoint"`
+	ReadOnly   bool   `json:"readOnly,omitempty"`
+}
+
+// ManifestChange is a single difference between a manifest and the current
+// state of an app. Before and After are meant for display, private values are
+// masked on them, while Desired holds what must be applied.
+type ManifestChange struct {
+	Field   string      `json:"field"`
+	Action  string      `json:"action"`
+	Name    string      `json:"name,omitempty"`
+	Before  interface{} `json:"before,omitempty"`
+	After   interface{} `json:"after,omitempty"`
+	Desired interface{} `json:"-" bson:"-"`
+}
@@ -48,6 +48,7 @@ type AppVersion interface {
 	TsuruYamlData() (provTypes.TsuruYamlData, error)
 	WebProcess() (string, error)
 	AddData(AddVersionDataArgs) error
+	SetCustomData(key string, value interface{}) error
 	String() string
 	ToggleEnabled(enabled bool, reason string) error
 	UpdatePastUnits(process string, replicas int) error
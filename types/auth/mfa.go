@@ -0,0 +1,13 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+// MFAEnrollment holds the data a user needs to configure an authenticator
+// app. Recovery codes are only returned once, when the enrollment starts.
+type MFAEnrollment struct {
+	Secret        string   `json:"secret"`
+	URL           string   `json:"url"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+type PersonalTokenCreateArgs struct {
+	TokenID     string   `json:"token_id" form:"token_id"`
+	Description string   `json:"description" form:"description"`
+	ExpiresIn   int      `json:"expires_in" form:"expires_in"`
+	Scopes      []string `json:"scopes" form:"scopes"`
+}
+
+// PersonalTokenScope restricts a personal token to a permission, optionally
+// in a single context. Permissions of the token are always limited to the
+// permissions of its user.
+type PersonalTokenScope struct {
+	Permission   string `json:"permission"`
+	ContextType  string `json:"context_type,omitempty"`
+	ContextValue string `json:"context_value,omitempty"`
+}
+
+// PersonalToken is a named token acting on behalf of a user. Token is only
+// set when the token is created, only its hash is stored.
+type PersonalToken struct {
+	Token       string               `json:"token,omitempty" bson:"-"`
+	TokenID     string               `json:"token_id"`
+	Description string               `json:"description"`
+	UserEmail   string               `json:"user_email"`
+	CreatedAt   time.Time            `json:"created_at"`
+	ExpiresAt   time.Time            `json:"expires_at"`
+	LastAccess  time.Time            `json:"last_access"`
+	Scopes      []PersonalTokenScope `json:"scopes,omitempty"`
+}
+
+var (
+	ErrPersonalTokenAlreadyExists = errors.New("personal token already exists")
+	ErrPersonalTokenNotFound      = errors.New("personal token not found")
+	ErrPersonalTokenExpired       = errors.New("personal token expired")
+)
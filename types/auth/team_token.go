@@ -11,17 +11,25 @@ import (
 )
 
 type TeamTokenCreateArgs struct {
-	TokenID     string `json:"token_id" form:"token_id"`
-	Description string `json:"description" form:"description"`
-	ExpiresIn   int    `json:"expires_in" form:"expires_in"`
-	Team        string `json:"team" form:"team"`
+	TokenID          string `json:"token_id" form:"token_id"`
+	Description      string `json:"description" form:"description"`
+	ExpiresIn        int    `json:"expires_in" form:"expires_in"`
+	Team             string `json:"team" form:"team"`
+	RotationInterval int    `json:"rotation_interval" form:"rotation_interval"`
 }
 
+// TeamTokenUpdateArgs changes a team token. Regenerate replaces the token
+// value immediately, while Rotate keeps the previous value valid for
+// GracePeriod seconds. A negative ExpiresIn or RotationInterval removes the
+// expiration or the automatic rotation.
 type TeamTokenUpdateArgs struct {
-	TokenID     string `json:"token_id" form:"token_id"`
-	Regenerate  bool   `json:"regenerate" form:"regenerate"`
-	Description string `json:"description" form:"description"`
-	ExpiresIn   int    `json:"expires_in" form:"expires_in"`
+	TokenID          string `json:"token_id" form:"token_id"`
+	Regenerate       bool   `json:"regenerate" form:"regenerate"`
+	Rotate           bool   `json:"rotate" form:"rotate"`
+	GracePeriod      int    `json:"grace_period" form:"grace_period"`
+	Description      string `json:"description" form:"description"`
+	ExpiresIn        int    `json:"expires_in" form:"expires_in"`
+	RotationInterval int    `json:"rotation_interval" form:"rotation_interval"`
 }
 
 type TeamToken struct {
@@ -34,11 +42,21 @@ type TeamToken struct {
 	CreatorEmail string         `json:"creator_email"`
 	Team         string         `json:"team"`
 	Roles        []RoleInstance `json:"roles,omitempty"`
+	// PreviousToken is the value replaced by the last rotation, it's still
+	// accepted until PreviousTokenExpiresAt.
+	PreviousToken          string    `json:"-"`
+	PreviousTokenExpiresAt time.Time `json:"previous_token_expires_at"`
+	RotatedAt              time.Time `json:"rotated_at"`
+	// RotationInterval is the number of seconds between automatic
+	// rotations, zero disables them.
+	RotationInterval int `json:"rotation_interval,omitempty"`
 }
 
 type TeamTokenStorage interface {
 	Insert(context.Context, TeamToken) error
 	FindByTokenID(ctx context.Context, tokenID string) (*TeamToken, error)
+	// FindByToken returns the token whose current or previous value is
+	// token.
 	FindByToken(ctx context.Context, token string) (*TeamToken, error)
 	FindByTeams(ctx context.Context, teams []string) ([]TeamToken, error)
 	UpdateLastAccess(ctx context.Context, token string) error
@@ -54,6 +72,8 @@ type TeamTokenService interface {
 	Authenticate(ctx context.Context, header string) (Token, error)
 	FindByTokenID(ctx context.Context, tokenID string) (TeamToken, error)
 	FindByUserToken(ctx context.Context, t Token) ([]TeamToken, error)
+	FindAll(ctx context.Context) ([]TeamToken, error)
+	Rotate(ctx context.Context, tokenID string, gracePeriod time.Duration) (TeamToken, error)
 	AddRole(ctx context.Context, tokenID string, roleName, contextValue string) error
 	RemoveRole(ctx context.Context, tokenID string, roleName, contextValue string) error
 }
@@ -62,5 +82,6 @@ var (
 	ErrTeamTokenAlreadyExists           = errors.New("team token already exists")
 	ErrTeamTokenNotFound                = errors.New("team token not found")
 	ErrTeamTokenExpired                 = errors.New("team token expired")
+	ErrInvalidTeamTokenRotation         = errors.New("rotation interval and grace period must not be negative")
 	ErrCannotRemoveTeamTokenWhoOwnsApps = errors.New("cannot remove team token who owns apps")
 )
@@ -5,11 +5,22 @@ package bind
 
 // EnvVar represents a environment variable for an app.
 type EnvVar struct {
-	Name      string `json:"name"`
-	Value     string `json:"value"`
-	Alias     string `json:"alias"`
-	Public    bool   `json:"public"`
-	ManagedBy string `json:"managedBy,omitempty"`
+	Name      string     `json:"name"`
+	Value     string     `json:"value"`
+	Alias     string     `json:"alias"`
+	Public    bool       `json:"public"`
+	ManagedBy string     `json:"managedBy,omitempty"`
+	SecretRef *SecretRef `json:"secretRef,omitempty" bson:",omitempty"`
+}
+
+// SecretRef references a value kept in an external secret store instead of
+// tsuru. Provider is the name of a configured secret provider, or kubernetes
+// for a Secret in the app namespace, Path identifies the secret in the
+// provider and Key the value inside it.
+type SecretRef struct {
+	Provider string `json:"provider"`
+	Path     string `json:"path"`
+	Key      string `json:"key"`
 }
 
 type ServiceEnvVar struct {
@@ -158,6 +158,8 @@ var (
 	TargetTypeWebhook         = TargetType("webhook")
 	TargetTypeGC              = TargetType("gc")
 	TargetTypeRouter          = TargetType("router")
+	TargetTypeJobWorkflow     = TargetType("job-workflow")
+	TargetTypeTeamToken       = TargetType("team-token")
 
 	ErrInvalidTargetType = errors.New("invalid event target type")
 )
@@ -202,6 +204,10 @@ func GetTargetType(t string) (TargetType, error) {
 		return TargetTypeWebhook, nil
 	case "router":
 		return TargetTypeRouter, nil
+	case "job-workflow":
+		return TargetTypeJobWorkflow, nil
+	case "team-token":
+		return TargetTypeTeamToken, nil
 	}
 	return TargetType(""), ErrInvalidTargetType
 }
@@ -8,11 +8,13 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 )
 
 var (
-	ErrWebhookAlreadyExists = errors.New("webhook already exists with the same name")
-	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrWebhookAlreadyExists    = errors.New("webhook already exists with the same name")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
 )
 
 type WebhookEventFilter struct {
@@ -35,6 +37,23 @@ type Webhook struct {
 	Method      string             `json:"method" form:"method"`
 	Body        string             `json:"body" form:"body"`
 	Insecure    bool               `json:"insecure" form:"insecure"`
+	Secret      string             `json:"-" form:"secret"`
+	ClearSecret bool               `json:"-" bson:"-" form:"clear_secret"`
+}
+
+// WebhookDelivery records the outcome of sending an event to a webhook,
+// including all the attempts made before giving up.
+type WebhookDelivery struct {
+	ID         string        `json:"id" bson:"_id"`
+	Webhook    string        `json:"webhook"`
+	EventID    string        `json:"event_id"`
+	Timestamp  time.Time     `json:"timestamp"`
+	Duration   time.Duration `json:"duration"`
+	Attempts   int           `json:"attempts"`
+	StatusCode int           `json:"status_code"`
+	Error      string        `json:"error,omitempty"`
+	Success    bool          `json:"success"`
+	Redelivery bool          `json:"redelivery"`
 }
 
 type WebhookService interface {
@@ -44,6 +63,8 @@ type WebhookService interface {
 	Delete(context.Context, string) error
 	Find(context.Context, string) (Webhook, error)
 	List(context.Context, []string) ([]Webhook, error)
+	Deliveries(ctx context.Context, name string) ([]WebhookDelivery, error)
+	Redeliver(ctx context.Context, name, deliveryID string) (WebhookDelivery, error)
 }
 
 type WebhookStorage interface {
@@ -53,4 +74,7 @@ type WebhookStorage interface {
 	FindByName(context.Context, string) (*Webhook, error)
 	FindByEvent(ctx context.Context, f WebhookEventFilter, isSuccess bool) ([]Webhook, error)
 	Delete(context.Context, string) error
+	InsertDelivery(context.Context, WebhookDelivery) error
+	FindDeliveries(ctx context.Context, name string, limit int) ([]WebhookDelivery, error)
+	FindDelivery(ctx context.Context, name, id string) (*WebhookDelivery, error)
 }
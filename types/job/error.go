@@ -12,12 +12,15 @@ import (
 var (
 	ErrJobNotFound              = errors.New("Job not found")
 	ErrJobUnitNotFound          = errors.New("Job unit not found")
+	ErrJobRunNotFound           = errors.New("Job run not found")
 	MaxAttempts                 = 5
 	ErrMaxAttemptsReached       = fmt.Errorf("Unable to generate unique job name: max attempts reached (%d)", MaxAttempts)
 	ErrJobAlreadyExists         = errors.New("a job with the same name already exists")
 	ErrInvalidSchedule          = errors.New("invalid schedule")
 	ErrInvalidConcurrencyPolicy = errors.New("invalid concurrency policy, allowed values are: Allow, Forbid, Replace")
 	ErrInvalidDeployKind        = errors.New("invalid deploy kind")
+	ErrInvalidRunsHistoryLimit  = errors.New("invalid runs history limit, it must not be negative")
+	ErrJobTriggersCycle         = errors.New("invalid triggers, a job must not trigger itself, directly or through other jobs")
 	ErrInvalidJobName           = errors.New("your job should have at most 40 " +
 		"characters, containing only lower case letters, numbers or dashes, " +
 		"starting with a letter.")
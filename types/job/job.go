@@ -7,6 +7,7 @@ package job
 import (
 	"context"
 	"io"
+	"time"
 
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -32,6 +33,11 @@ type Job struct {
 	DeployOptions *DeployOptions `json:"deployOptions"`
 
 	Spec JobSpec `json:"spec"`
+
+	// ExpiresAt is the time when the job is deleted by the expiration
+	// reaper, jobs without it never expire.
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	ExpirationWarned bool       `json:"expirationWarned,omitempty"`
 }
 
 func (job *Job) GetName() string {
@@ -54,6 +60,8 @@ type JobSpec struct {
 	Parallelism           *int32                    `json:"parallelism,omitempty"`
 	ActiveDeadlineSeconds *int64                    `json:"activeDeadlineSeconds,omitempty"`
 	BackoffLimit          *int32                    `json:"backoffLimit,omitempty"`
+	RunsHistoryLimit      *int                      `json:"runsHistoryLimit,omitempty"`
+	Triggers              *JobTriggers              `json:"triggers,omitempty"`
 	Schedule              string                    `json:"schedule"`
 	Manual                bool                      `json:"manual"`
 	Container             ContainerInfo             `json:"container"`
@@ -69,6 +77,8 @@ type Filter struct {
 	Pools     []string
 	Tags      []string
 	Extra     map[string][]string
+
+	ExpiresBefore *time.Time
 }
 
 type AddInstanceArgs struct {
@@ -97,6 +107,9 @@ type JobService interface {
 	BaseImageName(ctx context.Context, job *Job) (string, error)
 	KillUnit(ctx context.Context, job *Job, unitName string, force bool) error
 	Deploy(ctx context.Context, opts DeployOptions, job *Job, output io.Writer) (string, error)
+	SaveRun(ctx context.Context, run *JobRun) error
+	ListRuns(ctx context.Context, job *Job) ([]JobRun, error)
+	GetRun(ctx context.Context, job *Job, id string) (*JobRun, error)
 }
 
 type JobInfo struct {
@@ -29,6 +29,9 @@ type MockJobService struct {
 	OnBaseImageName    func(context.Context, *Job) (string, error)
 	OnKillUnit         func(*Job, string) error
 	OnDeploy           func(context.Context, DeployOptions, *Job, io.Writer) (string, error)
+	OnSaveRun          func(*JobRun) error
+	OnListRuns         func(*Job) ([]JobRun, error)
+	OnGetRun           func(*Job, string) (*JobRun, error)
 }
 
 func (m *MockJobService) CreateJob(ctx context.Context, job *Job, user *authTypes.User) error {
@@ -128,3 +131,24 @@ func (m *MockJobService) Deploy(ctx context.Context, opts DeployOptions, job *Jo
 	}
 	return m.OnDeploy(ctx, opts, job, output)
 }
+
+func (m *MockJobService) SaveRun(ctx context.Context, run *JobRun) error {
+	if m.OnSaveRun == nil {
+		return nil
+	}
+	return m.OnSaveRun(run)
+}
+
+func (m *MockJobService) ListRuns(ctx context.Context, job *Job) ([]JobRun, error) {
+	if m.OnListRuns == nil {
+		return nil, nil
+	}
+	return m.OnListRuns(job)
+}
+
+func (m *MockJobService) GetRun(ctx context.Context, job *Job, id string) (*JobRun, error) {
+	if m.OnGetRun == nil {
+		return nil, nil
+	}
+	return m.OnGetRun(job, id)
+}
@@ -0,0 +1,34 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import "time"
+
+const (
+	RunTriggerCron     = "cron"
+	RunTriggerManual   = "manual"
+	RunTriggerAPI      = "api"
+	RunTriggerWorkflow = "workflow"
+
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// JobRun is the record of a single execution of a job. It's kept after the
+// provisioner objects related to the execution are garbage collected.
+type JobRun struct {
+	ID        string     `json:"id" bson:"_id"`
+	Job       string     `json:"job"`
+	Unit      string     `json:"unit"`
+	Trigger   string     `json:"trigger"`
+	Status    string     `json:"status"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	ExitCode  *int32     `json:"exitCode,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Workflow  string     `json:"workflow,omitempty"`
+	Log       string     `json:"-"`
+}
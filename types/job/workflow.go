@@ -0,0 +1,37 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import "context"
+
+// JobTriggers lists the jobs triggered once a run of the job finishes,
+// allowing jobs to be chained in workflows.
+type JobTriggers struct {
+	OnSuccess []string `json:"onSuccess,omitempty"`
+	OnFailure []string `json:"onFailure,omitempty"`
+}
+
+// Jobs returns every job triggered by either outcome.
+func (t *JobTriggers) Jobs() []string {
+	if t == nil {
+		return nil
+	}
+	return append(append([]string{}, t.OnSuccess...), t.OnFailure...)
+}
+
+type workflowContextKey struct{}
+
+// ContextWithWorkflow returns a context carrying the ID of the workflow
+// that is triggering a job. The workflow ID is the ID of the run that
+// started it.
+func ContextWithWorkflow(ctx context.Context, workflow string) context.Context {
+	return context.WithValue(ctx, workflowContextKey{}, workflow)
+}
+
+// WorkflowFromContext returns the workflow ID set with ContextWithWorkflow.
+func WorkflowFromContext(ctx context.Context) string {
+	workflow, _ := ctx.Value(workflowContextKey{}).(string)
+	return workflow
+}
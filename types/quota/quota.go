@@ -22,6 +22,13 @@ func (q *Quota) IsUnlimited() bool {
 	return -1 == q.Limit
 }
 
+// ResourceQuota limits the CPU, in millicores, and the memory, in bytes,
+// reserved by the apps of a pool or team.
+type ResourceQuota struct {
+	CPUMilli Quota `json:"cpumilli"`
+	Memory   Quota `json:"memory"`
+}
+
 type QuotaItem interface {
 	GetName() string
 }
@@ -29,6 +36,9 @@ type QuotaItem interface {
 type QuotaService[T any] interface {
 	Inc(ctx context.Context, item T, delta int) error
 	Set(ctx context.Context, item T, quantity int) error
+	// CompareAndSet sets the quantity in use only if the recorded quantity
+	// is still old, returning ErrQuotaChanged otherwise.
+	CompareAndSet(ctx context.Context, item T, old, quantity int) error
 	SetLimit(ctx context.Context, item T, limit int) error
 	Get(ctx context.Context, item T) (*Quota, error)
 }
@@ -39,14 +49,21 @@ type QuotaStorage interface {
 	SetLimit(ctx context.Context, name string, limit int) error
 	Get(ctx context.Context, name string) (*Quota, error)
 	Set(ctx context.Context, name string, quantity int) error
+	CompareAndSet(ctx context.Context, name string, old, quantity int) error
 }
 
 type QuotaExceededError struct {
 	Requested uint
 	Available uint
+	// Resource describes the exceeded quota when it's not a unit or app
+	// count, e.g. memory of pool "pool1".
+	Resource string
 }
 
 func (err *QuotaExceededError) Error() string {
+	if err.Resource != "" {
+		return fmt.Sprintf("Quota exceeded for %s. Available: %d, Requested: %d.", err.Resource, err.Available, err.Requested)
+	}
 	return fmt.Sprintf("Quota exceeded. Available: %d, Requested: %d.", err.Available, err.Requested)
 }
 
@@ -55,4 +72,5 @@ var (
 	ErrLimitLowerThanAllocated = errors.New("New limit is less than the current allocated value")
 	ErrLessThanZero            = errors.New("Invalid value, cannot be less than 0")
 	ErrQuotaNotFound           = errors.New("quota not found")
+	ErrQuotaChanged            = errors.New("quota in use changed")
 )
@@ -12,15 +12,20 @@ var (
 )
 
 type MockQuotaStorage struct {
-	OnSet      func(string, int) error
-	OnSetLimit func(string, int) error
-	OnGet      func(string) (*Quota, error)
+	OnSet           func(string, int) error
+	OnCompareAndSet func(string, int, int) error
+	OnSetLimit      func(string, int) error
+	OnGet           func(string) (*Quota, error)
 }
 
 func (m *MockQuotaStorage) Set(ctx context.Context, name string, limit int) error {
 	return m.OnSet(name, limit)
 }
 
+func (m *MockQuotaStorage) CompareAndSet(ctx context.Context, name string, old, quantity int) error {
+	return m.OnCompareAndSet(name, old, quantity)
+}
+
 func (m *MockQuotaStorage) SetLimit(ctx context.Context, name string, limit int) error {
 	return m.OnSetLimit(name, limit)
 }
@@ -30,10 +35,11 @@ func (m *MockQuotaStorage) Get(ctx context.Context, name string) (*Quota, error)
 }
 
 type MockQuotaService[I any] struct {
-	OnInc      func(I, int) error
-	OnSet      func(I, int) error
-	OnSetLimit func(I, int) error
-	OnGet      func(I) (*Quota, error)
+	OnInc           func(I, int) error
+	OnSet           func(I, int) error
+	OnCompareAndSet func(I, int, int) error
+	OnSetLimit      func(I, int) error
+	OnGet           func(I) (*Quota, error)
 }
 
 func (m *MockQuotaService[I]) Inc(ctx context.Context, item I, delta int) error {
@@ -57,6 +63,13 @@ func (m *MockQuotaService[I]) Set(ctx context.Context, item I, quantity int) err
 	return m.OnSet(item, quantity)
 }
 
+func (m *MockQuotaService[I]) CompareAndSet(ctx context.Context, item I, old, quantity int) error {
+	if m.OnCompareAndSet == nil {
+		return nil
+	}
+	return m.OnCompareAndSet(item, old, quantity)
+}
+
 func (m *MockQuotaService[I]) Get(ctx context.Context, item I) (*Quota, error) {
 	return m.OnGet(item)
 }
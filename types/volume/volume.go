@@ -29,6 +29,7 @@ type VolumeBindID struct {
 	App        string
 	MountPoint string
 	Volume     string
+	Job        string `bson:",omitempty"`
 }
 
 type VolumeBind struct {
@@ -57,6 +58,7 @@ func (v *Volume) UnmarshalPlan(result interface{}) error {
 type BindOpts struct {
 	Volume     *Volume
 	AppName    string
+	JobName    string
 	MountPoint string
 	ReadOnly   bool
 }
@@ -72,6 +74,7 @@ type VolumeService interface {
 	Update(ctx context.Context, v *Volume) error
 	Delete(ctx context.Context, v *Volume) error
 	ListByApp(ctx context.Context, appName string) ([]Volume, error)
+	ListByJob(ctx context.Context, jobName string) ([]Volume, error)
 	ListByFilter(ctx context.Context, f *Filter) ([]Volume, error)
 	ListPlans(ctx context.Context) (map[string][]VolumePlan, error)
 	CheckPoolVolumeConstraints(ctx context.Context, volume Volume) error
@@ -80,6 +83,9 @@ type VolumeService interface {
 	BindApp(ctx context.Context, opts *BindOpts) error
 	UnbindApp(ctx context.Context, opts *BindOpts) error
 	BindsForApp(ctx context.Context, v *Volume, appName string) ([]VolumeBind, error)
+	BindJob(ctx context.Context, opts *BindOpts) error
+	UnbindJob(ctx context.Context, opts *BindOpts) error
+	BindsForJob(ctx context.Context, v *Volume, jobName string) ([]VolumeBind, error)
 	Binds(ctx context.Context, v *Volume) ([]VolumeBind, error)
 }
 
@@ -93,6 +99,7 @@ type VolumeStorage interface {
 	RemoveBind(ctx context.Context, id VolumeBindID) error
 	Binds(ctx context.Context, volumeName string) ([]VolumeBind, error)
 	BindsForApp(ctx context.Context, volumeName, appName string) ([]VolumeBind, error)
+	BindsForJob(ctx context.Context, volumeName, jobName string) ([]VolumeBind, error)
 
 	RenameTeam(ctx context.Context, oldName, newName string) error
 }
@@ -13,12 +13,16 @@ type MockVolumeService struct {
 	OnUpdate                     func(ctx context.Context, v *Volume) error
 	OnGet                        func(ctx context.Context, appName string) (*Volume, error)
 	OnListByApp                  func(ctx context.Context, appName string) ([]Volume, error)
+	OnListByJob                  func(ctx context.Context, jobName string) ([]Volume, error)
 	OnListByFilter               func(ctx context.Context, f *Filter) ([]Volume, error)
 	OnDelete                     func(ctx context.Context, v *Volume) error
 	OnBindApp                    func(ctx context.Context, opts *BindOpts) error
 	OnUnbindApp                  func(ctx context.Context, opts *BindOpts) error
 	OnBinds                      func(ctx context.Context, v *Volume) ([]VolumeBind, error)
 	OnBindsForApp                func(ctx context.Context, v *Volume, appName string) ([]VolumeBind, error)
+	OnBindJob                    func(ctx context.Context, opts *BindOpts) error
+	OnUnbindJob                  func(ctx context.Context, opts *BindOpts) error
+	OnBindsForJob                func(ctx context.Context, v *Volume, jobName string) ([]VolumeBind, error)
 	OnListPlans                  func(ctx context.Context) (map[string][]VolumePlan, error)
 	OnCheckPoolVolumeConstraints func(ctx context.Context, volume Volume) error
 }
@@ -65,6 +69,13 @@ func (m *MockVolumeService) ListByApp(ctx context.Context, appName string) ([]Vo
 	return nil, nil
 }
 
+func (m *MockVolumeService) ListByJob(ctx context.Context, jobName string) ([]Volume, error) {
+	if m.OnListByJob != nil {
+		return m.OnListByJob(ctx, jobName)
+	}
+	return nil, nil
+}
+
 func (m *MockVolumeService) ListByFilter(ctx context.Context, f *Filter) ([]Volume, error) {
 	if m.OnListByFilter != nil {
 		return m.OnListByFilter(ctx, f)
@@ -107,6 +118,27 @@ func (m *MockVolumeService) BindsForApp(ctx context.Context, v *Volume, appName
 	return nil, nil
 }
 
+func (m *MockVolumeService) BindJob(ctx context.Context, opts *BindOpts) error {
+	if m.OnBindJob != nil {
+		return m.OnBindJob(ctx, opts)
+	}
+	return nil
+}
+
+func (m *MockVolumeService) UnbindJob(ctx context.Context, opts *BindOpts) error {
+	if m.OnUnbindJob != nil {
+		return m.OnUnbindJob(ctx, opts)
+	}
+	return nil
+}
+
+func (m *MockVolumeService) BindsForJob(ctx context.Context, v *Volume, jobName string) ([]VolumeBind, error) {
+	if m.OnBindsForJob != nil {
+		return m.OnBindsForJob(ctx, v, jobName)
+	}
+	return nil, nil
+}
+
 func (m *MockVolumeService) ListPlans(ctx context.Context) (map[string][]VolumePlan, error) {
 	if m.OnListPlans != nil {
 		return m.OnListPlans(ctx)
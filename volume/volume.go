@@ -91,6 +91,18 @@ func (s *volumeService) ListByApp(ctx context.Context, appName string) ([]volume
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return s.volumesForBinds(ctx, binds)
+}
+
+func (s *volumeService) ListByJob(ctx context.Context, jobName string) ([]volumeTypes.Volume, error) {
+	binds, err := s.storage.BindsForJob(ctx, "", jobName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return s.volumesForBinds(ctx, binds)
+}
+
+func (s *volumeService) volumesForBinds(ctx context.Context, binds []volumeTypes.VolumeBind) ([]volumeTypes.Volume, error) {
 	if len(binds) == 0 {
 		return []volumeTypes.Volume{}, nil
 	}
@@ -174,6 +186,61 @@ func (s *volumeService) UnbindApp(ctx context.Context, opts *volumeTypes.BindOpt
 	})
 }
 
+func (s *volumeService) BindJob(ctx context.Context, opts *volumeTypes.BindOpts) error {
+	job, err := servicemanager.Job.GetByName(ctx, opts.JobName)
+	if err != nil {
+		return err
+	}
+	if job.Pool != opts.Volume.Pool {
+		return &tsuruErrors.ValidationError{
+			Message: fmt.Sprintf("volume %q and job %q must be in the same pool, volume is in %q and job is in %q", opts.Volume.Name, job.Name, opts.Volume.Pool, job.Pool),
+		}
+	}
+	err = s.CheckPoolVolumeConstraints(ctx, *opts.Volume)
+	if err != nil {
+		return err
+	}
+	bind := &volumeTypes.VolumeBind{
+		ID: volumeTypes.VolumeBindID{
+			Job:        opts.JobName,
+			MountPoint: opts.MountPoint,
+			Volume:     opts.Volume.Name,
+		},
+		ReadOnly: opts.ReadOnly,
+	}
+	err = s.storage.InsertBind(ctx, bind)
+	if err == volumeTypes.ErrVolumeBindAlreadyExists {
+		return volumeTypes.ErrVolumeAlreadyBound
+	}
+	return err
+}
+
+func (s *volumeService) UnbindJob(ctx context.Context, opts *volumeTypes.BindOpts) error {
+	return s.storage.RemoveBind(ctx, volumeTypes.VolumeBindID{
+		Job:        opts.JobName,
+		Volume:     opts.Volume.Name,
+		MountPoint: opts.MountPoint,
+	})
+}
+
+func (s *volumeService) BindsForJob(ctx context.Context, v *volumeTypes.Volume, jobName string) ([]volumeTypes.VolumeBind, error) {
+	if v != nil && v.Binds != nil {
+		binds := []volumeTypes.VolumeBind{}
+		for _, bind := range v.Binds {
+			if bind.ID.Job == jobName {
+				binds = append(binds, bind)
+			}
+		}
+		return binds, nil
+	}
+
+	var volumeName string
+	if v != nil {
+		volumeName = v.Name
+	}
+	return s.storage.BindsForJob(ctx, volumeName, jobName)
+}
+
 func (s *volumeService) Binds(ctx context.Context, v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error) {
 	if v.Binds != nil {
 		return v.Binds, nil
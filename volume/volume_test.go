@@ -20,6 +20,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 )
 
@@ -584,6 +585,142 @@ func TestListByApp(t *testing.T) {
 	require.Len(t, appVolumes, 0)
 }
 
+func setupJobService(jobs ...jobTypes.Job) {
+	servicemanager.Job = &jobTypes.MockJobService{
+		OnGetByName: func(name string) (*jobTypes.Job, error) {
+			for _, j := range jobs {
+				if j.Name == name {
+					return &j, nil
+				}
+			}
+			return nil, jobTypes.ErrJobNotFound
+		},
+	}
+}
+
+func TestVolumeBindJob(t *testing.T) {
+	setupTest(t)
+	setupJobService(jobTypes.Job{Name: "myjob", Pool: "mypool"})
+	err := pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: "mypool", Field: pool.ConstraintTypeVolumePlan, Values: []string{"p1"}})
+	require.NoError(t, err)
+	volumeService := &volumeService{storage: &volumeTypes.MockVolumeStorage{}}
+	vol := volumeTypes.Volume{
+		Name:      "v1",
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "mypool",
+		TeamOwner: "myteam",
+	}
+	err = volumeService.Create(context.TODO(), &vol)
+	require.NoError(t, err)
+	err = volumeService.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+		ReadOnly:   true,
+	})
+	require.NoError(t, err)
+	err = volumeService.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	require.ErrorIs(t, err, volumeTypes.ErrVolumeAlreadyBound)
+	expected := []volumeTypes.VolumeBind{{ID: volumeTypes.VolumeBindID{Job: "myjob", MountPoint: "/mnt1", Volume: "v1"}, ReadOnly: true}}
+	binds, err := volumeService.BindsForJob(context.TODO(), &vol, "myjob")
+	require.NoError(t, err)
+	require.EqualValues(t, expected, binds)
+	binds, err = volumeService.BindsForApp(context.TODO(), &vol, "myjob")
+	require.NoError(t, err)
+	require.Len(t, binds, 0)
+	jobVolumes, err := volumeService.ListByJob(context.TODO(), "myjob")
+	require.NoError(t, err)
+	require.Len(t, jobVolumes, 1)
+	require.Equal(t, "v1", jobVolumes[0].Name)
+}
+
+func TestVolumeBindJobConstraints(t *testing.T) {
+	setupTest(t)
+	setupJobService(jobTypes.Job{Name: "myjob", Pool: "mypool"}, jobTypes.Job{Name: "otherjob", Pool: "otherpool"})
+	volumeService := &volumeService{storage: &volumeTypes.MockVolumeStorage{}}
+	vol := volumeTypes.Volume{
+		Name:      "v1",
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "mypool",
+		TeamOwner: "myteam",
+	}
+	err := volumeService.Create(context.TODO(), &vol)
+	require.NoError(t, err)
+	err = volumeService.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "otherjob",
+		MountPoint: "/mnt1",
+	})
+	require.ErrorContains(t, err, `volume "v1" and job "otherjob" must be in the same pool`)
+	var validationErr *tsuruErrors.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	err = pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: "mypool", Field: pool.ConstraintTypeVolumePlan, Values: []string{"p2"}})
+	require.NoError(t, err)
+	err = volumeService.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	require.ErrorIs(t, err, volumeTypes.ErrVolumePlanNotFound)
+	err = volumeService.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "unknown",
+		MountPoint: "/mnt1",
+	})
+	require.ErrorIs(t, err, jobTypes.ErrJobNotFound)
+	binds, err := volumeService.Binds(context.TODO(), &vol)
+	require.NoError(t, err)
+	require.Len(t, binds, 0)
+}
+
+func TestVolumeUnbindJob(t *testing.T) {
+	setupTest(t)
+	setupJobService(jobTypes.Job{Name: "myjob", Pool: "mypool"})
+	err := pool.SetPoolConstraint(context.TODO(), &pool.PoolConstraint{PoolExpr: "mypool", Field: pool.ConstraintTypeVolumePlan, Values: []string{"p1"}})
+	require.NoError(t, err)
+	volumeService := &volumeService{storage: &volumeTypes.MockVolumeStorage{}}
+	vol := volumeTypes.Volume{
+		Name:      "v1",
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "mypool",
+		TeamOwner: "myteam",
+	}
+	err = volumeService.Create(context.TODO(), &vol)
+	require.NoError(t, err)
+	err = volumeService.BindApp(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		AppName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	require.NoError(t, err)
+	err = volumeService.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	require.NoError(t, err)
+	err = volumeService.UnbindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	require.NoError(t, err)
+	binds, err := volumeService.Binds(context.TODO(), &vol)
+	require.NoError(t, err)
+	expected := []volumeTypes.VolumeBind{{ID: volumeTypes.VolumeBindID{App: "myjob", MountPoint: "/mnt1", Volume: "v1"}}}
+	require.EqualValues(t, expected, binds)
+	err = volumeService.UnbindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &vol,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	require.ErrorIs(t, err, volumeTypes.ErrVolumeBindNotFound)
+}
+
 func TestVolumeDelete(t *testing.T) {
 	setupTest(t)
 	volumeService := &volumeService{storage: &volumeTypes.MockVolumeStorage{}}